@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"merchant_back/internal/config"
+	"merchant_back/internal/migrations"
+	"merchant_back/internal/storage"
 
 	"gorm.io/gorm"
 )
@@ -20,6 +22,15 @@ func InitDB() {
 	}
 
 	DB = db
+
+	// 执行数据迁移（商家图片转存需要媒体存储）
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatal("Failed to init media storage: ", err)
+	}
+	if err := migrations.Run(DB, store); err != nil {
+		log.Fatal("Failed to run migrations: ", err)
+	}
 }
 
 func CloseDB() {
@@ -3,7 +3,9 @@ package main
 import (
 	"log"
 	"merchant_back/internal/config"
+	"merchant_back/internal/jobs"
 	"merchant_back/internal/routes"
+	_ "time/tzdata" // 内置时区数据，保证商家时区可解析
 
 	_ "merchant_back/docs" // docs is generated by Swag CLI
 
@@ -51,8 +53,15 @@ func main() {
 	InitDB()
 	defer CloseDB()
 
-	// 使用路由配置，SetupRoutes 函数内部会创建所需的服务和控制器
-	r := routes.SetupRoutes(DB)
+	// 使用路由配置，SetupRoutes 函数内部会创建所需的服务和控制器，并注册后台任务
+	scheduler := jobs.NewScheduler()
+	r := routes.SetupRoutes(DB, cfg, scheduler)
+
+	// 启动后台任务
+	if cfg.Jobs.Enabled {
+		scheduler.Start()
+		defer scheduler.Stop()
+	}
 
 	// 添加 Swagger 路由
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -0,0 +1,195 @@
+package common
+
+import (
+	"regexp"
+	"strings"
+)
+
+// AddressParts 结构化地址
+type AddressParts struct {
+	Country    string
+	Province   string
+	City       string
+	District   string
+	Street     string
+	PostalCode string
+}
+
+var (
+	// 日本邮编：〒150-0043 / 150-0043
+	jpPostalPattern = regexp.MustCompile(`〒?\s*(\d{3}-\d{4})`)
+	// 中国邮编：6位数字
+	cnPostalPattern = regexp.MustCompile(`(?:^|[^\d-])(\d{6})(?:$|[^\d-])`)
+
+	// 日文地址：東京都渋谷区道玄坂1-2-3
+	jpAddressPattern = regexp.MustCompile(`^(東京都|北海道|京都府|大阪府|[^\s都道府県]{2,3}県)([^\s]+?(?:市|郡))?([^\s]+?(?:区|町|村))?(.*)$`)
+	// 中文地址：广东省深圳市南山区科技园路1号
+	cnAddressPattern = regexp.MustCompile(`^([^\s]+?(?:省|自治区|特别行政区))?([^\s]+?(?:市|自治州|地区|盟))?([^\s]+?(?:区|县|旗|市))?(.*)$`)
+	// 中国直辖市
+	cnMunicipalities = []string{"北京市", "上海市", "天津市", "重庆市"}
+)
+
+// 日本都道府县（罗马字）
+var jpPrefectures = map[string]bool{
+	"hokkaido": true, "aomori": true, "iwate": true, "miyagi": true, "akita": true, "yamagata": true,
+	"fukushima": true, "ibaraki": true, "tochigi": true, "gunma": true, "saitama": true, "chiba": true,
+	"tokyo": true, "kanagawa": true, "niigata": true, "toyama": true, "ishikawa": true, "fukui": true,
+	"yamanashi": true, "nagano": true, "gifu": true, "shizuoka": true, "aichi": true, "mie": true,
+	"shiga": true, "kyoto": true, "osaka": true, "hyogo": true, "nara": true, "wakayama": true,
+	"tottori": true, "shimane": true, "okayama": true, "hiroshima": true, "yamaguchi": true, "tokushima": true,
+	"kagawa": true, "ehime": true, "kochi": true, "fukuoka": true, "saga": true, "nagasaki": true,
+	"kumamoto": true, "oita": true, "miyazaki": true, "kagoshima": true, "okinawa": true,
+}
+
+// 日本主要城市所属都道府县（罗马字）
+var jpCityPrefectures = map[string]string{
+	"sapporo": "Hokkaido", "sendai": "Miyagi", "yokohama": "Kanagawa", "kawasaki": "Kanagawa",
+	"nagoya": "Aichi", "kobe": "Hyogo", "kitakyushu": "Fukuoka", "hamamatsu": "Shizuoka", "sakai": "Osaka",
+}
+
+// ParseAddress 尽力将地址字符串解析为结构化地址，无法识别的部分留空
+func ParseAddress(address string) AddressParts {
+	var parts AddressParts
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return parts
+	}
+
+	// 提取邮编
+	if m := jpPostalPattern.FindStringSubmatchIndex(address); m != nil {
+		parts.PostalCode = address[m[2]:m[3]]
+		parts.Country = "JP"
+		address = strings.TrimSpace(address[:m[0]] + " " + address[m[1]:])
+	} else if m := cnPostalPattern.FindStringSubmatchIndex(address); m != nil {
+		parts.PostalCode = address[m[2]:m[3]]
+		parts.Country = "CN"
+		address = strings.TrimSpace(address[:m[2]] + " " + address[m[3]:])
+	}
+	address = strings.Trim(address, " ,，、")
+
+	switch {
+	case strings.ContainsAny(address, "都道府県") && jpAddressPattern.MatchString(address):
+		m := jpAddressPattern.FindStringSubmatch(address)
+		parts.Country = "JP"
+		parts.Province = m[1]
+		parts.City = m[2]
+		parts.District = m[3]
+		parts.Street = strings.TrimSpace(m[4])
+		// 东京23区等直属于都道府县的区
+		if parts.City == "" && parts.District != "" {
+			parts.City, parts.District = parts.District, ""
+		}
+	case containsHan(address) && !strings.Contains(address, ","):
+		parseChineseAddress(address, &parts)
+	default:
+		parseLatinAddress(address, &parts)
+	}
+
+	return parts
+}
+
+// parseChineseAddress 解析中文地址
+func parseChineseAddress(address string, parts *AddressParts) {
+	for _, municipality := range cnMunicipalities {
+		if strings.HasPrefix(address, municipality) {
+			parts.Country = "CN"
+			parts.Province = municipality
+			parts.City = municipality
+			rest := strings.TrimPrefix(address, municipality)
+			m := cnAddressPattern.FindStringSubmatch(rest)
+			parts.District = m[3]
+			parts.Street = strings.TrimSpace(m[4])
+			return
+		}
+	}
+
+	m := cnAddressPattern.FindStringSubmatch(address)
+	if m[1] == "" && m[2] == "" && m[3] == "" {
+		parts.Street = address
+		return
+	}
+	parts.Country = "CN"
+	parts.Province = m[1]
+	parts.City = m[2]
+	parts.District = m[3]
+	parts.Street = strings.TrimSpace(m[4])
+}
+
+// parseLatinAddress 解析逗号分隔的拉丁字母地址，如 "Tokyo, Shibuya 1-2-3"
+func parseLatinAddress(address string, parts *AddressParts) {
+	segments := strings.Split(address, ",")
+	for i := range segments {
+		segments[i] = strings.TrimSpace(segments[i])
+	}
+	if len(segments) < 2 {
+		parts.Street = address
+		return
+	}
+
+	// 末尾为国家名
+	last := strings.ToLower(segments[len(segments)-1])
+	switch last {
+	case "japan", "jp", "日本":
+		parts.Country = "JP"
+		segments = segments[:len(segments)-1]
+	case "china", "cn", "中国":
+		parts.Country = "CN"
+		segments = segments[:len(segments)-1]
+	}
+	if len(segments) == 0 {
+		return
+	}
+
+	// 第一段为都道府县或城市
+	head := segments[0]
+	key := strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(head, "-ken"), "-fu"))
+	if jpPrefectures[key] {
+		parts.Country = "JP"
+		parts.Province = head
+		parts.City = head
+	} else if prefecture, ok := jpCityPrefectures[key]; ok {
+		parts.Country = "JP"
+		parts.Province = prefecture
+		parts.City = head
+	} else {
+		parts.City = head
+	}
+
+	// 剩余部分为街道，首个非数字词视为区
+	rest := strings.Join(segments[1:], ", ")
+	parts.Street = rest
+	if fields := strings.Fields(rest); len(fields) > 1 && !startsWithDigit(fields[0]) {
+		parts.District = strings.TrimSuffix(fields[0], ",")
+		parts.Street = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+	}
+}
+
+// FormatAddress 将结构化地址拼接为展示用字符串
+func FormatAddress(parts AddressParts) string {
+	var segments []string
+	for _, s := range []string{parts.Province, parts.City, parts.District, parts.Street} {
+		if s != "" && (len(segments) == 0 || segments[len(segments)-1] != s) {
+			segments = append(segments, s)
+		}
+	}
+	address := strings.Join(segments, " ")
+	if parts.PostalCode != "" {
+		address = strings.TrimSpace(parts.PostalCode + " " + address)
+	}
+	return address
+}
+
+// containsHan 检查字符串是否包含汉字
+func containsHan(s string) bool {
+	for _, r := range s {
+		if r >= 0x4E00 && r <= 0x9FFF {
+			return true
+		}
+	}
+	return false
+}
+
+// startsWithDigit 检查字符串是否以数字开头
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
@@ -0,0 +1,302 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 自定义属性支持的类型
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeInteger = "integer"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeArray   = "array" // 元素为 string/integer/number
+)
+
+// 自定义属性限制
+const (
+	maxAttributeProperties  = 50   // 每个分类最多定义的属性数
+	maxAttributeStringBytes = 1000 // 字符串属性值的最大长度（字节）
+	maxAttributeArrayItems  = 50   // 数组属性最多的元素数
+)
+
+// AttributeNamePattern 属性名格式，属性名会出现在列表查询参数中（attributes.属性名）
+var AttributeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// AttributeSchema 分类自定义属性定义，为 JSON Schema 的子集：
+// 根为 object，properties 定义各属性，required 列出必填属性，不允许未定义的属性
+type AttributeSchema struct {
+	Type       string                        `json:"type"`
+	Properties map[string]*AttributeProperty `json:"properties"`
+	Required   []string                      `json:"required,omitempty"`
+}
+
+// AttributeProperty 单个属性的定义
+type AttributeProperty struct {
+	Type        string             `json:"type"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Items       *AttributeProperty `json:"items,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	UniqueItems bool               `json:"uniqueItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ParseAttributeSchema 解析并校验属性定义，不支持的关键字视为错误，避免定义被静默忽略
+func ParseAttributeSchema(raw []byte) (*AttributeSchema, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.DisallowUnknownFields()
+	var schema AttributeSchema
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, errors.New("属性定义格式错误: " + err.Error())
+	}
+
+	if schema.Type != "object" {
+		return nil, errors.New(`属性定义的 type 必须为 "object"`)
+	}
+	if len(schema.Properties) > maxAttributeProperties {
+		return nil, fmt.Errorf("最多定义%d个属性", maxAttributeProperties)
+	}
+	for name, prop := range schema.Properties {
+		if !AttributeNamePattern.MatchString(name) {
+			return nil, errors.New("属性名 " + name + " 无效：须以字母开头，只能包含字母、数字和下划线，不超过64个字符")
+		}
+		if prop == nil {
+			return nil, errors.New("属性 " + name + " 缺少定义")
+		}
+		if err := prop.compile(name, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; !ok {
+			return nil, errors.New("必填属性 " + name + " 未定义")
+		}
+	}
+	return &schema, nil
+}
+
+// compile 校验属性定义并预编译正则
+func (p *AttributeProperty) compile(name string, item bool) error {
+	switch p.Type {
+	case AttributeTypeString, AttributeTypeInteger, AttributeTypeNumber:
+	case AttributeTypeBoolean, AttributeTypeArray:
+		if item {
+			return errors.New("属性 " + name + " 的数组元素只能是 string、integer 或 number")
+		}
+	default:
+		return errors.New("属性 " + name + " 的类型无效，支持 string、integer、number、boolean、array")
+	}
+
+	if p.Type == AttributeTypeArray {
+		if p.Items == nil {
+			return errors.New("数组属性 " + name + " 缺少 items 定义")
+		}
+		if err := p.Items.compile(name, true); err != nil {
+			return err
+		}
+	} else if p.Items != nil || p.MinItems != nil || p.MaxItems != nil || p.UniqueItems {
+		return errors.New("属性 " + name + " 不是数组，不能定义 items/minItems/maxItems/uniqueItems")
+	}
+	if p.Type != AttributeTypeString && (p.MinLength != nil || p.MaxLength != nil || p.Pattern != "") {
+		return errors.New("属性 " + name + " 不是字符串，不能定义 minLength/maxLength/pattern")
+	}
+	if p.Type != AttributeTypeInteger && p.Type != AttributeTypeNumber && (p.Minimum != nil || p.Maximum != nil) {
+		return errors.New("属性 " + name + " 不是数值，不能定义 minimum/maximum")
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return errors.New("属性 " + name + " 的 pattern 无效: " + err.Error())
+		}
+		p.pattern = re
+	}
+	for _, v := range p.Enum {
+		if err := p.checkScalar(name, v); err != nil {
+			return errors.New("属性 " + name + " 的 enum 取值与类型不符")
+		}
+	}
+	return nil
+}
+
+// MergeAttributeSchemas 合并多个分类的属性定义，同名属性以先出现的为准
+func MergeAttributeSchemas(schemas ...*AttributeSchema) *AttributeSchema {
+	merged := &AttributeSchema{Type: "object", Properties: map[string]*AttributeProperty{}}
+	required := map[string]bool{}
+	for _, schema := range schemas {
+		if schema == nil {
+			continue
+		}
+		for name, prop := range schema.Properties {
+			if _, ok := merged.Properties[name]; !ok {
+				merged.Properties[name] = prop
+			}
+		}
+		for _, name := range schema.Required {
+			if !required[name] {
+				required[name] = true
+				merged.Required = append(merged.Required, name)
+			}
+		}
+	}
+	return merged
+}
+
+// Validate 校验属性值，值为 null 的属性视为未填写并移除，返回的错误信息指明属性名
+func (s *AttributeSchema) Validate(values map[string]interface{}) error {
+	for _, name := range s.Required {
+		if v, ok := values[name]; !ok || v == nil {
+			return errors.New("属性 " + name + " 为必填项")
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		if !ok {
+			return errors.New("属性 " + name + " 未在商家所属分类中定义")
+		}
+		if values[name] == nil {
+			delete(values, name)
+			continue
+		}
+		v, err := prop.validate(name, values[name])
+		if err != nil {
+			return err
+		}
+		values[name] = v
+	}
+	return nil
+}
+
+// validate 校验单个属性值
+func (p *AttributeProperty) validate(name string, v interface{}) (interface{}, error) {
+	if p.Type != AttributeTypeArray {
+		return p.validateScalar(name, v)
+	}
+
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("属性 " + name + " 必须是数组")
+	}
+	if len(items) > maxAttributeArrayItems {
+		return nil, fmt.Errorf("属性 %s 最多%d个元素", name, maxAttributeArrayItems)
+	}
+	if p.MinItems != nil && len(items) < *p.MinItems {
+		return nil, fmt.Errorf("属性 %s 至少%d个元素", name, *p.MinItems)
+	}
+	if p.MaxItems != nil && len(items) > *p.MaxItems {
+		return nil, fmt.Errorf("属性 %s 最多%d个元素", name, *p.MaxItems)
+	}
+	normalized := make([]interface{}, len(items))
+	for i, item := range items {
+		value, err := p.Items.validateScalar(name, item)
+		if err != nil {
+			return nil, err
+		}
+		if p.UniqueItems {
+			for _, prev := range normalized[:i] {
+				if reflect.DeepEqual(prev, value) {
+					return nil, errors.New("属性 " + name + " 的元素不能重复")
+				}
+			}
+		}
+		normalized[i] = value
+	}
+	return normalized, nil
+}
+
+// validateScalar 校验标量值的类型与约束
+func (p *AttributeProperty) validateScalar(name string, v interface{}) (interface{}, error) {
+	if err := p.checkScalar(name, v); err != nil {
+		return nil, err
+	}
+
+	switch p.Type {
+	case AttributeTypeString:
+		s := v.(string)
+		if len(s) > maxAttributeStringBytes {
+			return nil, fmt.Errorf("属性 %s 不能超过%d个字节", name, maxAttributeStringBytes)
+		}
+		n := utf8.RuneCountInString(s)
+		if p.MinLength != nil && n < *p.MinLength {
+			return nil, fmt.Errorf("属性 %s 至少%d个字符", name, *p.MinLength)
+		}
+		if p.MaxLength != nil && n > *p.MaxLength {
+			return nil, fmt.Errorf("属性 %s 不能超过%d个字符", name, *p.MaxLength)
+		}
+		if p.pattern != nil && !p.pattern.MatchString(s) {
+			return nil, errors.New("属性 " + name + " 格式不正确")
+		}
+	case AttributeTypeInteger, AttributeTypeNumber:
+		f := v.(float64)
+		if p.Minimum != nil && f < *p.Minimum {
+			return nil, fmt.Errorf("属性 %s 不能小于%v", name, *p.Minimum)
+		}
+		if p.Maximum != nil && f > *p.Maximum {
+			return nil, fmt.Errorf("属性 %s 不能大于%v", name, *p.Maximum)
+		}
+	}
+
+	if len(p.Enum) > 0 {
+		matched := false
+		for _, option := range p.Enum {
+			if fmt.Sprint(option) == fmt.Sprint(v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, errors.New("属性 " + name + " 的取值不在可选范围内")
+		}
+	}
+	return v, nil
+}
+
+// checkScalar 检查 JSON 解码后的值是否符合属性类型
+func (p *AttributeProperty) checkScalar(name string, v interface{}) error {
+	ok := false
+	switch p.Type {
+	case AttributeTypeString:
+		_, ok = v.(string)
+	case AttributeTypeBoolean:
+		_, ok = v.(bool)
+	case AttributeTypeNumber:
+		_, ok = v.(float64)
+	case AttributeTypeInteger:
+		f, isNumber := v.(float64)
+		ok = isNumber && f == math.Trunc(f) && math.Abs(f) <= 1<<53
+	}
+	if !ok {
+		return errors.New("属性 " + name + " 必须是 " + p.Type + " 类型")
+	}
+	return nil
+}
+
+// IsNumeric 属性（或数组元素）是否为数值类型，决定按数值还是文本筛选与排序
+func (p *AttributeProperty) IsNumeric() bool {
+	if p.Type == AttributeTypeArray {
+		return p.Items.IsNumeric()
+	}
+	return p.Type == AttributeTypeInteger || p.Type == AttributeTypeNumber
+}
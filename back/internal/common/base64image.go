@@ -0,0 +1,34 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// DecodeBase64Image 解码 base64 图片，兼容 data URI 前缀（data:image/png;base64,）、换行及无填充的编码
+func DecodeBase64Image(data string) ([]byte, error) {
+	data = strings.TrimSpace(data)
+	if strings.HasPrefix(data, "data:") {
+		i := strings.Index(data, ",")
+		if i < 0 || !strings.HasSuffix(data[:i], ";base64") {
+			return nil, errors.New("图片 data URI 格式不正确")
+		}
+		data = data[i+1:]
+	}
+	data = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, data)
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "="))
+	}
+	if err != nil {
+		return nil, errors.New("图片 base64 编码不正确")
+	}
+	return decoded, nil
+}
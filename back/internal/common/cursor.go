@@ -0,0 +1,62 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+var cursorSecret = []byte(getCursorSecret())
+
+// getCursorSecret 获取游标签名密钥，未单独配置时沿用 JWT 密钥
+func getCursorSecret() string {
+	if secret := os.Getenv("LIST_CURSOR_SECRET"); secret != "" {
+		return secret
+	}
+	return string(JwtSecret)
+}
+
+// ListCursor 游标分页的位置，客户端只拿到签名后的不透明字符串
+type ListCursor struct {
+	Scope  string        `json:"c"`           // 列表标识，防止游标跨列表使用
+	Sort   string        `json:"s"`           // 排序签名，排序条件变化后游标失效
+	Values []interface{} `json:"v"`           // 边界记录的排序字段值（最后一个为 id）
+	Before bool          `json:"b,omitempty"` // true 表示取边界之前的一页
+}
+
+// EncodeListCursor 序列化并签名游标
+func EncodeListCursor(cursor *ListCursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signCursor(encoded), nil
+}
+
+// DecodeListCursor 校验签名并解析游标
+func DecodeListCursor(token string) (*ListCursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signCursor(encoded))) {
+		return nil, InvalidListQuery("游标无效")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, InvalidListQuery("游标无效")
+	}
+	var cursor ListCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, InvalidListQuery("游标无效")
+	}
+	return &cursor, nil
+}
+
+// signCursor 计算游标签名（HMAC-SHA256）
+func signCursor(encoded string) string {
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// fieldCipherVersion 密文前缀，便于日后更换算法或轮换密钥
+const fieldCipherVersion = "v1:"
+
+// FieldCipher 字段级加密（AES-256-GCM）。密文格式为 v1: + base64(nonce || 密文)，
+// 加密时的附加数据（如记录所属的商家）必须在解密时一致，防止密文被挪用到其他记录
+type FieldCipher struct {
+	aead cipher.AEAD
+}
+
+// ParseFieldKey 解析 base64 编码的 32 字节密钥
+func ParseFieldKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("加密密钥不是有效的 base64 编码")
+	}
+	if len(key) != 32 {
+		return nil, errors.New("加密密钥长度必须为 32 字节")
+	}
+	return key, nil
+}
+
+// NewFieldCipher 使用 32 字节密钥创建字段加密器
+func NewFieldCipher(key []byte) (*FieldCipher, error) {
+	if len(key) != 32 {
+		return nil, errors.New("加密密钥长度必须为 32 字节")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldCipher{aead: aead}, nil
+}
+
+// Encrypt 加密字段值
+func (c *FieldCipher) Encrypt(plaintext, associated string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(associated))
+	return fieldCipherVersion + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密字段值
+func (c *FieldCipher) Decrypt(ciphertext, associated string) (string, error) {
+	if !strings.HasPrefix(ciphertext, fieldCipherVersion) {
+		return "", errors.New("不支持的密文格式")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, fieldCipherVersion))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("密文已损坏")
+	}
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, []byte(associated))
+	if err != nil {
+		return "", errors.New("解密失败：密钥不匹配或密文已损坏")
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,407 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 补丁格式（请求的 Content-Type）
+const (
+	PatchTypeMerge = "application/merge-patch+json" // RFC 7396 JSON Merge Patch
+	PatchTypeJSON  = "application/json-patch+json"  // RFC 6902 JSON Patch
+)
+
+// ErrUnsupportedPatchType 不支持的补丁格式
+var ErrUnsupportedPatchType = errors.New("不支持的补丁格式，请使用 " + PatchTypeMerge + " 或 " + PatchTypeJSON)
+
+// jsonPatchOperation RFC 6902 补丁操作，value 保留原始 JSON 以区分缺失与 null
+type jsonPatchOperation map[string]json.RawMessage
+
+// ApplyPatch 按补丁格式对 JSON 对象文档应用补丁，返回新文档与被修改的顶层字段（按字母排序）
+func ApplyPatch(patchType string, doc, patch []byte) ([]byte, []string, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, nil, err
+	}
+
+	var result interface{}
+	var touched []string
+	var err error
+	switch patchType {
+	case PatchTypeMerge:
+		result, touched, err = applyMergePatch(target, patch)
+	case PatchTypeJSON:
+		result, touched, err = applyJSONPatch(target, patch)
+	default:
+		return nil, nil, ErrUnsupportedPatchType
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := result.(map[string]interface{}); !ok {
+		return nil, nil, errors.New("补丁结果必须是 JSON 对象")
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, touched, nil
+}
+
+// applyMergePatch 应用 RFC 7396 合并补丁，null 表示删除字段
+func applyMergePatch(target interface{}, patch []byte) (interface{}, []string, error) {
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, nil, errors.New("补丁不是有效的 JSON: " + err.Error())
+	}
+	fields, ok := p.(map[string]interface{})
+	if !ok {
+		return nil, nil, errors.New("合并补丁必须是 JSON 对象")
+	}
+
+	touched := make([]string, 0, len(fields))
+	for k := range fields {
+		touched = append(touched, k)
+	}
+	sort.Strings(touched)
+
+	return mergePatch(target, p), touched, nil
+}
+
+// mergePatch RFC 7396 合并算法
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
+
+// applyJSONPatch 按顺序应用 RFC 6902 补丁操作，任一操作失败则整体失败
+func applyJSONPatch(target interface{}, patch []byte) (interface{}, []string, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, nil, errors.New("JSON Patch 必须是操作数组: " + err.Error())
+	}
+
+	touchedSet := map[string]bool{}
+	doc := target
+	for i, op := range ops {
+		prefix := "第" + strconv.Itoa(i+1) + "个补丁操作："
+
+		name, err := op.stringField("op")
+		if err != nil {
+			return nil, nil, errors.New(prefix + err.Error())
+		}
+		path, err := op.pointerField("path")
+		if err != nil {
+			return nil, nil, errors.New(prefix + err.Error())
+		}
+		if len(path) == 0 {
+			return nil, nil, errors.New(prefix + "不允许替换整个文档")
+		}
+
+		switch name {
+		case "add", "replace", "test":
+			value, err := op.valueField()
+			if err != nil {
+				return nil, nil, errors.New(prefix + err.Error())
+			}
+			switch name {
+			case "add":
+				doc, err = addValue(doc, path, value, false)
+			case "replace":
+				doc, err = addValue(doc, path, value, true)
+			case "test":
+				var current interface{}
+				current, err = getValue(doc, path)
+				if err == nil && !reflect.DeepEqual(current, value) {
+					err = errors.New("test 操作的值不匹配")
+				}
+			}
+			if err != nil {
+				return nil, nil, errors.New(prefix + err.Error())
+			}
+		case "remove":
+			if doc, err = removeValue(doc, path); err != nil {
+				return nil, nil, errors.New(prefix + err.Error())
+			}
+		case "move", "copy":
+			from, err := op.pointerField("from")
+			if err != nil {
+				return nil, nil, errors.New(prefix + err.Error())
+			}
+			value, err := getValue(doc, from)
+			if err != nil {
+				return nil, nil, errors.New(prefix + err.Error())
+			}
+			if name == "move" {
+				if isPointerPrefix(from, path) && len(from) < len(path) {
+					return nil, nil, errors.New(prefix + "不能移动到自身的子路径")
+				}
+				if doc, err = removeValue(doc, from); err != nil {
+					return nil, nil, errors.New(prefix + err.Error())
+				}
+				if len(from) > 0 {
+					touchedSet[from[0]] = true
+				}
+			} else {
+				value = deepCopy(value)
+			}
+			if doc, err = addValue(doc, path, value, false); err != nil {
+				return nil, nil, errors.New(prefix + err.Error())
+			}
+		default:
+			return nil, nil, errors.New(prefix + "不支持的操作: " + name)
+		}
+
+		if name != "test" {
+			touchedSet[path[0]] = true
+		}
+	}
+
+	touched := make([]string, 0, len(touchedSet))
+	for k := range touchedSet {
+		touched = append(touched, k)
+	}
+	sort.Strings(touched)
+	return doc, touched, nil
+}
+
+// stringField 读取操作中的字符串字段
+func (op jsonPatchOperation) stringField(name string) (string, error) {
+	raw, ok := op[name]
+	if !ok {
+		return "", errors.New("缺少 " + name + " 字段")
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", errors.New(name + " 字段必须是字符串")
+	}
+	return value, nil
+}
+
+// pointerField 读取并解析操作中的 JSON Pointer 字段
+func (op jsonPatchOperation) pointerField(name string) ([]string, error) {
+	value, err := op.stringField(name)
+	if err != nil {
+		return nil, err
+	}
+	return parsePointer(value)
+}
+
+// valueField 读取操作中的 value 字段（允许为 null）
+func (op jsonPatchOperation) valueField() (interface{}, error) {
+	raw, ok := op["value"]
+	if !ok {
+		return nil, errors.New("缺少 value 字段")
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parsePointer 解析 RFC 6901 JSON Pointer
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("路径必须以 / 开头: " + pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// isPointerPrefix 检查 prefix 是否为 path 的前缀
+func isPointerPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex 解析数组下标，max 为允许的最大下标
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, errors.New("无效的数组下标: " + token)
+	}
+	return index, nil
+}
+
+// getValue 获取路径上的值
+func getValue(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, errors.New("路径不存在: " + token)
+			}
+			node = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, errors.New("路径不存在: " + token)
+		}
+	}
+	return node, nil
+}
+
+// addValue 在路径上添加（replace 为 true 时替换已有）值，返回更新后的节点
+func addValue(node interface{}, path []string, value interface{}, replace bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token := path[0]
+	last := len(path) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if last {
+			if replace && !ok {
+				return nil, errors.New("路径不存在: " + token)
+			}
+			n[token] = value
+			return n, nil
+		}
+		if !ok {
+			return nil, errors.New("路径不存在: " + token)
+		}
+		updated, err := addValue(child, path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		if last {
+			if replace {
+				index, err := arrayIndex(token, len(n)-1)
+				if err != nil {
+					return nil, err
+				}
+				n[index] = value
+				return n, nil
+			}
+			if token == "-" {
+				return append(n, value), nil
+			}
+			index, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, err
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		index, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := addValue(n[index], path[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+	default:
+		return nil, errors.New("路径不存在: " + token)
+	}
+}
+
+// removeValue 删除路径上的值，返回更新后的节点
+func removeValue(node interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("不允许删除整个文档")
+	}
+	token := path[0]
+	last := len(path) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, errors.New("路径不存在: " + token)
+		}
+		if last {
+			delete(n, token)
+			return n, nil
+		}
+		updated, err := removeValue(child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			return append(n[:index], n[index+1:]...), nil
+		}
+		updated, err := removeValue(n[index], path[1:])
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+	default:
+		return nil, errors.New("路径不存在: " + token)
+	}
+}
+
+// deepCopy 深拷贝 JSON 值
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			m[k] = deepCopy(child)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, child := range v {
+			s[i] = deepCopy(child)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,289 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 列表筛选操作符
+const (
+	FilterOpEq      = "eq"      // 等于
+	FilterOpIn      = "in"      // 属于（逗号分隔多个值）
+	FilterOpGte     = "gte"     // 大于等于
+	FilterOpLte     = "lte"     // 小于等于
+	FilterOpLike    = "like"    // 模糊匹配（包含）
+	FilterOpBetween = "between" // 闭区间（逗号分隔两个值）
+)
+
+// 列表查询限制
+const (
+	DefaultListPageSize = 10
+	MaxListPageSize     = 100
+	maxListSortFields   = 5
+	maxListInValues     = 100
+)
+
+// ErrInvalidListQuery 列表查询参数无效，具体原因由包装的错误说明
+var ErrInvalidListQuery = errors.New("查询参数错误")
+
+// filterParamPattern 匹配 filter[字段] 与 filter[字段][操作符]，字段可带一级前缀（如 attributes.seats）
+var filterParamPattern = regexp.MustCompile(`^filter\[([A-Za-z][A-Za-z0-9]*(?:\.[A-Za-z][A-Za-z0-9_]*)?)\](?:\[([a-z]+)\])?$`)
+
+// ListFilter 单个筛选条件
+type ListFilter struct {
+	Field  string   // 字段名（JSON 字段名）
+	Op     string   // 操作符
+	Values []string // 取值，in 为多个，between 为两个，其余为一个
+}
+
+// ListSort 单个排序字段
+type ListSort struct {
+	Field string // 字段名（JSON 字段名）
+	Desc  bool   // 是否倒序
+}
+
+// ListQuery 统一的列表查询条件
+//
+// 查询参数格式：
+//
+//	filter[rating][gte]=4&filter[type][in]=restaurant,cafe&filter[name][like]=咖啡
+//	filter[status]=active            （省略操作符即为 eq）
+//	filter[createdAt][between]=2024-01-01,2024-06-30
+//	sort=-rating,name                 （- 表示倒序）
+//	fields=id,name,rating             （仅返回指定字段）
+//	page=1&pageSize=20                （偏移分页，不传则不分页）
+//	cursor=&pageSize=20               （游标分页，首页传空游标，之后传响应中的 nextCursor/prevCursor）
+type ListQuery struct {
+	Filters    []ListFilter
+	Sorts      []ListSort
+	Fields     []string
+	Page       int
+	PageSize   int         // 0 表示不分页
+	CursorMode bool        // 是否使用游标分页
+	Cursor     *ListCursor // 游标位置，游标分页的首页为 nil
+}
+
+// ListPageInfo 列表分页信息
+type ListPageInfo struct {
+	Total      int64  // 符合条件的总数
+	NextCursor string // 下一页游标，没有下一页时为空（仅游标分页）
+	PrevCursor string // 上一页游标，没有上一页时为空（仅游标分页）
+}
+
+// ParseListQuery 从查询参数解析列表查询条件，仅做语法校验，字段白名单由使用方校验
+func ParseListQuery(values url.Values) (*ListQuery, error) {
+	q := &ListQuery{}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+		m := filterParamPattern.FindStringSubmatch(key)
+		if m == nil {
+			return nil, InvalidListQuery("无效的筛选参数 " + key)
+		}
+		field, op := m[1], m[2]
+		if op == "" {
+			op = FilterOpEq
+		}
+		for _, raw := range values[key] {
+			filter, err := parseListFilter(field, op, raw)
+			if err != nil {
+				return nil, err
+			}
+			q.Filters = append(q.Filters, filter)
+		}
+	}
+
+	if raw := strings.TrimSpace(values.Get("sort")); raw != "" {
+		seen := map[string]bool{}
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			s := ListSort{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+			if s.Field == "" {
+				return nil, InvalidListQuery("排序字段不能为空")
+			}
+			if seen[s.Field] {
+				return nil, InvalidListQuery("排序字段 " + s.Field + " 重复")
+			}
+			seen[s.Field] = true
+			q.Sorts = append(q.Sorts, s)
+		}
+		if len(q.Sorts) > maxListSortFields {
+			return nil, InvalidListQuery("排序字段不能超过" + strconv.Itoa(maxListSortFields) + "个")
+		}
+	}
+
+	if raw := strings.TrimSpace(values.Get("fields")); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				q.Fields = append(q.Fields, field)
+			}
+		}
+	}
+
+	pageStr, pageSizeStr := values.Get("page"), values.Get("pageSize")
+	if _, ok := values["cursor"]; ok {
+		if pageStr != "" {
+			return nil, InvalidListQuery("cursor 与 page 不能同时使用")
+		}
+		q.CursorMode = true
+		if token := values.Get("cursor"); token != "" {
+			cursor, err := DecodeListCursor(token)
+			if err != nil {
+				return nil, err
+			}
+			q.Cursor = cursor
+		}
+	}
+	if pageStr != "" || pageSizeStr != "" || q.CursorMode {
+		q.Page = 1
+		q.PageSize = DefaultListPageSize
+		if pageStr != "" {
+			page, err := strconv.Atoi(pageStr)
+			if err != nil || page < 1 {
+				return nil, InvalidListQuery("页码必须是正整数")
+			}
+			q.Page = page
+		}
+		if pageSizeStr != "" {
+			pageSize, err := strconv.Atoi(pageSizeStr)
+			if err != nil || pageSize < 1 {
+				return nil, InvalidListQuery("每页数量必须是正整数")
+			}
+			if pageSize > MaxListPageSize {
+				pageSize = MaxListPageSize // 限制最大页面大小
+			}
+			q.PageSize = pageSize
+		}
+	}
+
+	return q, nil
+}
+
+// parseListFilter 按操作符拆分并校验筛选值
+func parseListFilter(field, op, raw string) (ListFilter, error) {
+	filter := ListFilter{Field: field, Op: op}
+	switch op {
+	case FilterOpEq, FilterOpGte, FilterOpLte, FilterOpLike:
+		if op != FilterOpEq && raw == "" {
+			return filter, InvalidListQuery("字段 " + field + " 的 " + op + " 条件不能为空")
+		}
+		filter.Values = []string{raw}
+	case FilterOpIn:
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				filter.Values = append(filter.Values, v)
+			}
+		}
+		if len(filter.Values) == 0 {
+			return filter, InvalidListQuery("字段 " + field + " 的 in 条件不能为空")
+		}
+		if len(filter.Values) > maxListInValues {
+			return filter, InvalidListQuery("字段 " + field + " 的 in 条件不能超过" + strconv.Itoa(maxListInValues) + "个值")
+		}
+	case FilterOpBetween:
+		parts := strings.Split(raw, ",")
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return filter, InvalidListQuery("字段 " + field + " 的 between 条件必须是两个逗号分隔的值")
+		}
+		filter.Values = []string{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])}
+	default:
+		return filter, InvalidListQuery("不支持的筛选操作符: " + op)
+	}
+	return filter, nil
+}
+
+// InvalidListQuery 包装 ErrInvalidListQuery，便于调用方识别为参数错误
+func InvalidListQuery(msg string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidListQuery, msg)
+}
+
+// HasFilter 检查是否包含指定字段的筛选条件
+func (q *ListQuery) HasFilter(field string) bool {
+	for _, f := range q.Filters {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Paged 是否使用偏移分页
+func (q *ListQuery) Paged() bool {
+	return q.PageSize > 0 && !q.CursorMode
+}
+
+// SortSignature 排序条件签名，用于校验游标与当前排序一致
+func (q *ListQuery) SortSignature() string {
+	parts := make([]string, len(q.Sorts))
+	for i, s := range q.Sorts {
+		parts[i] = s.Field
+		if s.Desc {
+			parts[i] = "-" + s.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Offset 分页偏移量
+func (q *ListQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// CheckFields 校验 fields 参数中的字段均可返回
+func (q *ListQuery) CheckFields(allowed []string) error {
+	for _, field := range q.Fields {
+		ok := false
+		for _, a := range allowed {
+			if a == field {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return InvalidListQuery("字段 " + field + " 不支持返回")
+		}
+	}
+	return nil
+}
+
+// ProjectFields 将列表转换为仅包含指定字段的对象数组，fields 为空时原样返回
+func ProjectFields(items interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	projected := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		p := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if v, ok := row[field]; ok {
+				p[field] = v
+			}
+		}
+		projected[i] = p
+	}
+	return projected, nil
+}
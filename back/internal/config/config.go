@@ -4,6 +4,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/driver/mysql"
@@ -28,10 +30,76 @@ type ServerConfig struct {
 	Mode string
 }
 
+// JobsConfig 后台任务配置
+type JobsConfig struct {
+	Enabled                bool          // 是否启用后台任务
+	StatusScheduleInterval time.Duration // 计划状态流转检查间隔
+	TrashPurgeInterval     time.Duration // 回收站过期清理间隔
+	TrashRetentionDays     int           // 回收站保留天数，0 表示不自动清理
+	SearchReindexInterval  time.Duration // 联想索引及进程内搜索索引重建间隔
+	MediaProcessInterval   time.Duration // 待处理图片（缩略图生成）检查间隔
+	RatingRebuildInterval  time.Duration // 商家评分按评价重新汇总的间隔（修正偏差、应用评分配置变更）
+	DocumentExpiryInterval time.Duration // 资质证件到期检查间隔
+}
+
+// SearchConfig 搜索配置
+type SearchConfig struct {
+	Backend string // 搜索后端：mysql（FULLTEXT ngram）或 memory（进程内索引）
+}
+
+// StorageConfig 媒体文件存储配置
+type StorageConfig struct {
+	Backend       string // 存储后端：local（本地文件系统）或 s3（S3 兼容对象存储）
+	LocalDir      string // 本地存储根目录
+	PublicBaseURL string // 对象的公开访问地址前缀（如 CDN），为空时由 API 转发文件内容
+	MaxUploadSize int64  // 单个文件的最大字节数
+	WebPEncoder   string // cwebp 可执行文件路径，为空时不生成 WebP 缩略图
+
+	S3Endpoint  string // S3 服务地址，如 https://s3.ap-northeast-1.amazonaws.com 或 http://127.0.0.1:9000
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool // 使用路径形式访问桶（MinIO 等自建服务通常需要）
+}
+
+// ReviewConfig 顾客评价与评分配置
+type ReviewConfig struct {
+	Bayesian   bool    // 商家评分是否使用贝叶斯平均（评价数少时向先验均值收缩），否则为算术平均
+	PriorMean  float64 // 贝叶斯平均的先验均值
+	PriorCount float64 // 贝叶斯平均的先验权重（相当于多少条先验评价）
+
+	// 自动审核规则，命中的评价进入审核队列
+	BurstWindow       time.Duration // 发表频率的统计时间窗
+	BurstMaxPerUser   int           // 时间窗内同一用户最多发表的评价数
+	BurstMaxPerIP     int           // 时间窗内同一 IP 最多发表的评价数
+	SpikeMinReviews   int           // 商家 24 小时内评价数达到该值且远超日常水平时视为激增
+	OutlierMinReviews int           // 商家已有评价数达到该值后才检查星级是否偏离历史评分
+	ProfanityFile     string        // 不雅词汇表文件（每行一个词，# 开头为注释），为空时使用内置词表
+}
+
+// DocumentConfig 商家资质证件配置
+type DocumentConfig struct {
+	ExpiryWarningDays int      // 有效期在该天数内的证件标记为即将到期
+	MandatoryTypes    []string // 必备证件类型，已核验的证件过期且没有其他有效证件时视为缺失
+	AutoSuspend       bool     // 必备证件缺失时是否自动暂停商家
+}
+
+// SettlementConfig 商家结算账户配置
+type SettlementConfig struct {
+	EncryptionKey string // 银行账号加密密钥（base64 编码的 32 字节），未配置时不能提交结算账户变更
+}
+
 // Config 应用配置
 type Config struct {
-	Database *DatabaseConfig
-	Server   *ServerConfig
+	Database   *DatabaseConfig
+	Server     *ServerConfig
+	Jobs       *JobsConfig
+	Search     *SearchConfig
+	Storage    *StorageConfig
+	Review     *ReviewConfig
+	Document   *DocumentConfig
+	Settlement *SettlementConfig
 }
 
 // getEnv 获取环境变量，如果不存在则使用默认值
@@ -42,6 +110,49 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvBool 获取布尔类型环境变量
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt 获取整数类型环境变量
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvFloat 获取浮点数类型环境变量
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvList 获取以逗号分隔的列表类型环境变量，忽略空项
+func getEnvList(key, defaultValue string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvDuration 获取时长类型环境变量（如 30s、5m）
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 // LoadConfig 加载配置
 func LoadConfig() *Config {
 	return &Config{
@@ -58,6 +169,52 @@ func LoadConfig() *Config {
 			Port: getEnv("SERVER_PORT", "8088"),
 			Mode: getEnv("GIN_MODE", "debug"),
 		},
+		Jobs: &JobsConfig{
+			Enabled:                getEnvBool("JOBS_ENABLED", true),
+			StatusScheduleInterval: getEnvDuration("JOB_STATUS_SCHEDULE_INTERVAL", time.Minute),
+			TrashPurgeInterval:     getEnvDuration("JOB_TRASH_PURGE_INTERVAL", time.Hour),
+			TrashRetentionDays:     getEnvInt("TRASH_RETENTION_DAYS", 30),
+			SearchReindexInterval:  getEnvDuration("JOB_SEARCH_REINDEX_INTERVAL", 30*time.Minute),
+			MediaProcessInterval:   getEnvDuration("JOB_MEDIA_PROCESS_INTERVAL", 30*time.Second),
+			RatingRebuildInterval:  getEnvDuration("JOB_RATING_REBUILD_INTERVAL", 24*time.Hour),
+			DocumentExpiryInterval: getEnvDuration("JOB_DOCUMENT_EXPIRY_INTERVAL", 24*time.Hour),
+		},
+		Search: &SearchConfig{
+			Backend: getEnv("SEARCH_BACKEND", "mysql"),
+		},
+		Storage: &StorageConfig{
+			Backend:       getEnv("MEDIA_STORAGE", "local"),
+			LocalDir:      getEnv("MEDIA_LOCAL_DIR", "uploads"),
+			PublicBaseURL: getEnv("MEDIA_PUBLIC_BASE_URL", ""),
+			MaxUploadSize: int64(getEnvInt("MEDIA_MAX_UPLOAD_SIZE", 5<<20)),
+			WebPEncoder:   getEnv("MEDIA_WEBP_ENCODER", ""),
+			S3Endpoint:    getEnv("S3_ENDPOINT", ""),
+			S3Region:      getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:      getEnv("S3_BUCKET", ""),
+			S3AccessKey:   getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:   getEnv("S3_SECRET_KEY", ""),
+			S3PathStyle:   getEnvBool("S3_PATH_STYLE", true),
+		},
+		Review: &ReviewConfig{
+			Bayesian:   getEnvBool("REVIEW_RATING_BAYESIAN", false),
+			PriorMean:  getEnvFloat("REVIEW_BAYESIAN_PRIOR_MEAN", 3.5),
+			PriorCount: getEnvFloat("REVIEW_BAYESIAN_PRIOR_COUNT", 5),
+
+			BurstWindow:       getEnvDuration("REVIEW_BURST_WINDOW", time.Hour),
+			BurstMaxPerUser:   getEnvInt("REVIEW_BURST_MAX_PER_USER", 3),
+			BurstMaxPerIP:     getEnvInt("REVIEW_BURST_MAX_PER_IP", 5),
+			SpikeMinReviews:   getEnvInt("REVIEW_SPIKE_MIN_REVIEWS", 10),
+			OutlierMinReviews: getEnvInt("REVIEW_OUTLIER_MIN_REVIEWS", 10),
+			ProfanityFile:     getEnv("REVIEW_PROFANITY_FILE", ""),
+		},
+		Document: &DocumentConfig{
+			ExpiryWarningDays: getEnvInt("DOCUMENT_EXPIRY_WARNING_DAYS", 30),
+			MandatoryTypes:    getEnvList("DOCUMENT_MANDATORY_TYPES", "business_license"),
+			AutoSuspend:       getEnvBool("DOCUMENT_AUTO_SUSPEND", false),
+		},
+		Settlement: &SettlementConfig{
+			EncryptionKey: getEnv("SETTLEMENT_ENCRYPTION_KEY", ""),
+		},
 	}
 }
 
@@ -0,0 +1,464 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"merchant_back/internal/common"
+	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BrandController 品牌控制器
+type BrandController struct {
+	brandService services.BrandService
+}
+
+// NewBrandController 创建品牌控制器实例
+func NewBrandController(brandService services.BrandService) *BrandController {
+	return &BrandController{
+		brandService: brandService,
+	}
+}
+
+// parseBrandID 解析路径中的品牌ID
+func parseBrandID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的品牌ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// listResponse 组装列表响应（含分页信息）
+func listResponse(message string, data interface{}, query *common.ListQuery, page *common.ListPageInfo) gin.H {
+	resp := gin.H{
+		"code":    200,
+		"message": message,
+		"data":    data,
+		"total":   page.Total,
+	}
+	if query.Paged() {
+		resp["page"] = query.Page
+		resp["pageSize"] = query.PageSize
+	}
+	if query.CursorMode {
+		resp["pageSize"] = query.PageSize
+		resp["nextCursor"] = page.NextCursor
+		resp["prevCursor"] = page.PrevCursor
+	}
+	return resp
+}
+
+// GetBrands 获取品牌列表
+// @Summary 获取品牌列表
+// @Description 默认按名称返回品牌列表，含门店数量 storeCount 与标志地址。
+// @Description 支持 filter[id|name|email|categoryId|createdAt|updatedAt][操作符] 筛选、sort=-createdAt 排序（id,name,createdAt,updatedAt），
+// @Description 以及 page/pageSize 偏移分页或 cursor 游标分页
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param filter[name][like] query string false "示例：按名称模糊匹配"
+// @Param sort query string false "排序字段，- 表示倒序"
+// @Param page query int false "页码（偏移分页）"
+// @Param pageSize query int false "每页数量（最大100）"
+// @Param cursor query string false "分页游标（游标分页）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "查询参数错误"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/brands [get]
+func (bc *BrandController) GetBrands(c *gin.Context) {
+	query, err := common.ParseListQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	brands, page, err := bc.brandService.GetBrands(query)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取品牌列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse("获取品牌列表成功", brands, query, page))
+}
+
+// GetBrand 获取品牌详情
+// @Summary 获取品牌详情
+// @Description 获取品牌资料、标志地址与门店数量
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的品牌ID"
+// @Failure 404 {object} map[string]interface{} "品牌不存在"
+// @Router /api/v1/brands/{id} [get]
+func (bc *BrandController) GetBrand(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+
+	brand, err := bc.brandService.GetBrand(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取品牌成功",
+		"data":    brand,
+	})
+}
+
+// CreateBrand 创建品牌
+// @Summary 创建品牌
+// @Description 创建品牌（连锁商家），仅商家用户或管理员可操作，创建人成为品牌管理员。
+// @Description 品牌的描述与标志（logoMediaId，需先通过媒体文件接口上传）作为门店未填写时的默认值，categoryId 为创建门店未指定分类时使用的分类
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param brand body model.BrandRequest true "品牌资料"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /api/v1/brands [post]
+func (bc *BrandController) CreateBrand(c *gin.Context) {
+	var req model.BrandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	brand, err := bc.brandService.CreateBrand(&req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "创建品牌失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "品牌创建成功",
+		"data":    brand,
+	})
+}
+
+// UpdateBrand 修改品牌
+// @Summary 修改品牌
+// @Description 整体替换品牌资料（仅品牌管理员或管理员），未单独填写描述、图片的门店随之显示新的品牌描述与标志
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Param brand body model.BrandRequest true "品牌资料"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} map[string]interface{} "修改失败"
+// @Router /api/v1/brands/{id} [put]
+func (bc *BrandController) UpdateBrand(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+
+	var req model.BrandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	brand, err := bc.brandService.UpdateBrand(id, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "修改品牌失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "品牌修改成功",
+		"data":    brand,
+	})
+}
+
+// DeleteBrand 删除品牌
+// @Summary 删除品牌
+// @Description 删除品牌（仅品牌管理员或管理员），品牌下仍有门店时不能删除；回收站中的门店改为独立商家
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Router /api/v1/brands/{id} [delete]
+func (bc *BrandController) DeleteBrand(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := bc.brandService.DeleteBrand(id, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除品牌失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "品牌删除成功",
+	})
+}
+
+// GetBrandStores 获取品牌的门店
+// @Summary 获取品牌的门店
+// @Description 获取品牌下的门店，筛选、排序、字段选择与分页参数与商家列表相同。
+// @Description 门店未填写的描述、图片使用品牌的描述与标志，继承的字段列在 inheritedFields 中
+// @Description 门店通过商家接口创建与维护：创建或修改商家时设置 brandId 即加入品牌，设为 null 即移出品牌（须为品牌管理员）
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序"
+// @Param fields query string false "返回字段，逗号分隔"
+// @Param page query int false "页码（偏移分页）"
+// @Param pageSize query int false "每页数量（最大100）"
+// @Param cursor query string false "分页游标（游标分页）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "查询参数错误"
+// @Failure 404 {object} map[string]interface{} "品牌不存在"
+// @Router /api/v1/brands/{id}/stores [get]
+func (bc *BrandController) GetBrandStores(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+
+	query, err := common.ParseListQuery(c.Request.URL.Query())
+	if err == nil {
+		err = query.CheckFields(businessResponseFields)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	stores, page, err := bc.brandService.GetStores(id, query)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	data, err := common.ProjectFields(stores, query.Fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取门店列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse("获取门店列表成功", data, query, page))
+}
+
+// GetBrandStats 获取品牌汇总统计
+// @Summary 获取品牌汇总统计
+// @Description 汇总品牌全部门店（不含回收站）：门店数量、各状态门店数、各城市门店数，
+// @Description 以及全部门店已公开评价的评价数、星级分布与品牌评分（按评价的星级合计计算，评价多的门店权重更大）
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的品牌ID"
+// @Failure 404 {object} map[string]interface{} "品牌不存在"
+// @Router /api/v1/brands/{id}/stats [get]
+func (bc *BrandController) GetBrandStats(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := bc.brandService.GetStats(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取品牌统计成功",
+		"data":    stats,
+	})
+}
+
+// GetBrandAdmins 获取品牌管理员
+// @Summary 获取品牌管理员
+// @Description 获取品牌管理员列表，品牌管理员可维护品牌资料及其全部门店
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的品牌ID"
+// @Failure 404 {object} map[string]interface{} "品牌不存在"
+// @Router /api/v1/brands/{id}/admins [get]
+func (bc *BrandController) GetBrandAdmins(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+
+	admins, err := bc.brandService.GetAdmins(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取品牌管理员成功",
+		"data":    admins,
+	})
+}
+
+// AddBrandAdmin 添加品牌管理员
+// @Summary 添加品牌管理员
+// @Description 添加品牌管理员（仅品牌管理员或管理员）
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Param admin body model.BrandAdminRequest true "用户"
+// @Success 201 {object} map[string]interface{} "添加成功"
+// @Failure 400 {object} map[string]interface{} "添加失败"
+// @Router /api/v1/brands/{id}/admins [post]
+func (bc *BrandController) AddBrandAdmin(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+
+	var req model.BrandAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	admin, err := bc.brandService.AddAdmin(id, req.UserID, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "添加品牌管理员失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "品牌管理员添加成功",
+		"data":    admin,
+	})
+}
+
+// RemoveBrandAdmin 移除品牌管理员
+// @Summary 移除品牌管理员
+// @Description 移除品牌管理员（仅品牌管理员或管理员），品牌至少保留一名管理员
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path int true "品牌ID"
+// @Param userId path int true "用户ID"
+// @Success 200 {object} map[string]interface{} "移除成功"
+// @Failure 400 {object} map[string]interface{} "移除失败"
+// @Router /api/v1/brands/{id}/admins/{userId} [delete]
+func (bc *BrandController) RemoveBrandAdmin(c *gin.Context) {
+	id, ok := parseBrandID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的用户ID",
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := bc.brandService.RemoveAdmin(id, uint(userID), actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "移除品牌管理员失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "品牌管理员移除成功",
+	})
+}
@@ -0,0 +1,389 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BusinessContractController 商家合同控制器
+type BusinessContractController struct {
+	contractService services.BusinessContractService
+}
+
+// NewBusinessContractController 创建商家合同控制器实例
+func NewBusinessContractController(contractService services.BusinessContractService) *BusinessContractController {
+	return &BusinessContractController{
+		contractService: contractService,
+	}
+}
+
+// parseContractPath 解析路径中的商家ID与合同ID
+func parseContractPath(c *gin.Context) (int, uint, bool) {
+	businessID, ok := parseBusinessID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	contractID, err := strconv.ParseUint(c.Param("contractId"), 10, 64)
+	if err != nil || contractID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的合同ID",
+		})
+		return 0, 0, false
+	}
+	return businessID, uint(contractID), true
+}
+
+// GetContracts 获取商家合同
+// @Summary 获取商家合同
+// @Description 获取商家的全部合同，按生效日期倒序（店主、品牌管理员或管理员）
+// @Tags business-contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/{id}/contracts [get]
+func (cc *BusinessContractController) GetContracts(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	contracts, err := cc.contractService.GetContracts(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取合同失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取合同成功",
+		"data":    contracts,
+	})
+}
+
+// GetContract 获取合同详情
+// @Summary 获取合同详情
+// @Description 获取单个合同的条款（店主、品牌管理员或管理员）
+// @Tags business-contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param contractId path int true "合同ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Failure 404 {object} map[string]interface{} "合同不存在"
+// @Router /api/v1/business/{id}/contracts/{contractId} [get]
+func (cc *BusinessContractController) GetContract(c *gin.Context) {
+	businessID, contractID, ok := parseContractPath(c)
+	if !ok {
+		return
+	}
+
+	contract, err := cc.contractService.GetContract(businessID, contractID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取合同成功",
+		"data":    contract,
+	})
+}
+
+// GetEffectiveTerms 获取生效的合同条款
+// @Summary 获取生效的合同条款
+// @Description 获取商家在指定日期生效的合同（店主、品牌管理员或管理员）。指定 amount 时按该结算周期交易额返回适用的佣金比例 rate，
+// @Description 供结算等模块计算佣金使用
+// @Tags business-contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param date query string false "日期（YYYY-MM-DD），默认今天"
+// @Param amount query int false "结算周期交易额（分）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Failure 404 {object} map[string]interface{} "该日期没有生效的合同"
+// @Router /api/v1/business/{id}/contracts/effective [get]
+func (cc *BusinessContractController) GetEffectiveTerms(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	var amount *int64
+	if value := c.Query("amount"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "无效的交易额",
+			})
+			return
+		}
+		amount = &parsed
+	}
+
+	terms, err := cc.contractService.GetEffectiveTerms(id, c.Query("date"), amount)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrNoEffectiveContract) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取生效合同成功",
+		"data":    terms,
+	})
+}
+
+// CreateContract 创建合同
+// @Summary 创建合同
+// @Description 管理员为商家录入合同。有效期不能与该商家的其他合同重叠；佣金为固定比例（commissionRate）或阶梯比例（commissionTiers，
+// @Description 第一档下限为 0 且递增），比例为百分比，最多两位小数；金额单位为分
+// @Tags business-contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param contract body model.BusinessContractRequest true "合同条款"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误或有效期重叠"
+// @Router /api/v1/business/{id}/contracts [post]
+func (cc *BusinessContractController) CreateContract(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	contract, err := cc.contractService.CreateContract(id, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "创建合同失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "合同创建成功",
+		"data":    contract,
+	})
+}
+
+// UpdateContract 修改合同
+// @Summary 修改合同
+// @Description 管理员修改合同。未生效的合同可修改全部条款；已生效的合同只能修改备注，尚未结束时可修改结束日期（不早于今天）以提前终止或续期，
+// @Description 其他条款变更需签订新合同
+// @Tags business-contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param contractId path int true "合同ID"
+// @Param contract body model.BusinessContractRequest true "合同条款"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} map[string]interface{} "修改失败"
+// @Router /api/v1/business/{id}/contracts/{contractId} [put]
+func (cc *BusinessContractController) UpdateContract(c *gin.Context) {
+	businessID, contractID, ok := parseContractPath(c)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	contract, err := cc.contractService.UpdateContract(businessID, contractID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "修改合同失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "合同修改成功",
+		"data":    contract,
+	})
+}
+
+// DeleteContract 删除合同
+// @Summary 删除合同
+// @Description 管理员删除尚未生效的合同及其附件，已生效的合同需通过修改结束日期提前终止
+// @Tags business-contracts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param contractId path int true "合同ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Router /api/v1/business/{id}/contracts/{contractId} [delete]
+func (cc *BusinessContractController) DeleteContract(c *gin.Context) {
+	businessID, contractID, ok := parseContractPath(c)
+	if !ok {
+		return
+	}
+
+	if err := cc.contractService.DeleteContract(businessID, contractID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除合同失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "合同删除成功",
+	})
+}
+
+// UploadAttachment 上传合同附件
+// @Summary 上传合同附件
+// @Description 管理员以 multipart/form-data 上传已签署的合同 PDF（字段名 file），替换原附件；大小上限同媒体文件，文件不公开
+// @Tags business-contracts
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param contractId path int true "合同ID"
+// @Param file formData file true "合同 PDF"
+// @Success 200 {object} map[string]interface{} "上传成功"
+// @Failure 400 {object} map[string]interface{} "上传失败"
+// @Failure 413 {object} map[string]interface{} "文件过大"
+// @Failure 415 {object} map[string]interface{} "不是 PDF 文件"
+// @Router /api/v1/business/{id}/contracts/{contractId}/attachment [put]
+func (cc *BusinessContractController) UploadAttachment(c *gin.Context) {
+	businessID, contractID, ok := parseContractPath(c)
+	if !ok {
+		return
+	}
+
+	maxSize := cc.contractService.MaxUploadSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    413,
+				"message": services.ErrMediaTooLarge.Error() + "（最大 " + strconv.FormatInt(maxSize, 10) + " 字节）",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请选择要上传的文件: " + err.Error(),
+		})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "读取上传文件失败: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	upload := &services.DocumentUpload{File: file, Size: header.Size, Filename: header.Filename}
+	contract, err := cc.contractService.UploadAttachment(businessID, contractID, upload)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"code": 413, "message": err.Error()})
+		case errors.Is(err, services.ErrContractAttachmentNotPDF):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"code": 415, "message": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "上传合同附件失败: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "合同附件上传成功",
+		"data":    contract,
+	})
+}
+
+// GetAttachment 下载合同附件
+// @Summary 下载合同附件
+// @Description 下载已签署的合同 PDF（店主、品牌管理员或管理员），响应不允许缓存
+// @Tags business-contracts
+// @Produce application/pdf
+// @Param id path int true "商家ID"
+// @Param contractId path int true "合同ID"
+// @Success 200 {file} file "文件内容"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Failure 404 {object} map[string]interface{} "合同或附件不存在"
+// @Router /api/v1/business/{id}/contracts/{contractId}/attachment [get]
+func (cc *BusinessContractController) GetAttachment(c *gin.Context) {
+	businessID, contractID, ok := parseContractPath(c)
+	if !ok {
+		return
+	}
+
+	contract, body, err := cc.contractService.OpenAttachment(businessID, contractID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	extraHeaders := map[string]string{}
+	if contract.AttachmentFilename != "" {
+		extraHeaders["Content-Disposition"] = "attachment; filename*=UTF-8''" + url.PathEscape(contract.AttachmentFilename)
+	}
+	c.DataFromReader(http.StatusOK, contract.AttachmentSize, "application/pdf", io.LimitReader(body, contract.AttachmentSize), extraHeaders)
+}
@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"errors"
+	"merchant_back/internal/common"
+	"merchant_back/internal/middleware"
 	model "merchant_back/internal/models"
 	"merchant_back/internal/services"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,18 +26,86 @@ func NewBusinessController(businessService services.BusinessService) *BusinessCo
 	}
 }
 
+// businessResponseFields 商家列表可通过 fields 参数选择返回的字段
+var businessResponseFields = []string{
+	"id", "name", "email", "address", "country", "province", "city", "district", "street", "postalCode",
+	"type", "contact", "rating", "ratingCount", "latitude", "longitude", "otherInfo", "description",
+	"imageMediaId", "imageUrl", "thumbnailUrl", "imageColor",
+	"status", "phone", "timezone", "createdAt", "updatedAt", "reviewerId", "rejectReason", "submittedAt",
+	"version", "deletedAt", "openingHours", "specialHours", "categoryIds", "tags", "attributes", "isOpen", "nextChange",
+	"searchScore", "highlights", "brandId", "inheritedFields",
+}
+
 // GetBusinesses 获取商家列表
 // @Summary 获取商家列表
-// @Description 获取所有商家信息列表
+// @Description 获取商家信息列表，支持组合筛选、多字段排序、字段选择与分页
+// @Description 筛选：filter[字段][操作符]=值，操作符为 eq/in/gte/lte/like/between，省略操作符即为 eq；in 与 between 的多个值以逗号分隔
+// @Description 可筛选字段：id,name,email,type,status,rating,ratingCount,country,province,city,district,postalCode,address,phone,timezone,createdAt,updatedAt,tags,brandId
+// @Description 自定义属性：filter[attributes.属性名]=值、sort=attributes.属性名，属性名为分类中定义的属性；数组属性任一元素满足即匹配，布尔属性取值为 true/false
+// @Description 排序：sort=-rating,name（- 表示倒序）；字段选择：fields=id,name,rating；未传 page/pageSize/cursor 时返回全部
+// @Description 游标分页：首页传 cursor=（空值），之后传响应中的 nextCursor/prevCursor；排序条件须与生成游标时一致
+// @Description 商家图片只返回缩略图地址 thumbnailUrl 与占位色 imageColor，原图地址 imageUrl 见商家详情
 // @Tags business
 // @Accept json
 // @Produce json
+// @Param filter[type][in] query string false "示例：按类型筛选（逗号分隔）"
+// @Param filter[rating][gte] query number false "示例：最低评分"
+// @Param filter[tags][in] query string false "示例：包含任一标签（逗号分隔）"
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序"
+// @Param fields query string false "返回字段，逗号分隔"
+// @Param page query int false "页码（偏移分页）"
+// @Param pageSize query int false "每页数量（最大100）"
+// @Param cursor query string false "分页游标（游标分页）"
+// @Param country query string false "国家/地区代码"
+// @Param province query string false "省/都道府县"
+// @Param city query string false "城市"
+// @Param district query string false "区/县"
+// @Param openNow query bool false "仅返回当前营业的商家"
+// @Param openAt query string false "仅返回指定时刻营业的商家（RFC3339）"
 // @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
 // @Failure 500 {object} map[string]interface{} "获取失败"
 // @Router /api/v1/business [get]
 func (bc *BusinessController) GetBusinesses(c *gin.Context) {
-	businesses, err := bc.businessService.GetBusinesses()
+	var filter model.RegionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	openAt, err := parseOpenAt(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	query, err := common.ParseListQuery(c.Request.URL.Query())
+	if err == nil {
+		err = query.CheckFields(businessResponseFields)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	businesses, page, err := bc.businessService.GetBusinesses(query, filter, openAt)
 	if err != nil {
+		if errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "获取商家列表失败: " + err.Error(),
@@ -40,17 +113,36 @@ func (bc *BusinessController) GetBusinesses(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	data, err := common.ProjectFields(businesses, query.Fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取商家列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	resp := gin.H{
 		"code":    200,
 		"message": "获取商家列表成功",
-		"data":    businesses,
-		"total":   len(businesses),
-	})
+		"data":    data,
+		"total":   page.Total,
+	}
+	if query.Paged() {
+		resp["page"] = query.Page
+		resp["pageSize"] = query.PageSize
+	}
+	if query.CursorMode {
+		resp["pageSize"] = query.PageSize
+		resp["nextCursor"] = page.NextCursor
+		resp["prevCursor"] = page.PrevCursor
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // GetBusiness 获取单个商家
 // @Summary 获取商家详情
-// @Description 根据商家ID获取单个商家的详细信息
+// @Description 根据商家ID获取单个商家的详细信息；品牌门店未填写的描述、图片使用品牌的描述与标志，继承的字段列在 inheritedFields 中
 // @Tags business
 // @Accept json
 // @Produce json
@@ -78,6 +170,7 @@ func (bc *BusinessController) GetBusiness(c *gin.Context) {
 		return
 	}
 
+	setETag(c, business.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "获取商家成功",
@@ -88,6 +181,9 @@ func (bc *BusinessController) GetBusiness(c *gin.Context) {
 // CreateBusiness 创建商家
 // @Summary 创建新商家
 // @Description 创建一个新的商家账户
+// @Description 通过 categoryIds 指定所属分类（第一个为主分类，type 自动设为主分类的标识）；只传 type 时按分类的标识或名称匹配
+// @Description 商家图片：先通过 POST /api/v1/media 上传，再以 imageMediaId 引用；仍兼容提交 imageBase64（自动转存为媒体文件）
+// @Description 品牌门店：设置 brandId（须为品牌管理员），邮箱可不填；未指定分类时使用品牌的默认分类，描述、图片不填或与品牌相同时随品牌显示
 // @Tags business
 // @Accept json
 // @Produce json
@@ -106,7 +202,8 @@ func (bc *BusinessController) CreateBusiness(c *gin.Context) {
 		return
 	}
 
-	err := bc.businessService.CreateBusiness(&business)
+	actorID, _ := middleware.GetUserID(c)
+	err := bc.businessService.CreateBusiness(&business, actorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -124,15 +221,20 @@ func (bc *BusinessController) CreateBusiness(c *gin.Context) {
 
 // UpdateBusiness 更新商家
 // @Summary 更新商家信息
-// @Description 根据商家ID更新商家的信息
+// @Description 根据商家ID更新商家的信息，修改前的资料保存为修订版本
+// @Description 修改 brandId 即加入、更换或移出品牌（须为相应品牌的管理员），移出品牌的门店须填写邮箱；inheritedFields 中的字段提交 null 或与品牌相同的值即保持继承
 // @Tags business
 // @Accept json
 // @Produce json
 // @Param id path int true "商家ID"
+// @Param If-Match header string true "商家当前 ETag（版本号），* 表示不校验"
 // @Param business body model.Business true "商家更新信息"
 // @Success 200 {object} map[string]interface{} "更新成功"
 // @Failure 400 {object} map[string]interface{} "无效的商家ID或请求参数错误"
+// @Failure 412 {object} map[string]interface{} "版本不一致，返回最新数据"
+// @Failure 428 {object} map[string]interface{} "缺少 If-Match 请求头"
 // @Failure 500 {object} map[string]interface{} "更新失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
 // @Router /api/v1/business/{id} [put]
 func (bc *BusinessController) UpdateBusiness(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -153,7 +255,22 @@ func (bc *BusinessController) UpdateBusiness(c *gin.Context) {
 		return
 	}
 
-	err = bc.businessService.UpdateBusiness(id, &business)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"code":    428,
+			"message": err.Error(),
+		})
+		return
+	}
+	business.Version = version
+
+	actorID, _ := middleware.GetUserID(c)
+	err = bc.businessService.UpdateBusiness(id, &business, actorID)
+	if errors.Is(err, services.ErrVersionConflict) {
+		bc.respondVersionConflict(c, id)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -162,6 +279,80 @@ func (bc *BusinessController) UpdateBusiness(c *gin.Context) {
 		return
 	}
 
+	setETag(c, business.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "商家更新成功",
+		"data":    business,
+	})
+}
+
+// PatchBusiness 部分更新商家
+// @Summary 部分更新商家信息
+// @Description 支持 JSON Merge Patch（application/merge-patch+json）与 JSON Patch（application/json-patch+json），仅校验补丁涉及的字段；不可修改 id、createdAt 等字段，状态与营业时间需通过对应接口修改
+// @Tags business
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param If-Match header string true "商家当前 ETag（版本号），* 表示不校验"
+// @Param patch body object true "补丁内容"
+// @Success 200 {object} map[string]interface{} "更新成功"
+// @Failure 400 {object} map[string]interface{} "无效的补丁或校验失败"
+// @Failure 412 {object} map[string]interface{} "版本不一致，返回最新数据"
+// @Failure 415 {object} map[string]interface{} "不支持的补丁格式"
+// @Failure 428 {object} map[string]interface{} "缺少 If-Match 请求头"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id} [patch]
+func (bc *BusinessController) PatchBusiness(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"code":    428,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "读取请求内容失败: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	business, err := bc.businessService.PatchBusiness(id, c.ContentType(), patch, version, actorID)
+	if errors.Is(err, services.ErrVersionConflict) {
+		bc.respondVersionConflict(c, id)
+		return
+	}
+	if errors.Is(err, common.ErrUnsupportedPatchType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"code":    415,
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "更新商家失败: " + err.Error(),
+		})
+		return
+	}
+
+	setETag(c, business.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "商家更新成功",
@@ -171,15 +362,19 @@ func (bc *BusinessController) UpdateBusiness(c *gin.Context) {
 
 // UpdateBusinessStatus 更新商家状态
 // @Summary 更新商家状态
-// @Description 更新指定商家的状态（启用/禁用）
+// @Description 按状态机流转商家状态，仅允许合法的流转（如 approved → active、active → suspended）；设置 until 时到期自动恢复原状态
+// @Description 解除暂停（suspended → active/inactive）仅管理员可操作
 // @Tags business
 // @Accept json
 // @Produce json
 // @Param id path int true "商家ID"
-// @Param status body map[string]interface{} true "状态信息" example({"status": "active"})
+// @Param If-Match header string true "商家当前 ETag（版本号），* 表示不校验"
+// @Param status body model.BusinessStatusRequest true "状态信息"
 // @Success 200 {object} map[string]interface{} "更新成功"
-// @Failure 400 {object} map[string]interface{} "无效的商家ID或请求参数错误"
-// @Failure 500 {object} map[string]interface{} "更新失败"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID、请求参数错误或不允许的状态流转"
+// @Failure 412 {object} map[string]interface{} "版本不一致，返回最新数据"
+// @Failure 428 {object} map[string]interface{} "缺少 If-Match 请求头"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
 // @Router /api/v1/business/{id}/status [put]
 func (bc *BusinessController) UpdateBusinessStatus(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -191,28 +386,29 @@ func (bc *BusinessController) UpdateBusinessStatus(c *gin.Context) {
 		return
 	}
 
-	var statusData map[string]interface{}
-	if err := c.ShouldBindJSON(&statusData); err != nil {
+	var req model.BusinessStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "请求参数错误: " + err.Error(),
+			"message": "缺少状态参数: " + err.Error(),
 		})
 		return
 	}
 
-	status, ok := statusData["status"].(string)
+	version, ok := bc.checkVersion(c, id)
 	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "缺少状态参数",
-		})
 		return
 	}
 
-	err = bc.businessService.UpdateBusinessStatus(id, status)
+	actorID, _ := middleware.GetUserID(c)
+	err = bc.businessService.UpdateBusinessStatus(id, &req, version, actorID)
+	if errors.Is(err, services.ErrVersionConflict) {
+		bc.respondVersionConflict(c, id)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
 			"message": "更新商家状态失败: " + err.Error(),
 		})
 		return
@@ -223,21 +419,25 @@ func (bc *BusinessController) UpdateBusinessStatus(c *gin.Context) {
 		"message": "商家状态更新成功",
 		"data": map[string]interface{}{
 			"id":     id,
-			"status": status,
+			"status": req.Status,
 		},
 	})
 }
 
 // DeleteBusiness 删除商家
 // @Summary 删除商家
-// @Description 根据商家ID删除商家账户
+// @Description 根据商家ID删除商家，删除后移入回收站，可在保留期内恢复
 // @Tags business
 // @Accept json
 // @Produce json
 // @Param id path int true "商家ID"
+// @Param If-Match header string true "商家当前 ETag（版本号），* 表示不校验"
 // @Success 200 {object} map[string]interface{} "删除成功"
 // @Failure 400 {object} map[string]interface{} "无效的商家ID"
+// @Failure 412 {object} map[string]interface{} "版本不一致，返回最新数据"
+// @Failure 428 {object} map[string]interface{} "缺少 If-Match 请求头"
 // @Failure 500 {object} map[string]interface{} "删除失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
 // @Router /api/v1/business/{id} [delete]
 func (bc *BusinessController) DeleteBusiness(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -249,7 +449,16 @@ func (bc *BusinessController) DeleteBusiness(c *gin.Context) {
 		return
 	}
 
-	err = bc.businessService.DeleteBusiness(id)
+	version, ok := bc.checkVersion(c, id)
+	if !ok {
+		return
+	}
+
+	err = bc.businessService.DeleteBusiness(id, version)
+	if errors.Is(err, services.ErrVersionConflict) {
+		bc.respondVersionConflict(c, id)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -305,19 +514,68 @@ func (bc *BusinessController) GetBusinessByStatus(c *gin.Context) {
 
 // SearchBusinesses 搜索商家
 // @Summary 搜索商家
-// @Description 根据关键词搜索商家
+// @Description 按关键词全文搜索商家的名称、类型、地址、联系方式与描述，结果按相关度排序
+// @Description 支持中文、日文（按相邻两字匹配），每个结果附带相关度 searchScore 与命中字段的高亮文本 highlights（命中片段以 <em> 标记）
+// @Description 同时返回匹配关键词的商家按类型、状态、评分区间、地区、标签的分面统计 facets；各维度的数量不受本维度筛选条件影响，
+// @Description 地区分面按已选地区的下一级统计（regionLevel）；total 与分面在数据库中统计，关键词命中超过1000个时只统计最相关的1000个，
+// @Description 此时 facets.truncated 为 true
 // @Tags business
 // @Accept json
 // @Produce json
-// @Param q query string true "搜索关键词"
+// @Param q query string false "搜索关键词（最多100个字符），为空时匹配全部商家"
+// @Param type query string false "商家类型，多个以逗号分隔"
+// @Param status query string false "状态，多个以逗号分隔"
+// @Param rating query string false "评分区间（4-5、3-4、2-3、1-2、0-1），多个以逗号分隔"
+// @Param tag query string false "标签，多个以逗号分隔（包含任一即匹配）"
+// @Param country query string false "国家/地区代码"
+// @Param province query string false "省/都道府县"
+// @Param city query string false "城市"
+// @Param district query string false "区/县"
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量（最大100），不传则返回全部结果"
 // @Success 200 {object} map[string]interface{} "搜索成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
 // @Failure 500 {object} map[string]interface{} "搜索失败"
 // @Router /api/v1/business/search [get]
 func (bc *BusinessController) SearchBusinesses(c *gin.Context) {
 	query := c.Query("q")
 
-	businesses, err := bc.businessService.SearchBusinesses(query)
+	filter := model.BusinessSearchFilter{
+		Types:    queryValues(c, "type"),
+		Statuses: queryValues(c, "status"),
+		Ratings:  queryValues(c, "rating"),
+		Tags:     queryValues(c, "tag"),
+	}
+	if err := c.ShouldBindQuery(&filter.Region); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	page, pageSize := 1, 0
+	if c.Query("page") != "" || c.Query("pageSize") != "" {
+		var err error
+		page, err = strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		pageSize, err = strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+		if err != nil || pageSize < 1 || pageSize > 100 {
+			pageSize = 10
+		}
+	}
+
+	businesses, total, facets, err := bc.businessService.SearchBusinesses(query, filter, page, pageSize)
 	if err != nil {
+		if errors.Is(err, services.ErrSearchQueryTooLong) || errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "搜索商家失败: " + err.Error(),
@@ -325,12 +583,116 @@ func (bc *BusinessController) SearchBusinesses(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"code":    200,
 		"message": "搜索完成",
 		"data":    businesses,
-		"total":   len(businesses),
+		"total":   total,
 		"query":   query,
+		"facets":  facets,
+	}
+	if pageSize > 0 {
+		resp["page"] = page
+		resp["pageSize"] = pageSize
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// queryValues 读取可重复或以逗号分隔的查询参数，忽略空值
+func queryValues(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// SuggestBusinesses 商家名称联想
+// @Summary 商家名称联想
+// @Description 按输入的前缀返回匹配的商家名称，用于搜索框输入时的自动补全
+// @Description 支持拼写容错（如 stabucks → Starbucks）、假名与罗马字互相匹配（如 ramen → ラーメン），常被搜索的商家排序靠前；同时返回以该前缀开头的热门搜索关键词
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param q query string true "输入的前缀（最多100个字符）"
+// @Param limit query int false "联想条数（默认10，最大20）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /api/v1/business/suggest [get]
+func (bc *BusinessController) SuggestBusinesses(c *gin.Context) {
+	query := c.Query("q")
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "联想条数格式错误",
+		})
+		return
+	}
+
+	suggestions, popular, err := bc.businessService.SuggestBusinesses(query, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrSearchQueryTooLong) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取联想失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    suggestions,
+		"queries": popular,
+		"query":   query,
+	})
+}
+
+// GetTags 标签统计
+// @Summary 标签统计与联想
+// @Description 按使用的商家数倒序返回标签，指定前缀时只返回以其开头的标签，用于标签输入联想与标签云
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param prefix query string false "标签前缀"
+// @Param limit query int false "返回条数（默认20，最大100）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /api/v1/business/tags [get]
+func (bc *BusinessController) GetTags(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "返回条数格式错误",
+		})
+		return
+	}
+
+	tags, err := bc.businessService.GetTags(c.Query("prefix"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取标签失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    tags,
 	})
 }
 
@@ -375,7 +737,8 @@ func (bc *BusinessController) GetBusinessesByType(c *gin.Context) {
 
 // GetBusinessesByRating 根据评分获取商家
 // @Summary 根据评分获取商家列表
-// @Description 获取评分高于指定值的商家列表
+// @Description 获取评分不低于指定值的商家列表（不含暂停营业的商家），按评分从高到低排序，评分相同时评价多的在前
+// @Description 评分由已公开的顾客评价计算，按配置取算术平均或贝叶斯平均，尚无评价的商家评分为 0
 // @Tags business
 // @Accept json
 // @Produce json
@@ -474,6 +837,8 @@ func (bc *BusinessController) GetBusinessesWithPagination(c *gin.Context) {
 // @Param lat query number true "纬度"
 // @Param lng query number true "经度"
 // @Param radius query number false "搜索半径(公里)" default(5.0)
+// @Param openNow query bool false "仅返回当前营业的商家"
+// @Param openAt query string false "仅返回指定时刻营业的商家（RFC3339）"
 // @Success 200 {object} map[string]interface{} "获取成功"
 // @Failure 400 {object} map[string]interface{} "缺少经纬度参数或参数无效"
 // @Failure 500 {object} map[string]interface{} "获取失败"
@@ -514,7 +879,16 @@ func (bc *BusinessController) GetNearbyBusinesses(c *gin.Context) {
 		radius = 5.0 // 默认5公里
 	}
 
-	businesses, err := bc.businessService.GetNearbyBusinesses(lat, lng, radius)
+	openAt, err := parseOpenAt(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	businesses, err := bc.businessService.GetNearbyBusinesses(lat, lng, radius, openAt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -536,15 +910,29 @@ func (bc *BusinessController) GetNearbyBusinesses(c *gin.Context) {
 
 // GetBusinessCount 获取商家总数
 // @Summary 获取商家总数
-// @Description 获取所有商家的总数量
+// @Description 获取商家的总数量（不含已暂停的商家），支持按地区筛选
 // @Tags business
 // @Accept json
 // @Produce json
+// @Param country query string false "国家/地区代码"
+// @Param province query string false "省/都道府县"
+// @Param city query string false "城市"
+// @Param district query string false "区/县"
 // @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
 // @Failure 500 {object} map[string]interface{} "获取失败"
 // @Router /api/v1/business/count [get]
 func (bc *BusinessController) GetBusinessCount(c *gin.Context) {
-	count, err := bc.businessService.GetBusinessCount()
+	var filter model.RegionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	count, err := bc.businessService.GetBusinessCount(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -597,3 +985,913 @@ func (bc *BusinessController) GetBusinessCountByType(c *gin.Context) {
 		"type":    businessType,
 	})
 }
+
+// GetRegionStats 按地区统计商家数量
+// @Summary 按地区统计商家数量
+// @Description 按指定地区层级聚合商家数量，可叠加上级地区筛选，用于仪表盘展示
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param level query string false "聚合层级" Enums(country,province,city,district) default(province)
+// @Param country query string false "国家/地区代码"
+// @Param province query string false "省/都道府县"
+// @Param city query string false "城市"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /api/v1/business/regions [get]
+func (bc *BusinessController) GetRegionStats(c *gin.Context) {
+	var filter model.RegionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	level := c.DefaultQuery("level", model.RegionLevelProvince)
+	stats, err := bc.businessService.GetRegionStats(level, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "获取地区统计失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取地区统计成功",
+		"data":    stats,
+		"level":   level,
+	})
+}
+
+// SetOpeningHours 设置商家营业时间
+// @Summary 设置商家营业时间
+// @Description 整体替换商家的时区、每周营业时段（支持一天多个时段与跨夜）和节假日/特殊日期安排
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param If-Match header string true "商家当前 ETag（版本号），* 表示不校验"
+// @Param hours body model.OpeningHoursRequest true "营业时间"
+// @Success 200 {object} map[string]interface{} "设置成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或请求参数错误"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Failure 412 {object} map[string]interface{} "版本不一致，返回最新数据"
+// @Failure 428 {object} map[string]interface{} "缺少 If-Match 请求头"
+// @Router /api/v1/business/{id}/hours [put]
+func (bc *BusinessController) SetOpeningHours(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	var req model.OpeningHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"code":    428,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	business, err := bc.businessService.SetOpeningHours(id, &req, version, actorID)
+	if errors.Is(err, services.ErrVersionConflict) {
+		bc.respondVersionConflict(c, id)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "设置营业时间失败: " + err.Error(),
+		})
+		return
+	}
+
+	setETag(c, business.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "营业时间设置成功",
+		"data":    business,
+	})
+}
+
+// parseOpenAt 解析营业时间筛选参数：openAt 优先，其次 openNow
+func parseOpenAt(c *gin.Context) (*time.Time, error) {
+	if openAtStr := c.Query("openAt"); openAtStr != "" {
+		openAt, err := time.Parse(time.RFC3339, openAtStr)
+		if err != nil {
+			return nil, errors.New("无效的openAt参数，应为RFC3339格式")
+		}
+		return &openAt, nil
+	}
+
+	if openNowStr := c.Query("openNow"); openNowStr != "" {
+		openNow, err := strconv.ParseBool(openNowStr)
+		if err != nil {
+			return nil, errors.New("无效的openNow参数")
+		}
+		if openNow {
+			now := time.Now()
+			return &now, nil
+		}
+	}
+	return nil, nil
+}
+
+// SubmitBusiness 提交入驻申请
+// @Summary 提交入驻申请
+// @Description 将草稿或已驳回的商家提交审核
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "提交成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或当前状态不允许提交"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/submit [post]
+func (bc *BusinessController) SubmitBusiness(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := bc.businessService.SubmitBusiness(id, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "提交入驻申请失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "入驻申请已提交",
+	})
+}
+
+// AssignReviewer 分配审核人
+// @Summary 分配入驻审核人
+// @Description 为已提交的入驻申请分配审核人并进入审核中，审核中的申请可重新分配（仅管理员）
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param reviewer body model.AssignReviewerRequest true "审核人"
+// @Success 200 {object} map[string]interface{} "分配成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或请求参数错误"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/business/{id}/reviewer [put]
+func (bc *BusinessController) AssignReviewer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	var req model.AssignReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := bc.businessService.AssignReviewer(id, req.ReviewerID, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "分配审核人失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "审核人分配成功",
+	})
+}
+
+// ApproveBusiness 审核通过
+// @Summary 入驻审核通过
+// @Description 审核中的入驻申请由指定审核人或管理员审核通过
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param decision body model.ReviewDecisionRequest false "审核意见"
+// @Success 200 {object} map[string]interface{} "审核通过"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或当前状态不允许审核"
+// @Router /api/v1/business/{id}/approve [post]
+func (bc *BusinessController) ApproveBusiness(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	var req model.ReviewDecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "请求参数错误: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := bc.businessService.ApproveBusiness(id, actorID, req.Note); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "审核失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "入驻申请审核通过",
+	})
+}
+
+// RejectBusiness 驳回入驻申请
+// @Summary 驳回入驻申请
+// @Description 审核中的入驻申请由指定审核人或管理员驳回，必须填写驳回原因，商家修改后可重新提交
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param decision body model.ReviewDecisionRequest true "驳回原因"
+// @Success 200 {object} map[string]interface{} "已驳回"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID、缺少驳回原因或当前状态不允许审核"
+// @Router /api/v1/business/{id}/reject [post]
+func (bc *BusinessController) RejectBusiness(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	var req model.ReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := bc.businessService.RejectBusiness(id, actorID, req.Note); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "驳回失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "入驻申请已驳回",
+	})
+}
+
+// GetStatusHistory 获取商家状态变更历史
+// @Summary 获取商家状态变更历史
+// @Description 默认按时间顺序返回商家的全部状态变更，含操作人、原因代码、备注和触发的计划流转
+// @Description 支持 filter[fromStatus|toStatus|reasonCode|actorId|createdAt][操作符] 筛选、sort=-createdAt 排序，
+// @Description 以及 page/pageSize 偏移分页或 cursor 游标分页（首页传空值，之后传响应中的 nextCursor/prevCursor）
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param sort query string false "排序字段（id,createdAt），- 表示倒序"
+// @Param page query int false "页码（偏移分页）"
+// @Param pageSize query int false "每页数量（最大100）"
+// @Param cursor query string false "分页游标（游标分页）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或查询参数错误"
+// @Failure 404 {object} map[string]interface{} "商家不存在"
+// @Failure 403 {object} map[string]interface{} "不是该商家的成员"
+// @Router /api/v1/business/{id}/status-history [get]
+func (bc *BusinessController) GetStatusHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	query, err := common.ParseListQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	history, page, err := bc.businessService.GetStatusHistory(id, query)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	resp := gin.H{
+		"code":    200,
+		"message": "获取状态变更历史成功",
+		"data":    history,
+		"total":   page.Total,
+	}
+	if query.Paged() {
+		resp["page"] = query.Page
+		resp["pageSize"] = query.PageSize
+	}
+	if query.CursorMode {
+		resp["pageSize"] = query.PageSize
+		resp["nextCursor"] = page.NextCursor
+		resp["prevCursor"] = page.PrevCursor
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ScheduleStatusTransition 安排计划状态流转
+// @Summary 安排计划状态流转
+// @Description 在指定时间自动将商家流转到目标状态（仅支持 active/inactive/suspended），执行时商家状态须与安排时一致
+// @Description 解除暂停的计划仅管理员可安排
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param schedule body model.ScheduleTransitionRequest true "计划流转"
+// @Success 201 {object} map[string]interface{} "安排成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或请求参数错误"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/scheduled-transitions [post]
+func (bc *BusinessController) ScheduleStatusTransition(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	var req model.ScheduleTransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	schedule, err := bc.businessService.ScheduleStatusTransition(id, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "安排计划流转失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "计划流转安排成功",
+		"data":    schedule,
+	})
+}
+
+// GetScheduledTransitions 获取商家计划流转
+// @Summary 获取商家计划流转
+// @Description 获取商家全部计划流转及其执行状态
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID"
+// @Failure 404 {object} map[string]interface{} "商家不存在"
+// @Failure 403 {object} map[string]interface{} "不是该商家的成员"
+// @Router /api/v1/business/{id}/scheduled-transitions [get]
+func (bc *BusinessController) GetScheduledTransitions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	schedules, err := bc.businessService.GetScheduledTransitions(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取计划流转成功",
+		"data":    schedules,
+		"total":   len(schedules),
+	})
+}
+
+// CancelScheduledTransition 取消计划流转
+// @Summary 取消计划流转
+// @Description 取消尚未执行的计划流转
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param scheduleId path int true "计划流转ID"
+// @Success 200 {object} map[string]interface{} "取消成功"
+// @Failure 400 {object} map[string]interface{} "无效的ID或计划流转已执行"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/scheduled-transitions/{scheduleId} [delete]
+func (bc *BusinessController) CancelScheduledTransition(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	scheduleID, err := strconv.ParseUint(c.Param("scheduleId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的计划流转ID",
+		})
+		return
+	}
+
+	if err := bc.businessService.CancelScheduledTransition(id, uint(scheduleID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "取消计划流转失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "计划流转已取消",
+	})
+}
+
+// GetReviewQueue 获取待审核队列
+// @Summary 获取入驻待审核队列
+// @Description 获取已提交和审核中的入驻申请，按提交时间排序；mine=true 时只返回分配给当前用户的申请
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param reviewerId query int false "审核人ID"
+// @Param mine query bool false "只看分配给我的"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/review-queue [get]
+func (bc *BusinessController) GetReviewQueue(c *gin.Context) {
+	var reviewerID uint
+	if mine, _ := strconv.ParseBool(c.Query("mine")); mine {
+		reviewerID, _ = middleware.GetUserID(c)
+	} else if reviewerIDStr := c.Query("reviewerId"); reviewerIDStr != "" {
+		id, err := strconv.ParseUint(reviewerIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "无效的审核人ID",
+			})
+			return
+		}
+		reviewerID = uint(id)
+	}
+
+	businesses, err := bc.businessService.GetReviewQueue(reviewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取待审核队列失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取待审核队列成功",
+		"data":    businesses,
+		"total":   len(businesses),
+	})
+}
+
+// GetDeletedBusinesses 获取回收站中的商家
+// @Summary 获取回收站商家列表
+// @Description 分页获取已删除（在回收站中）的商家，按删除时间倒序（仅管理员）
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 403 {object} map[string]interface{} "仅管理员可操作"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/trash [get]
+func (bc *BusinessController) GetDeletedBusinesses(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 10
+	}
+
+	businesses, total, err := bc.businessService.GetDeletedBusinesses(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取回收站商家失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":     200,
+		"message":  "获取回收站商家成功",
+		"data":     businesses,
+		"total":    total,
+		"page":     page,
+		"pageSize": pageSize,
+	})
+}
+
+// RestoreBusiness 从回收站恢复商家
+// @Summary 恢复商家
+// @Description 从回收站恢复已删除的商家，邮箱已被其他商家使用时无法恢复
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "恢复成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或无法恢复"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Router /api/v1/business/{id}/restore [post]
+func (bc *BusinessController) RestoreBusiness(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	business, err := bc.businessService.RestoreBusiness(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "恢复商家失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "商家恢复成功",
+		"data":    business,
+	})
+}
+
+// PurgeBusiness 彻底删除商家
+// @Summary 彻底删除商家
+// @Description 彻底删除回收站中的商家及其营业时间、状态历史等关联数据，不可恢复，仅管理员可操作
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或商家不在回收站中"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/business/{id}/purge [delete]
+func (bc *BusinessController) PurgeBusiness(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	if err := bc.businessService.PurgeBusiness(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "彻底删除商家失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "商家已彻底删除",
+	})
+}
+
+// GetRevisions 获取商家修订记录
+// @Summary 获取商家修订记录
+// @Description 获取商家资料的全部修订版本（按版本倒序），包含每个版本的修改来源、变化字段与操作人
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或商家不存在"
+// @Failure 403 {object} map[string]interface{} "不是该商家的成员"
+// @Router /api/v1/business/{id}/revisions [get]
+func (bc *BusinessController) GetRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	revisions, err := bc.businessService.GetRevisions(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "获取修订记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取修订记录成功",
+		"data":    revisions,
+		"total":   len(revisions),
+	})
+}
+
+// GetRevision 获取商家指定修订版本
+// @Summary 获取商家修订版本详情
+// @Description 获取商家指定版本的修订记录及当时的完整资料
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的参数"
+// @Failure 404 {object} map[string]interface{} "修订版本不存在"
+// @Failure 403 {object} map[string]interface{} "不是该商家的成员"
+// @Router /api/v1/business/{id}/revisions/{version} [get]
+func (bc *BusinessController) GetRevision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的版本号",
+		})
+		return
+	}
+
+	revision, err := bc.businessService.GetRevision(id, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "获取修订版本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取修订版本成功",
+		"data":    revision,
+	})
+}
+
+// DiffRevisions 对比商家两个修订版本
+// @Summary 对比商家修订版本
+// @Description 对比商家任意两个修订版本，返回发生变化的字段及前后取值
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param from query int true "起始版本号"
+// @Param to query int true "目标版本号"
+// @Success 200 {object} map[string]interface{} "对比成功"
+// @Failure 400 {object} map[string]interface{} "无效的参数或版本不存在"
+// @Failure 403 {object} map[string]interface{} "不是该商家的成员"
+// @Router /api/v1/business/{id}/revisions/diff [get]
+func (bc *BusinessController) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的起始版本号",
+		})
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的目标版本号",
+		})
+		return
+	}
+
+	diffs, err := bc.businessService.DiffRevisions(id, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "对比修订版本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "对比修订版本成功",
+		"data": map[string]interface{}{
+			"from":    from,
+			"to":      to,
+			"changes": diffs,
+		},
+	})
+}
+
+// RestoreRevision 恢复商家到指定修订版本
+// @Summary 恢复商家修订版本
+// @Description 将商家资料与营业时间恢复为指定版本，恢复本身作为新的修订版本记录；商家状态不随之恢复
+// @Tags business
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} map[string]interface{} "恢复成功"
+// @Failure 400 {object} map[string]interface{} "无效的参数或恢复失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/revisions/{version}/restore [post]
+func (bc *BusinessController) RestoreRevision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的版本号",
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	business, err := bc.businessService.RestoreRevision(id, version, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "恢复修订版本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "修订版本恢复成功",
+		"data":    business,
+	})
+}
+
+// checkVersion 预先校验 If-Match 与商家当前版本并返回期望的版本号（0 表示不校验），未通过时已写入响应（428/404/412）。
+// 调用方须将版本号传入更新语句，以免校验与更新之间的并发修改被覆盖
+func (bc *BusinessController) checkVersion(c *gin.Context, id int) (int, bool) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"code":    428,
+			"message": err.Error(),
+		})
+		return 0, false
+	}
+
+	business, err := bc.businessService.GetBusiness(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "商家不存在",
+		})
+		return 0, false
+	}
+
+	if !versionMatches(version, business.Version) {
+		writeBusinessConflict(c, business)
+		return 0, false
+	}
+	return version, true
+}
+
+// respondVersionConflict 返回 412 与商家最新数据
+func (bc *BusinessController) respondVersionConflict(c *gin.Context, id int) {
+	business, err := bc.businessService.GetBusiness(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "商家不存在",
+		})
+		return
+	}
+	writeBusinessConflict(c, business)
+}
+
+// writeBusinessConflict 写入 412 响应，附带商家最新数据与 ETag
+func writeBusinessConflict(c *gin.Context, business *model.Business) {
+	setETag(c, business.Version)
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"code":    412,
+		"message": "商家已被其他人修改，请基于最新数据重新操作",
+		"data":    business,
+	})
+}
@@ -0,0 +1,417 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BusinessDocumentController 商家资质证件控制器
+type BusinessDocumentController struct {
+	documentService services.BusinessDocumentService
+}
+
+// NewBusinessDocumentController 创建商家资质证件控制器实例
+func NewBusinessDocumentController(documentService services.BusinessDocumentService) *BusinessDocumentController {
+	return &BusinessDocumentController{
+		documentService: documentService,
+	}
+}
+
+// parseDocumentPath 解析路径中的商家ID与证件ID
+func parseDocumentPath(c *gin.Context) (int, uint, bool) {
+	businessID, ok := parseBusinessID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	documentID, err := strconv.ParseUint(c.Param("documentId"), 10, 64)
+	if err != nil || documentID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的证件ID",
+		})
+		return 0, 0, false
+	}
+	return businessID, uint(documentID), true
+}
+
+// bindDocumentForm 解析证件表单与文件（字段名 file），未上传文件时 upload 为空；调用方需关闭返回的文件
+func (dc *BusinessDocumentController) bindDocumentForm(c *gin.Context) (*model.BusinessDocumentRequest, *services.DocumentUpload, multipart.File, bool) {
+	maxSize := dc.documentService.MaxUploadSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	var req model.BusinessDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    413,
+				"message": services.ErrMediaTooLarge.Error() + "（最大 " + strconv.FormatInt(maxSize, 10) + " 字节）",
+			})
+			return nil, nil, nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return nil, nil, nil, false
+	}
+
+	header, err := c.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		return &req, nil, nil, true
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "读取上传文件失败: " + err.Error(),
+		})
+		return nil, nil, nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "读取上传文件失败: " + err.Error(),
+		})
+		return nil, nil, nil, false
+	}
+	return &req, &services.DocumentUpload{File: file, Size: header.Size, Filename: header.Filename}, file, true
+}
+
+// documentError 按错误类型返回证件上传、修改失败的响应
+func documentError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrMediaTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"code": 413, "message": err.Error()})
+	case errors.Is(err, services.ErrDocumentTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"code": 415, "message": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": message + ": " + err.Error()})
+	}
+}
+
+// GetDocuments 获取商家资质证件
+// @Summary 获取商家资质证件
+// @Description 获取商家的营业执照、许可证、身份证件等资质证件及审核状态（店主、店长、品牌管理员或管理员）。
+// @Description expiryFlaggedAt 不为空表示证件即将到期，文件通过 fileUrl 下载
+// @Tags business-documents
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/{id}/documents [get]
+func (dc *BusinessDocumentController) GetDocuments(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	documents, err := dc.documentService.GetDocuments(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取资质证件失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取资质证件成功",
+		"data":    documents,
+	})
+}
+
+// UploadDocument 上传资质证件
+// @Summary 上传资质证件
+// @Description 以 multipart/form-data 上传资质证件（店主、店长、品牌管理员或管理员），文件字段名 file，仅支持 PDF、JPEG、PNG，
+// @Description 大小上限同媒体文件。证件文件不公开，上传后等待管理员核验
+// @Tags business-documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param type formData string true "证件类型（business_license, food_permit, id_card, other）"
+// @Param number formData string true "证件编号"
+// @Param issuingAuthority formData string false "发证机关"
+// @Param validFrom formData string false "有效期起（YYYY-MM-DD）"
+// @Param validUntil formData string false "有效期至（YYYY-MM-DD），不填表示长期有效"
+// @Param file formData file true "证件文件"
+// @Success 201 {object} map[string]interface{} "上传成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Failure 413 {object} map[string]interface{} "文件过大"
+// @Failure 415 {object} map[string]interface{} "不支持的文件类型"
+// @Router /api/v1/business/{id}/documents [post]
+func (dc *BusinessDocumentController) UploadDocument(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+	req, upload, file, ok := dc.bindDocumentForm(c)
+	if !ok {
+		return
+	}
+	if file != nil {
+		defer file.Close()
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	document, err := dc.documentService.UploadDocument(id, req, upload, actorID)
+	if err != nil {
+		documentError(c, "上传资质证件失败", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "资质证件上传成功，等待核验",
+		"data":    document,
+	})
+}
+
+// UpdateDocument 修改资质证件
+// @Summary 修改资质证件
+// @Description 修改证件信息，可同时上传新文件替换原文件（店主、店长、品牌管理员或管理员）；修改后需重新核验
+// @Tags business-documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param documentId path int true "证件ID"
+// @Param type formData string true "证件类型（business_license, food_permit, id_card, other）"
+// @Param number formData string true "证件编号"
+// @Param issuingAuthority formData string false "发证机关"
+// @Param validFrom formData string false "有效期起（YYYY-MM-DD）"
+// @Param validUntil formData string false "有效期至（YYYY-MM-DD），不填表示长期有效"
+// @Param file formData file false "新的证件文件，不传时保留原文件"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} map[string]interface{} "修改失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Failure 413 {object} map[string]interface{} "文件过大"
+// @Failure 415 {object} map[string]interface{} "不支持的文件类型"
+// @Router /api/v1/business/{id}/documents/{documentId} [put]
+func (dc *BusinessDocumentController) UpdateDocument(c *gin.Context) {
+	businessID, documentID, ok := parseDocumentPath(c)
+	if !ok {
+		return
+	}
+	req, upload, file, ok := dc.bindDocumentForm(c)
+	if !ok {
+		return
+	}
+	if file != nil {
+		defer file.Close()
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	document, err := dc.documentService.UpdateDocument(businessID, documentID, req, upload, actorID)
+	if err != nil {
+		documentError(c, "修改资质证件失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "资质证件修改成功，等待核验",
+		"data":    document,
+	})
+}
+
+// DeleteDocument 删除资质证件
+// @Summary 删除资质证件
+// @Description 删除证件及其文件（店主、店长、品牌管理员或管理员），已核验的证件仅管理员可删除
+// @Tags business-documents
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param documentId path int true "证件ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/documents/{documentId} [delete]
+func (dc *BusinessDocumentController) DeleteDocument(c *gin.Context) {
+	businessID, documentID, ok := parseDocumentPath(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := dc.documentService.DeleteDocument(businessID, documentID, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除资质证件失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "资质证件删除成功",
+	})
+}
+
+// GetDocumentFile 下载资质证件文件
+// @Summary 下载资质证件文件
+// @Description 下载证件文件（店主、店长、品牌管理员或管理员），响应不允许缓存
+// @Tags business-documents
+// @Produce octet-stream
+// @Param id path int true "商家ID"
+// @Param documentId path int true "证件ID"
+// @Success 200 {file} file "文件内容"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Failure 404 {object} map[string]interface{} "证件不存在"
+// @Router /api/v1/business/{id}/documents/{documentId}/file [get]
+func (dc *BusinessDocumentController) GetDocumentFile(c *gin.Context) {
+	businessID, documentID, ok := parseDocumentPath(c)
+	if !ok {
+		return
+	}
+
+	document, body, err := dc.documentService.OpenDocumentFile(businessID, documentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	extraHeaders := map[string]string{}
+	if document.Filename != "" {
+		extraHeaders["Content-Disposition"] = "attachment; filename*=UTF-8''" + url.PathEscape(document.Filename)
+	}
+	c.DataFromReader(http.StatusOK, document.Size, document.ContentType, io.LimitReader(body, document.Size), extraHeaders)
+}
+
+// VerifyDocument 核验通过资质证件
+// @Summary 核验通过资质证件
+// @Description 管理员核验证件真实有效，已过有效期的证件不能核验
+// @Tags business-documents
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param documentId path int true "证件ID"
+// @Success 200 {object} map[string]interface{} "核验成功"
+// @Failure 400 {object} map[string]interface{} "核验失败"
+// @Router /api/v1/business/{id}/documents/{documentId}/verify [post]
+func (dc *BusinessDocumentController) VerifyDocument(c *gin.Context) {
+	businessID, documentID, ok := parseDocumentPath(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	document, err := dc.documentService.VerifyDocument(businessID, documentID, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "核验失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "证件已核验",
+		"data":    document,
+	})
+}
+
+// RejectDocument 驳回资质证件
+// @Summary 驳回资质证件
+// @Description 管理员驳回证件，需填写驳回原因
+// @Tags business-documents
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param documentId path int true "证件ID"
+// @Param decision body model.ReviewDecisionRequest true "驳回原因"
+// @Success 200 {object} map[string]interface{} "驳回成功"
+// @Failure 400 {object} map[string]interface{} "驳回失败"
+// @Router /api/v1/business/{id}/documents/{documentId}/reject [post]
+func (dc *BusinessDocumentController) RejectDocument(c *gin.Context) {
+	businessID, documentID, ok := parseDocumentPath(c)
+	if !ok {
+		return
+	}
+
+	var req model.ReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	document, err := dc.documentService.RejectDocument(businessID, documentID, actorID, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "驳回失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "证件已驳回",
+		"data":    document,
+	})
+}
+
+// GetExpiringDocuments 获取即将到期的资质证件
+// @Summary 获取即将到期的资质证件
+// @Description 获取全部商家在 days 天内到期的待审核或已核验证件，按到期日排序（仅管理员）
+// @Tags business-documents
+// @Accept json
+// @Produce json
+// @Param days query int false "天数，默认使用 DOCUMENT_EXPIRY_WARNING_DAYS 配置（30）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /api/v1/business/documents/expiring [get]
+func (dc *BusinessDocumentController) GetExpiringDocuments(c *gin.Context) {
+	days := 0
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "无效的天数",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	documents, err := dc.documentService.GetExpiringDocuments(days)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取即将到期的证件成功",
+		"data":    documents,
+		"total":   len(documents),
+	})
+}
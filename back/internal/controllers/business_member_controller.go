@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BusinessMemberController 商家成员控制器
+type BusinessMemberController struct {
+	memberService services.BusinessMemberService
+}
+
+// NewBusinessMemberController 创建商家成员控制器实例
+func NewBusinessMemberController(memberService services.BusinessMemberService) *BusinessMemberController {
+	return &BusinessMemberController{
+		memberService: memberService,
+	}
+}
+
+// parseBusinessID 解析路径中的商家ID
+func parseBusinessID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// parseMemberUserID 解析路径中的成员用户ID
+func parseMemberUserID(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的用户ID",
+		})
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// GetMembers 获取商家成员
+// @Summary 获取商家成员
+// @Description 获取商家的成员及待接受的邀请（商家成员、品牌管理员或管理员），店主在前
+// @Tags business-members
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID"
+// @Failure 403 {object} map[string]interface{} "无权查看"
+// @Router /api/v1/business/{id}/members [get]
+func (mc *BusinessMemberController) GetMembers(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	members, err := mc.memberService.GetMembers(id, actorID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取商家成员成功",
+		"data":    members,
+	})
+}
+
+// InviteMember 邀请商家成员
+// @Summary 邀请商家成员
+// @Description 按用户ID或邮箱邀请用户加入商家（仅店主、品牌管理员或管理员），角色为 owner/manager/cashier/viewer，被邀请人接受后生效
+// @Tags business-members
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param member body model.BusinessMemberInviteRequest true "被邀请人与角色"
+// @Success 201 {object} map[string]interface{} "邀请成功"
+// @Failure 400 {object} map[string]interface{} "邀请失败"
+// @Router /api/v1/business/{id}/members [post]
+func (mc *BusinessMemberController) InviteMember(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessMemberInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	member, err := mc.memberService.InviteMember(id, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "邀请商家成员失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "邀请已发送",
+		"data":    member,
+	})
+}
+
+// UpdateMemberRole 修改商家成员角色
+// @Summary 修改商家成员角色
+// @Description 修改成员角色（仅店主、品牌管理员或管理员），商家至少保留一名店主
+// @Tags business-members
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param userId path int true "用户ID"
+// @Param role body model.BusinessMemberRoleRequest true "角色"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} map[string]interface{} "修改失败"
+// @Router /api/v1/business/{id}/members/{userId} [put]
+func (mc *BusinessMemberController) UpdateMemberRole(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	member, err := mc.memberService.UpdateMemberRole(id, userID, req.Role, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "修改成员角色失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "成员角色修改成功",
+		"data":    member,
+	})
+}
+
+// RemoveMember 移除商家成员
+// @Summary 移除商家成员
+// @Description 移除成员或撤回邀请（仅店主、品牌管理员或管理员），商家至少保留一名店主
+// @Tags business-members
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param userId path int true "用户ID"
+// @Success 200 {object} map[string]interface{} "移除成功"
+// @Failure 400 {object} map[string]interface{} "移除失败"
+// @Router /api/v1/business/{id}/members/{userId} [delete]
+func (mc *BusinessMemberController) RemoveMember(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseMemberUserID(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := mc.memberService.RemoveMember(id, userID, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "移除商家成员失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "商家成员移除成功",
+	})
+}
+
+// GetMemberships 获取我的商家
+// @Summary 获取我的商家
+// @Description 获取当前用户加入或被邀请的商家及角色（不含回收站中的商家）
+// @Tags business-members
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/memberships [get]
+func (mc *BusinessMemberController) GetMemberships(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	members, err := mc.memberService.GetMemberships(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取我的商家失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取我的商家成功",
+		"data":    members,
+	})
+}
+
+// AcceptInvitation 接受商家邀请
+// @Summary 接受商家邀请
+// @Description 当前用户接受商家的成员邀请，接受后按角色获得该商家的权限
+// @Tags business-members
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "已加入"
+// @Failure 400 {object} map[string]interface{} "邀请不存在或已加入"
+// @Router /api/v1/business/{id}/membership/accept [post]
+func (mc *BusinessMemberController) AcceptInvitation(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	member, err := mc.memberService.AcceptInvitation(id, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已加入商家",
+		"data":    member,
+	})
+}
+
+// LeaveBusiness 退出商家
+// @Summary 退出商家
+// @Description 当前用户退出商家或拒绝邀请，唯一的店主不能退出
+// @Tags business-members
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "已退出"
+// @Failure 400 {object} map[string]interface{} "退出失败"
+// @Router /api/v1/business/{id}/membership [delete]
+func (mc *BusinessMemberController) LeaveBusiness(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	if err := mc.memberService.LeaveBusiness(id, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "退出商家失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已退出商家",
+	})
+}
@@ -0,0 +1,396 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BusinessPhotoController 商家相册控制器
+type BusinessPhotoController struct {
+	photoService services.BusinessPhotoService
+}
+
+// NewBusinessPhotoController 创建商家相册控制器实例
+func NewBusinessPhotoController(photoService services.BusinessPhotoService) *BusinessPhotoController {
+	return &BusinessPhotoController{
+		photoService: photoService,
+	}
+}
+
+// parsePhotoPath 解析路径中的商家ID与照片ID，photo 为 false 时只解析商家ID
+func parsePhotoPath(c *gin.Context, photo bool) (int, uint, bool) {
+	businessID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return 0, 0, false
+	}
+	if !photo {
+		return businessID, 0, true
+	}
+	photoID, err := strconv.ParseUint(c.Param("photoId"), 10, 64)
+	if err != nil || photoID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的照片ID",
+		})
+		return 0, 0, false
+	}
+	return businessID, uint(photoID), true
+}
+
+// GetPhotos 获取商家相册
+// @Summary 获取商家相册
+// @Description 按顺序返回商家相册照片。管理员可见全部照片并可按审核状态筛选；其他用户可见已审核通过的照片及自己添加的照片
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param status query string false "审核状态（pending, approved, rejected）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /api/v1/business/{id}/photos [get]
+func (pc *BusinessPhotoController) GetPhotos(c *gin.Context) {
+	businessID, _, ok := parsePhotoPath(c, false)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	photos, err := pc.photoService.GetPhotos(businessID, c.Query("status"), actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "获取相册失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    photos,
+	})
+}
+
+// AddPhoto 添加相册照片
+// @Summary 添加相册照片
+// @Description 将自己上传的图片（POST /api/v1/media）添加到商家相册末尾（店主、店长、品牌管理员或管理员）。管理员添加的照片直接通过，其他用户添加的需管理员审核后公开显示；
+// @Description 只有审核通过的照片可设为封面，每个商家至多一张封面
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param photo body model.BusinessPhotoRequest true "照片信息"
+// @Success 201 {object} map[string]interface{} "添加成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/photos [post]
+func (pc *BusinessPhotoController) AddPhoto(c *gin.Context) {
+	businessID, _, ok := parsePhotoPath(c, false)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	photo, err := pc.photoService.AddPhoto(businessID, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "添加照片失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "照片添加成功",
+		"data":    photo,
+	})
+}
+
+// UpdatePhoto 修改相册照片
+// @Summary 修改相册照片
+// @Description 修改照片说明或封面标记，未传的字段不修改。非管理员修改已通过照片的说明后需重新审核
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param photoId path int true "照片ID"
+// @Param photo body model.BusinessPhotoUpdateRequest true "修改内容"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/photos/{photoId} [patch]
+func (pc *BusinessPhotoController) UpdatePhoto(c *gin.Context) {
+	businessID, photoID, ok := parsePhotoPath(c, true)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessPhotoUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	photo, err := pc.photoService.UpdatePhoto(businessID, photoID, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "修改照片失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "照片修改成功",
+		"data":    photo,
+	})
+}
+
+// ReorderPhotos 调整相册顺序
+// @Summary 调整相册顺序
+// @Description 按 photoIds 的顺序排列照片，未列出的照片保持原有相对顺序排在其后
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param order body model.BusinessPhotoIDsRequest true "照片ID顺序"
+// @Success 200 {object} map[string]interface{} "排序成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/photos/order [put]
+func (pc *BusinessPhotoController) ReorderPhotos(c *gin.Context) {
+	businessID, _, ok := parsePhotoPath(c, false)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessPhotoIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	photos, err := pc.photoService.ReorderPhotos(businessID, req.PhotoIDs, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "调整顺序失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "顺序调整成功",
+		"data":    photos,
+	})
+}
+
+// DeletePhoto 删除相册照片
+// @Summary 删除相册照片
+// @Description 从相册中删除照片（图片文件保留，可通过媒体文件接口删除），非管理员只能删除自己添加的照片
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param photoId path int true "照片ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/photos/{photoId} [delete]
+func (pc *BusinessPhotoController) DeletePhoto(c *gin.Context) {
+	businessID, photoID, ok := parsePhotoPath(c, true)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := pc.photoService.DeletePhotos(businessID, []uint{photoID}, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除照片失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "照片删除成功",
+	})
+}
+
+// BatchDeletePhotos 批量删除相册照片
+// @Summary 批量删除相册照片
+// @Description 批量删除照片，非管理员只能删除自己添加的照片；任一照片不存在或不能删除时全部不删除
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param photos body model.BusinessPhotoIDsRequest true "照片ID列表"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的编辑权限（需店主或店长）"
+// @Router /api/v1/business/{id}/photos/batch-delete [post]
+func (pc *BusinessPhotoController) BatchDeletePhotos(c *gin.Context) {
+	businessID, _, ok := parsePhotoPath(c, false)
+	if !ok {
+		return
+	}
+
+	var req model.BusinessPhotoIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := pc.photoService.DeletePhotos(businessID, req.PhotoIDs, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "批量删除照片失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "照片批量删除成功",
+		"data":    gin.H{"deleted": len(req.PhotoIDs)},
+	})
+}
+
+// ApprovePhoto 审核通过照片
+// @Summary 审核通过照片
+// @Description 审核通过后照片公开显示（仅管理员）
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param photoId path int true "照片ID"
+// @Success 200 {object} map[string]interface{} "审核成功"
+// @Failure 400 {object} map[string]interface{} "审核失败"
+// @Router /api/v1/business/{id}/photos/{photoId}/approve [post]
+func (pc *BusinessPhotoController) ApprovePhoto(c *gin.Context) {
+	businessID, photoID, ok := parsePhotoPath(c, true)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	photo, err := pc.photoService.ApprovePhoto(businessID, photoID, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "审核失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "照片已审核通过",
+		"data":    photo,
+	})
+}
+
+// RejectPhoto 驳回照片
+// @Summary 驳回照片
+// @Description 驳回照片并记录原因（note 必填），封面照片被驳回后取消封面（仅管理员）
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param photoId path int true "照片ID"
+// @Param decision body model.ReviewDecisionRequest true "驳回原因"
+// @Success 200 {object} map[string]interface{} "驳回成功"
+// @Failure 400 {object} map[string]interface{} "驳回失败"
+// @Router /api/v1/business/{id}/photos/{photoId}/reject [post]
+func (pc *BusinessPhotoController) RejectPhoto(c *gin.Context) {
+	businessID, photoID, ok := parsePhotoPath(c, true)
+	if !ok {
+		return
+	}
+
+	var req model.ReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	photo, err := pc.photoService.RejectPhoto(businessID, photoID, actorID, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "驳回失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "照片已驳回",
+		"data":    photo,
+	})
+}
+
+// GetPendingPhotos 获取待审核照片
+// @Summary 获取待审核照片
+// @Description 获取全部商家待审核的相册照片，按添加时间排序（仅管理员）
+// @Tags business-photos
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/photos/pending [get]
+func (pc *BusinessPhotoController) GetPendingPhotos(c *gin.Context) {
+	photos, err := pc.photoService.GetPendingPhotos()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取待审核照片失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    photos,
+	})
+}
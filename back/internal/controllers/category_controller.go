@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryController 商家分类控制器
+type CategoryController struct {
+	categoryService services.CategoryService
+}
+
+// NewCategoryController 创建商家分类控制器实例
+func NewCategoryController(categoryService services.CategoryService) *CategoryController {
+	return &CategoryController{
+		categoryService: categoryService,
+	}
+}
+
+// parseCategoryID 解析路径中的分类ID
+func parseCategoryID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的分类ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetCategoryTree 获取分类树
+// @Summary 获取商家分类树
+// @Description 获取全部商家分类的层级结构，同级按 sortOrder、ID 升序，每个分类附带直接关联的商家数量
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param locale query string false "语言代码（如 zh、en、ja-JP），指定时返回该语言的显示名称 displayName"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/categories [get]
+func (cc *CategoryController) GetCategoryTree(c *gin.Context) {
+	categories, err := cc.categoryService.GetCategoryTree(c.Query("locale"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    categories,
+	})
+}
+
+// GetCategory 获取单个分类
+// @Summary 获取单个商家分类
+// @Description 根据ID获取商家分类
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Param locale query string false "语言代码，指定时返回该语言的显示名称 displayName"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的分类ID"
+// @Failure 404 {object} map[string]interface{} "分类不存在"
+// @Router /api/v1/categories/{id} [get]
+func (cc *CategoryController) GetCategory(c *gin.Context) {
+	id, ok := parseCategoryID(c)
+	if !ok {
+		return
+	}
+
+	category, err := cc.categoryService.GetCategory(id, c.Query("locale"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    category,
+	})
+}
+
+// CreateCategory 创建分类
+// @Summary 创建商家分类
+// @Description 创建商家分类，最多3级；slug 为空时由名称生成（名称不含字母或数字时必须填写），仅管理员可操作
+// @Description attributeSchema 定义该分类商家的自定义属性（JSON Schema 子集：type 为 object，properties 中各属性的 type 为 string/integer/number/boolean/array，
+// @Description 支持 enum、minimum/maximum、minLength/maxLength/pattern、items/minItems/maxItems/uniqueItems，required 列出必填属性），保存商家时按其校验
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body model.CategoryRequest true "分类信息"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/categories [post]
+func (cc *CategoryController) CreateCategory(c *gin.Context) {
+	var req model.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	category, err := cc.categoryService.CreateCategory(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "创建分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "分类创建成功",
+		"data":    category,
+	})
+}
+
+// UpdateCategory 更新分类
+// @Summary 更新商家分类
+// @Description 更新商家分类；修改 slug 时，以该分类为主分类的商家的 type 同步更新，仅管理员可操作
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Param category body model.CategoryRequest true "分类信息"
+// @Success 200 {object} map[string]interface{} "更新成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/categories/{id} [put]
+func (cc *CategoryController) UpdateCategory(c *gin.Context) {
+	id, ok := parseCategoryID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	category, err := cc.categoryService.UpdateCategory(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "更新分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "分类更新成功",
+		"data":    category,
+	})
+}
+
+// DeleteCategory 删除分类
+// @Summary 删除商家分类
+// @Description 删除没有子分类且没有商家使用的分类；仍有商家使用时请先合并到其他分类，仅管理员可操作
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "分类不存在或仍在使用"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/categories/{id} [delete]
+func (cc *CategoryController) DeleteCategory(c *gin.Context) {
+	id, ok := parseCategoryID(c)
+	if !ok {
+		return
+	}
+
+	if err := cc.categoryService.DeleteCategory(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "分类删除成功",
+	})
+}
+
+// MergeCategory 合并分类
+// @Summary 合并商家分类
+// @Description 将分类合并到目标分类：关联该分类的商家改为关联目标分类（主分类变化的商家同步 type），
+// @Description 子分类移到目标分类下，然后删除该分类，仅管理员可操作
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "被合并的分类ID"
+// @Param request body model.CategoryMergeRequest true "目标分类"
+// @Success 200 {object} map[string]interface{} "合并成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/categories/{id}/merge [post]
+func (cc *CategoryController) MergeCategory(c *gin.Context) {
+	id, ok := parseCategoryID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CategoryMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	affected, err := cc.categoryService.MergeCategories(id, req.TargetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "合并分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "分类合并成功",
+		"data": gin.H{
+			"targetId":           req.TargetID,
+			"affectedBusinesses": affected,
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag 以数据版本号设置响应的 ETag
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion 解析 If-Match 请求头中的版本号（支持 "3"、W/"3"），"*" 返回 0 表示不校验版本
+func ifMatchVersion(c *gin.Context) (int, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" {
+		return 0, errors.New("缺少 If-Match 请求头，请先获取最新数据")
+	}
+	if value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	version, err := strconv.Atoi(strings.Trim(value, `"`))
+	if err != nil || version <= 0 {
+		return 0, errors.New("If-Match 请求头格式无效")
+	}
+	return version, nil
+}
+
+// versionMatches 检查 If-Match 版本号与当前版本是否一致，0 表示不校验
+func versionMatches(expected, current int) bool {
+	return expected == 0 || expected == current
+}
@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"merchant_back/internal/middleware"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead 上传请求中文件以外部分（分隔符、表单头等）允许的字节数
+const multipartOverhead = 64 << 10
+
+// 媒体内容的缓存策略：内容写入后不再修改，允许客户端与 CDN 长期缓存；
+// 缩略图生成前以原图代替时只短期缓存，以便生成后尽快使用缩略图
+const (
+	mediaCacheControl            = "public, max-age=31536000, immutable"
+	mediaProvisionalCacheControl = "public, max-age=60"
+)
+
+// MediaController 媒体文件控制器
+type MediaController struct {
+	mediaService services.MediaService
+}
+
+// NewMediaController 创建媒体文件控制器实例
+func NewMediaController(mediaService services.MediaService) *MediaController {
+	return &MediaController{
+		mediaService: mediaService,
+	}
+}
+
+// parseMediaID 解析路径中的媒体文件ID
+func parseMediaID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的文件ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// UploadMedia 上传媒体文件
+// @Summary 上传媒体文件
+// @Description 以 multipart/form-data 上传图片（字段名 file），类型按文件内容识别，仅支持 JPEG、PNG、GIF、WebP；
+// @Description 大小上限由 MEDIA_MAX_UPLOAD_SIZE 配置（默认 5MB）。返回的 id 可用作商家的 imageMediaId
+// @Description 保存前移除 EXIF（含 GPS 位置）、XMP 等元数据；缩略图与主色调在后台生成，status 为 ready 后可在 variants 中查看
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "图片文件"
+// @Success 201 {object} map[string]interface{} "上传成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 413 {object} map[string]interface{} "文件过大"
+// @Failure 415 {object} map[string]interface{} "不支持的文件类型"
+// @Failure 500 {object} map[string]interface{} "上传失败"
+// @Router /api/v1/media [post]
+func (mc *MediaController) UploadMedia(c *gin.Context) {
+	maxSize := mc.mediaService.MaxUploadSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    413,
+				"message": services.ErrMediaTooLarge.Error() + "（最大 " + strconv.FormatInt(maxSize, 10) + " 字节）",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请通过 file 字段上传文件: " + err.Error(),
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "读取上传文件失败: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	actorID, _ := middleware.GetUserID(c)
+	media, err := mc.mediaService.UploadMedia(file, header.Size, header.Filename, actorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"code": 413, "message": err.Error()})
+		case errors.Is(err, services.ErrMediaTypeNotAllowed):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"code": 415, "message": err.Error()})
+		case errors.Is(err, services.ErrMediaInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "上传失败: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "上传成功",
+		"data":    media,
+	})
+}
+
+// GetMedia 获取媒体文件信息
+// @Summary 获取媒体文件信息
+// @Description 获取媒体文件的类型、大小、尺寸、访问地址、处理状态、主色调（dominantColor）及已生成的缩略图（variants）
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param id path int true "文件ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的文件ID"
+// @Failure 404 {object} map[string]interface{} "文件不存在"
+// @Router /api/v1/media/{id} [get]
+func (mc *MediaController) GetMedia(c *gin.Context) {
+	id, ok := parseMediaID(c)
+	if !ok {
+		return
+	}
+
+	media, err := mc.mediaService.GetMedia(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    media,
+	})
+}
+
+// GetMediaContent 获取媒体文件内容
+// @Summary 获取媒体文件内容
+// @Description 返回原图或缩略图（无需登录，可直接用于 img 标签）；存储配置了公开地址时重定向到该地址。
+// @Description size 为缩略图规格：sm（160px）、md（480px）、lg（1200px），按最长边等比缩小，已按 EXIF 方向摆正并去除全部元数据；
+// @Description 服务配置了 WebP 编码时，format=webp 或请求头 Accept 包含 image/webp 时返回 WebP 缩略图。
+// @Description 缩略图在上传后异步生成，生成前返回原图并只允许短期缓存；其余内容写入后不再修改，响应带长期缓存头与 ETag，支持 If-None-Match
+// @Tags media
+// @Produce octet-stream
+// @Param id path int true "文件ID"
+// @Param size query string false "缩略图规格：sm、md、lg，不传返回原图"
+// @Param format query string false "缩略图格式：webp，不传时按 Accept 请求头选择"
+// @Success 200 {file} file "文件内容"
+// @Success 302 {string} string "重定向到存储的公开地址"
+// @Success 304 {string} string "未修改"
+// @Failure 400 {object} map[string]interface{} "无效的规格或格式"
+// @Failure 404 {object} map[string]interface{} "文件不存在"
+// @Router /api/v1/media/{id}/content [get]
+func (mc *MediaController) GetMediaContent(c *gin.Context) {
+	id, ok := parseMediaID(c)
+	if !ok {
+		return
+	}
+
+	size, format := c.Query("size"), c.Query("format")
+	acceptWebP := strings.Contains(c.GetHeader("Accept"), "image/webp")
+	content, err := mc.mediaService.OpenMediaContent(id, size, format, acceptWebP)
+	if err != nil {
+		if errors.Is(err, services.ErrMediaVariantInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// 缩略图按 Accept 选择格式时，缓存需区分 Accept
+	if size != "" && format == "" {
+		c.Header("Vary", "Accept")
+	}
+	if content.Provisional {
+		c.Header("Cache-Control", mediaProvisionalCacheControl)
+	} else {
+		c.Header("Cache-Control", mediaCacheControl)
+	}
+	if content.Redirect != "" {
+		c.Redirect(http.StatusFound, content.Redirect)
+		return
+	}
+	defer content.Body.Close()
+
+	etag := `"` + content.Checksum + `"`
+	c.Header("ETag", etag)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, content.Size, content.ContentType, io.LimitReader(content.Body, content.Size), nil)
+}
+
+// DeleteMedia 删除媒体文件
+// @Summary 删除媒体文件
+// @Description 删除媒体文件及其存储内容，仅上传者或管理员可操作；仍被商家使用的文件不能删除
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param id path int true "文件ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Router /api/v1/media/{id} [delete]
+func (mc *MediaController) DeleteMedia(c *gin.Context) {
+	id, ok := parseMediaID(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := mc.mediaService.DeleteMedia(id, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除文件失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "文件删除成功",
+	})
+}
@@ -0,0 +1,558 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"merchant_back/internal/common"
+	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewController 顾客评价控制器
+type ReviewController struct {
+	reviewService services.ReviewService
+}
+
+// NewReviewController 创建顾客评价控制器实例
+func NewReviewController(reviewService services.ReviewService) *ReviewController {
+	return &ReviewController{
+		reviewService: reviewService,
+	}
+}
+
+// parseReviewID 解析路径中的评价ID
+func parseReviewID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的评价ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetReviews 获取商家的评价
+// @Summary 获取商家的评价
+// @Description 默认按发表时间倒序返回商家的评价，含照片地址与商家回复。管理员可见全部评价，其他用户只能看到已公开的评价
+// @Description 支持 filter[stars|status|userId|createdAt][操作符] 筛选、sort=-stars 排序（id,stars,createdAt,updatedAt），
+// @Description 以及 page/pageSize 偏移分页或 cursor 游标分页（首页传空值，之后传响应中的 nextCursor/prevCursor）
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param sort query string false "排序字段，- 表示倒序"
+// @Param page query int false "页码（偏移分页）"
+// @Param pageSize query int false "每页数量（最大100）"
+// @Param cursor query string false "分页游标（游标分页）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID或查询参数错误"
+// @Failure 404 {object} map[string]interface{} "商家不存在"
+// @Router /api/v1/business/{id}/reviews [get]
+func (rc *ReviewController) GetReviews(c *gin.Context) {
+	businessID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	query, err := common.ParseListQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	reviews, page, err := rc.reviewService.GetReviews(businessID, query, actorID)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	resp := gin.H{
+		"code":    200,
+		"message": "获取评价成功",
+		"data":    reviews,
+		"total":   page.Total,
+	}
+	if query.Paged() {
+		resp["page"] = query.Page
+		resp["pageSize"] = query.PageSize
+	}
+	if query.CursorMode {
+		resp["pageSize"] = query.PageSize
+		resp["nextCursor"] = page.NextCursor
+		resp["prevCursor"] = page.PrevCursor
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRating 获取商家评分
+// @Summary 获取商家评分
+// @Description 返回商家评分汇总：rating 为商家评分（与商家的 rating 字段一致），average 为算术平均，bayesian 为贝叶斯平均，
+// @Description count 为计入评分的评价数，distribution 为各星级的评价数。只统计已公开的评价，随评价变化实时更新
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的商家ID"
+// @Failure 404 {object} map[string]interface{} "商家不存在"
+// @Router /api/v1/business/{id}/rating [get]
+func (rc *ReviewController) GetRating(c *gin.Context) {
+	businessID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	rating, err := rc.reviewService.GetRating(businessID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取评分成功",
+		"data":    rating,
+	})
+}
+
+// CreateReview 发表评价
+// @Summary 发表评价
+// @Description 对营业中的商家发表评价（星级 1-5，内容最多 2000 字，最多 9 张照片），每个用户对每个商家只能发表一条评价。
+// @Description 照片需先通过媒体文件接口上传，只能使用自己上传的图片。评价发表后立即公开并计入商家评分；
+// @Description 命中自动审核规则（内容重复、频繁发表、不雅词汇、星级异常等）的评价进入待审核，审核通过后才公开并计入评分
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param review body model.ReviewRequest true "评价"
+// @Success 201 {object} map[string]interface{} "发表成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /api/v1/business/{id}/reviews [post]
+func (rc *ReviewController) CreateReview(c *gin.Context) {
+	businessID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商家ID",
+		})
+		return
+	}
+
+	var req model.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	review, err := rc.reviewService.CreateReview(businessID, &req, actorID, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "发表评价失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "评价发表成功",
+		"data":    review,
+	})
+}
+
+// GetReview 获取评价
+// @Summary 获取评价
+// @Description 获取评价详情，未公开的评价只有作者与管理员可见
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的评价ID"
+// @Failure 404 {object} map[string]interface{} "评价不存在"
+// @Router /api/v1/reviews/{id} [get]
+func (rc *ReviewController) GetReview(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	review, err := rc.reviewService.GetReview(id, actorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取评价成功",
+		"data":    review,
+	})
+}
+
+// UpdateReview 修改评价
+// @Summary 修改评价
+// @Description 修改自己的评价（星级、内容与照片整体替换），商家评分随之更新；被驳回或修改后命中自动审核规则的评价进入待审核，被隐藏的评价保持隐藏
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Param review body model.ReviewRequest true "评价"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} map[string]interface{} "修改失败"
+// @Router /api/v1/reviews/{id} [put]
+func (rc *ReviewController) UpdateReview(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	review, err := rc.reviewService.UpdateReview(id, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "修改评价失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "评价修改成功",
+		"data":    review,
+	})
+}
+
+// DeleteReview 删除评价
+// @Summary 删除评价
+// @Description 删除评价并从商家评分中扣除，仅作者或管理员可操作
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Router /api/v1/reviews/{id} [delete]
+func (rc *ReviewController) DeleteReview(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	if err := rc.reviewService.DeleteReview(id, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除评价失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "评价删除成功",
+	})
+}
+
+// moderateReview 执行审核操作
+func (rc *ReviewController) moderateReview(c *gin.Context, action, message string) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ReviewModerationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "请求参数错误: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	review, err := rc.reviewService.ModerateReview(id, action, &req, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "审核失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    review,
+	})
+}
+
+// ApproveReview 审核通过评价
+// @Summary 审核通过评价
+// @Description 公开显示评价并计入商家评分，可附原因代码（默认 verified）与备注（仅管理员）
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Param decision body model.ReviewModerationRequest false "原因与备注"
+// @Success 200 {object} map[string]interface{} "审核通过"
+// @Failure 400 {object} map[string]interface{} "审核失败"
+// @Router /api/v1/reviews/{id}/approve [post]
+func (rc *ReviewController) ApproveReview(c *gin.Context) {
+	rc.moderateReview(c, model.ReviewActionApprove, "评价已审核通过")
+}
+
+// RejectReview 驳回评价
+// @Summary 驳回评价
+// @Description 驳回评价，评价不再公开显示并从商家评分中扣除；作者修改后重新进入审核（仅管理员）。
+// @Description 原因代码必填：spam、fake、offensive、off_topic、privacy、other（other 须填写备注）
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Param decision body model.ReviewModerationRequest true "原因与备注"
+// @Success 200 {object} map[string]interface{} "驳回成功"
+// @Failure 400 {object} map[string]interface{} "驳回失败"
+// @Router /api/v1/reviews/{id}/reject [post]
+func (rc *ReviewController) RejectReview(c *gin.Context) {
+	rc.moderateReview(c, model.ReviewActionReject, "评价已驳回")
+}
+
+// HideReview 隐藏评价
+// @Summary 隐藏评价
+// @Description 隐藏评价，评价不再公开显示并从商家评分中扣除；作者修改后仍保持隐藏，需管理员重新审核通过（仅管理员）。
+// @Description 原因代码必填：spam、fake、offensive、off_topic、privacy、other（other 须填写备注）
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Param decision body model.ReviewModerationRequest true "原因与备注"
+// @Success 200 {object} map[string]interface{} "隐藏成功"
+// @Failure 400 {object} map[string]interface{} "隐藏失败"
+// @Router /api/v1/reviews/{id}/hide [post]
+func (rc *ReviewController) HideReview(c *gin.Context) {
+	rc.moderateReview(c, model.ReviewActionHide, "评价已隐藏")
+}
+
+// GetModerationQueue 获取评价审核队列
+// @Summary 获取评价审核队列
+// @Description 默认按发表先后返回被自动规则标记、等待审核的评价，含命中的标记（flags）（仅管理员）。
+// @Description 标记代码：duplicate_text（内容重复）、user_burst（同一用户频繁发表）、ip_burst（同一 IP 频繁发表）、
+// @Description profanity（不雅词汇）、rating_outlier（星级偏离商家历史评分）、rating_spike（商家评价数激增）。
+// @Description 支持 filter[businessId|stars|status|userId|createdAt][操作符] 筛选（按 status 筛选时不限于待审核）、排序与分页
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param flag query string false "只返回带有该标记的评价"
+// @Param sort query string false "排序字段（id,stars,createdAt,updatedAt），- 表示倒序"
+// @Param page query int false "页码（偏移分页）"
+// @Param pageSize query int false "每页数量（最大100）"
+// @Param cursor query string false "分页游标（游标分页）"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "查询参数错误"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/reviews/moderation-queue [get]
+func (rc *ReviewController) GetModerationQueue(c *gin.Context) {
+	query, err := common.ParseListQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	reviews, page, err := rc.reviewService.GetModerationQueue(c.Query("flag"), query)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取审核队列失败: " + err.Error(),
+		})
+		return
+	}
+
+	resp := gin.H{
+		"code":    200,
+		"message": "获取审核队列成功",
+		"data":    reviews,
+		"total":   page.Total,
+	}
+	if query.Paged() {
+		resp["page"] = query.Page
+		resp["pageSize"] = query.PageSize
+	}
+	if query.CursorMode {
+		resp["pageSize"] = query.PageSize
+		resp["nextCursor"] = page.NextCursor
+		resp["prevCursor"] = page.PrevCursor
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetModerationLogs 获取评价的审核记录
+// @Summary 获取评价的审核记录
+// @Description 按时间先后返回评价的自动标记（actorId 为 0）与审核人的操作，含原因代码与备注（仅管理员）
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "无效的评价ID"
+// @Failure 404 {object} map[string]interface{} "评价不存在"
+// @Router /api/v1/reviews/{id}/moderation-logs [get]
+func (rc *ReviewController) GetModerationLogs(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	logs, err := rc.reviewService.GetModerationLogs(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取审核记录成功",
+		"data":    logs,
+	})
+}
+
+// ReplyReview 回复评价
+// @Summary 回复评价
+// @Description 商家回复评价（最多 1000 字），已有回复时覆盖，仅该商家的店主、店长、收银员、品牌管理员或管理员可操作
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Param reply body model.ReviewReplyRequest true "回复"
+// @Success 200 {object} map[string]interface{} "回复成功"
+// @Failure 400 {object} map[string]interface{} "回复失败"
+// @Router /api/v1/reviews/{id}/reply [put]
+func (rc *ReviewController) ReplyReview(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ReviewReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	review, err := rc.reviewService.ReplyReview(id, req.Content, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "回复失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "回复成功",
+		"data":    review,
+	})
+}
+
+// DeleteReply 删除回复
+// @Summary 删除评价的商家回复
+// @Description 删除评价的商家回复，权限同回复评价
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "评价ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "删除失败"
+// @Router /api/v1/reviews/{id}/reply [delete]
+func (rc *ReviewController) DeleteReply(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	review, err := rc.reviewService.DeleteReply(id, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "删除回复失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "回复已删除",
+		"data":    review,
+	})
+}
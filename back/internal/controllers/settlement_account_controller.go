@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SettlementAccountController 商家结算账户控制器
+type SettlementAccountController struct {
+	settlementService services.SettlementAccountService
+}
+
+// NewSettlementAccountController 创建商家结算账户控制器实例
+func NewSettlementAccountController(settlementService services.SettlementAccountService) *SettlementAccountController {
+	return &SettlementAccountController{
+		settlementService: settlementService,
+	}
+}
+
+// parseSettlementChangePath 解析路径中的商家ID与变更申请ID
+func parseSettlementChangePath(c *gin.Context) (int, uint, bool) {
+	businessID, ok := parseBusinessID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	changeID, err := strconv.ParseUint(c.Param("changeId"), 10, 64)
+	if err != nil || changeID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的变更申请ID",
+		})
+		return 0, 0, false
+	}
+	return businessID, uint(changeID), true
+}
+
+// GetAccount 获取商家结算账户
+// @Summary 获取商家结算账户
+// @Description 获取商家当前生效的结算银行账户（店主、品牌管理员或管理员），账号只返回后四位脱敏结果，未设置时 data 为 null
+// @Tags settlement-accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/{id}/settlement-account [get]
+func (sc *SettlementAccountController) GetAccount(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	account, err := sc.settlementService.GetAccount(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取结算账户失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取结算账户成功",
+		"data":    account,
+	})
+}
+
+// GetChanges 获取结算账户变更历史
+// @Summary 获取结算账户变更历史
+// @Description 获取商家的结算账户变更申请及审批结果，最新在前（店主、品牌管理员或管理员）。账号均为脱敏结果，
+// @Description 已通过的申请同时返回变更前的户名、银行与脱敏账号
+// @Tags settlement-accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/{id}/settlement-account/changes [get]
+func (sc *SettlementAccountController) GetChanges(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	changes, err := sc.settlementService.GetChanges(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取变更历史失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取变更历史成功",
+		"data":    changes,
+	})
+}
+
+// RequestChange 提交结算账户变更申请
+// @Summary 提交结算账户变更申请
+// @Description 提交新的结算银行账户（店主、品牌管理员或管理员），账号加密保存，由申请人以外的管理员审批后生效。
+// @Description 同一商家同时只能有一个待审批的申请
+// @Tags settlement-accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param change body model.SettlementAccountChangeRequest true "新的结算账户"
+// @Success 201 {object} map[string]interface{} "提交成功"
+// @Failure 400 {object} map[string]interface{} "请求参数错误或已有待审批的申请"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Failure 503 {object} map[string]interface{} "未配置加密密钥"
+// @Router /api/v1/business/{id}/settlement-account/changes [post]
+func (sc *SettlementAccountController) RequestChange(c *gin.Context) {
+	id, ok := parseBusinessID(c)
+	if !ok {
+		return
+	}
+
+	var req model.SettlementAccountChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	change, err := sc.settlementService.RequestChange(id, &req, actorID)
+	if errors.Is(err, services.ErrSettlementEncryptionDisabled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "提交变更申请失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "变更申请已提交，等待管理员审批",
+		"data":    change,
+	})
+}
+
+// CancelChange 撤回结算账户变更申请
+// @Summary 撤回结算账户变更申请
+// @Description 撤回待审批的变更申请（申请人或管理员）
+// @Tags settlement-accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param changeId path int true "变更申请ID"
+// @Success 200 {object} map[string]interface{} "撤回成功"
+// @Failure 400 {object} map[string]interface{} "撤回失败"
+// @Failure 403 {object} map[string]interface{} "没有该商家的管理权限（需店主）"
+// @Router /api/v1/business/{id}/settlement-account/changes/{changeId}/cancel [post]
+func (sc *SettlementAccountController) CancelChange(c *gin.Context) {
+	businessID, changeID, ok := parseSettlementChangePath(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	change, err := sc.settlementService.CancelChange(businessID, changeID, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "撤回失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "变更申请已撤回",
+		"data":    change,
+	})
+}
+
+// ApproveChange 审批通过结算账户变更
+// @Summary 审批通过结算账户变更
+// @Description 管理员审批通过变更申请，商家结算账户随即更新；审批人不能是申请人
+// @Tags settlement-accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param changeId path int true "变更申请ID"
+// @Success 200 {object} map[string]interface{} "审批成功"
+// @Failure 400 {object} map[string]interface{} "审批失败"
+// @Router /api/v1/business/{id}/settlement-account/changes/{changeId}/approve [post]
+func (sc *SettlementAccountController) ApproveChange(c *gin.Context) {
+	businessID, changeID, ok := parseSettlementChangePath(c)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	account, err := sc.settlementService.ApproveChange(businessID, changeID, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "审批失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "结算账户已更新",
+		"data":    account,
+	})
+}
+
+// RejectChange 驳回结算账户变更
+// @Summary 驳回结算账户变更
+// @Description 管理员驳回变更申请，需填写驳回原因
+// @Tags settlement-accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "商家ID"
+// @Param changeId path int true "变更申请ID"
+// @Param decision body model.ReviewDecisionRequest true "驳回原因"
+// @Success 200 {object} map[string]interface{} "驳回成功"
+// @Failure 400 {object} map[string]interface{} "驳回失败"
+// @Router /api/v1/business/{id}/settlement-account/changes/{changeId}/reject [post]
+func (sc *SettlementAccountController) RejectChange(c *gin.Context) {
+	businessID, changeID, ok := parseSettlementChangePath(c)
+	if !ok {
+		return
+	}
+
+	var req model.ReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	actorID, _ := middleware.GetUserID(c)
+	change, err := sc.settlementService.RejectChange(businessID, changeID, actorID, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "驳回失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "变更申请已驳回",
+		"data":    change,
+	})
+}
+
+// GetPendingChanges 获取待审批的结算账户变更
+// @Summary 获取待审批的结算账户变更
+// @Description 获取全部商家待审批的结算账户变更申请，最早提交的在前（仅管理员），账号为脱敏结果
+// @Tags settlement-accounts
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 500 {object} map[string]interface{} "获取失败"
+// @Router /api/v1/business/settlement-account/changes/pending [get]
+func (sc *SettlementAccountController) GetPendingChanges(c *gin.Context) {
+	changes, err := sc.settlementService.GetPendingChanges()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取待审批申请失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取待审批申请成功",
+		"data":    changes,
+		"total":   len(changes),
+	})
+}
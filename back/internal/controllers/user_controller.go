@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"merchant_back/internal/common"
 	model "merchant_back/internal/models"
 	"merchant_back/internal/services"
 
@@ -22,35 +24,63 @@ func NewUserController(userService services.UserService) *UserController {
 	}
 }
 
+// userListResponseFields 用户列表可通过 fields 参数选择返回的字段
+var userListResponseFields = []string{
+	"id", "username", "email", "firstName", "lastName", "phone", "avatar",
+	"role", "status", "lastLogin", "createdAt", "updatedAt",
+}
+
 // GetUsers 获取用户列表
 // @Summary 获取用户列表
-// @Description 分页获取所有用户信息，支持分页参数
+// @Description 分页获取用户信息列表，支持组合筛选、多字段排序与字段选择
+// @Description 筛选：filter[字段][操作符]=值，操作符为 eq/in/gte/lte/like/between，省略操作符即为 eq
+// @Description 可筛选字段：id,username,email,firstName,lastName,phone,role,status,lastLogin,createdAt,updatedAt
+// @Description 排序：sort=-createdAt,username（- 表示倒序）；字段选择：fields=id,username,email
+// @Description 默认偏移分页；传 cursor 参数时使用游标分页（首页传空值，之后传 pagination 中的 nextCursor/prevCursor）
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param filter[role] query string false "示例：按角色筛选"
+// @Param filter[createdAt][between] query string false "示例：按创建时间区间筛选（逗号分隔）"
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序"
+// @Param fields query string false "返回字段，逗号分隔"
 // @Param page query int false "页码" default(1)
-// @Param pageSize query int false "每页数量" default(10)
+// @Param pageSize query int false "每页数量（最大100）" default(10)
+// @Param cursor query string false "分页游标（游标分页）"
 // @Success 200 {object} SuccessResponse{data=map[string]interface{}} "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
 // @Failure 500 {object} ErrorResponse "获取失败"
 // @Router /api/v1/users [get]
 func (uc *UserController) GetUsers(c *gin.Context) {
-	// 获取分页参数
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("pageSize", "10")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	query, err := common.ParseListQuery(c.Request.URL.Query())
+	if err == nil {
+		err = query.CheckFields(userListResponseFields)
 	}
-
-	pageSize, err := strconv.Atoi(pageSizeStr)
-	if err != nil || pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+	// 用户列表始终分页，未指定游标时使用偏移分页
+	if !query.Paged() && !query.CursorMode {
+		query.Page = 1
+		query.PageSize = common.DefaultListPageSize
 	}
 
 	// 获取用户列表
-	users, total, err := uc.userService.GetUsers(page, pageSize)
+	users, page, err := uc.userService.GetUsers(query)
 	if err != nil {
+		if errors.Is(err, common.ErrInvalidListQuery) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    400,
+				Message: "请求参数错误",
+				Error:   err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    500,
 			Message: "获取用户列表失败",
@@ -76,19 +106,33 @@ func (uc *UserController) GetUsers(c *gin.Context) {
 			"createdAt": user.CreatedAt,
 			"updatedAt": user.UpdatedAt,
 		}
+		if len(query.Fields) > 0 {
+			projected := make(map[string]interface{}, len(query.Fields))
+			for _, field := range query.Fields {
+				projected[field] = userList[i][field]
+			}
+			userList[i] = projected
+		}
+	}
+
+	pagination := map[string]interface{}{
+		"pageSize": query.PageSize,
+		"total":    page.Total,
+	}
+	if query.CursorMode {
+		pagination["nextCursor"] = page.NextCursor
+		pagination["prevCursor"] = page.PrevCursor
+	} else {
+		pagination["page"] = query.Page
+		pagination["pages"] = (page.Total + int64(query.PageSize) - 1) / int64(query.PageSize)
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Code:    200,
 		Message: "获取用户列表成功",
 		Data: map[string]interface{}{
-			"users": userList,
-			"pagination": map[string]interface{}{
-				"page":     page,
-				"pageSize": pageSize,
-				"total":    total,
-				"pages":    (total + int64(pageSize) - 1) / int64(pageSize),
-			},
+			"users":      userList,
+			"pagination": pagination,
 		},
 	})
 }
@@ -126,25 +170,11 @@ func (uc *UserController) GetUser(c *gin.Context) {
 	}
 
 	// 返回用户信息（不包含密码）
-	userInfo := map[string]interface{}{
-		"id":        user.ID,
-		"username":  user.Username,
-		"email":     user.Email,
-		"firstName": user.FirstName,
-		"lastName":  user.LastName,
-		"phone":     user.Phone,
-		"avatar":    user.Avatar,
-		"role":      user.Role,
-		"status":    user.Status,
-		"lastLogin": user.LastLogin,
-		"createdAt": user.CreatedAt,
-		"updatedAt": user.UpdatedAt,
-	}
-
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, SuccessResponse{
 		Code:    200,
 		Message: "获取用户信息成功",
-		Data:    userInfo,
+		Data:    userDetail(user),
 	})
 }
 
@@ -217,11 +247,14 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "用户ID"
+// @Param If-Match header string true "用户当前 ETag（版本号），* 表示不校验"
 // @Param user body models.UserUpdateRequest true "用户更新信息"
 // @Success 200 {object} SuccessResponse{data=map[string]interface{}} "更新成功"
 // @Failure 400 {object} ErrorResponse "无效的用户ID或参数验证失败"
 // @Failure 404 {object} ErrorResponse "用户不存在"
 // @Failure 500 {object} ErrorResponse "更新失败"
+// @Failure 412 {object} ErrorResponse "版本不一致，返回最新数据"
+// @Failure 428 {object} ErrorResponse "缺少 If-Match 请求头"
 // @Router /api/v1/users/{id} [put]
 func (uc *UserController) UpdateUser(c *gin.Context) {
 	idStr := c.Param("id")
@@ -244,6 +277,15 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionRequired, ErrorResponse{
+			Code:    428,
+			Message: err.Error(),
+		})
+		return
+	}
+
 	// 获取现有用户
 	user, err := uc.userService.GetUserByID(uint(id))
 	if err != nil {
@@ -254,6 +296,10 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		})
 		return
 	}
+	if !versionMatches(version, user.Version) {
+		writeUserConflict(c, user)
+		return
+	}
 
 	// 更新用户信息
 	user.FirstName = req.FirstName
@@ -262,7 +308,14 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 	user.Avatar = req.Avatar
 
 	// 保存更新
-	if err := uc.userService.UpdateUser(user); err != nil {
+	err = uc.userService.UpdateUser(user)
+	if errors.Is(err, services.ErrVersionConflict) {
+		if current, err := uc.userService.GetUserByID(uint(id)); err == nil {
+			writeUserConflict(c, current)
+			return
+		}
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    500,
 			Message: "更新用户失败",
@@ -272,39 +325,104 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 	}
 
 	// 返回更新后的用户信息
-	userInfo := map[string]interface{}{
-		"id":        user.ID,
-		"username":  user.Username,
-		"email":     user.Email,
-		"firstName": user.FirstName,
-		"lastName":  user.LastName,
-		"phone":     user.Phone,
-		"avatar":    user.Avatar,
-		"role":      user.Role,
-		"status":    user.Status,
-		"lastLogin": user.LastLogin,
-		"createdAt": user.CreatedAt,
-		"updatedAt": user.UpdatedAt,
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Code:    200,
+		Message: "更新用户成功",
+		Data:    userDetail(user),
+	})
+}
+
+// PatchUser 部分更新用户信息
+// @Summary 部分更新用户信息
+// @Description 支持 JSON Merge Patch（application/merge-patch+json）与 JSON Patch（application/json-patch+json），仅可修改 firstName、lastName、phone、avatar，且仅校验补丁涉及的字段
+// @Tags users
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param If-Match header string true "用户当前 ETag（版本号），* 表示不校验"
+// @Param patch body object true "补丁内容"
+// @Success 200 {object} SuccessResponse{data=map[string]interface{}} "更新成功"
+// @Failure 400 {object} ErrorResponse "无效的补丁或校验失败"
+// @Failure 412 {object} ErrorResponse "版本不一致，返回最新数据"
+// @Failure 415 {object} ErrorResponse "不支持的补丁格式"
+// @Failure 428 {object} ErrorResponse "缺少 If-Match 请求头"
+// @Router /api/v1/users/{id} [patch]
+func (uc *UserController) PatchUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "无效的用户ID",
+		})
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionRequired, ErrorResponse{
+			Code:    428,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "读取请求内容失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	user, err := uc.userService.PatchUser(uint(id), c.ContentType(), patch, version)
+	if errors.Is(err, services.ErrVersionConflict) {
+		if current, err := uc.userService.GetUserByID(uint(id)); err == nil {
+			writeUserConflict(c, current)
+			return
+		}
+	}
+	if errors.Is(err, common.ErrUnsupportedPatchType) {
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{
+			Code:    415,
+			Message: "不支持的补丁格式",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "更新用户失败",
+			Error:   err.Error(),
+		})
+		return
 	}
 
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, SuccessResponse{
 		Code:    200,
 		Message: "更新用户成功",
-		Data:    userInfo,
+		Data:    userDetail(user),
 	})
 }
 
 // DeleteUser 删除用户
 // @Summary 删除用户
-// @Description 根据用户ID删除用户账户
+// @Description 根据用户ID删除用户账户，删除后移入回收站，可在保留期内恢复
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "用户ID"
+// @Param If-Match header string true "用户当前 ETag（版本号），* 表示不校验"
 // @Success 200 {object} SuccessResponse "删除成功"
 // @Failure 400 {object} ErrorResponse "无效的用户ID"
 // @Failure 404 {object} ErrorResponse "用户不存在"
 // @Failure 500 {object} ErrorResponse "删除失败"
+// @Failure 412 {object} ErrorResponse "版本不一致，返回最新数据"
+// @Failure 428 {object} ErrorResponse "缺少 If-Match 请求头"
 // @Router /api/v1/users/{id} [delete]
 func (uc *UserController) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
@@ -317,19 +435,19 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	// 检查用户是否存在
-	_, err = uc.userService.GetUserByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{
-			Code:    404,
-			Message: "用户不存在",
-			Error:   err.Error(),
-		})
+	// 检查用户是否存在及版本号
+	version, ok := uc.checkVersion(c, uint(id))
+	if !ok {
 		return
 	}
 
 	// 删除用户
-	if err := uc.userService.DeleteUser(uint(id)); err != nil {
+	err = uc.userService.DeleteUser(uint(id), version)
+	if errors.Is(err, services.ErrVersionConflict) {
+		uc.respondVersionConflict(c, uint(id))
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    500,
 			Message: "删除用户失败",
@@ -399,10 +517,13 @@ func (uc *UserController) ChangePassword(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "用户ID"
+// @Param If-Match header string true "用户当前 ETag（版本号），* 表示不校验"
 // @Param status body object{status=string} true "用户状态"
 // @Success 200 {object} SuccessResponse "更新成功"
 // @Failure 400 {object} ErrorResponse "无效的用户ID或状态值"
 // @Failure 500 {object} ErrorResponse "更新失败"
+// @Failure 412 {object} ErrorResponse "版本不一致，返回最新数据"
+// @Failure 428 {object} ErrorResponse "缺少 If-Match 请求头"
 // @Router /api/v1/users/{id}/status [put]
 func (uc *UserController) UpdateUserStatus(c *gin.Context) {
 	idStr := c.Param("id")
@@ -429,13 +550,18 @@ func (uc *UserController) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
+	version, ok := uc.checkVersion(c, uint(id))
+	if !ok {
+		return
+	}
+
 	switch req.Status {
 	case model.UserStatusActive:
-		err = uc.userService.ActivateUser(uint(id))
+		err = uc.userService.ActivateUser(uint(id), version)
 	case model.UserStatusInactive:
-		err = uc.userService.DeactivateUser(uint(id))
+		err = uc.userService.DeactivateUser(uint(id), version)
 	case model.UserStatusSuspended:
-		err = uc.userService.SuspendUser(uint(id))
+		err = uc.userService.SuspendUser(uint(id), version)
 	default:
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Code:    400,
@@ -444,6 +570,10 @@ func (uc *UserController) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
+	if errors.Is(err, services.ErrVersionConflict) {
+		uc.respondVersionConflict(c, uint(id))
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    500,
@@ -564,3 +694,217 @@ func (uc *UserController) GetUsersByStatus(c *gin.Context) {
 		Data:    userList,
 	})
 }
+
+// GetDeletedUsers 获取回收站中的用户
+// @Summary 获取回收站用户列表
+// @Description 分页获取已删除（在回收站中）的用户，按删除时间倒序，仅管理员可操作
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} SuccessResponse{data=map[string]interface{}} "获取成功"
+// @Failure 500 {object} ErrorResponse "获取失败"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/users/trash [get]
+func (uc *UserController) GetDeletedUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	users, total, err := uc.userService.GetDeletedUsers(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    500,
+			Message: "获取回收站用户失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userList := make([]map[string]interface{}, len(users))
+	for i, user := range users {
+		userList[i] = map[string]interface{}{
+			"id":        user.ID,
+			"username":  user.Username,
+			"email":     user.Email,
+			"firstName": user.FirstName,
+			"lastName":  user.LastName,
+			"role":      user.Role,
+			"status":    user.Status,
+			"createdAt": user.CreatedAt,
+			"deletedAt": user.DeletedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Code:    200,
+		Message: "获取回收站用户成功",
+		Data: map[string]interface{}{
+			"list":     userList,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+	})
+}
+
+// RestoreUser 从回收站恢复用户
+// @Summary 恢复用户
+// @Description 从回收站恢复已删除的用户，用户名或邮箱已被其他用户使用时无法恢复，仅管理员可操作
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} SuccessResponse{data=map[string]interface{}} "恢复成功"
+// @Failure 400 {object} ErrorResponse "无效的用户ID或无法恢复"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/users/{id}/restore [post]
+func (uc *UserController) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "无效的用户ID",
+		})
+		return
+	}
+
+	user, err := uc.userService.RestoreUser(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "恢复用户失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Code:    200,
+		Message: "恢复用户成功",
+		Data: map[string]interface{}{
+			"id":        user.ID,
+			"username":  user.Username,
+			"email":     user.Email,
+			"role":      user.Role,
+			"status":    user.Status,
+			"createdAt": user.CreatedAt,
+			"updatedAt": user.UpdatedAt,
+		},
+	})
+}
+
+// PurgeUser 彻底删除用户
+// @Summary 彻底删除用户
+// @Description 彻底删除回收站中的用户，不可恢复，仅管理员可操作
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} SuccessResponse "删除成功"
+// @Failure 400 {object} ErrorResponse "无效的用户ID或用户不在回收站中"
+// @Failure 403 {object} map[string]interface{} "需要管理员权限"
+// @Router /api/v1/users/{id}/purge [delete]
+func (uc *UserController) PurgeUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "无效的用户ID",
+		})
+		return
+	}
+
+	if err := uc.userService.PurgeUser(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    400,
+			Message: "彻底删除用户失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Code:    200,
+		Message: "用户已彻底删除",
+	})
+}
+
+// userDetail 用户详情（不包含密码）
+func userDetail(user *model.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        user.ID,
+		"username":  user.Username,
+		"email":     user.Email,
+		"firstName": user.FirstName,
+		"lastName":  user.LastName,
+		"phone":     user.Phone,
+		"avatar":    user.Avatar,
+		"role":      user.Role,
+		"status":    user.Status,
+		"lastLogin": user.LastLogin,
+		"version":   user.Version,
+		"createdAt": user.CreatedAt,
+		"updatedAt": user.UpdatedAt,
+	}
+}
+
+// checkVersion 预先校验 If-Match 与用户当前版本并返回期望的版本号（0 表示不校验），未通过时已写入响应（428/404/412）。
+// 调用方须将版本号传入更新语句，以免校验与更新之间的并发修改被覆盖
+func (uc *UserController) checkVersion(c *gin.Context, id uint) (int, bool) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusPreconditionRequired, ErrorResponse{
+			Code:    428,
+			Message: err.Error(),
+		})
+		return 0, false
+	}
+
+	user, err := uc.userService.GetUserByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    404,
+			Message: "用户不存在",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+
+	if !versionMatches(version, user.Version) {
+		writeUserConflict(c, user)
+		return 0, false
+	}
+	return version, true
+}
+
+// respondVersionConflict 返回 412 与用户最新数据
+func (uc *UserController) respondVersionConflict(c *gin.Context, id uint) {
+	user, err := uc.userService.GetUserByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    404,
+			Message: "用户不存在",
+			Error:   err.Error(),
+		})
+		return
+	}
+	writeUserConflict(c, user)
+}
+
+// writeUserConflict 写入 412 响应，附带用户最新数据与 ETag
+func writeUserConflict(c *gin.Context, user *model.User) {
+	setETag(c, user.Version)
+	c.JSON(http.StatusPreconditionFailed, SuccessResponse{
+		Code:    412,
+		Message: "用户已被其他人修改，请基于最新数据重新操作",
+		Data:    userDetail(user),
+	})
+}
@@ -0,0 +1,51 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// dominantSampleSide 计算主色调前先缩小到的最大边长
+const dominantSampleSide = 64
+
+// DominantColor 计算图片的主色调（#rrggbb），用作图片加载前的占位背景色。
+// 颜色按每通道高 4 位分组，取像素最多的一组的平均色；忽略大部分透明的像素，全透明时返回空字符串
+func DominantColor(img image.Image) string {
+	b := img.Bounds()
+	w, h := Fit(b.Dx(), b.Dy(), dominantSampleSide)
+	sample := Resize(img, w, h)
+
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[int]*bucket)
+	var best *bucket
+	for i := 0; i+3 < len(sample.Pix); i += 4 {
+		a := int(sample.Pix[i+3])
+		if a < 128 {
+			continue
+		}
+		// 还原预乘 alpha
+		r := int(sample.Pix[i]) * 255 / a
+		g := int(sample.Pix[i+1]) * 255 / a
+		bl := int(sample.Pix[i+2]) * 255 / a
+		key := (r>>4)<<8 | (g>>4)<<4 | bl>>4
+		bk := buckets[key]
+		if bk == nil {
+			bk = &bucket{}
+			buckets[key] = bk
+		}
+		bk.count++
+		bk.r += r
+		bk.g += g
+		bk.b += bl
+		if best == nil || bk.count > best.count {
+			best = bk
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
@@ -0,0 +1,96 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// JPEGQuality 缩略图的 JPEG 压缩质量
+const JPEGQuality = 82
+
+// Opaque 图像是否完全不透明
+func Opaque(img *image.RGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xFF {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode 编码缩略图：不透明的编码为 JPEG，含透明像素的编码为 PNG。重新编码的图像不含任何元数据
+func Encode(img *image.RGBA) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if Opaque(img) {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEGQuality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// WebPEncoder WebP 编码器。标准库及项目依赖中没有 WebP 编码实现，需要时通过外部程序接入
+type WebPEncoder interface {
+	EncodeWebP(ctx context.Context, img image.Image) ([]byte, error)
+}
+
+// cwebpTimeout 单张图片的编码超时
+const cwebpTimeout = 30 * time.Second
+
+// CWebPEncoder 调用 libwebp 的 cwebp 命令编码 WebP
+type CWebPEncoder struct {
+	path    string
+	quality string
+}
+
+// NewCWebPEncoder 创建 cwebp 编码器，path 为 cwebp 可执行文件路径（或 PATH 中的命令名）
+func NewCWebPEncoder(path string) (*CWebPEncoder, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, errors.New("找不到 WebP 编码程序: " + path)
+	}
+	return &CWebPEncoder{path: resolved, quality: "80"}, nil
+}
+
+// EncodeWebP 以无损 PNG 作为中间格式交给 cwebp 编码，透明通道保留
+func (e *CWebPEncoder) EncodeWebP(ctx context.Context, img image.Image) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "cwebp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "in.png")
+	output := filepath.Join(dir, "out.webp")
+	f, err := os.Create(input)
+	if err != nil {
+		return nil, err
+	}
+	err = (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(f, img)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cwebpTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.path, "-quiet", "-metadata", "none", "-q", e.quality, input, "-o", output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, errors.New("WebP 编码失败: " + err.Error() + " " + string(bytes.TrimSpace(out)))
+	}
+	return os.ReadFile(output)
+}
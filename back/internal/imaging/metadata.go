@@ -0,0 +1,228 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// 图片元数据处理：上传的照片常带有 EXIF（含拍摄设备、时间与 GPS 位置）、XMP、IPTC 等信息，
+// 保存前逐段移除，不重新编码图像数据，画质不受影响
+
+// exifHeader JPEG APP1 段中 EXIF 数据的标识
+var exifHeader = []byte("Exif\x00\x00")
+
+// orientationTag EXIF 方向标签
+const orientationTag = 0x0112
+
+// StripMetadata 移除图片中的 EXIF、XMP、IPTC 及文本注释等元数据，返回处理后的内容与原 EXIF 方向（1-8，无方向信息时为 1）。
+// JPEG 的方向不为 1 时保留只含方向的最小 EXIF，避免图片显示方向错误；ICC 色彩配置保留。
+// 支持 JPEG、PNG、WebP，其他类型原样返回
+func StripMetadata(data []byte, contentType string) ([]byte, int, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		out, err := stripPNG(data)
+		return out, 1, err
+	case "image/webp":
+		out, err := stripWebP(data)
+		return out, 1, err
+	default:
+		return data, 1, nil
+	}
+}
+
+// stripJPEG 移除 APP1（EXIF、XMP）、APP13（IPTC）与注释段
+func stripJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 1, errors.New("不是有效的 JPEG 文件")
+	}
+
+	orientation := 1
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	var kept [][]byte
+	pos := 2
+	for {
+		if pos+4 > len(data) || data[pos] != 0xFF {
+			return nil, 1, errors.New("JPEG 文件结构不完整")
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		if marker == 0xDA {
+			break
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			kept = append(kept, data[pos:pos+2])
+			pos += 2
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 1, errors.New("JPEG 文件结构不完整")
+		}
+		payload := data[pos+4 : end]
+		switch marker {
+		case 0xE1:
+			if bytes.HasPrefix(payload, exifHeader) {
+				if o := exifOrientation(payload[len(exifHeader):]); o != 0 {
+					orientation = o
+				}
+			}
+		case 0xED, 0xFE:
+		default:
+			kept = append(kept, data[pos:end])
+		}
+		pos = end
+	}
+
+	// JFIF 要求 APP0 紧跟 SOI，方向段放在其后
+	inserted := orientation == 1
+	for _, segment := range kept {
+		if !inserted && segment[1] != 0xE0 {
+			out.Write(orientationSegment(orientation))
+			inserted = true
+		}
+		out.Write(segment)
+	}
+	if !inserted {
+		out.Write(orientationSegment(orientation))
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), orientation, nil
+}
+
+// exifOrientation 读取 TIFF 结构中 IFD0 的方向标签，无法读取时返回 0
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationSegment 只含方向标签的 APP1 段
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // 大端字节序，IFD0 偏移 8
+		0x00, 0x01, // 1 个条目
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, byte(orientation >> 8), byte(orientation), 0x00, 0x00, // Orientation SHORT
+		0x00, 0x00, 0x00, 0x00, // 无后续 IFD
+	}
+	length := 2 + len(exifHeader) + len(tiff)
+	segment := []byte{0xFF, 0xE1, byte(length >> 8), byte(length)}
+	segment = append(segment, exifHeader...)
+	return append(segment, tiff...)
+}
+
+// pngSignature PNG 文件签名
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks 移除的 PNG 数据块：EXIF、文本（可含 XMP）、修改时间
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "iTXt": true, "zTXt": true, "tIME": true}
+
+// stripPNG 移除元数据块，其余数据块原样保留
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errors.New("不是有效的 PNG 文件")
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, errors.New("PNG 文件结构不完整")
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errors.New("PNG 文件结构不完整")
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		if !pngMetadataChunks[chunkType] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// VP8X 扩展头中元数据的标志位
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+// stripWebP 移除 EXIF、XMP 数据块并清除扩展头中对应的标志位
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errors.New("不是有效的 WebP 文件")
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+	pos := 12
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			if pos+8+size == len(data) {
+				end = len(data) // 末尾块缺少填充字节
+			} else {
+				return nil, errors.New("WebP 文件结构不完整")
+			}
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			out.Write(chunk)
+		default:
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Fit 计算在 maxSide×maxSide 范围内保持宽高比的尺寸，不放大
+func Fit(width, height, maxSide int) (int, int) {
+	if width <= maxSide && height <= maxSide {
+		return width, height
+	}
+	if width >= height {
+		return maxSide, max(1, int(math.Round(float64(height)*float64(maxSide)/float64(width))))
+	}
+	return max(1, int(math.Round(float64(width)*float64(maxSide)/float64(height)))), maxSide
+}
+
+// toRGBA 转换为以 (0,0) 为原点的 RGBA 图像
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	return dst
+}
+
+// contribution 缩放时目标像素对应的源像素及其权重
+type contribution struct {
+	index  int
+	weight float32
+}
+
+// boxWeights 按面积计算一维缩放权重：每个目标像素取其覆盖的源像素按覆盖比例加权平均
+func boxWeights(srcSize, dstSize int) [][]contribution {
+	scale := float64(srcSize) / float64(dstSize)
+	weights := make([][]contribution, dstSize)
+	for i := range weights {
+		start := float64(i) * scale
+		end := math.Min(float64(i+1)*scale, float64(srcSize))
+		var list []contribution
+		for j := int(start); float64(j) < end; j++ {
+			overlap := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+			if overlap > 0 {
+				list = append(list, contribution{index: j, weight: float32(overlap / (end - start))})
+			}
+		}
+		weights[i] = list
+	}
+	return weights
+}
+
+// Resize 缩放到指定尺寸，缩小时按面积平均（画质接近区域采样），在预乘 alpha 的空间计算避免透明边缘发暗
+func Resize(src image.Image, width, height int) *image.RGBA {
+	s := toRGBA(src)
+	sw, sh := s.Rect.Dx(), s.Rect.Dy()
+	if sw == width && sh == height {
+		return s
+	}
+
+	// 先横向缩放到 width×sh，再纵向缩放到 width×height
+	xWeights := boxWeights(sw, width)
+	tmp := make([]float32, width*sh*4)
+	for y := 0; y < sh; y++ {
+		row := s.Pix[y*s.Stride:]
+		for x, list := range xWeights {
+			var r, g, b, a float32
+			for _, c := range list {
+				p := row[c.index*4:]
+				r += float32(p[0]) * c.weight
+				g += float32(p[1]) * c.weight
+				b += float32(p[2]) * c.weight
+				a += float32(p[3]) * c.weight
+			}
+			t := tmp[(y*width+x)*4:]
+			t[0], t[1], t[2], t[3] = r, g, b, a
+		}
+	}
+
+	yWeights := boxWeights(sh, height)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, list := range yWeights {
+		for x := 0; x < width; x++ {
+			var r, g, b, a float32
+			for _, c := range list {
+				t := tmp[(c.index*width+x)*4:]
+				r += t[0] * c.weight
+				g += t[1] * c.weight
+				b += t[2] * c.weight
+				a += t[3] * c.weight
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = clamp8(r), clamp8(g), clamp8(b), clamp8(a)
+		}
+	}
+	return dst
+}
+
+// clamp8 四舍五入并限制在 0-255
+func clamp8(v float32) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
+
+// Orient 按 EXIF 方向（1-8）旋转、翻转图像，使其按正常方向显示
+func Orient(src image.Image, orientation int) *image.RGBA {
+	s := toRGBA(src)
+	if orientation < 2 || orientation > 8 {
+		return s
+	}
+
+	w, h := s.Rect.Dx(), s.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 水平翻转
+				sx, sy = w-1-x, y
+			case 3: // 旋转 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				sx, sy = x, h-1-y
+			case 5: // 沿主对角线翻转
+				sx, sy = y, x
+			case 6: // 顺时针旋转 90°
+				sx, sy = y, h-1-x
+			case 7: // 沿副对角线翻转
+				sx, sy = w-1-y, h-1-x
+			case 8: // 逆时针旋转 90°
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], s.Pix[sy*s.Stride+sx*4:])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"merchant_back/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware 仅允许状态正常的管理员访问，需在 AuthMiddleware 之后使用
+func AdminMiddleware(userRepo repositories.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.GetByID(userID)
+		if err != nil || !user.IsAdmin() || !user.IsActive() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "需要管理员权限"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BusinessAuthorizer 检查用户对商家的权限
+type BusinessAuthorizer interface {
+	CheckPermission(businessID int, userID uint, permission string) (bool, error)
+}
+
+// BusinessPermissionMiddleware 仅允许对路径参数 :id 指定的商家拥有权限的用户访问（成员角色见 model.BusinessRoleAllows），
+// 需在 AuthMiddleware 之后使用
+func BusinessPermissionMiddleware(authorizer BusinessAuthorizer, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+			c.Abort()
+			return
+		}
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的商家ID"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := authorizer.CheckPermission(id, userID, permission)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "没有该商家的操作权限"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// backfillBusinessAddress 从展示地址尽力解析结构化地址字段
+func backfillBusinessAddress(tx *gorm.DB) error {
+	var businesses []*model.Business
+	update := tx.Session(&gorm.Session{NewDB: true})
+	err := tx.Select("id", "address").
+		Where("country = '' AND province = '' AND city = '' AND district = ''").
+		FindInBatches(&businesses, 200, func(_ *gorm.DB, _ int) error {
+			for _, business := range businesses {
+				parts := common.ParseAddress(business.Address)
+				err := update.Model(&model.Business{}).Where("id = ?", business.ID).Updates(map[string]interface{}{
+					"country":     parts.Country,
+					"province":    parts.Province,
+					"city":        parts.City,
+					"district":    parts.District,
+					"street":      parts.Street,
+					"postal_code": parts.PostalCode,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	return err
+}
@@ -0,0 +1,111 @@
+package migrations
+
+import (
+	"strconv"
+	"strings"
+
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// builtinCategories 内置分类，对应原有的商家类型常量
+var builtinCategories = []model.Category{
+	{Slug: model.BusinessTypeRestaurant, Name: "餐厅", Names: map[string]string{"zh": "餐厅", "en": "Restaurant", "ja": "レストラン"}, SortOrder: 10},
+	{Slug: model.BusinessTypeRetail, Name: "零售", Names: map[string]string{"zh": "零售", "en": "Retail", "ja": "小売"}, SortOrder: 20},
+	{Slug: model.BusinessTypeService, Name: "服务", Names: map[string]string{"zh": "服务", "en": "Service", "ja": "サービス"}, SortOrder: 30},
+	{Slug: model.BusinessTypeEntertainment, Name: "娱乐", Names: map[string]string{"zh": "娱乐", "en": "Entertainment", "ja": "エンターテインメント"}, SortOrder: 40},
+	{Slug: model.BusinessTypeOther, Name: "其他", Names: map[string]string{"zh": "其他", "en": "Other", "ja": "その他"}, SortOrder: 90},
+}
+
+// migrateBusinessTypes 将商家的自由文本类型转换为分类
+// 类型按分类的标识或任一语言的名称匹配（不区分大小写），无法匹配的类型各自新建分类，
+// 之后可由管理员合并；商家关联到匹配的分类，type 改为分类标识
+func migrateBusinessTypes(tx *gorm.DB) error {
+	for _, builtin := range builtinCategories {
+		category := builtin
+		if err := tx.Where("slug = ?", category.Slug).FirstOrCreate(&category).Error; err != nil {
+			return err
+		}
+	}
+
+	var categories []*model.Category
+	if err := tx.Find(&categories).Error; err != nil {
+		return err
+	}
+	slugs := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		slugs[category.Slug] = true
+	}
+
+	var types []string
+	if err := tx.Unscoped().Model(&model.Business{}).Distinct().Pluck("type", &types).Error; err != nil {
+		return err
+	}
+
+	for _, businessType := range types {
+		category := matchCategory(categories, businessType)
+		if category == nil {
+			category = &model.Category{
+				Slug:      uniqueSlug(slugs, businessType),
+				Name:      strings.TrimSpace(businessType),
+				SortOrder: 100,
+			}
+			if category.Name == "" {
+				category.Name = "未分类"
+			}
+			if err := tx.Create(category).Error; err != nil {
+				return err
+			}
+			categories = append(categories, category)
+			slugs[category.Slug] = true
+		}
+
+		err := tx.Exec(`INSERT INTO business_categories (business_id, category_id, position)
+			SELECT id, ?, 0 FROM business
+			WHERE type = ? AND id NOT IN (SELECT business_id FROM business_categories)`,
+			category.ID, businessType).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&model.Business{}).
+			Where("type = ? AND type <> ?", businessType, category.Slug).
+			Updates(map[string]interface{}{
+				"type":    category.Slug,
+				"version": gorm.Expr("version + 1"),
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchCategory 按标识优先、其次名称匹配分类
+func matchCategory(categories []*model.Category, label string) *model.Category {
+	for _, category := range categories {
+		if strings.EqualFold(strings.TrimSpace(label), category.Slug) {
+			return category
+		}
+	}
+	for _, category := range categories {
+		if category.Matches(label) {
+			return category
+		}
+	}
+	return nil
+}
+
+// uniqueSlug 由类型文本生成未被使用的分类标识，不含字母或数字的文本使用 type-N
+func uniqueSlug(used map[string]bool, text string) string {
+	slug := model.Slugify(text)
+	base, n := slug, 1
+	if slug == "" {
+		base, slug = "type", "type-1"
+	}
+	for used[slug] {
+		n++
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug
+}
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"strings"
+
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 表示全天营业的其他信息文本
+var allDayPhrases = []string{"open 24 hours", "24 hours", "24小时营业", "24時間営業"}
+
+// backfillBusinessHours 将其他信息中的全天营业说明转换为每周营业时段
+func backfillBusinessHours(tx *gorm.DB) error {
+	var businesses []*model.Business
+	if err := tx.Select("id", "other_info").Where("other_info IS NOT NULL").Find(&businesses).Error; err != nil {
+		return err
+	}
+
+	for _, business := range businesses {
+		if !isAllDay(*business.OtherInfo) {
+			continue
+		}
+
+		var count int64
+		if err := tx.Model(&model.BusinessHours{}).Where("business_id = ?", business.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		hours := make([]model.BusinessHours, 7)
+		for weekday := range hours {
+			hours[weekday] = model.BusinessHours{
+				BusinessID: business.ID,
+				Weekday:    weekday,
+				OpenTime:   "00:00",
+				CloseTime:  "00:00",
+			}
+		}
+		if err := tx.Create(&hours).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isAllDay 检查文本是否表示全天营业
+func isAllDay(info string) bool {
+	info = strings.ToLower(strings.TrimSpace(info))
+	for _, phrase := range allDayPhrases {
+		if info == phrase {
+			return true
+		}
+	}
+	return false
+}
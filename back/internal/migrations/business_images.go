@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	_ "image/gif" // 注册解码器，用于读取图片尺寸
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// legacyBusinessImage 商家表中尚未转存的 base64 图片
+type legacyBusinessImage struct {
+	ID          uint
+	ImageBase64 string
+}
+
+// extractBusinessImages 返回迁移函数：将商家表中的 base64 图片转存到媒体存储并改为引用媒体文件。
+// 无法解码或类型不受支持的图片跳过并记录日志，原数据保持不变
+func extractBusinessImages(store storage.Storage) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		var rows []legacyBusinessImage
+		update := tx.Session(&gorm.Session{NewDB: true})
+		return tx.Table("business").
+			Select("id", "image_base64").
+			Where("image_base64 IS NOT NULL AND image_base64 <> '' AND image_media_id IS NULL").
+			FindInBatches(&rows, 50, func(_ *gorm.DB, _ int) error {
+				for _, row := range rows {
+					media, err := storeLegacyImage(update, store, row.ImageBase64)
+					if err != nil {
+						return err
+					}
+					if media == nil {
+						log.Printf("Business %d image skipped: not a valid JPEG/PNG/GIF/WebP image", row.ID)
+						continue
+					}
+					err = update.Table("business").Where("id = ?", row.ID).Updates(map[string]interface{}{
+						"image_media_id": media.ID,
+						"image_base64":   nil,
+					}).Error
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+	}
+}
+
+// storeLegacyImage 写入存储并创建媒体文件记录；图片无效时返回 nil
+func storeLegacyImage(tx *gorm.DB, store storage.Storage, data string) (*model.Media, error) {
+	decoded, err := common.DecodeBase64Image(data)
+	if err != nil || len(decoded) == 0 {
+		return nil, nil
+	}
+	contentType := http.DetectContentType(decoded)
+	ext, ok := model.MediaTypeExtensions[contentType]
+	if !ok {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256(decoded)
+	media := &model.Media{
+		StorageKey:  storage.NewKey("media", ext),
+		ContentType: contentType,
+		Size:        int64(len(decoded)),
+		Checksum:    hex.EncodeToString(sum[:]),
+	}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(decoded)); err == nil {
+		media.Width, media.Height = cfg.Width, cfg.Height
+	}
+
+	ctx := context.Background()
+	if err := store.Put(ctx, media.StorageKey, bytes.NewReader(decoded), media.Size, contentType); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(media).Error; err != nil {
+		_ = store.Delete(ctx, media.StorageKey)
+		return nil, err
+	}
+	return media, nil
+}
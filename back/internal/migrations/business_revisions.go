@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// backfillBusinessRevisions 为启用修订记录前已存在的商家保存初始版本
+func backfillBusinessRevisions(tx *gorm.DB) error {
+	var businesses []*model.Business
+	return tx.Unscoped().
+		Where("NOT EXISTS (SELECT 1 FROM business_revisions r WHERE r.business_id = business.id)").
+		FindInBatches(&businesses, 100, func(batch *gorm.DB, _ int) error {
+			for _, business := range businesses {
+				if err := tx.Where("business_id = ?", business.ID).Order("weekday, open_time").Find(&business.OpeningHours).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("business_id = ?", business.ID).Order("date, open_time").Find(&business.SpecialHours).Error; err != nil {
+					return err
+				}
+
+				snapshot, err := business.RevisionSnapshot()
+				if err != nil {
+					return err
+				}
+				revision := &model.BusinessRevision{
+					BusinessID: business.ID,
+					Version:    1,
+					Action:     model.RevisionActionBaseline,
+					Snapshot:   snapshot,
+				}
+				if err := tx.Create(revision).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"merchant_back/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// Migration 数据迁移
+// 表结构变更见 sql/migrations，此处仅处理需要程序逻辑的数据迁移
+type Migration struct {
+	Name string
+	Up   func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Name      string    `gorm:"type:varchar(100);primaryKey"`
+	AppliedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrationList 按顺序执行的数据迁移列表
+func migrationList(store storage.Storage) []Migration {
+	return []Migration{
+		{Name: "001_backfill_business_address", Up: backfillBusinessAddress},
+		{Name: "002_backfill_business_hours", Up: backfillBusinessHours},
+		{Name: "003_backfill_business_revisions", Up: backfillBusinessRevisions},
+		{Name: "004_migrate_business_types", Up: migrateBusinessTypes},
+		{Name: "005_extract_business_images", Up: extractBusinessImages(store)},
+	}
+}
+
+// Run 执行尚未执行的数据迁移，每个迁移在独立事务中执行；store 为媒体存储，用于转存商家图片
+func Run(db *gorm.DB, store storage.Storage) error {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, m := range migrationList(store) {
+		var count int64
+		if err := db.Model(&SchemaMigration{}).Where("name = ?", m.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Name: m.Name}).Error
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("Migration applied: %s", m.Name)
+	}
+	return nil
+}
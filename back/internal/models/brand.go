@@ -0,0 +1,84 @@
+package model
+
+import "time"
+
+// 可由品牌继承的门店字段（门店未填写时使用品牌的值）
+const (
+	BrandInheritedDescription = "description"  // 描述
+	BrandInheritedImage       = "imageMediaId" // 商家图片（品牌标志）
+)
+
+// Brand 品牌（连锁商家），下属多个门店（BrandID 指向该品牌的商家）
+// 门店未填写描述、图片时使用品牌的描述与标志；未指定分类时创建门店使用品牌的默认分类；地址、营业时间与坐标由门店各自维护
+type Brand struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_brands_name" json:"name"` // 品牌名称（唯一）
+	Email       string    `gorm:"type:varchar(255);not null;default:''" json:"email"`                // 品牌联系邮箱，门店可不填写邮箱而统一使用品牌邮箱
+	Contact     string    `gorm:"type:varchar(255);not null;default:''" json:"contact"`              // 联系方式
+	Description *string   `gorm:"type:text" json:"description"`                                      // 品牌描述，门店未填写时继承
+	LogoMediaID *uint     `gorm:"index" json:"logoMediaId"`                                          // 品牌标志（媒体文件ID），门店未设置图片时继承
+	CategoryID  *uint     `gorm:"index" json:"categoryId"`                                           // 默认分类，创建门店未指定分类时使用
+	CreatedBy   uint      `gorm:"not null" json:"createdBy"`                                         // 创建人ID
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`                                   // 创建时间
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updatedAt"`                                   // 更新时间
+
+	LogoURL      string `gorm:"-" json:"logoUrl,omitempty"`      // 品牌标志原图地址（计算字段）
+	ThumbnailURL string `gorm:"-" json:"thumbnailUrl,omitempty"` // 品牌标志缩略图地址（计算字段）
+	StoreCount   int64  `gorm:"-" json:"storeCount"`             // 门店数量（计算字段，不含回收站中的门店）
+}
+
+// TableName 指定表名
+func (Brand) TableName() string {
+	return "brands"
+}
+
+// ApplyLogoURL 根据标志媒体ID设置标志访问地址
+func (b *Brand) ApplyLogoURL() {
+	b.LogoURL, b.ThumbnailURL = "", ""
+	if b.LogoMediaID == nil {
+		return
+	}
+	b.LogoURL = MediaContentURL(*b.LogoMediaID)
+	b.ThumbnailURL = MediaVariantURL(*b.LogoMediaID, MediaThumbnailSize)
+}
+
+// BrandAdmin 品牌管理员，可维护品牌资料及其全部门店
+type BrandAdmin struct {
+	BrandID   uint      `gorm:"primaryKey;autoIncrement:false" json:"brandId"`      // 品牌ID
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false;index" json:"userId"` // 用户ID
+	CreatedBy uint      `gorm:"not null" json:"createdBy"`                          // 添加人ID
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`                    // 添加时间
+
+	Username string `gorm:"-" json:"username,omitempty"` // 用户名（计算字段）
+}
+
+// TableName 指定表名
+func (BrandAdmin) TableName() string {
+	return "brand_admins"
+}
+
+// BrandRequest 创建或修改品牌请求
+type BrandRequest struct {
+	Name        string  `json:"name" binding:"required"` // 品牌名称
+	Email       string  `json:"email"`                   // 品牌联系邮箱
+	Contact     string  `json:"contact"`                 // 联系方式
+	Description *string `json:"description"`             // 品牌描述
+	LogoMediaID *uint   `json:"logoMediaId"`             // 品牌标志（媒体文件ID）
+	CategoryID  *uint   `json:"categoryId"`              // 默认分类ID
+}
+
+// BrandAdminRequest 添加品牌管理员请求
+type BrandAdminRequest struct {
+	UserID uint `json:"userId" binding:"required"` // 用户ID
+}
+
+// BrandStats 品牌汇总统计（不含回收站中的门店）
+type BrandStats struct {
+	BrandID      uint             `json:"brandId"`      // 品牌ID
+	StoreCount   int64            `json:"storeCount"`   // 门店数量
+	StatusCounts map[string]int64 `json:"statusCounts"` // 各状态的门店数量
+	Cities       []*RegionCount   `json:"cities"`       // 各城市的门店数量（按数量倒序）
+	ReviewCount  int64            `json:"reviewCount"`  // 全部门店计入评分的评价数
+	Rating       float64          `json:"rating"`       // 品牌评分：全部门店已公开评价的算术平均（0-5）
+	Distribution map[int]int64    `json:"distribution"` // 全部门店各星级的评价数
+}
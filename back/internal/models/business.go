@@ -1,25 +1,66 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Business 商家模型
 type Business struct {
 	ID          int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name        string    `gorm:"type:varchar(255);not null" json:"name"`              // 商家名称
-	Email       string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"email"` // 邮箱（唯一）
-	Address     string    `gorm:"type:varchar(255);not null" json:"address"`           // 地址
-	Type        string    `gorm:"type:varchar(100);not null" json:"type"`              // 商家类型
-	Contact     string    `gorm:"type:varchar(255);not null" json:"contact"`           // 联系方式
-	Rating      float64   `gorm:"default:0" json:"rating"`                             // 评分（0-5）
-	Latitude    *float64  `gorm:"type:double" json:"latitude"`                         // 纬度（可空）
-	Longitude   *float64  `gorm:"type:double" json:"longitude"`                        // 经度（可空）
-	OtherInfo   *string   `gorm:"type:text" json:"otherInfo"`                          // 其他信息（可空）
-	ImageBase64 *string   `gorm:"type:longtext" json:"imageBase64"`                    // base64图片（可空）
-	Description *string   `gorm:"type:text" json:"description"`                        // 描述（可空）
-	Status      string    `gorm:"type:varchar(50);default:'active'" json:"status"`     // 状态（active, inactive, suspended）
-	Phone       string    `gorm:"type:varchar(20)" json:"phone"`                       // 电话号码
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`                     // 创建时间
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updatedAt"`                     // 更新时间
+	Name        string    `gorm:"type:varchar(255);not null" json:"name"`                                // 商家名称
+	Email       string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_business_email" json:"email"` // 邮箱（未删除商家中唯一），品牌门店可为空
+	Address     string    `gorm:"type:varchar(255);not null" json:"address"`                             // 地址（展示用）
+	Country     string    `gorm:"type:varchar(2);index" json:"country"`                                  // 国家/地区代码（ISO 3166-1 alpha-2）
+	Province    string    `gorm:"type:varchar(100);index" json:"province"`                               // 省/都道府县
+	City        string    `gorm:"type:varchar(100);index" json:"city"`                                   // 城市
+	District    string    `gorm:"type:varchar(100);index" json:"district"`                               // 区/县
+	Street      string    `gorm:"type:varchar(255)" json:"street"`                                       // 街道门牌
+	PostalCode  string    `gorm:"type:varchar(20)" json:"postalCode"`                                    // 邮政编码
+	Type        string    `gorm:"type:varchar(100);not null" json:"type"`                                // 商家类型（主分类的标识，见 CategoryIDs）
+	Contact     string    `gorm:"type:varchar(255);not null" json:"contact"`                             // 联系方式
+	Rating      float64   `gorm:"default:0" json:"rating"`                                               // 评分（0-5），由已公开的顾客评价计算（见 BusinessRating），不可直接修改
+	Latitude    *float64  `gorm:"type:double" json:"latitude"`                                           // 纬度（可空）
+	Longitude   *float64  `gorm:"type:double" json:"longitude"`                                          // 经度（可空）
+	OtherInfo   *string   `gorm:"type:text" json:"otherInfo"`                                            // 其他信息（可空）
+	ImageBase64 *string   `gorm:"-" json:"imageBase64,omitempty"`                                        // base64图片，仅用于兼容旧客户端提交：保存时转存为媒体文件（见 ImageMediaID），不再返回
+	Description *string   `gorm:"type:text" json:"description"`                                          // 描述（可空）
+	Status      string    `gorm:"type:varchar(50);default:'draft'" json:"status"`                        // 状态（见 BusinessStatus 常量）
+	Phone       string    `gorm:"type:varchar(20)" json:"phone"`                                         // 电话号码
+	Timezone    string    `gorm:"type:varchar(64)" json:"timezone"`                                      // IANA 时区
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`                                       // 创建时间
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updatedAt"`                                       // 更新时间
+
+	ReviewerID   *uint      `gorm:"index" json:"reviewerId"`          // 入驻审核人ID（可空）
+	RejectReason *string    `gorm:"type:text" json:"rejectReason"`    // 最近一次驳回原因（可空）
+	SubmittedAt  *time.Time `gorm:"type:datetime" json:"submittedAt"` // 最近一次提交审核时间（可空）
+
+	Version int `gorm:"not null;default:1" json:"version"` // 数据版本号，每次修改加一，用于乐观并发控制（ETag）
+
+	ImageMediaID *uint                  `gorm:"index" json:"imageMediaId"`                             // 商家图片（媒体文件ID，可空）
+	ImageURL     string                 `gorm:"-" json:"imageUrl,omitempty"`                           // 商家图片原图地址（计算字段，仅详情返回）
+	ThumbnailURL string                 `gorm:"-" json:"thumbnailUrl,omitempty"`                       // 商家图片缩略图地址（计算字段）
+	ImageColor   string                 `gorm:"-" json:"imageColor,omitempty"`                         // 商家图片主色调（计算字段），用作图片加载前的占位色
+	Attributes   map[string]interface{} `gorm:"type:json;serializer:json" json:"attributes,omitempty"` // 自定义属性（按所属分类的属性定义校验，见 Category.AttributeSchema）
+
+	RatingCount int `gorm:"not null;default:0" json:"ratingCount"` // 计入评分的评价数
+
+	BrandID         *uint    `gorm:"index" json:"brandId"`               // 所属品牌ID（可空，为空表示独立商家）
+	InheritedFields []string `gorm:"-" json:"inheritedFields,omitempty"` // 继承自品牌的字段（计算字段），修改时提交 null 保持继承
+
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`                                    // 删除时间（软删除）
+	DeleteMarker int            `gorm:"not null;default:0;uniqueIndex:uk_business_email" json:"-"` // 删除标记：未删除为0，删除后为自身ID，使唯一索引忽略已删除记录
+
+	OpeningHours []BusinessHours        `gorm:"foreignKey:BusinessID" json:"openingHours,omitempty"` // 每周营业时段
+	SpecialHours []BusinessSpecialHours `gorm:"foreignKey:BusinessID" json:"specialHours,omitempty"` // 特殊日期安排
+	CategoryIDs  []uint                 `gorm:"-" json:"categoryIds,omitempty"`                      // 所属分类ID，第一个为主分类（见 business_categories 表）
+	Tags         []string               `gorm:"-" json:"tags,omitempty"`                             // 标签（见 business_tags 表）
+	IsOpen       *bool                  `gorm:"-" json:"isOpen,omitempty"`                           // 当前是否营业（计算字段）
+	NextChange   *time.Time             `gorm:"-" json:"nextChange,omitempty"`                       // 下次营业状态变化时间（计算字段，31天内不变化时为空）
+
+	SearchScore *float64          `gorm:"-" json:"searchScore,omitempty"` // 搜索相关度（仅搜索结果返回）
+	Highlights  map[string]string `gorm:"-" json:"highlights,omitempty"`  // 搜索命中字段的高亮文本（仅搜索结果返回）
 }
 
 // TableName 指定表名
@@ -32,6 +73,13 @@ const (
 	BusinessStatusActive    = "active"    // 活跃
 	BusinessStatusInactive  = "inactive"  // 非活跃
 	BusinessStatusSuspended = "suspended" // 暂停
+
+	// 入驻审核流程状态
+	BusinessStatusDraft       = "draft"        // 草稿
+	BusinessStatusSubmitted   = "submitted"    // 已提交
+	BusinessStatusUnderReview = "under_review" // 审核中
+	BusinessStatusApproved    = "approved"     // 审核通过
+	BusinessStatusRejected    = "rejected"     // 已驳回
 )
 
 // BusinessType 商家类型常量
@@ -42,3 +90,55 @@ const (
 	BusinessTypeEntertainment = "entertainment" // 娱乐
 	BusinessTypeOther         = "other"         // 其他
 )
+
+// 地区聚合层级
+const (
+	RegionLevelCountry  = "country"  // 国家
+	RegionLevelProvince = "province" // 省/都道府县
+	RegionLevelCity     = "city"     // 城市
+	RegionLevelDistrict = "district" // 区/县
+)
+
+// RegionFilter 地区筛选条件
+type RegionFilter struct {
+	Country  string `form:"country" json:"country"`   // 国家/地区代码
+	Province string `form:"province" json:"province"` // 省/都道府县
+	City     string `form:"city" json:"city"`         // 城市
+	District string `form:"district" json:"district"` // 区/县
+}
+
+// IsEmpty 检查是否未设置任何地区条件
+func (f RegionFilter) IsEmpty() bool {
+	return f.Country == "" && f.Province == "" && f.City == "" && f.District == ""
+}
+
+// RegionCount 地区聚合统计结果
+type RegionCount struct {
+	Region string `json:"region"` // 地区名称
+	Count  int64  `json:"count"`  // 商家数量
+}
+
+// VersionConflict 批量更新中版本号不一致的记录
+type VersionConflict struct {
+	Index           int `json:"index"`           // 在请求列表中的下标（从0开始）
+	ID              int `json:"id"`              // 商家ID
+	ExpectedVersion int `json:"expectedVersion"` // 请求携带的版本号
+	CurrentVersion  int `json:"currentVersion"`  // 当前版本号
+}
+
+// HasStructuredAddress 检查是否已填写结构化地址
+func (b *Business) HasStructuredAddress() bool {
+	return b.Country != "" || b.Province != "" || b.City != "" || b.District != "" || b.Street != "" || b.PostalCode != ""
+}
+
+// ApplyImageURL 根据图片媒体ID设置图片访问地址：列表只返回缩略图地址，full 为 true 时（详情）同时返回原图地址
+func (b *Business) ApplyImageURL(full bool) {
+	b.ImageURL, b.ThumbnailURL = "", ""
+	if b.ImageMediaID == nil {
+		return
+	}
+	b.ThumbnailURL = MediaVariantURL(*b.ImageMediaID, MediaThumbnailSize)
+	if full {
+		b.ImageURL = MediaContentURL(*b.ImageMediaID)
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+// BusinessTag 商家标签
+type BusinessTag struct {
+	BusinessID int    `gorm:"primaryKey" json:"businessId"`                 // 商家ID
+	Tag        string `gorm:"type:varchar(50);primaryKey;index" json:"tag"` // 标签
+}
+
+// TableName 指定表名
+func (BusinessTag) TableName() string {
+	return "business_tags"
+}
+
+// BusinessAttributeValue 商家自定义属性的查询索引，每个属性值（数组属性的每个元素）一行
+// 属性以 Business.Attributes 为准，保存商家时重建索引，用于列表的筛选与排序
+type BusinessAttributeValue struct {
+	ID          uint     `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID  int      `gorm:"not null;index" json:"businessId"`                                                                        // 商家ID
+	Name        string   `gorm:"type:varchar(64);not null;index:idx_attr_string,priority:1;index:idx_attr_number,priority:1" json:"name"` // 属性名
+	StringValue *string  `gorm:"type:varchar(255);index:idx_attr_string,priority:2" json:"stringValue"`                                   // 文本值（字符串、布尔属性）
+	NumberValue *float64 `gorm:"type:double;index:idx_attr_number,priority:2" json:"numberValue"`                                         // 数值（integer、number 属性）
+}
+
+// TableName 指定表名
+func (BusinessAttributeValue) TableName() string {
+	return "business_attribute_values"
+}
+
+// TagCount 标签及使用该标签的商家数量
+type TagCount struct {
+	Tag   string `json:"tag"`   // 标签
+	Count int64  `json:"count"` // 商家数量
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// 佣金计算方式
+const (
+	CommissionTypePercentage = "percentage" // 固定比例
+	CommissionTypeTiered     = "tiered"     // 按结算周期内交易额阶梯计费
+)
+
+// 固定费用收取周期
+const (
+	FeePeriodNone    = ""        // 无固定费用
+	FeePeriodOnce    = "once"    // 一次性
+	FeePeriodMonthly = "monthly" // 每月
+	FeePeriodYearly  = "yearly"  // 每年
+)
+
+// 结算周期
+const (
+	SettlementCycleDaily   = "daily"
+	SettlementCycleWeekly  = "weekly"
+	SettlementCycleMonthly = "monthly"
+)
+
+// CommissionTier 阶梯佣金：结算周期内交易额达到 MinAmount 后适用 Rate
+type CommissionTier struct {
+	MinAmount int64   `json:"minAmount"` // 交易额下限（分），第一档必须为 0
+	Rate      float64 `json:"rate"`      // 佣金比例（百分比，如 5.5 表示 5.5%）
+}
+
+// BusinessContract 商家合同：约定有效期内的佣金、固定费用与结算账期。
+// 同一商家的合同有效期不能重叠；已生效的合同只能修改结束日期与备注（提前终止或续期），其余条款需签订新合同
+type BusinessContract struct {
+	ID              uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID      int              `gorm:"not null;index:idx_business_contracts_period,priority:1" json:"businessId"`              // 商家ID
+	ContractNo      string           `gorm:"type:varchar(64);not null" json:"contractNo"`                                            // 合同编号
+	StartDate       string           `gorm:"type:char(10);not null;index:idx_business_contracts_period,priority:2" json:"startDate"` // 生效日期（YYYY-MM-DD）
+	EndDate         string           `gorm:"type:char(10);not null;default:''" json:"endDate"`                                       // 截止日期（YYYY-MM-DD，含当天），为空表示长期有效
+	CommissionType  string           `gorm:"type:varchar(20);not null" json:"commissionType"`                                        // 佣金计算方式
+	CommissionRate  float64          `gorm:"type:decimal(5,2);not null;default:0" json:"commissionRate"`                             // 佣金比例（百分比），固定比例时使用
+	CommissionTiers []CommissionTier `gorm:"type:json;serializer:json" json:"commissionTiers,omitempty"`                             // 阶梯佣金（按交易额下限升序），阶梯计费时使用
+	FixedFee        int64            `gorm:"not null;default:0" json:"fixedFee"`                                                     // 固定费用（分）
+	FixedFeePeriod  string           `gorm:"type:varchar(20);not null;default:''" json:"fixedFeePeriod"`                             // 固定费用收取周期
+	SettlementCycle string           `gorm:"type:varchar(20);not null" json:"settlementCycle"`                                       // 结算周期
+	PaymentDays     int              `gorm:"not null;default:0" json:"paymentDays"`                                                  // 账期：结算周期结束后多少天内付款
+	Notes           string           `gorm:"type:text" json:"notes"`                                                                 // 备注
+
+	AttachmentKey      string `gorm:"type:varchar(255);not null;default:''" json:"-"`                  // 已签署合同 PDF 在存储中的键
+	AttachmentFilename string `gorm:"type:varchar(255);not null;default:''" json:"attachmentFilename"` // 附件原始文件名
+	AttachmentSize     int64  `gorm:"not null;default:0" json:"attachmentSize"`                        // 附件大小（字节）
+	AttachmentChecksum string `gorm:"type:char(64);not null;default:''" json:"attachmentChecksum"`     // 附件 SHA-256
+
+	CreatedBy uint      `gorm:"not null" json:"createdBy"`       // 创建人ID
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"` // 创建时间
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"` // 更新时间
+
+	AttachmentURL string `gorm:"-" json:"attachmentUrl,omitempty"` // 附件下载地址（计算字段，需登录，未上传时为空）
+}
+
+// TableName 指定表名
+func (BusinessContract) TableName() string {
+	return "business_contracts"
+}
+
+// ApplyAttachmentURL 设置附件下载地址
+func (c *BusinessContract) ApplyAttachmentURL() {
+	c.AttachmentURL = ""
+	if c.AttachmentKey != "" {
+		c.AttachmentURL = "/api/v1/business/" + strconv.Itoa(c.BusinessID) + "/contracts/" + strconv.FormatUint(uint64(c.ID), 10) + "/attachment"
+	}
+}
+
+// CoversDate 合同在指定日期（YYYY-MM-DD）是否有效
+func (c *BusinessContract) CoversDate(date string) bool {
+	return c.StartDate <= date && (c.EndDate == "" || c.EndDate >= date)
+}
+
+// RateFor 结算周期内交易额为 amount（分）时适用的佣金比例
+func (c *BusinessContract) RateFor(amount int64) float64 {
+	if c.CommissionType != CommissionTypeTiered {
+		return c.CommissionRate
+	}
+	rate := 0.0
+	for _, tier := range c.CommissionTiers {
+		if amount < tier.MinAmount {
+			break
+		}
+		rate = tier.Rate
+	}
+	return rate
+}
+
+// BusinessContractRequest 创建或修改合同请求
+type BusinessContractRequest struct {
+	ContractNo      string           `json:"contractNo" binding:"required"`      // 合同编号
+	StartDate       string           `json:"startDate" binding:"required"`       // 生效日期（YYYY-MM-DD）
+	EndDate         string           `json:"endDate"`                            // 截止日期（YYYY-MM-DD，含当天），为空表示长期有效
+	CommissionType  string           `json:"commissionType" binding:"required"`  // 佣金计算方式：percentage, tiered
+	CommissionRate  float64          `json:"commissionRate"`                     // 佣金比例（百分比）
+	CommissionTiers []CommissionTier `json:"commissionTiers"`                    // 阶梯佣金
+	FixedFee        int64            `json:"fixedFee"`                           // 固定费用（分）
+	FixedFeePeriod  string           `json:"fixedFeePeriod"`                     // 固定费用收取周期：once, monthly, yearly
+	SettlementCycle string           `json:"settlementCycle" binding:"required"` // 结算周期：daily, weekly, monthly
+	PaymentDays     int              `json:"paymentDays"`                        // 账期天数
+	Notes           string           `json:"notes"`                              // 备注
+}
+
+// ContractTerms 商家在某一日期生效的合同条款
+type ContractTerms struct {
+	Date     string            `json:"date"`             // 查询日期（YYYY-MM-DD）
+	Contract *BusinessContract `json:"contract"`         // 生效的合同
+	Amount   *int64            `json:"amount,omitempty"` // 查询的结算周期交易额（分）
+	Rate     *float64          `json:"rate,omitempty"`   // 该交易额适用的佣金比例（百分比），查询时指定交易额才返回
+}
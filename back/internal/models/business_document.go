@@ -0,0 +1,105 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// 资质证件类型
+const (
+	DocumentTypeBusinessLicense = "business_license" // 营业执照
+	DocumentTypeFoodPermit      = "food_permit"      // 食品经营许可证
+	DocumentTypeIDCard          = "id_card"          // 法人身份证件
+	DocumentTypeOther           = "other"            // 其他
+)
+
+// DocumentTypes 全部资质证件类型
+var DocumentTypes = []string{
+	DocumentTypeBusinessLicense,
+	DocumentTypeFoodPermit,
+	DocumentTypeIDCard,
+	DocumentTypeOther,
+}
+
+// IsValidDocumentType 检查资质证件类型是否有效
+func IsValidDocumentType(documentType string) bool {
+	for _, t := range DocumentTypes {
+		if t == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// 资质证件审核状态
+const (
+	DocumentStatusPending  = "pending"  // 待审核
+	DocumentStatusVerified = "verified" // 已核验
+	DocumentStatusRejected = "rejected" // 已驳回
+	DocumentStatusExpired  = "expired"  // 已过期（由到期检查任务设置）
+)
+
+// DocumentTypeExtensions 允许上传的证件文件类型（按内容识别）及存储扩展名
+var DocumentTypeExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// BusinessDocument 商家资质证件（营业执照、许可证、身份证件等）。
+// 文件直接保存在存储中且不公开，只能通过商家证件接口下载；修改证件信息或文件后需重新核验
+type BusinessDocument struct {
+	ID               uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID       int        `gorm:"not null;index" json:"businessId"`                              // 商家ID
+	Type             string     `gorm:"type:varchar(30);not null" json:"type"`                         // 证件类型（见 DocumentType 常量）
+	Number           string     `gorm:"type:varchar(100);not null" json:"number"`                      // 证件编号
+	IssuingAuthority string     `gorm:"type:varchar(255);not null;default:''" json:"issuingAuthority"` // 发证机关
+	ValidFrom        string     `gorm:"type:char(10);not null;default:''" json:"validFrom"`            // 有效期起（YYYY-MM-DD）
+	ValidUntil       string     `gorm:"type:char(10);not null;default:'';index" json:"validUntil"`     // 有效期至（YYYY-MM-DD），为空表示长期有效
+	StorageKey       string     `gorm:"type:varchar(255);not null" json:"-"`                           // 文件在存储中的键
+	Filename         string     `gorm:"type:varchar(255);not null;default:''" json:"filename"`         // 原始文件名
+	ContentType      string     `gorm:"type:varchar(100);not null" json:"contentType"`                 // 文件类型
+	Size             int64      `gorm:"not null" json:"size"`                                          // 文件大小（字节）
+	Checksum         string     `gorm:"type:char(64);not null" json:"checksum"`                        // SHA-256
+	Status           string     `gorm:"type:varchar(20);not null;default:pending;index" json:"status"` // 审核状态
+	RejectReason     *string    `gorm:"type:text" json:"rejectReason"`                                 // 驳回原因
+	ReviewedBy       *uint      `json:"reviewedBy"`                                                    // 审核人ID
+	ReviewedAt       *time.Time `gorm:"type:datetime" json:"reviewedAt"`                               // 审核时间
+	ExpiryFlaggedAt  *time.Time `gorm:"type:datetime" json:"expiryFlaggedAt"`                          // 被标记为即将到期的时间
+	UploadedBy       uint       `gorm:"not null" json:"uploadedBy"`                                    // 上传人ID
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"createdAt"`                               // 上传时间
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`                               // 更新时间
+
+	FileURL string `gorm:"-" json:"fileUrl"` // 文件下载地址（计算字段，需登录）
+}
+
+// TableName 指定表名
+func (BusinessDocument) TableName() string {
+	return "business_documents"
+}
+
+// ApplyFileURL 设置文件下载地址
+func (d *BusinessDocument) ApplyFileURL() {
+	d.FileURL = "/api/v1/business/" + strconv.Itoa(d.BusinessID) + "/documents/" + strconv.FormatUint(uint64(d.ID), 10) + "/file"
+}
+
+// IsLapsed 证件在指定日期（YYYY-MM-DD）是否已过有效期
+func (d *BusinessDocument) IsLapsed(today string) bool {
+	return d.ValidUntil != "" && d.ValidUntil < today
+}
+
+// BusinessDocumentRequest 上传或修改资质证件的表单字段（文件字段名 file）
+type BusinessDocumentRequest struct {
+	Type             string `form:"type" binding:"required"`   // 证件类型
+	Number           string `form:"number" binding:"required"` // 证件编号
+	IssuingAuthority string `form:"issuingAuthority"`          // 发证机关
+	ValidFrom        string `form:"validFrom"`                 // 有效期起（YYYY-MM-DD）
+	ValidUntil       string `form:"validUntil"`                // 有效期至（YYYY-MM-DD），不填表示长期有效
+}
+
+// DocumentExpiryResult 资质证件到期检查结果
+type DocumentExpiryResult struct {
+	Flagged   int   `json:"flagged"`   // 新标记为即将到期的证件数
+	Expired   int   `json:"expired"`   // 新过期的证件数
+	Suspended []int `json:"suspended"` // 因必备证件过期被暂停的商家
+}
@@ -0,0 +1,64 @@
+package model
+
+// RatingBucket 评分区间，包含下限、不包含上限（最高区间包含上限）
+type RatingBucket struct {
+	Key string  `json:"key"` // 区间标识，如 "4-5"
+	Min float64 `json:"min"` // 下限
+	Max float64 `json:"max"` // 上限
+}
+
+// RatingBuckets 评分分面的区间（由高到低）
+var RatingBuckets = []RatingBucket{
+	{Key: "4-5", Min: 4, Max: 5},
+	{Key: "3-4", Min: 3, Max: 4},
+	{Key: "2-3", Min: 2, Max: 3},
+	{Key: "1-2", Min: 1, Max: 2},
+	{Key: "0-1", Min: 0, Max: 1},
+}
+
+// RatingBucketOf 返回评分所在的区间标识
+func RatingBucketOf(rating float64) string {
+	for i, bucket := range RatingBuckets {
+		if rating >= bucket.Min && (rating < bucket.Max || i == 0) {
+			return bucket.Key
+		}
+	}
+	return RatingBuckets[len(RatingBuckets)-1].Key
+}
+
+// 分面维度，地区维度使用 RegionLevel* 层级
+const (
+	FacetType   = "type"   // 商家类型
+	FacetStatus = "status" // 状态
+	FacetRating = "rating" // 评分区间
+	FacetTags   = "tags"   // 标签
+)
+
+// FacetCount 分面中一个取值及匹配的商家数量
+type FacetCount struct {
+	Value string `json:"value"` // 取值
+	Count int64  `json:"count"` // 商家数量
+}
+
+// BusinessFacets 搜索结果的分面统计
+// 每个维度的数量按当前关键词及其他维度的筛选条件计算（不含本维度的筛选），
+// 因此同一维度内选择多个取值时，各取值的数量仍可见
+type BusinessFacets struct {
+	Type        []FacetCount `json:"type"`        // 商家类型
+	Status      []FacetCount `json:"status"`      // 状态
+	Rating      []FacetCount `json:"rating"`      // 评分区间（见 RatingBuckets，固定顺序）
+	Region      []FacetCount `json:"region"`      // 地区（RegionLevel 层级）
+	RegionLevel string       `json:"regionLevel"` // 地区分面的层级：已选地区的下一级，未选时为国家
+	Tags        []FacetCount `json:"tags"`        // 标签（按数量取前50个）
+	Truncated   bool         `json:"truncated"`   // 关键词命中数超过上限，总数与各分面只统计最相关的部分商家
+}
+
+// BusinessSearchFilter 搜索结果的分面筛选条件
+// 同一维度的多个取值之间为“或”，不同维度之间为“且”
+type BusinessSearchFilter struct {
+	Types    []string     // 商家类型
+	Statuses []string     // 状态
+	Ratings  []string     // 评分区间标识（见 RatingBuckets）
+	Region   RegionFilter // 地区
+	Tags     []string     // 标签
+}
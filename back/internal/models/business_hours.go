@@ -0,0 +1,198 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// 营业时间格式
+const (
+	HoursTimeLayout = "15:04"      // 时段时间格式
+	HoursDateLayout = "2006-01-02" // 特殊日期格式
+)
+
+// DefaultBusinessTimezone 商家默认时区
+const DefaultBusinessTimezone = "Asia/Tokyo"
+
+// openStatusLookaheadDays 计算下次营业状态变化时向后查找的天数
+const openStatusLookaheadDays = 31
+
+// BusinessHours 商家每周营业时段，同一天可有多个时段
+type BusinessHours struct {
+	ID         int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID int    `gorm:"not null;index" json:"businessId"`       // 商家ID
+	Weekday    int    `gorm:"type:tinyint;not null" json:"weekday"`   // 星期（0=周日，6=周六）
+	OpenTime   string `gorm:"type:char(5);not null" json:"openTime"`  // 开始时间（HH:MM）
+	CloseTime  string `gorm:"type:char(5);not null" json:"closeTime"` // 结束时间（HH:MM），不晚于开始时间表示跨夜
+}
+
+// TableName 指定表名
+func (BusinessHours) TableName() string {
+	return "business_hours"
+}
+
+// BusinessSpecialHours 节假日/特殊日期营业安排，覆盖当天的每周营业时段
+type BusinessSpecialHours struct {
+	ID         int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID int    `gorm:"not null;index:idx_special_hours_business_date" json:"businessId"`         // 商家ID
+	Date       string `gorm:"type:char(10);not null;index:idx_special_hours_business_date" json:"date"` // 日期（YYYY-MM-DD）
+	Closed     bool   `gorm:"default:false" json:"closed"`                                              // 是否全天休息
+	OpenTime   string `gorm:"type:char(5)" json:"openTime"`                                             // 开始时间（HH:MM）
+	CloseTime  string `gorm:"type:char(5)" json:"closeTime"`                                            // 结束时间（HH:MM）
+	Note       string `gorm:"type:varchar(255)" json:"note"`                                            // 备注（如节假日名称）
+}
+
+// TableName 指定表名
+func (BusinessSpecialHours) TableName() string {
+	return "business_special_hours"
+}
+
+// OpeningHoursRequest 营业时间设置请求
+type OpeningHoursRequest struct {
+	Timezone     string                 `json:"timezone"`     // IANA 时区，如 Asia/Tokyo
+	OpeningHours []BusinessHours        `json:"openingHours"` // 每周营业时段
+	SpecialHours []BusinessSpecialHours `json:"specialHours"` // 特殊日期安排
+}
+
+// hoursInterval 具体时间区间 [start, end)
+type hoursInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+// Location 获取商家时区，无效时使用默认时区
+func (b *Business) Location() *time.Location {
+	tz := b.Timezone
+	if tz == "" {
+		tz = DefaultBusinessTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// OpenStatusAt 计算指定时刻是否营业以及下一次营业状态变化的时间
+// 查找范围内营业状态不再变化时（如全天候营业）下一次变化时间为 nil
+func (b *Business) OpenStatusAt(t time.Time) (bool, *time.Time) {
+	loc := b.Location()
+	local := t.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	horizon := today.AddDate(0, 0, openStatusLookaheadDays+1)
+
+	// 前一天的跨夜时段可能延续到今天
+	var intervals []hoursInterval
+	for i := -1; i <= openStatusLookaheadDays; i++ {
+		intervals = append(intervals, b.intervalsOn(today.AddDate(0, 0, i))...)
+	}
+	intervals = mergeIntervals(intervals)
+
+	for _, iv := range intervals {
+		if !t.Before(iv.start) && t.Before(iv.end) {
+			// 营业区间延续到查找范围末尾，实际结束时间未知
+			if !iv.end.Before(horizon) {
+				return true, nil
+			}
+			end := iv.end
+			return true, &end
+		}
+		if iv.start.After(t) {
+			start := iv.start
+			return false, &start
+		}
+	}
+	return false, nil
+}
+
+// IsOpenAt 检查指定时刻是否营业
+func (b *Business) IsOpenAt(t time.Time) bool {
+	open, _ := b.OpenStatusAt(t)
+	return open
+}
+
+// ApplyOpenStatus 填充指定时刻的 isOpen / nextChange 字段
+func (b *Business) ApplyOpenStatus(t time.Time) {
+	open, next := b.OpenStatusAt(t)
+	b.IsOpen = &open
+	b.NextChange = next
+}
+
+// intervalsOn 获取某一天（商家时区）开始的营业区间，特殊日期安排优先于每周营业时段
+func (b *Business) intervalsOn(day time.Time) []hoursInterval {
+	date := day.Format(HoursDateLayout)
+
+	var special []BusinessSpecialHours
+	for _, sh := range b.SpecialHours {
+		if sh.Date == date {
+			special = append(special, sh)
+		}
+	}
+
+	var intervals []hoursInterval
+	if len(special) > 0 {
+		for _, sh := range special {
+			if sh.Closed {
+				return nil
+			}
+			if iv, ok := buildInterval(day, sh.OpenTime, sh.CloseTime); ok {
+				intervals = append(intervals, iv)
+			}
+		}
+		return intervals
+	}
+
+	weekday := int(day.Weekday())
+	for _, h := range b.OpeningHours {
+		if h.Weekday != weekday {
+			continue
+		}
+		if iv, ok := buildInterval(day, h.OpenTime, h.CloseTime); ok {
+			intervals = append(intervals, iv)
+		}
+	}
+	return intervals
+}
+
+// buildInterval 根据日期与 HH:MM 构建营业区间，结束时间不晚于开始时间时视为跨夜
+func buildInterval(day time.Time, openTime, closeTime string) (hoursInterval, bool) {
+	open, err := time.Parse(HoursTimeLayout, openTime)
+	if err != nil {
+		return hoursInterval{}, false
+	}
+	closing, err := time.Parse(HoursTimeLayout, closeTime)
+	if err != nil {
+		return hoursInterval{}, false
+	}
+
+	loc := day.Location()
+	start := time.Date(day.Year(), day.Month(), day.Day(), open.Hour(), open.Minute(), 0, 0, loc)
+	end := time.Date(day.Year(), day.Month(), day.Day(), closing.Hour(), closing.Minute(), 0, 0, loc)
+	if !end.After(start) {
+		end = time.Date(day.Year(), day.Month(), day.Day()+1, closing.Hour(), closing.Minute(), 0, 0, loc)
+	}
+	return hoursInterval{start: start, end: end}, true
+}
+
+// mergeIntervals 合并重叠或相邻的区间
+func mergeIntervals(intervals []hoursInterval) []hoursInterval {
+	if len(intervals) == 0 {
+		return nil
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	merged := []hoursInterval{intervals[0]}
+	for _, iv := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if !iv.start.After(last.end) {
+			if iv.end.After(last.end) {
+				last.end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
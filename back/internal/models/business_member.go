@@ -0,0 +1,88 @@
+package model
+
+import "time"
+
+// 商家成员角色（按权限从高到低）
+const (
+	BusinessRoleOwner   = "owner"   // 店主：全部权限，可邀请、移除成员
+	BusinessRoleManager = "manager" // 店长：维护商家资料、营业时间、相册与状态
+	BusinessRoleCashier = "cashier" // 收银员：回复顾客评价
+	BusinessRoleViewer  = "viewer"  // 观察者：只读查看修订记录、状态历史与成员
+)
+
+// 商家操作权限
+const (
+	BusinessPermissionView   = "view"   // 查看修订记录、状态历史、计划流转与成员
+	BusinessPermissionReply  = "reply"  // 回复顾客评价
+	BusinessPermissionEdit   = "edit"   // 修改资料、营业时间、相册顺序，提交入驻申请，变更状态
+	BusinessPermissionManage = "manage" // 删除与恢复商家，邀请、移除成员及修改成员角色
+)
+
+// businessRolePermissions 各角色拥有的权限
+var businessRolePermissions = map[string][]string{
+	BusinessRoleOwner:   {BusinessPermissionView, BusinessPermissionReply, BusinessPermissionEdit, BusinessPermissionManage},
+	BusinessRoleManager: {BusinessPermissionView, BusinessPermissionReply, BusinessPermissionEdit},
+	BusinessRoleCashier: {BusinessPermissionView, BusinessPermissionReply},
+	BusinessRoleViewer:  {BusinessPermissionView},
+}
+
+// IsValidBusinessRole 检查商家成员角色是否有效
+func IsValidBusinessRole(role string) bool {
+	_, ok := businessRolePermissions[role]
+	return ok
+}
+
+// BusinessRoleAllows 检查角色是否拥有权限
+func BusinessRoleAllows(role, permission string) bool {
+	for _, p := range businessRolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// 商家成员状态
+const (
+	BusinessMemberStatusInvited = "invited" // 已邀请，接受前没有任何权限
+	BusinessMemberStatusActive  = "active"  // 已加入
+)
+
+// BusinessMember 商家成员：用户在某个商家的角色，同一用户在不同商家可以有不同角色
+// 系统管理员及商家所属品牌的管理员无需成为成员，拥有全部权限
+type BusinessMember struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID int        `gorm:"not null;uniqueIndex:uk_business_member,priority:1" json:"businessId"`   // 商家ID
+	UserID     uint       `gorm:"not null;uniqueIndex:uk_business_member,priority:2;index" json:"userId"` // 用户ID
+	Role       string     `gorm:"type:varchar(20);not null" json:"role"`                                  // 角色（见 BusinessRole 常量）
+	Status     string     `gorm:"type:varchar(20);not null;default:invited" json:"status"`                // 状态（见 BusinessMemberStatus 常量）
+	InvitedBy  *uint      `json:"invitedBy"`                                                              // 邀请人ID（创建商家时自动成为店主的为空）
+	JoinedAt   *time.Time `gorm:"type:datetime" json:"joinedAt"`                                          // 接受邀请时间
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"createdAt"`                                        // 邀请时间
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`                                        // 更新时间
+
+	Username     string `gorm:"-" json:"username,omitempty"`     // 用户名（计算字段）
+	BusinessName string `gorm:"-" json:"businessName,omitempty"` // 商家名称（计算字段，仅我的商家列表返回）
+}
+
+// TableName 指定表名
+func (BusinessMember) TableName() string {
+	return "business_members"
+}
+
+// IsActive 检查成员是否已加入
+func (m *BusinessMember) IsActive() bool {
+	return m.Status == BusinessMemberStatusActive
+}
+
+// BusinessMemberInviteRequest 邀请商家成员请求，userId 与 email 二选一
+type BusinessMemberInviteRequest struct {
+	UserID uint   `json:"userId"`                  // 用户ID
+	Email  string `json:"email"`                   // 用户邮箱
+	Role   string `json:"role" binding:"required"` // 角色
+}
+
+// BusinessMemberRoleRequest 修改商家成员角色请求
+type BusinessMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"` // 角色
+}
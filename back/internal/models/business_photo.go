@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// 商家照片审核状态
+const (
+	BusinessPhotoStatusPending  = "pending"  // 待审核
+	BusinessPhotoStatusApproved = "approved" // 已通过，公开显示
+	BusinessPhotoStatusRejected = "rejected" // 已驳回
+)
+
+// MaxBusinessPhotos 每个商家相册的照片上限
+const MaxBusinessPhotos = 50
+
+// BusinessPhoto 商家相册照片，图片保存为媒体文件。管理员添加的照片直接通过，其他用户添加的需管理员审核后公开显示
+type BusinessPhoto struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID   int        `gorm:"not null;index:idx_business_photos_position,priority:1" json:"businessId"`         // 商家ID
+	MediaID      uint       `gorm:"not null;index" json:"mediaId"`                                                    // 媒体文件ID
+	Caption      string     `gorm:"type:varchar(255);not null;default:''" json:"caption"`                             // 说明文字
+	Position     int        `gorm:"not null;default:0;index:idx_business_photos_position,priority:2" json:"position"` // 顺序（升序）
+	IsCover      bool       `gorm:"not null;default:false" json:"isCover"`                                            // 是否为封面，每个商家至多一张
+	Status       string     `gorm:"type:varchar(20);not null;default:pending;index" json:"status"`                    // 审核状态
+	RejectReason *string    `gorm:"type:text" json:"rejectReason"`                                                    // 驳回原因
+	UploadedBy   *uint      `json:"uploadedBy"`                                                                       // 添加照片的用户ID
+	ReviewedBy   *uint      `json:"reviewedBy"`                                                                       // 审核人ID
+	ReviewedAt   *time.Time `json:"reviewedAt"`                                                                       // 审核时间
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"createdAt"`                                                  // 添加时间
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`                                                  // 更新时间
+
+	URL           string `gorm:"-" json:"url"`                     // 原图地址（计算字段）
+	ThumbnailURL  string `gorm:"-" json:"thumbnailUrl"`            // 缩略图地址（计算字段）
+	DominantColor string `gorm:"-" json:"dominantColor,omitempty"` // 主色调（计算字段），用作加载前的占位色
+}
+
+// TableName 指定表名
+func (BusinessPhoto) TableName() string {
+	return "business_photos"
+}
+
+// ApplyURL 根据媒体文件ID设置原图与缩略图地址
+func (p *BusinessPhoto) ApplyURL() {
+	p.URL = MediaContentURL(p.MediaID)
+	p.ThumbnailURL = MediaVariantURL(p.MediaID, MediaThumbnailSize)
+}
+
+// BusinessPhotoRequest 添加相册照片请求，图片需先通过媒体文件接口上传
+type BusinessPhotoRequest struct {
+	MediaID uint   `json:"mediaId" binding:"required"` // 媒体文件ID
+	Caption string `json:"caption"`                    // 说明文字
+	IsCover bool   `json:"isCover"`                    // 设为封面（仅已审核通过的照片）
+}
+
+// BusinessPhotoUpdateRequest 修改相册照片请求，未传的字段不修改
+type BusinessPhotoUpdateRequest struct {
+	Caption *string `json:"caption"` // 说明文字
+	IsCover *bool   `json:"isCover"` // 是否为封面
+}
+
+// BusinessPhotoIDsRequest 按ID批量操作相册照片（排序、批量删除）
+type BusinessPhotoIDsRequest struct {
+	PhotoIDs []uint `json:"photoIds" binding:"required"` // 照片ID列表
+}
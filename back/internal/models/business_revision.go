@@ -0,0 +1,131 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// 修订记录来源
+const (
+	RevisionActionCreate   = "create"   // 创建商家
+	RevisionActionUpdate   = "update"   // 更新商家资料
+	RevisionActionHours    = "hours"    // 修改营业时间
+	RevisionActionRestore  = "restore"  // 恢复到历史版本
+	RevisionActionBaseline = "baseline" // 启用修订记录前的初始版本
+)
+
+// revisionIgnoredFields 对比修订时忽略的字段
+var revisionIgnoredFields = map[string]bool{
+	"updatedAt": true,
+	"deletedAt": true,
+	"version":   true,
+}
+
+// BusinessRevision 商家资料修订记录，每次修改保存一份完整快照
+type BusinessRevision struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID    int       `gorm:"not null;uniqueIndex:uk_business_revision,priority:1" json:"businessId"` // 商家ID
+	Version       int       `gorm:"not null;uniqueIndex:uk_business_revision,priority:2" json:"version"`    // 版本号（从1开始递增）
+	Action        string    `gorm:"type:varchar(20);not null" json:"action"`                                // 修订来源
+	ChangedFields string    `gorm:"type:text" json:"changedFields"`                                         // 相对上一版本变化的字段（逗号分隔）
+	Snapshot      string    `gorm:"type:longtext;not null" json:"-"`                                        // 商家资料快照（JSON）
+	RestoredFrom  *int      `json:"restoredFrom"`                                                           // 恢复来源版本号（可空）
+	ActorID       uint      `gorm:"index" json:"actorId"`                                                   // 操作人ID（0 表示系统）
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"createdAt"`                                        // 修订时间
+
+	Business *Business `gorm:"-" json:"business,omitempty"` // 快照还原的商家资料（仅查看单个版本时返回）
+}
+
+// TableName 指定表名
+func (BusinessRevision) TableName() string {
+	return "business_revisions"
+}
+
+// RevisionFieldDiff 两个修订版本间单个字段的差异
+type RevisionFieldDiff struct {
+	Field string      `json:"field"` // 字段名
+	From  interface{} `json:"from"`  // 原值
+	To    interface{} `json:"to"`    // 新值
+}
+
+// RevisionSnapshot 生成商家资料快照，计算字段不计入，营业时间去掉自增ID以便对比
+func (b *Business) RevisionSnapshot() (string, error) {
+	snapshot := *b
+	snapshot.IsOpen = nil
+	snapshot.NextChange = nil
+	snapshot.SearchScore = nil
+	snapshot.Highlights = nil
+	snapshot.ImageURL = ""
+	snapshot.ThumbnailURL = ""
+	snapshot.ImageColor = ""
+	// 评分由顾客评价计算，不属于商家资料的版本内容
+	snapshot.Rating = 0
+	snapshot.RatingCount = 0
+
+	snapshot.OpeningHours = make([]BusinessHours, len(b.OpeningHours))
+	for i, h := range b.OpeningHours {
+		h.ID = 0
+		h.BusinessID = 0
+		snapshot.OpeningHours[i] = h
+	}
+	snapshot.SpecialHours = make([]BusinessSpecialHours, len(b.SpecialHours))
+	for i, sh := range b.SpecialHours {
+		sh.ID = 0
+		sh.BusinessID = 0
+		snapshot.SpecialHours[i] = sh
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SnapshotBusiness 从快照还原商家资料
+func (r *BusinessRevision) SnapshotBusiness() (*Business, error) {
+	var business Business
+	if err := json.Unmarshal([]byte(r.Snapshot), &business); err != nil {
+		return nil, err
+	}
+	return &business, nil
+}
+
+// DiffRevisionSnapshots 对比两份快照，按字段名返回差异
+// from 为空表示没有上一版本，此时全部字段视为变化
+func DiffRevisionSnapshots(from, to string) ([]RevisionFieldDiff, error) {
+	fromFields := map[string]interface{}{}
+	if from != "" {
+		if err := json.Unmarshal([]byte(from), &fromFields); err != nil {
+			return nil, err
+		}
+	}
+	toFields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(to), &toFields); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(toFields))
+	for k := range fromFields {
+		keys[k] = true
+	}
+	for k := range toFields {
+		keys[k] = true
+	}
+
+	diffs := []RevisionFieldDiff{}
+	for k := range keys {
+		if revisionIgnoredFields[k] {
+			continue
+		}
+		if !reflect.DeepEqual(fromFields[k], toFields[k]) {
+			diffs = append(diffs, RevisionFieldDiff{Field: k, From: fromFields[k], To: toFields[k]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Field < diffs[j].Field
+	})
+	return diffs, nil
+}
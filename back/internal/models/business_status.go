@@ -0,0 +1,157 @@
+package model
+
+import "time"
+
+// businessStatusTransitions 商家状态合法流转表
+// 入驻流程：draft → submitted → under_review → approved/rejected → active，驳回后可重新提交
+var businessStatusTransitions = map[string][]string{
+	BusinessStatusDraft:       {BusinessStatusSubmitted},
+	BusinessStatusSubmitted:   {BusinessStatusUnderReview},
+	BusinessStatusUnderReview: {BusinessStatusApproved, BusinessStatusRejected},
+	BusinessStatusRejected:    {BusinessStatusSubmitted},
+	BusinessStatusApproved:    {BusinessStatusActive},
+	BusinessStatusActive:      {BusinessStatusInactive, BusinessStatusSuspended},
+	BusinessStatusInactive:    {BusinessStatusActive, BusinessStatusSuspended},
+	BusinessStatusSuspended:   {BusinessStatusActive, BusinessStatusInactive},
+}
+
+// CanTransitionBusinessStatus 检查商家状态能否从 from 流转到 to
+func CanTransitionBusinessStatus(from, to string) bool {
+	for _, next := range businessStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextBusinessStatuses 获取当前状态可流转到的状态
+func NextBusinessStatuses(from string) []string {
+	return businessStatusTransitions[from]
+}
+
+// IsPendingReview 检查商家是否处于待审核队列
+func (b *Business) IsPendingReview() bool {
+	return b.Status == BusinessStatusSubmitted || b.Status == BusinessStatusUnderReview
+}
+
+// BusinessStatusTransition 商家状态流转记录
+type BusinessStatusTransition struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID int       `gorm:"not null;index" json:"businessId"`          // 商家ID
+	FromStatus string    `gorm:"type:varchar(50)" json:"fromStatus"`        // 原状态
+	ToStatus   string    `gorm:"type:varchar(50);not null" json:"toStatus"` // 新状态
+	ActorID    uint      `gorm:"index" json:"actorId"`                      // 操作人ID（0 表示系统）
+	ReviewerID *uint     `json:"reviewerId"`                                // 流转后的审核人ID（可空）
+	ReasonCode string    `gorm:"type:varchar(50)" json:"reasonCode"`        // 原因代码
+	Note       string    `gorm:"type:text" json:"note"`                     // 备注/驳回原因
+	ScheduleID *uint     `json:"scheduleId"`                                // 触发的计划流转ID（可空）
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`           // 流转时间
+}
+
+// TableName 指定表名
+func (BusinessStatusTransition) TableName() string {
+	return "business_status_transitions"
+}
+
+// 状态变更原因代码
+const (
+	StatusReasonMerchantRequest = "merchant_request" // 商家申请
+	StatusReasonPolicyViolation = "policy_violation" // 违反平台规则
+	StatusReasonComplaint       = "complaint"        // 用户投诉
+	StatusReasonPaymentIssue    = "payment_issue"    // 结算/付款问题
+	StatusReasonDocumentExpired = "document_expired" // 资质证件过期
+	StatusReasonReviewApproved  = "review_approved"  // 入驻审核通过
+	StatusReasonReviewRejected  = "review_rejected"  // 入驻审核驳回
+	StatusReasonScheduled       = "scheduled"        // 计划流转自动执行
+	StatusReasonOther           = "other"            // 其他
+)
+
+// StatusReasonCodes 全部状态变更原因代码
+var StatusReasonCodes = []string{
+	StatusReasonMerchantRequest,
+	StatusReasonPolicyViolation,
+	StatusReasonComplaint,
+	StatusReasonPaymentIssue,
+	StatusReasonDocumentExpired,
+	StatusReasonReviewApproved,
+	StatusReasonReviewRejected,
+	StatusReasonScheduled,
+	StatusReasonOther,
+}
+
+// IsValidStatusReason 检查原因代码是否有效
+func IsValidStatusReason(code string) bool {
+	for _, c := range StatusReasonCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange 状态变更说明：操作人、原因代码与备注
+type StatusChange struct {
+	ActorID    uint   // 操作人ID（0 表示系统）
+	ReasonCode string // 原因代码
+	Note       string // 备注
+	ScheduleID *uint  // 触发的计划流转ID
+	Version    int    // 期望的商家版本号（If-Match），0 表示不校验
+}
+
+// 计划流转执行状态
+const (
+	ScheduleStatusPending   = "pending"   // 待执行
+	ScheduleStatusRunning   = "running"   // 执行中
+	ScheduleStatusDone      = "done"      // 已执行
+	ScheduleStatusSkipped   = "skipped"   // 商家状态已变化，跳过
+	ScheduleStatusFailed    = "failed"    // 执行失败
+	ScheduleStatusCancelled = "cancelled" // 已取消
+)
+
+// BusinessScheduledTransition 商家计划状态流转，由后台任务到期执行
+type BusinessScheduledTransition struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID int        `gorm:"not null;index" json:"businessId"`                                  // 商家ID
+	FromStatus string     `gorm:"type:varchar(50);not null" json:"fromStatus"`                       // 执行时商家应处的状态
+	ToStatus   string     `gorm:"type:varchar(50);not null" json:"toStatus"`                         // 目标状态
+	ExecuteAt  time.Time  `gorm:"not null;index:idx_scheduled_due,priority:2" json:"executeAt"`      // 计划执行时间
+	ReasonCode string     `gorm:"type:varchar(50)" json:"reasonCode"`                                // 原因代码
+	Note       string     `gorm:"type:text" json:"note"`                                             // 备注
+	ActorID    uint       `json:"actorId"`                                                           // 创建人ID
+	Status     string     `gorm:"type:varchar(20);index:idx_scheduled_due,priority:1" json:"status"` // 执行状态
+	ExecutedAt *time.Time `json:"executedAt"`                                                        // 实际执行时间（可空）
+	Error      string     `gorm:"type:varchar(500)" json:"error"`                                    // 失败原因
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"createdAt"`                                   // 创建时间
+}
+
+// TableName 指定表名
+func (BusinessScheduledTransition) TableName() string {
+	return "business_scheduled_transitions"
+}
+
+// BusinessStatusRequest 商家状态流转请求
+type BusinessStatusRequest struct {
+	Status     string     `json:"status" binding:"required"` // 目标状态
+	ReasonCode string     `json:"reasonCode"`                // 原因代码
+	Note       string     `json:"note"`                      // 备注/原因
+	Until      *time.Time `json:"until"`                     // 到期后自动恢复原状态（可空）
+}
+
+// ScheduleTransitionRequest 计划状态流转请求
+type ScheduleTransitionRequest struct {
+	Status     string    `json:"status" binding:"required"`    // 目标状态
+	ExecuteAt  time.Time `json:"executeAt" binding:"required"` // 计划执行时间
+	ReasonCode string    `json:"reasonCode"`                   // 原因代码
+	Note       string    `json:"note"`                         // 备注
+}
+
+// AssignReviewerRequest 分配审核人请求
+type AssignReviewerRequest struct {
+	ReviewerID uint `json:"reviewerId" binding:"required"` // 审核人ID
+}
+
+// ReviewDecisionRequest 审核结论请求
+type ReviewDecisionRequest struct {
+	Note string `json:"note"` // 审核意见，驳回时必填
+}
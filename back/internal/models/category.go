@@ -0,0 +1,116 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// MaxCategorySlugLength 分类标识最大长度
+const MaxCategorySlugLength = 100
+
+// Category 商家分类，支持多级层次结构及多语言名称
+type Category struct {
+	ID              uint              `gorm:"primaryKey;autoIncrement" json:"id"`
+	ParentID        *uint             `gorm:"index" json:"parentId"`                                               // 上级分类ID（顶级分类为空）
+	Slug            string            `gorm:"type:varchar(100);not null;uniqueIndex:uk_category_slug" json:"slug"` // 分类标识（唯一，用作商家 type 字段的值）
+	Name            string            `gorm:"type:varchar(100);not null" json:"name"`                              // 默认名称
+	Names           map[string]string `gorm:"type:text;serializer:json" json:"names"`                              // 各语言名称（语言代码 → 名称）
+	Icon            string            `gorm:"type:varchar(255)" json:"icon"`                                       // 图标（URL 或图标名称）
+	SortOrder       int               `gorm:"not null;default:0" json:"sortOrder"`                                 // 排序（升序）
+	AttributeSchema json.RawMessage   `gorm:"type:json" json:"attributeSchema,omitempty"`                          // 商家自定义属性定义（JSON Schema 子集，见 common.AttributeSchema）
+	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"createdAt"`                                     // 创建时间
+	UpdatedAt       time.Time         `gorm:"autoUpdateTime" json:"updatedAt"`                                     // 更新时间
+	Children        []*Category       `gorm:"-" json:"children,omitempty"`                                         // 子分类（树形查询时返回）
+	DisplayName     string            `gorm:"-" json:"displayName,omitempty"`                                      // 按请求语言显示的名称
+	BusinessCount   *int64            `gorm:"-" json:"businessCount,omitempty"`                                    // 直接关联的商家数量（树形查询时返回）
+}
+
+// TableName 指定表名
+func (Category) TableName() string {
+	return "categories"
+}
+
+// BusinessCategory 商家与分类的关联，Position 为 0 的是主分类
+type BusinessCategory struct {
+	BusinessID int  `gorm:"primaryKey" json:"businessId"`       // 商家ID
+	CategoryID uint `gorm:"primaryKey;index" json:"categoryId"` // 分类ID
+	Position   int  `gorm:"not null;default:0" json:"position"` // 顺序（0 为主分类）
+}
+
+// TableName 指定表名
+func (BusinessCategory) TableName() string {
+	return "business_categories"
+}
+
+// CategoryRequest 创建/更新分类请求
+type CategoryRequest struct {
+	ParentID        *uint             `json:"parentId"`        // 上级分类ID（为空表示顶级分类）
+	Slug            string            `json:"slug"`            // 分类标识，为空时由名称生成
+	Name            string            `json:"name"`            // 默认名称
+	Names           map[string]string `json:"names"`           // 各语言名称
+	Icon            string            `json:"icon"`            // 图标
+	SortOrder       int               `json:"sortOrder"`       // 排序
+	AttributeSchema json.RawMessage   `json:"attributeSchema"` // 自定义属性定义（为空表示无自定义属性）
+}
+
+// CategoryMergeRequest 合并分类请求
+type CategoryMergeRequest struct {
+	TargetID uint `json:"targetId" binding:"required"` // 合并到的分类ID
+}
+
+// LocalizedName 返回指定语言的名称，依次尝试完整语言代码（如 ja-JP）、主语言（ja），均无则返回默认名称
+func (c *Category) LocalizedName(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" {
+		return c.Name
+	}
+	if name := c.Names[locale]; name != "" {
+		return name
+	}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		if name := c.Names[locale[:i]]; name != "" {
+			return name
+		}
+	}
+	return c.Name
+}
+
+// Matches 检查文本是否为该分类的标识或任一语言的名称（不区分大小写）
+func (c *Category) Matches(label string) bool {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return false
+	}
+	if strings.EqualFold(label, c.Slug) || strings.EqualFold(label, c.Name) {
+		return true
+	}
+	for _, name := range c.Names {
+		if strings.EqualFold(label, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Slugify 由文本生成分类标识：保留 ASCII 字母与数字，其余字符转换为连字符
+func Slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	slug := b.String()
+	if len(slug) > MaxCategorySlugLength {
+		slug = strings.TrimRight(slug[:MaxCategorySlugLength], "-")
+	}
+	return slug
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// MediaTypeExtensions 允许上传的媒体类型（按文件内容识别）及保存时使用的扩展名
+var MediaTypeExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// 媒体文件处理状态：上传后异步生成缩略图
+const (
+	MediaStatusPending    = "pending"    // 等待处理
+	MediaStatusProcessing = "processing" // 处理中
+	MediaStatusReady      = "ready"      // 已处理（无法解码的格式如 WebP 原图不生成缩略图，直接使用原图）
+	MediaStatusFailed     = "failed"     // 多次处理失败
+)
+
+// MediaVariantSize 缩略图规格
+type MediaVariantSize struct {
+	Name    string // 规格名称，用于 size 参数
+	MaxSide int    // 最长边（像素），原图更小时不放大
+}
+
+// MediaVariantSizes 生成的缩略图规格
+var MediaVariantSizes = []MediaVariantSize{
+	{Name: "sm", MaxSide: 160},
+	{Name: "md", MaxSide: 480},
+	{Name: "lg", MaxSide: 1200},
+}
+
+// MediaThumbnailSize 列表中使用的缩略图规格
+const MediaThumbnailSize = "sm"
+
+// IsMediaVariantSize 是否为有效的缩略图规格
+func IsMediaVariantSize(name string) bool {
+	for _, size := range MediaVariantSizes {
+		if size.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Media 上传的媒体文件，内容保存在媒体存储中（本地文件系统或 S3 兼容对象存储），写入后不再修改
+type Media struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	StorageKey  string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_media_storage_key" json:"-"` // 存储中的对象键
+	Filename    string    `gorm:"type:varchar(255)" json:"filename"`                                    // 原始文件名
+	ContentType string    `gorm:"type:varchar(100);not null" json:"contentType"`                        // 按文件内容识别的类型
+	Size        int64     `gorm:"not null" json:"size"`                                                 // 字节数
+	Width       int       `gorm:"not null;default:0" json:"width"`                                      // 图片宽度（像素，无法识别时为 0）
+	Height      int       `gorm:"not null;default:0" json:"height"`                                     // 图片高度（像素，无法识别时为 0）
+	Checksum    string    `gorm:"type:char(64);not null;index" json:"checksum"`                         // 内容的 SHA-256（十六进制）
+	UploadedBy  *uint     `gorm:"index" json:"uploadedBy"`                                              // 上传用户ID（数据迁移导入的为空）
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`                                      // 上传时间
+	URL         string    `gorm:"-" json:"url"`                                                         // 访问地址
+
+	// 缩略图处理
+	Status        string          `gorm:"type:varchar(20);not null;default:pending;index" json:"status"` // 处理状态
+	DominantColor string          `gorm:"type:varchar(7);not null;default:''" json:"dominantColor"`      // 主色调（#rrggbb），用作加载前的占位色
+	Attempts      int             `gorm:"not null;default:0" json:"-"`                                   // 已尝试处理次数
+	ProcessingAt  *time.Time      `json:"-"`                                                             // 最近一次开始处理的时间
+	Variants      []*MediaVariant `gorm:"-" json:"variants,omitempty"`                                   // 已生成的缩略图
+}
+
+// TableName 指定表名
+func (Media) TableName() string {
+	return "media"
+}
+
+// MediaContentURL 媒体内容的访问地址，由 API 转发内容或重定向到存储的公开地址
+func MediaContentURL(id uint) string {
+	return "/api/v1/media/" + strconv.FormatUint(uint64(id), 10) + "/content"
+}
+
+// MediaVariantURL 缩略图的访问地址，缩略图尚未生成时返回原图
+func MediaVariantURL(id uint, size string) string {
+	return MediaContentURL(id) + "?size=" + size
+}
+
+// MediaVariant 媒体文件的缩略图，由原图按规格缩小并去除元数据后重新编码
+type MediaVariant struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	MediaID     uint      `gorm:"not null;uniqueIndex:uk_media_variant" json:"-"`                               // 媒体文件ID
+	Name        string    `gorm:"type:varchar(20);not null;uniqueIndex:uk_media_variant" json:"size"`           // 规格名称
+	ContentType string    `gorm:"type:varchar(100);not null;uniqueIndex:uk_media_variant" json:"contentType"`   // 编码类型（JPEG/PNG，配置了 WebP 编码时另有 WebP）
+	StorageKey  string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_media_variant_storage_key" json:"-"` // 存储中的对象键
+	Size        int64     `gorm:"not null" json:"bytes"`                                                        // 字节数
+	Width       int       `gorm:"not null" json:"width"`                                                        // 宽度（像素）
+	Height      int       `gorm:"not null" json:"height"`                                                       // 高度（像素）
+	Checksum    string    `gorm:"type:char(64);not null" json:"checksum"`                                       // 内容的 SHA-256（十六进制）
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`                                              // 生成时间
+	URL         string    `gorm:"-" json:"url"`                                                                 // 访问地址
+}
+
+// TableName 指定表名
+func (MediaVariant) TableName() string {
+	return "media_variants"
+}
@@ -0,0 +1,206 @@
+package model
+
+import "time"
+
+// 评价审核状态
+const (
+	ReviewStatusPending  = "pending"  // 待审核（被自动规则标记），不公开显示，不计入评分
+	ReviewStatusApproved = "approved" // 已公开，计入评分
+	ReviewStatusRejected = "rejected" // 已驳回，不公开显示，不计入评分；作者修改后重新审核
+	ReviewStatusHidden   = "hidden"   // 已隐藏，不公开显示，不计入评分；作者修改后仍保持隐藏
+)
+
+// 评价审核操作
+const (
+	ReviewActionFlag    = "flag"    // 自动规则标记，进入审核队列
+	ReviewActionApprove = "approve" // 审核通过
+	ReviewActionReject  = "reject"  // 驳回
+	ReviewActionHide    = "hide"    // 隐藏
+)
+
+// 评价审核原因代码
+const (
+	ReviewReasonSpam      = "spam"      // 垃圾广告、刷评
+	ReviewReasonFake      = "fake"      // 虚假评价、恶意评分
+	ReviewReasonOffensive = "offensive" // 辱骂、不雅内容
+	ReviewReasonOffTopic  = "off_topic" // 与商家无关
+	ReviewReasonPrivacy   = "privacy"   // 泄露个人信息
+	ReviewReasonVerified  = "verified"  // 核实无误（审核通过）
+	ReviewReasonOther     = "other"     // 其他（须填写备注）
+)
+
+// ReviewReasonCodes 全部评价审核原因代码
+var ReviewReasonCodes = []string{
+	ReviewReasonSpam,
+	ReviewReasonFake,
+	ReviewReasonOffensive,
+	ReviewReasonOffTopic,
+	ReviewReasonPrivacy,
+	ReviewReasonVerified,
+	ReviewReasonOther,
+}
+
+// IsValidReviewReason 检查评价审核原因代码是否有效
+func IsValidReviewReason(code string) bool {
+	for _, c := range ReviewReasonCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// 自动审核规则标记代码
+const (
+	ReviewFlagDuplicateText = "duplicate_text" // 与其他评价内容重复
+	ReviewFlagUserBurst     = "user_burst"     // 同一用户短时间内发表大量评价
+	ReviewFlagIPBurst       = "ip_burst"       // 同一 IP 短时间内发表大量评价
+	ReviewFlagProfanity     = "profanity"      // 包含不雅词汇
+	ReviewFlagRatingOutlier = "rating_outlier" // 星级明显偏离商家的历史评分
+	ReviewFlagRatingSpike   = "rating_spike"   // 商家短时间内评价数激增
+)
+
+// ReviewFlag 自动审核规则的标记
+type ReviewFlag struct {
+	Code   string `json:"code"`   // 标记代码（见 ReviewFlag 常量）
+	Detail string `json:"detail"` // 说明
+}
+
+// 评价限制
+const (
+	MaxReviewContentLength = 2000 // 评价内容最大长度（字符）
+	MaxReviewPhotos        = 9    // 每条评价最多的照片数
+	MaxReviewReplyLength   = 1000 // 商家回复最大长度（字符）
+)
+
+// Review 顾客对商家的评价，每个用户对每个商家只能有一条评价（可修改）
+type Review struct {
+	ID               uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID       int          `gorm:"not null;uniqueIndex:uk_review_business_user,priority:1" json:"businessId"`   // 商家ID
+	UserID           uint         `gorm:"not null;uniqueIndex:uk_review_business_user,priority:2;index" json:"userId"` // 评价人ID
+	Stars            int          `gorm:"not null" json:"stars"`                                                       // 星级（1-5）
+	Content          string       `gorm:"type:text" json:"content"`                                                    // 评价内容
+	PhotoMediaIDs    []uint       `gorm:"type:json;serializer:json" json:"photoMediaIds"`                              // 照片（媒体文件ID）
+	Status           string       `gorm:"type:varchar(20);not null;default:approved;index" json:"status"`              // 审核状态
+	ModerationReason string       `gorm:"type:varchar(30)" json:"moderationReason,omitempty"`                          // 最近一次审核的原因代码
+	ModerationNote   *string      `gorm:"type:text" json:"moderationNote"`                                             // 审核意见（驳回原因等）
+	ModeratedBy      *uint        `json:"moderatedBy"`                                                                 // 审核人ID
+	ModeratedAt      *time.Time   `json:"moderatedAt"`                                                                 // 审核时间
+	Reply            *string      `gorm:"type:text" json:"reply"`                                                      // 商家回复
+	RepliedBy        *uint        `json:"repliedBy"`                                                                   // 回复人ID
+	RepliedAt        *time.Time   `json:"repliedAt"`                                                                   // 回复时间
+	ClientIP         string       `gorm:"type:varchar(45);not null;default:'';index" json:"-"`                         // 提交评价的客户端 IP
+	ContentHash      string       `gorm:"type:varchar(64);not null;default:'';index" json:"-"`                         // 规范化内容的摘要，用于识别重复内容（内容过短时为空）
+	Flags            []ReviewFlag `gorm:"type:json;serializer:json" json:"flags,omitempty"`                            // 自动审核规则的标记（仅管理员可见）
+	CreatedAt        time.Time    `gorm:"autoCreateTime" json:"createdAt"`                                             // 创建时间
+	UpdatedAt        time.Time    `gorm:"autoUpdateTime" json:"updatedAt"`                                             // 更新时间
+
+	Photos []ReviewPhoto `gorm:"-" json:"photos"` // 照片地址（计算字段）
+}
+
+// TableName 指定表名
+func (Review) TableName() string {
+	return "reviews"
+}
+
+// Counted 评价是否计入商家评分，待审核、驳回与隐藏的评价均不计入
+func (r *Review) Counted() bool {
+	return r.Status == ReviewStatusApproved
+}
+
+// HasFlag 评价是否带有指定的自动审核标记
+func (r *Review) HasFlag(code string) bool {
+	for _, flag := range r.Flags {
+		if flag.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyPhotoURLs 根据照片媒体文件ID设置照片地址
+func (r *Review) ApplyPhotoURLs() {
+	r.Photos = make([]ReviewPhoto, len(r.PhotoMediaIDs))
+	for i, id := range r.PhotoMediaIDs {
+		r.Photos[i] = ReviewPhoto{MediaID: id, URL: MediaContentURL(id), ThumbnailURL: MediaVariantURL(id, MediaThumbnailSize)}
+	}
+}
+
+// ReviewPhoto 评价照片地址
+type ReviewPhoto struct {
+	MediaID      uint   `json:"mediaId"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// ReviewRequest 发表或修改评价请求，照片需先通过媒体文件接口上传
+type ReviewRequest struct {
+	Stars         int    `json:"stars" binding:"required"` // 星级（1-5）
+	Content       string `json:"content"`                  // 评价内容
+	PhotoMediaIDs []uint `json:"photoMediaIds"`            // 照片（媒体文件ID）
+}
+
+// ReviewModerationRequest 评价审核请求
+type ReviewModerationRequest struct {
+	Reason string `json:"reason"` // 原因代码（见 ReviewReasonCodes），驳回与隐藏时必填
+	Note   string `json:"note"`   // 备注，原因为 other 时必填
+}
+
+// ReviewModerationLog 评价审核记录：自动规则的标记与审核人的操作
+type ReviewModerationLog struct {
+	ID         uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReviewID   uint         `gorm:"not null;index" json:"reviewId"`                   // 评价ID
+	Action     string       `gorm:"type:varchar(20);not null" json:"action"`          // 操作（见 ReviewAction 常量）
+	FromStatus string       `gorm:"type:varchar(20)" json:"fromStatus"`               // 原状态
+	ToStatus   string       `gorm:"type:varchar(20);not null" json:"toStatus"`        // 新状态
+	ActorID    uint         `gorm:"index" json:"actorId"`                             // 操作人ID（0 表示自动规则）
+	Reason     string       `gorm:"type:varchar(30)" json:"reason"`                   // 原因代码
+	Note       string       `gorm:"type:text" json:"note"`                            // 备注
+	Flags      []ReviewFlag `gorm:"type:json;serializer:json" json:"flags,omitempty"` // 自动规则的标记
+	CreatedAt  time.Time    `gorm:"autoCreateTime" json:"createdAt"`                  // 操作时间
+}
+
+// TableName 指定表名
+func (ReviewModerationLog) TableName() string {
+	return "review_moderation_logs"
+}
+
+// ReviewReplyRequest 商家回复请求
+type ReviewReplyRequest struct {
+	Content string `json:"content" binding:"required"` // 回复内容
+}
+
+// BusinessRating 商家评分汇总，随评价的发表、修改、审核与删除增量维护，只统计已公开的评价
+type BusinessRating struct {
+	BusinessID  int       `gorm:"primaryKey;autoIncrement:false" json:"businessId"`
+	ReviewCount int       `gorm:"not null;default:0" json:"count"` // 评价数
+	StarsSum    int       `gorm:"not null;default:0" json:"-"`     // 星级合计
+	Star1       int       `gorm:"not null;default:0" json:"-"`     // 各星级评价数
+	Star2       int       `gorm:"not null;default:0" json:"-"`
+	Star3       int       `gorm:"not null;default:0" json:"-"`
+	Star4       int       `gorm:"not null;default:0" json:"-"`
+	Star5       int       `gorm:"not null;default:0" json:"-"`
+	Average     float64   `gorm:"not null;default:0" json:"average"`  // 算术平均
+	Bayesian    float64   `gorm:"not null;default:0" json:"bayesian"` // 贝叶斯平均
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+
+	Rating       float64     `gorm:"-" json:"rating"`       // 商家评分（按配置取算术平均或贝叶斯平均，即 Business.Rating）
+	Distribution map[int]int `gorm:"-" json:"distribution"` // 星级分布（星级 → 评价数）
+}
+
+// TableName 指定表名
+func (BusinessRating) TableName() string {
+	return "business_ratings"
+}
+
+// ApplyDistribution 根据各星级计数设置星级分布
+func (r *BusinessRating) ApplyDistribution() {
+	r.Distribution = map[int]int{1: r.Star1, 2: r.Star2, 3: r.Star3, 4: r.Star4, 5: r.Star5}
+}
+
+// RatingSettings 商家评分的计算方式
+type RatingSettings struct {
+	Bayesian   bool    // 使用贝叶斯平均：(先验均值×先验权重 + 星级合计) / (先验权重 + 评价数)
+	PriorMean  float64 // 先验均值
+	PriorCount float64 // 先验权重
+}
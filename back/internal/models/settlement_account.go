@@ -0,0 +1,105 @@
+package model
+
+import "time"
+
+// 结算账户变更申请状态
+const (
+	SettlementChangeStatusPending   = "pending"   // 待审批
+	SettlementChangeStatusApproved  = "approved"  // 已通过，账户已更新
+	SettlementChangeStatusRejected  = "rejected"  // 已驳回
+	SettlementChangeStatusCancelled = "cancelled" // 申请人已撤回
+)
+
+// MaskAccountNumber 脱敏显示账号，只保留后四位
+func MaskAccountNumber(last4 string) string {
+	if last4 == "" {
+		return ""
+	}
+	return "**** **** " + last4
+}
+
+// SettlementAccount 商家当前生效的结算（收款）银行账户，每个商家一个。
+// 账号加密保存，任何接口只返回脱敏后的账号；账户只能通过经第二名管理员审批的变更申请修改
+type SettlementAccount struct {
+	ID                  uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID          int       `gorm:"not null;uniqueIndex:uk_settlement_accounts_business_id" json:"businessId"` // 商家ID
+	AccountName         string    `gorm:"type:varchar(100);not null" json:"accountName"`                             // 户名
+	BankName            string    `gorm:"type:varchar(100);not null" json:"bankName"`                                // 开户银行
+	BranchName          string    `gorm:"type:varchar(255);not null;default:''" json:"branchName"`                   // 开户支行
+	AccountNumberCipher string    `gorm:"type:varchar(255);not null" json:"-"`                                       // 加密后的账号
+	AccountLast4        string    `gorm:"type:varchar(4);not null" json:"-"`                                         // 账号后四位
+	ChangeID            uint      `gorm:"not null" json:"changeId"`                                                  // 生效的变更申请ID
+	ApprovedBy          uint      `gorm:"not null" json:"approvedBy"`                                                // 审批人ID
+	ApprovedAt          time.Time `gorm:"type:datetime;not null" json:"approvedAt"`                                  // 审批时间
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"createdAt"`                                           // 创建时间
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updatedAt"`                                           // 更新时间
+
+	AccountNumberMasked string `gorm:"-" json:"accountNumberMasked"` // 脱敏账号（计算字段）
+}
+
+// TableName 指定表名
+func (SettlementAccount) TableName() string {
+	return "settlement_accounts"
+}
+
+// ApplyMask 设置脱敏账号
+func (a *SettlementAccount) ApplyMask() {
+	a.AccountNumberMasked = MaskAccountNumber(a.AccountLast4)
+}
+
+// SettlementAccountChange 结算账户变更申请，同时作为账户的变更历史。
+// 同一商家同时只能有一个待审批的申请；审批人必须是申请人以外的管理员，通过时记录变更前的账户信息
+type SettlementAccountChange struct {
+	ID                  uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BusinessID          int        `gorm:"not null;index:idx_settlement_changes_business_status,priority:1" json:"businessId"`                              // 商家ID
+	AccountName         string     `gorm:"type:varchar(100);not null" json:"accountName"`                                                                   // 户名
+	BankName            string     `gorm:"type:varchar(100);not null" json:"bankName"`                                                                      // 开户银行
+	BranchName          string     `gorm:"type:varchar(255);not null;default:''" json:"branchName"`                                                         // 开户支行
+	AccountNumberCipher string     `gorm:"type:varchar(255);not null" json:"-"`                                                                             // 加密后的账号
+	AccountLast4        string     `gorm:"type:varchar(4);not null" json:"-"`                                                                               // 账号后四位
+	Reason              string     `gorm:"type:text" json:"reason"`                                                                                         // 变更说明
+	Status              string     `gorm:"type:varchar(20);not null;default:pending;index:idx_settlement_changes_business_status,priority:2" json:"status"` // 申请状态
+	RequestedBy         uint       `gorm:"not null" json:"requestedBy"`                                                                                     // 申请人ID
+	ReviewedBy          *uint      `json:"reviewedBy"`                                                                                                      // 审批人ID（撤回时为撤回人）
+	ReviewedAt          *time.Time `gorm:"type:datetime" json:"reviewedAt"`                                                                                 // 处理时间
+	ReviewNote          *string    `gorm:"type:text" json:"reviewNote"`                                                                                     // 驳回原因
+
+	PreviousAccountName string `gorm:"type:varchar(100);not null;default:''" json:"previousAccountName"` // 变更前户名（通过时记录，首次设置为空）
+	PreviousBankName    string `gorm:"type:varchar(100);not null;default:''" json:"previousBankName"`    // 变更前开户银行
+	PreviousLast4       string `gorm:"type:varchar(4);not null;default:''" json:"-"`                     // 变更前账号后四位
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"` // 申请时间
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"` // 更新时间
+
+	AccountNumberMasked         string `gorm:"-" json:"accountNumberMasked"`                   // 脱敏账号（计算字段）
+	PreviousAccountNumberMasked string `gorm:"-" json:"previousAccountNumberMasked,omitempty"` // 变更前的脱敏账号（计算字段）
+}
+
+// TableName 指定表名
+func (SettlementAccountChange) TableName() string {
+	return "settlement_account_changes"
+}
+
+// ApplyMask 设置脱敏账号
+func (c *SettlementAccountChange) ApplyMask() {
+	c.AccountNumberMasked = MaskAccountNumber(c.AccountLast4)
+	c.PreviousAccountNumberMasked = MaskAccountNumber(c.PreviousLast4)
+}
+
+// SettlementAccountChangeRequest 提交结算账户变更申请
+type SettlementAccountChangeRequest struct {
+	AccountName   string `json:"accountName" binding:"required"`   // 户名
+	BankName      string `json:"bankName" binding:"required"`      // 开户银行
+	BranchName    string `json:"branchName"`                       // 开户支行
+	AccountNumber string `json:"accountNumber" binding:"required"` // 银行账号（可含空格或连字符）
+	Reason        string `json:"reason"`                           // 变更说明
+}
+
+// PayoutAccount 解密后的结算账户，仅供内部打款使用，不通过任何接口返回
+type PayoutAccount struct {
+	BusinessID    int
+	AccountName   string
+	BankName      string
+	BranchName    string
+	AccountNumber string
+}
@@ -2,13 +2,15 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // User 用户模型
 type User struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Username  string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"username"` // 用户名（唯一）
-	Email     string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"email"`   // 邮箱（唯一）
+	Username  string    `gorm:"type:varchar(50);not null;uniqueIndex:uk_users_username" json:"username"` // 用户名（未删除用户中唯一）
+	Email     string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_users_email" json:"email"`      // 邮箱（未删除用户中唯一）
 	Password  string    `gorm:"type:varchar(255);not null" json:"-"`                    // 密码（不返回给前端）
 	FirstName string    `gorm:"type:varchar(100)" json:"firstName"`                      // 名
 	LastName  string    `gorm:"type:varchar(100)" json:"lastName"`                       // 姓
@@ -19,6 +21,11 @@ type User struct {
 	LastLogin *time.Time `gorm:"type:datetime" json:"lastLogin"`                        // 最后登录时间
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`                        // 创建时间
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`                        // 更新时间
+
+	Version int `gorm:"not null;default:1" json:"version"` // 数据版本号，每次修改加一，用于乐观并发控制（ETag）
+
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`                                                      // 删除时间（软删除）
+	DeleteMarker uint           `gorm:"not null;default:0;uniqueIndex:uk_users_username;uniqueIndex:uk_users_email" json:"-"` // 删除标记：未删除为0，删除后为自身ID，使唯一索引忽略已删除记录
 }
 
 // TableName 指定表名
@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BrandRepository 品牌仓储接口
+type BrandRepository interface {
+	// Create 创建品牌，并将创建人设为品牌管理员
+	Create(brand *model.Brand) error
+	// Update 保存品牌资料
+	Update(brand *model.Brand) error
+	// Delete 删除品牌及其管理员，回收站中仍指向该品牌的门店改为独立商家
+	Delete(id uint) error
+	GetByID(id uint) (*model.Brand, error)
+	// GetByIDs 根据ID批量获取品牌（顺序不保证与 ids 一致）
+	GetByIDs(ids []uint) ([]*model.Brand, error)
+	GetByName(name string) (*model.Brand, error)
+	// List 按统一列表查询条件筛选、排序，支持偏移分页与游标分页（默认按名称）
+	List(q *common.ListQuery) ([]*model.Brand, *common.ListPageInfo, error)
+
+	// CountStores 统计各品牌的门店数量（不含回收站中的门店）
+	CountStores(ids []uint) (map[uint]int64, error)
+	// GetStats 汇总品牌全部门店的状态、城市分布与评价
+	GetStats(id uint) (*model.BrandStats, error)
+
+	// 品牌管理员
+	GetAdmins(brandID uint) ([]*model.BrandAdmin, error)
+	IsAdmin(brandID, userID uint) (bool, error)
+	AddAdmin(admin *model.BrandAdmin) error
+	RemoveAdmin(brandID, userID uint) error
+}
+
+// brandRepository 品牌仓储实现
+type brandRepository struct {
+	db *gorm.DB
+}
+
+// NewBrandRepository 创建品牌仓储实例
+func NewBrandRepository(db *gorm.DB) BrandRepository {
+	return &brandRepository{
+		db: db,
+	}
+}
+
+// Create 创建品牌
+func (r *brandRepository) Create(brand *model.Brand) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(brand).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.BrandAdmin{
+			BrandID:   brand.ID,
+			UserID:    brand.CreatedBy,
+			CreatedBy: brand.CreatedBy,
+		}).Error
+	})
+}
+
+// Update 保存品牌资料
+func (r *brandRepository) Update(brand *model.Brand) error {
+	return r.db.Model(brand).
+		Select("name", "email", "contact", "description", "logo_media_id", "category_id").
+		Updates(brand).Error
+}
+
+// Delete 删除品牌
+func (r *brandRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Business{}).
+			Where("brand_id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]interface{}{
+				"brand_id": nil,
+				"version":  gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("brand_id = ?", id).Delete(&model.BrandAdmin{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Brand{}, id).Error
+	})
+}
+
+// GetByID 根据ID获取品牌
+func (r *brandRepository) GetByID(id uint) (*model.Brand, error) {
+	var brand model.Brand
+	if err := r.db.First(&brand, id).Error; err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// GetByIDs 根据ID批量获取品牌
+func (r *brandRepository) GetByIDs(ids []uint) ([]*model.Brand, error) {
+	var brands []*model.Brand
+	if len(ids) == 0 {
+		return brands, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&brands).Error
+	return brands, err
+}
+
+// GetByName 根据名称获取品牌
+func (r *brandRepository) GetByName(name string) (*model.Brand, error) {
+	var brand model.Brand
+	if err := r.db.Where("name = ?", name).First(&brand).Error; err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// List 获取品牌列表
+func (r *brandRepository) List(q *common.ListQuery) ([]*model.Brand, *common.ListPageInfo, error) {
+	if len(q.Sorts) == 0 {
+		sorted := *q
+		sorted.Sorts = []common.ListSort{{Field: "name"}}
+		q = &sorted
+	}
+
+	query, err := ApplyListFilters(r.db.Model(&model.Brand{}), q, BrandListFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &common.ListPageInfo{}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var brands []*model.Brand
+	if q.CursorMode {
+		if query, err = ApplyListCursor(query, q, BrandListFields, "brands"); err != nil {
+			return nil, nil, err
+		}
+		if err := query.Find(&brands).Error; err != nil {
+			return nil, nil, err
+		}
+		brands, page.NextCursor, page.PrevCursor, err = ListCursorPage(brands, q, BrandListFields, "brands")
+		return brands, page, err
+	}
+
+	if query, err = ApplyListSort(query, q, BrandListFields); err != nil {
+		return nil, nil, err
+	}
+	err = ApplyListPage(query, q).Find(&brands).Error
+	return brands, page, err
+}
+
+// CountStores 统计各品牌的门店数量
+func (r *brandRepository) CountStores(ids []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		BrandID uint
+		Count   int64
+	}
+	if err := r.db.Model(&model.Business{}).
+		Select("brand_id, COUNT(*) AS count").
+		Where("brand_id IN ?", ids).
+		Group("brand_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.BrandID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetStats 汇总品牌统计，品牌评分按全部门店已公开评价的星级合计计算（而非门店评分的平均），评价多的门店权重更大
+func (r *brandRepository) GetStats(id uint) (*model.BrandStats, error) {
+	stats := &model.BrandStats{
+		BrandID:      id,
+		StatusCounts: map[string]int64{},
+		Distribution: map[int]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+	}
+	stores := func() *gorm.DB {
+		return r.db.Model(&model.Business{}).Where("brand_id = ?", id)
+	}
+
+	var statuses []struct {
+		Status string
+		Count  int64
+	}
+	if err := stores().Select("status, COUNT(*) AS count").Group("status").Scan(&statuses).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range statuses {
+		stats.StatusCounts[row.Status] = row.Count
+		stats.StoreCount += row.Count
+	}
+
+	if err := stores().Select("city AS region, COUNT(*) AS count").
+		Group("city").
+		Order("count DESC").
+		Scan(&stats.Cities).Error; err != nil {
+		return nil, err
+	}
+
+	var ratings struct {
+		ReviewCount int64
+		StarsSum    int64
+		Star1       int64
+		Star2       int64
+		Star3       int64
+		Star4       int64
+		Star5       int64
+	}
+	if err := r.db.Table("business_ratings AS r").
+		Select("COALESCE(SUM(r.review_count), 0) AS review_count, COALESCE(SUM(r.stars_sum), 0) AS stars_sum, "+
+			"COALESCE(SUM(r.star1), 0) AS star1, COALESCE(SUM(r.star2), 0) AS star2, COALESCE(SUM(r.star3), 0) AS star3, "+
+			"COALESCE(SUM(r.star4), 0) AS star4, COALESCE(SUM(r.star5), 0) AS star5").
+		Joins("JOIN business b ON b.id = r.business_id").
+		Where("b.brand_id = ? AND b.deleted_at IS NULL", id).
+		Scan(&ratings).Error; err != nil {
+		return nil, err
+	}
+	stats.ReviewCount = ratings.ReviewCount
+	if ratings.ReviewCount > 0 {
+		stats.Rating = float64(ratings.StarsSum) / float64(ratings.ReviewCount)
+	}
+	stats.Distribution[1] = ratings.Star1
+	stats.Distribution[2] = ratings.Star2
+	stats.Distribution[3] = ratings.Star3
+	stats.Distribution[4] = ratings.Star4
+	stats.Distribution[5] = ratings.Star5
+	return stats, nil
+}
+
+// GetAdmins 获取品牌管理员（按添加时间）
+func (r *brandRepository) GetAdmins(brandID uint) ([]*model.BrandAdmin, error) {
+	var admins []*model.BrandAdmin
+	err := r.db.Where("brand_id = ?", brandID).Order("created_at ASC, user_id ASC").Find(&admins).Error
+	return admins, err
+}
+
+// IsAdmin 用户是否为品牌管理员
+func (r *brandRepository) IsAdmin(brandID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.BrandAdmin{}).Where("brand_id = ? AND user_id = ?", brandID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// AddAdmin 添加品牌管理员
+func (r *brandRepository) AddAdmin(admin *model.BrandAdmin) error {
+	return r.db.Create(admin).Error
+}
+
+// RemoveAdmin 移除品牌管理员
+func (r *brandRepository) RemoveAdmin(brandID, userID uint) error {
+	result := r.db.Where("brand_id = ? AND user_id = ?", brandID, userID).Delete(&model.BrandAdmin{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// maxIndexedStringLength 属性索引中文本值的最大长度（字符），超出部分截断，仅影响筛选与排序
+const maxIndexedStringLength = 255
+
+// BusinessAttributeRepository 商家标签与自定义属性索引仓储接口
+type BusinessAttributeRepository interface {
+	LoadTags(businesses []*model.Business) error
+	// SetTags 替换商家标签
+	SetTags(businessID int, tags []string) error
+	// TagCounts 按使用的商家数倒序返回标签，prefix 不为空时只返回以其开头的标签
+	TagCounts(prefix string, limit int) ([]*model.TagCount, error)
+	// IndexAttributes 重建商家自定义属性的查询索引
+	IndexAttributes(businessID int, attributes map[string]interface{}) error
+}
+
+// businessAttributeRepository 商家标签与自定义属性索引仓储实现
+type businessAttributeRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessAttributeRepository 创建商家标签与自定义属性索引仓储实例
+func NewBusinessAttributeRepository(db *gorm.DB) BusinessAttributeRepository {
+	return &businessAttributeRepository{
+		db: db,
+	}
+}
+
+// LoadTags 批量加载商家标签（按标签排序）
+func (r *businessAttributeRepository) LoadTags(businesses []*model.Business) error {
+	if len(businesses) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(businesses))
+	byID := make(map[int]*model.Business, len(businesses))
+	for _, business := range businesses {
+		ids = append(ids, business.ID)
+		byID[business.ID] = business
+		business.Tags = nil
+	}
+
+	var tags []model.BusinessTag
+	if err := r.db.Where("business_id IN ?", ids).Order("business_id, tag").Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		business := byID[tag.BusinessID]
+		business.Tags = append(business.Tags, tag.Tag)
+	}
+	return nil
+}
+
+// SetTags 替换商家标签
+func (r *businessAttributeRepository) SetTags(businessID int, tags []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("business_id = ?", businessID).Delete(&model.BusinessTag{}).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+
+		rows := make([]model.BusinessTag, len(tags))
+		for i, tag := range tags {
+			rows[i] = model.BusinessTag{BusinessID: businessID, Tag: tag}
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// TagCounts 统计未删除商家的标签使用次数
+func (r *businessAttributeRepository) TagCounts(prefix string, limit int) ([]*model.TagCount, error) {
+	query := r.db.Model(&model.BusinessTag{}).
+		Select("business_tags.tag, COUNT(*) AS count").
+		Joins("JOIN business ON business.id = business_tags.business_id AND business.deleted_at IS NULL")
+	if prefix != "" {
+		query = query.Where("business_tags.tag LIKE ?", likeEscaper.Replace(prefix)+"%")
+	}
+
+	var counts []*model.TagCount
+	err := query.Group("business_tags.tag").
+		Order("count DESC, business_tags.tag ASC").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// IndexAttributes 删除商家原有的属性索引后按属性值重新写入，数组属性的每个元素各占一行
+func (r *businessAttributeRepository) IndexAttributes(businessID int, attributes map[string]interface{}) error {
+	var rows []model.BusinessAttributeValue
+	for name, value := range attributes {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			if row, ok := attributeIndexRow(businessID, name, v); ok {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("business_id = ?", businessID).Delete(&model.BusinessAttributeValue{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 100).Error
+	})
+}
+
+// attributeIndexRow 将单个属性值转换为索引行：数值写入 number_value，文本与布尔值写入 string_value
+func attributeIndexRow(businessID int, name string, value interface{}) (model.BusinessAttributeValue, bool) {
+	row := model.BusinessAttributeValue{BusinessID: businessID, Name: name}
+	switch v := value.(type) {
+	case float64:
+		row.NumberValue = &v
+	case string:
+		if utf8.RuneCountInString(v) > maxIndexedStringLength {
+			v = string([]rune(v)[:maxIndexedStringLength])
+		}
+		row.StringValue = &v
+	case bool:
+		s := strconv.FormatBool(v)
+		row.StringValue = &s
+	default:
+		return row, false
+	}
+	return row, true
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"errors"
+
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrContractOverlap 合同有效期与该商家的其他合同重叠
+var ErrContractOverlap = errors.New("合同有效期与已有合同重叠")
+
+// BusinessContractRepository 商家合同仓储接口，日期参数均为 YYYY-MM-DD
+type BusinessContractRepository interface {
+	// Create 创建合同，有效期与该商家的其他合同重叠时返回 ErrContractOverlap
+	Create(contract *model.BusinessContract) error
+	// Update 保存合同条款，有效期与该商家的其他合同重叠时返回 ErrContractOverlap
+	Update(contract *model.BusinessContract) error
+	// UpdateAttachment 保存已签署合同附件
+	UpdateAttachment(contract *model.BusinessContract) error
+	Delete(id uint) error
+	GetByID(id uint) (*model.BusinessContract, error)
+	// ListByBusiness 获取商家的合同（按生效日期倒序）
+	ListByBusiness(businessID int) ([]*model.BusinessContract, error)
+	// GetEffective 获取商家在指定日期生效的合同
+	GetEffective(businessID int, date string) (*model.BusinessContract, error)
+}
+
+// businessContractRepository 商家合同仓储实现
+type businessContractRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessContractRepository 创建商家合同仓储实例
+func NewBusinessContractRepository(db *gorm.DB) BusinessContractRepository {
+	return &businessContractRepository{
+		db: db,
+	}
+}
+
+// checkContractOverlap 锁定商家行后检查有效期重叠，同一商家的合同写入因此串行执行
+func checkContractOverlap(tx *gorm.DB, contract *model.BusinessContract) error {
+	var business model.Business
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&business, contract.BusinessID).Error; err != nil {
+		return err
+	}
+
+	// 两个闭区间 [s1, e1]、[s2, e2] 重叠当且仅当 s1 <= e2 且 s2 <= e1，结束日期为空视为无穷大
+	query := tx.Model(&model.BusinessContract{}).
+		Where("business_id = ? AND id <> ?", contract.BusinessID, contract.ID).
+		Where("end_date = '' OR end_date >= ?", contract.StartDate)
+	if contract.EndDate != "" {
+		query = query.Where("start_date <= ?", contract.EndDate)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrContractOverlap
+	}
+	return nil
+}
+
+// Create 创建合同
+func (r *businessContractRepository) Create(contract *model.BusinessContract) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkContractOverlap(tx, contract); err != nil {
+			return err
+		}
+		return tx.Create(contract).Error
+	})
+}
+
+// Update 保存合同条款
+func (r *businessContractRepository) Update(contract *model.BusinessContract) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkContractOverlap(tx, contract); err != nil {
+			return err
+		}
+		return tx.Model(contract).
+			Select("contract_no", "start_date", "end_date", "commission_type", "commission_rate", "commission_tiers",
+				"fixed_fee", "fixed_fee_period", "settlement_cycle", "payment_days", "notes").
+			Updates(contract).Error
+	})
+}
+
+// UpdateAttachment 保存合同附件
+func (r *businessContractRepository) UpdateAttachment(contract *model.BusinessContract) error {
+	return r.db.Model(contract).
+		Select("attachment_key", "attachment_filename", "attachment_size", "attachment_checksum").
+		Updates(contract).Error
+}
+
+// Delete 删除合同
+func (r *businessContractRepository) Delete(id uint) error {
+	return r.db.Delete(&model.BusinessContract{}, id).Error
+}
+
+// GetByID 根据ID获取合同
+func (r *businessContractRepository) GetByID(id uint) (*model.BusinessContract, error) {
+	var contract model.BusinessContract
+	if err := r.db.First(&contract, id).Error; err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// ListByBusiness 获取商家的合同
+func (r *businessContractRepository) ListByBusiness(businessID int) ([]*model.BusinessContract, error) {
+	var contracts []*model.BusinessContract
+	err := r.db.Where("business_id = ?", businessID).Order("start_date DESC, id DESC").Find(&contracts).Error
+	return contracts, err
+}
+
+// GetEffective 获取指定日期生效的合同
+func (r *businessContractRepository) GetEffective(businessID int, date string) (*model.BusinessContract, error) {
+	var contract model.BusinessContract
+	err := r.db.Where("business_id = ? AND start_date <= ?", businessID, date).
+		Where("end_date = '' OR end_date >= ?", date).
+		Order("start_date DESC").
+		First(&contract).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"time"
+
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BusinessDocumentRepository 商家资质证件仓储接口，日期参数均为 YYYY-MM-DD
+type BusinessDocumentRepository interface {
+	Create(document *model.BusinessDocument) error
+	// Update 保存证件信息、文件与审核状态
+	Update(document *model.BusinessDocument) error
+	Delete(id uint) error
+	GetByID(id uint) (*model.BusinessDocument, error)
+	// ListByBusiness 获取商家的资质证件（按类型、上传时间倒序）
+	ListByBusiness(businessID int) ([]*model.BusinessDocument, error)
+
+	// GetExpiring 获取有效期在 [today, deadline] 内的待审核或已核验证件（按到期日）
+	GetExpiring(today, deadline string) ([]*model.BusinessDocument, error)
+	// FlagExpiring 标记有效期在 [today, deadline] 内且尚未标记的待审核或已核验证件，返回新标记的数量
+	FlagExpiring(today, deadline string, now time.Time) (int64, error)
+	// GetLapsed 获取有效期早于 today 但尚未标记过期的待审核或已核验证件
+	GetLapsed(today string, limit int) ([]*model.BusinessDocument, error)
+	// MarkExpired 将证件标记为已过期，证件状态已被修改（如重新上传）时返回 false
+	MarkExpired(document *model.BusinessDocument) (bool, error)
+	// HasValid 商家是否有该类型在 today 仍有效的已核验证件
+	HasValid(businessID int, documentType, today string) (bool, error)
+}
+
+// businessDocumentRepository 商家资质证件仓储实现
+type businessDocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessDocumentRepository 创建商家资质证件仓储实例
+func NewBusinessDocumentRepository(db *gorm.DB) BusinessDocumentRepository {
+	return &businessDocumentRepository{
+		db: db,
+	}
+}
+
+// documentStatusesInForce 仍需跟踪有效期的证件状态
+var documentStatusesInForce = []string{model.DocumentStatusPending, model.DocumentStatusVerified}
+
+// Create 创建资质证件
+func (r *businessDocumentRepository) Create(document *model.BusinessDocument) error {
+	return r.db.Create(document).Error
+}
+
+// Update 保存资质证件
+func (r *businessDocumentRepository) Update(document *model.BusinessDocument) error {
+	return r.db.Model(document).
+		Select("type", "number", "issuing_authority", "valid_from", "valid_until",
+			"storage_key", "filename", "content_type", "size", "checksum",
+			"status", "reject_reason", "reviewed_by", "reviewed_at", "expiry_flagged_at").
+		Updates(document).Error
+}
+
+// Delete 删除资质证件
+func (r *businessDocumentRepository) Delete(id uint) error {
+	return r.db.Delete(&model.BusinessDocument{}, id).Error
+}
+
+// GetByID 根据ID获取资质证件
+func (r *businessDocumentRepository) GetByID(id uint) (*model.BusinessDocument, error) {
+	var document model.BusinessDocument
+	if err := r.db.First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// ListByBusiness 获取商家的资质证件
+func (r *businessDocumentRepository) ListByBusiness(businessID int) ([]*model.BusinessDocument, error) {
+	var documents []*model.BusinessDocument
+	err := r.db.Where("business_id = ?", businessID).
+		Order("type ASC, created_at DESC, id DESC").
+		Find(&documents).Error
+	return documents, err
+}
+
+// expiring 有效期在 [today, deadline] 内的待审核或已核验证件
+func (r *businessDocumentRepository) expiring(today, deadline string) *gorm.DB {
+	return r.db.Model(&model.BusinessDocument{}).
+		Where("status IN ? AND valid_until <> '' AND valid_until BETWEEN ? AND ?", documentStatusesInForce, today, deadline)
+}
+
+// GetExpiring 获取即将到期的证件
+func (r *businessDocumentRepository) GetExpiring(today, deadline string) ([]*model.BusinessDocument, error) {
+	var documents []*model.BusinessDocument
+	err := r.expiring(today, deadline).Order("valid_until ASC, id ASC").Find(&documents).Error
+	return documents, err
+}
+
+// FlagExpiring 标记即将到期的证件
+func (r *businessDocumentRepository) FlagExpiring(today, deadline string, now time.Time) (int64, error) {
+	result := r.expiring(today, deadline).
+		Where("expiry_flagged_at IS NULL").
+		Update("expiry_flagged_at", now)
+	return result.RowsAffected, result.Error
+}
+
+// GetLapsed 获取已过有效期的证件
+func (r *businessDocumentRepository) GetLapsed(today string, limit int) ([]*model.BusinessDocument, error) {
+	var documents []*model.BusinessDocument
+	err := r.db.Where("status IN ? AND valid_until <> '' AND valid_until < ?", documentStatusesInForce, today).
+		Order("valid_until ASC, id ASC").
+		Limit(limit).
+		Find(&documents).Error
+	return documents, err
+}
+
+// MarkExpired 将证件标记为已过期
+func (r *businessDocumentRepository) MarkExpired(document *model.BusinessDocument) (bool, error) {
+	result := r.db.Model(&model.BusinessDocument{}).
+		Where("id = ? AND status = ? AND valid_until = ?", document.ID, document.Status, document.ValidUntil).
+		Update("status", model.DocumentStatusExpired)
+	return result.RowsAffected > 0, result.Error
+}
+
+// HasValid 商家是否有该类型仍有效的已核验证件
+func (r *businessDocumentRepository) HasValid(businessID int, documentType, today string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.BusinessDocument{}).
+		Where("business_id = ? AND type = ? AND status = ?", businessID, documentType, model.DocumentStatusVerified).
+		Where("valid_until = '' OR valid_until >= ?", today).
+		Count(&count).Error
+	return count > 0, err
+}
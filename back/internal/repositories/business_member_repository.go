@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BusinessMemberRepository 商家成员仓储接口
+type BusinessMemberRepository interface {
+	Create(member *model.BusinessMember) error
+	// Update 保存成员角色、状态与加入时间
+	Update(member *model.BusinessMember) error
+	Delete(businessID int, userID uint) error
+	Get(businessID int, userID uint) (*model.BusinessMember, error)
+	// ListByBusiness 获取商家的成员（店主在前，其余按邀请时间）
+	ListByBusiness(businessID int) ([]*model.BusinessMember, error)
+	// ListByUser 获取用户加入或被邀请的商家成员记录（不含回收站中的商家）
+	ListByUser(userID uint) ([]*model.BusinessMember, error)
+	// CountOwners 统计商家已加入的店主数量
+	CountOwners(businessID int) (int64, error)
+}
+
+// businessMemberRepository 商家成员仓储实现
+type businessMemberRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessMemberRepository 创建商家成员仓储实例
+func NewBusinessMemberRepository(db *gorm.DB) BusinessMemberRepository {
+	return &businessMemberRepository{
+		db: db,
+	}
+}
+
+// Create 创建商家成员
+func (r *businessMemberRepository) Create(member *model.BusinessMember) error {
+	return r.db.Create(member).Error
+}
+
+// Update 保存成员角色与状态
+func (r *businessMemberRepository) Update(member *model.BusinessMember) error {
+	return r.db.Model(member).Select("role", "status", "joined_at").Updates(member).Error
+}
+
+// Delete 删除商家成员
+func (r *businessMemberRepository) Delete(businessID int, userID uint) error {
+	result := r.db.Where("business_id = ? AND user_id = ?", businessID, userID).Delete(&model.BusinessMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Get 获取用户在商家的成员记录
+func (r *businessMemberRepository) Get(businessID int, userID uint) (*model.BusinessMember, error) {
+	var member model.BusinessMember
+	if err := r.db.Where("business_id = ? AND user_id = ?", businessID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListByBusiness 获取商家的成员
+func (r *businessMemberRepository) ListByBusiness(businessID int) ([]*model.BusinessMember, error) {
+	var members []*model.BusinessMember
+	err := r.db.Where("business_id = ?", businessID).
+		Order("role = '" + model.BusinessRoleOwner + "' DESC").
+		Order("created_at ASC, id ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// ListByUser 获取用户的商家成员记录
+func (r *businessMemberRepository) ListByUser(userID uint) ([]*model.BusinessMember, error) {
+	var members []*model.BusinessMember
+	err := r.db.Where("user_id = ?", userID).
+		Where("business_id IN (?)", r.db.Model(&model.Business{}).Select("id")).
+		Order("created_at DESC, id DESC").
+		Find(&members).Error
+	return members, err
+}
+
+// CountOwners 统计商家已加入的店主数量
+func (r *businessMemberRepository) CountOwners(businessID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.BusinessMember{}).
+		Where("business_id = ? AND role = ? AND status = ?", businessID, model.BusinessRoleOwner, model.BusinessMemberStatusActive).
+		Count(&count).Error
+	return count, err
+}
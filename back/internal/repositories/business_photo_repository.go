@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BusinessPhotoRepository 商家相册仓储接口
+type BusinessPhotoRepository interface {
+	// Create 添加照片到相册末尾，设为封面时取消其他照片的封面
+	Create(photo *model.BusinessPhoto) error
+	// Update 保存说明、封面与审核信息，设为封面时取消其他照片的封面
+	Update(photo *model.BusinessPhoto) error
+	GetByID(id uint) (*model.BusinessPhoto, error)
+	// ListByBusiness 获取商家相册（按顺序），statuses 为空时不按审核状态筛选
+	ListByBusiness(businessID int, statuses ...string) ([]*model.BusinessPhoto, error)
+	CountByBusiness(businessID int) (int64, error)
+	// ExistsMedia 相册中是否已有该媒体文件
+	ExistsMedia(businessID int, mediaID uint) (bool, error)
+	// SetPositions 按给定顺序更新照片位置
+	SetPositions(businessID int, ids []uint) error
+	DeleteByIDs(businessID int, ids []uint) error
+	// GetPending 获取待审核照片（按添加时间）
+	GetPending() ([]*model.BusinessPhoto, error)
+}
+
+// businessPhotoRepository 商家相册仓储实现
+type businessPhotoRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessPhotoRepository 创建商家相册仓储实例
+func NewBusinessPhotoRepository(db *gorm.DB) BusinessPhotoRepository {
+	return &businessPhotoRepository{
+		db: db,
+	}
+}
+
+// clearCover 取消商家其他照片的封面标记
+func clearCover(tx *gorm.DB, photo *model.BusinessPhoto) error {
+	return tx.Model(&model.BusinessPhoto{}).
+		Where("business_id = ? AND id <> ? AND is_cover = ?", photo.BusinessID, photo.ID, true).
+		Update("is_cover", false).Error
+}
+
+// Create 添加照片，位置取相册当前最大位置加一
+func (r *businessPhotoRepository) Create(photo *model.BusinessPhoto) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var last struct{ Position *int }
+		if err := tx.Model(&model.BusinessPhoto{}).Select("MAX(position) AS position").
+			Where("business_id = ?", photo.BusinessID).Scan(&last).Error; err != nil {
+			return err
+		}
+		if last.Position != nil {
+			photo.Position = *last.Position + 1
+		}
+		if err := tx.Create(photo).Error; err != nil {
+			return err
+		}
+		if !photo.IsCover {
+			return nil
+		}
+		return clearCover(tx, photo)
+	})
+}
+
+// Update 保存照片信息
+func (r *businessPhotoRepository) Update(photo *model.BusinessPhoto) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(photo).
+			Select("caption", "is_cover", "status", "reject_reason", "reviewed_by", "reviewed_at").
+			Updates(photo).Error; err != nil {
+			return err
+		}
+		if !photo.IsCover {
+			return nil
+		}
+		return clearCover(tx, photo)
+	})
+}
+
+// GetByID 根据ID获取照片
+func (r *businessPhotoRepository) GetByID(id uint) (*model.BusinessPhoto, error) {
+	var photo model.BusinessPhoto
+	err := r.db.First(&photo, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// ListByBusiness 获取商家相册
+func (r *businessPhotoRepository) ListByBusiness(businessID int, statuses ...string) ([]*model.BusinessPhoto, error) {
+	var photos []*model.BusinessPhoto
+	query := r.db.Where("business_id = ?", businessID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	err := query.Order("position, id").Find(&photos).Error
+	return photos, err
+}
+
+// CountByBusiness 统计商家相册照片数
+func (r *businessPhotoRepository) CountByBusiness(businessID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.BusinessPhoto{}).Where("business_id = ?", businessID).Count(&count).Error
+	return count, err
+}
+
+// ExistsMedia 相册中是否已有该媒体文件
+func (r *businessPhotoRepository) ExistsMedia(businessID int, mediaID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.BusinessPhoto{}).Where("business_id = ? AND media_id = ?", businessID, mediaID).Count(&count).Error
+	return count > 0, err
+}
+
+// SetPositions 在事务中按顺序更新位置
+func (r *businessPhotoRepository) SetPositions(businessID int, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&model.BusinessPhoto{}).
+				Where("id = ? AND business_id = ?", id, businessID).
+				Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteByIDs 删除商家相册中的指定照片
+func (r *businessPhotoRepository) DeleteByIDs(businessID int, ids []uint) error {
+	return r.db.Where("business_id = ? AND id IN ?", businessID, ids).Delete(&model.BusinessPhoto{}).Error
+}
+
+// GetPending 获取待审核照片
+func (r *businessPhotoRepository) GetPending() ([]*model.BusinessPhoto, error) {
+	var photos []*model.BusinessPhoto
+	err := r.db.Where("status = ?", model.BusinessPhotoStatusPending).Order("created_at, id").Find(&photos).Error
+	return photos, err
+}
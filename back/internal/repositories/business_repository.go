@@ -1,19 +1,30 @@
 package repositories
 
 import (
+	"errors"
+	"strings"
+	"time"
+
+	"merchant_back/internal/common"
 	model "merchant_back/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrVersionConflict 数据已被并发修改（版本号不一致）
+var ErrVersionConflict = errors.New("数据已被其他人修改，请刷新后重试")
+
 // BusinessRepository 商家仓储接口
 type BusinessRepository interface {
 	// 基础CRUD操作
 	Create(business *model.Business) error
 	GetByID(id int) (*model.Business, error)
+	GetByIDs(ids []int) ([]*model.Business, error)
 	GetAll() ([]*model.Business, error)
 	Update(business *model.Business) error
-	Delete(id int) error
+	// Delete 删除商家（移入回收站），version 不为 0 时仅当版本号一致时生效，否则返回 ErrVersionConflict
+	Delete(id int, version int) error
 
 	// 业务查询方法
 	GetByEmail(email string) (*model.Business, error)
@@ -23,19 +34,48 @@ type BusinessRepository interface {
 	GetByRating(minRating float64) ([]*model.Business, error)
 	GetByLocation(lat, lng float64, radius float64) ([]*model.Business, error)
 	GetByStatus(status string) ([]*model.Business, error)
+	GetByRegion(filter model.RegionFilter) ([]*model.Business, error)
+
+	// ForEachBatch 按 ID 顺序分批遍历全部未删除的商家（含已暂停）
+	ForEachBatch(batchSize int, fn func(businesses []*model.Business) error) error
 
 	// 分页查询
 	GetWithPagination(page, pageSize int) ([]*model.Business, int64, error)
+	// List 按统一列表查询条件筛选、排序，支持偏移分页与游标分页
+	// fields 为允许筛选与排序的字段（BusinessListFields 加上分类定义的自定义属性字段）
+	List(q *common.ListQuery, region model.RegionFilter, fields ListFields) ([]*model.Business, *common.ListPageInfo, error)
 
 	// 批量操作
 	BatchCreate(businesses []*model.Business) error
-	BatchUpdate(businesses []*model.Business) error
+	BatchUpdate(businesses []*model.Business) ([]int, error)
 	BatchDelete(ids []int) error
 
+	// 回收站（软删除的商家）
+	GetDeleted(page, pageSize int) ([]*model.Business, int64, error)
+	GetDeletedByID(id int) (*model.Business, error)
+	GetDeletedBefore(before time.Time, limit int) ([]int, error)
+	Restore(id int) error
+	Purge(ids []int) error
+
+	// 营业时间
+	LoadOpeningHours(businesses []*model.Business) error
+	// ReplaceOpeningHours version 不为 0 时与数据库中的版本号一致才生效，否则返回 ErrVersionConflict
+	ReplaceOpeningHours(businessID int, timezone string, hours []model.BusinessHours, special []model.BusinessSpecialHours, version int) error
+
+	// 搜索筛选与分面（ids 为 nil 时为全部未暂停的商家，否则只在 ids 中未暂停的商家内筛选）
+	// FilterSearchIDs 返回 ids 中满足筛选条件的商家ID（顺序不保证）
+	FilterSearchIDs(ids []int, filter model.BusinessSearchFilter) ([]int, error)
+	// ListSearchMatches 按 ID 顺序分页获取满足筛选条件的商家及总数，pageSize 为 0 时不分页
+	ListSearchMatches(filter model.BusinessSearchFilter, page, pageSize int) ([]*model.Business, int64, error)
+	// CountSearchFacet 按分面维度（model.Facet* 或地区层级）分组统计满足筛选条件的商家数，按数量倒序，limit 为 0 时不限制
+	CountSearchFacet(ids []int, filter model.BusinessSearchFilter, facet string, limit int) ([]model.FacetCount, error)
+
 	// 统计方法
 	Count() (int64, error)
 	CountByType(businessType string) (int64, error)
 	CountByStatus(status string) (int64, error)
+	CountByRegion(filter model.RegionFilter) (int64, error)
+	AggregateByRegion(level string, filter model.RegionFilter) ([]*model.RegionCount, error)
 }
 
 // businessRepository 商家仓储实现
@@ -65,6 +105,16 @@ func (r *businessRepository) GetByID(id int) (*model.Business, error) {
 	return &business, nil
 }
 
+// GetByIDs 根据ID批量获取商家（顺序不保证与 ids 一致）
+func (r *businessRepository) GetByIDs(ids []int) ([]*model.Business, error) {
+	var businesses []*model.Business
+	if len(ids) == 0 {
+		return businesses, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&businesses).Error
+	return businesses, err
+}
+
 // GetAll 获取所有商家
 func (r *businessRepository) GetAll() ([]*model.Business, error) {
 	var businesses []*model.Business
@@ -72,14 +122,55 @@ func (r *businessRepository) GetAll() ([]*model.Business, error) {
 	return businesses, err
 }
 
-// Update 更新商家
+// Update 更新商家（营业时间通过 ReplaceOpeningHours 单独维护）
+// 仅当数据库中的版本号与 business.Version 一致时生效，成功后版本号加一，否则返回 ErrVersionConflict
 func (r *businessRepository) Update(business *model.Business) error {
-	return r.db.Save(business).Error
+	return updateBusinessVersioned(r.db, business)
+}
+
+// updateBusinessVersioned 按版本号条件更新商家全部字段（评分由评价仓储维护，不在此更新）
+func updateBusinessVersioned(db *gorm.DB, business *model.Business) error {
+	expected := business.Version
+	business.Version = expected + 1
+
+	result := db.Select("*").Omit(clause.Associations, "rating", "rating_count").Where("version = ?", expected).Updates(business)
+	if result.Error != nil {
+		business.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		business.Version = expected
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// Delete 删除商家
-func (r *businessRepository) Delete(id int) error {
-	return r.db.Delete(&model.Business{}, id).Error
+// Delete 删除商家（软删除，移入回收站）
+func (r *businessRepository) Delete(id int, version int) error {
+	if version == 0 {
+		return softDeleteBusinesses(r.db, []int{id})
+	}
+	result := r.db.Model(&model.Business{}).Where("id = ? AND version = ?", id, version).Updates(map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"delete_marker": gorm.Expr("id"),
+		"version":       gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// softDeleteBusinesses 软删除商家，同时写入删除标记以释放邮箱唯一索引
+func softDeleteBusinesses(db *gorm.DB, ids []int) error {
+	return db.Model(&model.Business{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"delete_marker": gorm.Expr("id"),
+		"version":       gorm.Expr("version + 1"),
+	}).Error
 }
 
 // GetByEmail 根据邮箱获取商家
@@ -113,10 +204,10 @@ func (r *businessRepository) SearchByAddress(keyword string) ([]*model.Business,
 	return businesses, err
 }
 
-// GetByRating 根据评分获取商家列表
+// GetByRating 根据评分（由顾客评价计算）获取商家列表，评分相同时评价多的在前
 func (r *businessRepository) GetByRating(minRating float64) ([]*model.Business, error) {
 	var businesses []*model.Business
-	err := r.db.Where("rating >= ? AND status != ?", minRating, model.BusinessStatusSuspended).Order("rating DESC").Find(&businesses).Error
+	err := r.db.Where("rating >= ? AND status != ?", minRating, model.BusinessStatusSuspended).Order("rating DESC, rating_count DESC").Find(&businesses).Error
 	return businesses, err
 }
 
@@ -142,6 +233,30 @@ func (r *businessRepository) GetByStatus(status string) ([]*model.Business, erro
 	return businesses, err
 }
 
+// GetByRegion 根据地区获取商家列表
+func (r *businessRepository) GetByRegion(filter model.RegionFilter) ([]*model.Business, error) {
+	var businesses []*model.Business
+	err := applyRegionFilter(r.db, filter).Where("status != ?", model.BusinessStatusSuspended).Find(&businesses).Error
+	return businesses, err
+}
+
+// applyRegionFilter 应用地区筛选条件
+func applyRegionFilter(db *gorm.DB, filter model.RegionFilter) *gorm.DB {
+	if filter.Country != "" {
+		db = db.Where("country = ?", filter.Country)
+	}
+	if filter.Province != "" {
+		db = db.Where("province = ?", filter.Province)
+	}
+	if filter.City != "" {
+		db = db.Where("city = ?", filter.City)
+	}
+	if filter.District != "" {
+		db = db.Where("district = ?", filter.District)
+	}
+	return db
+}
+
 // GetWithPagination 分页获取商家列表
 func (r *businessRepository) GetWithPagination(page, pageSize int) ([]*model.Business, int64, error) {
 	var businesses []*model.Business
@@ -159,26 +274,393 @@ func (r *businessRepository) GetWithPagination(page, pageSize int) ([]*model.Bus
 	return businesses, total, err
 }
 
+// ForEachBatch 分批遍历全部未删除的商家
+func (r *businessRepository) ForEachBatch(batchSize int, fn func(businesses []*model.Business) error) error {
+	var batch []*model.Business
+	return r.db.Order("id").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// List 按统一列表查询条件获取商家，未按状态筛选时不包含已暂停的商家
+func (r *businessRepository) List(q *common.ListQuery, region model.RegionFilter, fields ListFields) ([]*model.Business, *common.ListPageInfo, error) {
+	query := applyRegionFilter(r.db.Model(&model.Business{}), region)
+	if !q.HasFilter("status") {
+		query = query.Where("status != ?", model.BusinessStatusSuspended)
+	}
+	query, err := ApplyListFilters(query, q, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &common.ListPageInfo{}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var businesses []*model.Business
+	if q.CursorMode {
+		if query, err = ApplyListCursor(query, q, fields, "business"); err != nil {
+			return nil, nil, err
+		}
+		if err := query.Find(&businesses).Error; err != nil {
+			return nil, nil, err
+		}
+		businesses, page.NextCursor, page.PrevCursor, err = ListCursorPage(businesses, q, fields, "business")
+		return businesses, page, err
+	}
+
+	if query, err = ApplyListSort(query, q, fields); err != nil {
+		return nil, nil, err
+	}
+	err = ApplyListPage(query, q).Find(&businesses).Error
+	return businesses, page, err
+}
+
 // BatchCreate 批量创建商家
 func (r *businessRepository) BatchCreate(businesses []*model.Business) error {
 	return r.db.CreateInBatches(businesses, 100).Error
 }
 
-// BatchUpdate 批量更新商家
-func (r *businessRepository) BatchUpdate(businesses []*model.Business) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		for _, business := range businesses {
-			if err := tx.Save(business).Error; err != nil {
+// BatchUpdate 批量更新商家，任一商家版本号不一致时整体回滚并返回冲突商家的下标
+func (r *businessRepository) BatchUpdate(businesses []*model.Business) ([]int, error) {
+	versions := make([]int, len(businesses))
+	for i, business := range businesses {
+		versions[i] = business.Version
+	}
+
+	var conflicts []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i, business := range businesses {
+			err := updateBusinessVersioned(tx, business)
+			if errors.Is(err, ErrVersionConflict) {
+				conflicts = append(conflicts, i)
+				continue
+			}
+			if err != nil {
 				return err
 			}
 		}
+		if len(conflicts) > 0 {
+			return ErrVersionConflict
+		}
 		return nil
 	})
+	if err != nil {
+		// 事务已回滚，恢复调用方传入的版本号
+		for i, business := range businesses {
+			business.Version = versions[i]
+		}
+	}
+	return conflicts, err
 }
 
-// BatchDelete 批量删除商家
+// BatchDelete 批量删除商家（软删除，移入回收站）
 func (r *businessRepository) BatchDelete(ids []int) error {
-	return r.db.Delete(&model.Business{}, ids).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return softDeleteBusinesses(tx, ids)
+	})
+}
+
+// GetDeleted 分页获取回收站中的商家（按删除时间倒序）
+func (r *businessRepository) GetDeleted(page, pageSize int) ([]*model.Business, int64, error) {
+	var businesses []*model.Business
+	var total int64
+
+	offset := (page - 1) * pageSize
+
+	query := r.db.Unscoped().Model(&model.Business{}).Where("deleted_at IS NOT NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&businesses).Error
+	return businesses, total, err
+}
+
+// GetDeletedByID 根据ID获取回收站中的商家
+func (r *businessRepository) GetDeletedByID(id int) (*model.Business, error) {
+	var business model.Business
+	err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&business).Error
+	if err != nil {
+		return nil, err
+	}
+	return &business, nil
+}
+
+// GetDeletedBefore 获取删除时间早于 before 的商家ID
+func (r *businessRepository) GetDeletedBefore(before time.Time, limit int) ([]int, error) {
+	var ids []int
+	err := r.db.Unscoped().Model(&model.Business{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Restore 从回收站恢复商家
+func (r *businessRepository) Restore(id int) error {
+	result := r.db.Unscoped().Model(&model.Business{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at":    nil,
+			"delete_marker": 0,
+			"version":       gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge 彻底删除回收站中的商家及其关联数据，未在回收站中的商家不受影响
+func (r *businessRepository) Purge(ids []int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var trashed []int
+		if err := tx.Unscoped().Model(&model.Business{}).
+			Where("id IN ? AND deleted_at IS NOT NULL", ids).
+			Pluck("id", &trashed).Error; err != nil {
+			return err
+		}
+		if len(trashed) == 0 {
+			return nil
+		}
+
+		if err := deleteOpeningHours(tx, trashed); err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessScheduledTransition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessStatusTransition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessCategory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessAttributeValue{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessPhoto{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("review_id IN (?)", tx.Model(&model.Review{}).Select("id").Where("business_id IN ?", trashed)).
+			Delete(&model.ReviewModerationLog{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.Review{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessRating{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.BusinessMember{}).Error; err != nil {
+			return err
+		}
+		// 结算账户及变更申请含加密的银行账号，不随商家保留
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.SettlementAccountChange{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("business_id IN ?", trashed).Delete(&model.SettlementAccount{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.Business{}, trashed).Error
+	})
+}
+
+// LoadOpeningHours 批量加载商家营业时间
+func (r *businessRepository) LoadOpeningHours(businesses []*model.Business) error {
+	if len(businesses) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(businesses))
+	byID := make(map[int]*model.Business, len(businesses))
+	for _, business := range businesses {
+		ids = append(ids, business.ID)
+		byID[business.ID] = business
+		business.OpeningHours = nil
+		business.SpecialHours = nil
+	}
+
+	var hours []model.BusinessHours
+	if err := r.db.Where("business_id IN ?", ids).Order("weekday, open_time").Find(&hours).Error; err != nil {
+		return err
+	}
+	for _, h := range hours {
+		byID[h.BusinessID].OpeningHours = append(byID[h.BusinessID].OpeningHours, h)
+	}
+
+	var special []model.BusinessSpecialHours
+	if err := r.db.Where("business_id IN ?", ids).Order("date, open_time").Find(&special).Error; err != nil {
+		return err
+	}
+	for _, sh := range special {
+		byID[sh.BusinessID].SpecialHours = append(byID[sh.BusinessID].SpecialHours, sh)
+	}
+	return nil
+}
+
+// ReplaceOpeningHours 整体替换商家时区与营业时间
+func (r *businessRepository) ReplaceOpeningHours(businessID int, timezone string, hours []model.BusinessHours, special []model.BusinessSpecialHours, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.Business{}).Where("id = ?", businessID)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(map[string]interface{}{
+			"timezone": timezone,
+			"version":  gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 && version != 0 {
+			return ErrVersionConflict
+		}
+		if err := deleteOpeningHours(tx, []int{businessID}); err != nil {
+			return err
+		}
+		for i := range hours {
+			hours[i].ID = 0
+			hours[i].BusinessID = businessID
+		}
+		for i := range special {
+			special[i].ID = 0
+			special[i].BusinessID = businessID
+		}
+		if len(hours) > 0 {
+			if err := tx.Create(&hours).Error; err != nil {
+				return err
+			}
+		}
+		if len(special) > 0 {
+			if err := tx.Create(&special).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteOpeningHours 删除商家营业时间
+func deleteOpeningHours(tx *gorm.DB, businessIDs []int) error {
+	if err := tx.Where("business_id IN ?", businessIDs).Delete(&model.BusinessHours{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("business_id IN ?", businessIDs).Delete(&model.BusinessSpecialHours{}).Error
+}
+
+// searchScope 搜索的匹配集合：ids 为 nil 时为全部未暂停的商家，否则为 ids 中未暂停的商家
+func (r *businessRepository) searchScope(ids []int) *gorm.DB {
+	query := r.db.Model(&model.Business{}).Where("status != ?", model.BusinessStatusSuspended)
+	if ids != nil {
+		query = query.Where("id IN ?", ids)
+	}
+	return query
+}
+
+// ratingBucketExpr 计算评分区间标识的 SQL 表达式，与 model.RatingBucketOf 一致（区间由高到低依次判断）
+func ratingBucketExpr() (string, []interface{}) {
+	buckets := model.RatingBuckets
+	var expr strings.Builder
+	args := make([]interface{}, 0, len(buckets)*2)
+	expr.WriteString("CASE")
+	for _, bucket := range buckets[:len(buckets)-1] {
+		expr.WriteString(" WHEN rating >= ? THEN ?")
+		args = append(args, bucket.Min, bucket.Key)
+	}
+	expr.WriteString(" ELSE ? END")
+	args = append(args, buckets[len(buckets)-1].Key)
+	return expr.String(), args
+}
+
+// applySearchFilter 应用搜索分面筛选条件，标签按任一匹配（按列排序规则不区分大小写）
+func (r *businessRepository) applySearchFilter(db *gorm.DB, filter model.BusinessSearchFilter) *gorm.DB {
+	if len(filter.Types) > 0 {
+		db = db.Where("type IN ?", filter.Types)
+	}
+	if len(filter.Statuses) > 0 {
+		db = db.Where("status IN ?", filter.Statuses)
+	}
+	if len(filter.Ratings) > 0 {
+		expr, args := ratingBucketExpr()
+		db = db.Where("("+expr+") IN ?", append(args, filter.Ratings)...)
+	}
+	db = applyRegionFilter(db, filter.Region)
+	if len(filter.Tags) > 0 {
+		db = db.Where("id IN (?)", r.db.Model(&model.BusinessTag{}).Select("business_id").Where("tag IN ?", filter.Tags))
+	}
+	return db
+}
+
+// FilterSearchIDs 筛选搜索命中的商家
+func (r *businessRepository) FilterSearchIDs(ids []int, filter model.BusinessSearchFilter) ([]int, error) {
+	var matched []int
+	err := r.applySearchFilter(r.searchScope(ids), filter).Pluck("id", &matched).Error
+	return matched, err
+}
+
+// ListSearchMatches 分页获取满足筛选条件的商家
+func (r *businessRepository) ListSearchMatches(filter model.BusinessSearchFilter, page, pageSize int) ([]*model.Business, int64, error) {
+	query := r.applySearchFilter(r.searchScope(nil), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var businesses []*model.Business
+	query = query.Order("id")
+	if pageSize > 0 {
+		query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	err := query.Find(&businesses).Error
+	return businesses, total, err
+}
+
+// CountSearchFacet 分组统计分面取值
+func (r *businessRepository) CountSearchFacet(ids []int, filter model.BusinessSearchFilter, facet string, limit int) ([]model.FacetCount, error) {
+	scope := r.applySearchFilter(r.searchScope(ids), filter)
+
+	var query *gorm.DB
+	switch facet {
+	case model.FacetType, model.FacetStatus:
+		query = scope.Select(facet + " AS value, COUNT(*) AS count").Group(facet)
+	case model.RegionLevelCountry, model.RegionLevelProvince, model.RegionLevelCity, model.RegionLevelDistrict:
+		query = scope.Select(facet + " AS value, COUNT(*) AS count").Where(facet + " != ''").Group(facet)
+	case model.FacetRating:
+		expr, args := ratingBucketExpr()
+		query = scope.Select(expr+" AS value, COUNT(*) AS count", args...).Group("value")
+	case model.FacetTags:
+		query = r.db.Model(&model.BusinessTag{}).
+			Select("tag AS value, COUNT(*) AS count").
+			Where("business_id IN (?)", scope.Select("id")).
+			Group("tag")
+	default:
+		return nil, errors.New("未知的分面维度: " + facet)
+	}
+
+	query = query.Order("count DESC, value ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var counts []model.FacetCount
+	err := query.Scan(&counts).Error
+	return counts, err
 }
 
 // Count 获取商家总数
@@ -201,3 +683,23 @@ func (r *businessRepository) CountByStatus(status string) (int64, error) {
 	err := r.db.Model(&model.Business{}).Where("status = ?", status).Count(&count).Error
 	return count, err
 }
+
+// CountByRegion 根据地区获取商家数量
+func (r *businessRepository) CountByRegion(filter model.RegionFilter) (int64, error) {
+	var count int64
+	err := applyRegionFilter(r.db.Model(&model.Business{}), filter).Where("status != ?", model.BusinessStatusSuspended).Count(&count).Error
+	return count, err
+}
+
+// AggregateByRegion 按地区层级聚合商家数量
+func (r *businessRepository) AggregateByRegion(level string, filter model.RegionFilter) ([]*model.RegionCount, error) {
+	var counts []*model.RegionCount
+	// level 已在服务层校验为固定列名
+	err := applyRegionFilter(r.db.Model(&model.Business{}), filter).
+		Select(level+" AS region, COUNT(*) AS count").
+		Where("status != ?", model.BusinessStatusSuspended).
+		Group(level).
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
+}
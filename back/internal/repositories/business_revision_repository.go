@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BusinessRevisionRepository 商家修订记录仓储接口
+type BusinessRevisionRepository interface {
+	// Create 写入修订记录并分配下一个版本号
+	Create(revision *model.BusinessRevision) error
+	GetByBusinessID(businessID int) ([]*model.BusinessRevision, error)
+	GetByVersion(businessID, version int) (*model.BusinessRevision, error)
+	GetLatest(businessID int) (*model.BusinessRevision, error)
+}
+
+// businessRevisionRepository 商家修订记录仓储实现
+type businessRevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessRevisionRepository 创建商家修订记录仓储实例
+func NewBusinessRevisionRepository(db *gorm.DB) BusinessRevisionRepository {
+	return &businessRevisionRepository{
+		db: db,
+	}
+}
+
+// Create 写入修订记录，锁定商家行保证同一商家的版本号连续且不重复
+func (r *businessRevisionRepository) Create(revision *model.BusinessRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var business model.Business
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&business, revision.BusinessID).Error; err != nil {
+			return err
+		}
+
+		var version int
+		if err := tx.Model(&model.BusinessRevision{}).
+			Where("business_id = ?", revision.BusinessID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&version).Error; err != nil {
+			return err
+		}
+
+		revision.ID = 0
+		revision.Version = version + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// GetByBusinessID 获取商家全部修订记录（按版本倒序，不含快照）
+func (r *businessRevisionRepository) GetByBusinessID(businessID int) ([]*model.BusinessRevision, error) {
+	var revisions []*model.BusinessRevision
+	err := r.db.Omit("snapshot").Where("business_id = ?", businessID).Order("version DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// GetByVersion 获取商家指定版本的修订记录
+func (r *businessRevisionRepository) GetByVersion(businessID, version int) (*model.BusinessRevision, error) {
+	var revision model.BusinessRevision
+	err := r.db.Where("business_id = ? AND version = ?", businessID, version).First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetLatest 获取商家最新的修订记录
+func (r *businessRevisionRepository) GetLatest(businessID int) (*model.BusinessRevision, error) {
+	var revision model.BusinessRevision
+	err := r.db.Where("business_id = ?", businessID).Order("version DESC").First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
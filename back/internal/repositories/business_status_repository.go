@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrStatusConflict 商家状态已被并发修改
+var ErrStatusConflict = errors.New("商家状态已被修改，请刷新后重试")
+
+// BusinessStatusRepository 商家状态流转仓储接口
+type BusinessStatusRepository interface {
+	// Transition 在一个事务中更新商家状态相关字段并写入流转记录，
+	// 仅当商家当前状态仍为 transition.FromStatus 时生效；version 不为 0 时还要求版本号一致，否则返回 ErrVersionConflict
+	Transition(transition *model.BusinessStatusTransition, fields map[string]interface{}, version int) error
+	// GetTransitions 按统一列表查询条件获取商家状态流转记录，未指定排序时按时间正序
+	GetTransitions(businessID int, q *common.ListQuery) ([]*model.BusinessStatusTransition, *common.ListPageInfo, error)
+	GetReviewQueue(reviewerID uint) ([]*model.Business, error)
+
+	// 计划流转
+	CreateSchedule(schedule *model.BusinessScheduledTransition) error
+	GetSchedules(businessID int) ([]*model.BusinessScheduledTransition, error)
+	GetDueSchedules(now time.Time, limit int) ([]*model.BusinessScheduledTransition, error)
+	ClaimSchedule(id uint) (bool, error)
+	FinishSchedule(id uint, status, errMsg string) error
+	CancelSchedule(businessID int, id uint) (bool, error)
+	CancelPendingSchedules(businessID int) error
+}
+
+// businessStatusRepository 商家状态流转仓储实现
+type businessStatusRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessStatusRepository 创建商家状态流转仓储实例
+func NewBusinessStatusRepository(db *gorm.DB) BusinessStatusRepository {
+	return &businessStatusRepository{
+		db: db,
+	}
+}
+
+// Transition 更新商家状态并写入流转记录
+func (r *businessStatusRepository) Transition(transition *model.BusinessStatusTransition, fields map[string]interface{}, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"status":  transition.ToStatus,
+			"version": gorm.Expr("version + 1"),
+		}
+		for k, v := range fields {
+			updates[k] = v
+		}
+
+		query := tx.Model(&model.Business{}).Where("id = ? AND status = ?", transition.BusinessID, transition.FromStatus)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if version != 0 {
+				return ErrVersionConflict
+			}
+			return ErrStatusConflict
+		}
+
+		return tx.Create(transition).Error
+	})
+}
+
+// GetTransitions 获取商家状态流转记录，支持筛选、偏移分页与游标分页（默认按时间正序）
+func (r *businessStatusRepository) GetTransitions(businessID int, q *common.ListQuery) ([]*model.BusinessStatusTransition, *common.ListPageInfo, error) {
+	if len(q.Sorts) == 0 {
+		sorted := *q
+		sorted.Sorts = []common.ListSort{{Field: "createdAt"}}
+		q = &sorted
+	}
+
+	query, err := ApplyListFilters(r.db.Model(&model.BusinessStatusTransition{}).Where("business_id = ?", businessID), q, StatusTransitionListFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &common.ListPageInfo{}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var transitions []*model.BusinessStatusTransition
+	if q.CursorMode {
+		if query, err = ApplyListCursor(query, q, StatusTransitionListFields, "status-history"); err != nil {
+			return nil, nil, err
+		}
+		if err := query.Find(&transitions).Error; err != nil {
+			return nil, nil, err
+		}
+		transitions, page.NextCursor, page.PrevCursor, err = ListCursorPage(transitions, q, StatusTransitionListFields, "status-history")
+		return transitions, page, err
+	}
+
+	if query, err = ApplyListSort(query, q, StatusTransitionListFields); err != nil {
+		return nil, nil, err
+	}
+	err = ApplyListPage(query, q).Find(&transitions).Error
+	return transitions, page, err
+}
+
+// GetReviewQueue 获取待审核的入驻申请，reviewerID 为 0 时返回全部（按提交时间先后）
+func (r *businessStatusRepository) GetReviewQueue(reviewerID uint) ([]*model.Business, error) {
+	var businesses []*model.Business
+	query := r.db.Where("status IN ?", []string{model.BusinessStatusSubmitted, model.BusinessStatusUnderReview})
+	if reviewerID != 0 {
+		query = query.Where("reviewer_id = ?", reviewerID)
+	}
+	err := query.Order("submitted_at ASC, id ASC").Find(&businesses).Error
+	return businesses, err
+}
+
+// CreateSchedule 创建计划流转
+func (r *businessStatusRepository) CreateSchedule(schedule *model.BusinessScheduledTransition) error {
+	return r.db.Create(schedule).Error
+}
+
+// GetSchedules 获取商家的计划流转（按执行时间）
+func (r *businessStatusRepository) GetSchedules(businessID int) ([]*model.BusinessScheduledTransition, error) {
+	var schedules []*model.BusinessScheduledTransition
+	err := r.db.Where("business_id = ?", businessID).Order("execute_at ASC, id ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+// GetDueSchedules 获取已到期待执行的计划流转
+func (r *businessStatusRepository) GetDueSchedules(now time.Time, limit int) ([]*model.BusinessScheduledTransition, error) {
+	var schedules []*model.BusinessScheduledTransition
+	err := r.db.Where("status = ? AND execute_at <= ?", model.ScheduleStatusPending, now).
+		Order("execute_at ASC, id ASC").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// ClaimSchedule 抢占计划流转，多实例部署时保证只执行一次
+func (r *businessStatusRepository) ClaimSchedule(id uint) (bool, error) {
+	result := r.db.Model(&model.BusinessScheduledTransition{}).
+		Where("id = ? AND status = ?", id, model.ScheduleStatusPending).
+		Update("status", model.ScheduleStatusRunning)
+	return result.RowsAffected == 1, result.Error
+}
+
+// FinishSchedule 记录计划流转执行结果
+func (r *businessStatusRepository) FinishSchedule(id uint, status, errMsg string) error {
+	if len(errMsg) > 500 {
+		errMsg = errMsg[:500]
+	}
+	return r.db.Model(&model.BusinessScheduledTransition{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      status,
+		"error":       errMsg,
+		"executed_at": time.Now(),
+	}).Error
+}
+
+// CancelSchedule 取消待执行的计划流转
+func (r *businessStatusRepository) CancelSchedule(businessID int, id uint) (bool, error) {
+	result := r.db.Model(&model.BusinessScheduledTransition{}).
+		Where("id = ? AND business_id = ? AND status = ?", id, businessID, model.ScheduleStatusPending).
+		Update("status", model.ScheduleStatusCancelled)
+	return result.RowsAffected == 1, result.Error
+}
+
+// CancelPendingSchedules 取消商家全部待执行的计划流转
+func (r *businessStatusRepository) CancelPendingSchedules(businessID int) error {
+	return r.db.Model(&model.BusinessScheduledTransition{}).
+		Where("business_id = ? AND status = ?", businessID, model.ScheduleStatusPending).
+		Update("status", model.ScheduleStatusCancelled).Error
+}
@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRepository 商家分类仓储接口
+type CategoryRepository interface {
+	Create(category *model.Category) error
+	// Update 更新分类，标识变化时同步以该分类为主分类的商家的 type 字段，返回被同步的商家ID
+	Update(category *model.Category, previousSlug string) ([]int, error)
+	Delete(id uint) error
+	GetByID(id uint) (*model.Category, error)
+	GetByIDs(ids []uint) ([]*model.Category, error)
+	GetBySlug(slug string) (*model.Category, error)
+	// GetAll 获取全部分类（按排序、ID 升序）
+	GetAll() ([]*model.Category, error)
+	CountChildren(id uint) (int64, error)
+	// CountBusinesses 统计各分类直接关联的未删除商家数量
+	CountBusinesses() (map[uint]int64, error)
+
+	// 商家关联
+	LoadBusinessCategories(businesses []*model.Business) error
+	SetBusinessCategories(businessID int, categoryIDs []uint) error
+	// Merge 将源分类的商家关联与子分类转移到目标分类并删除源分类，返回关联发生变化的商家ID
+	Merge(sourceID uint, target *model.Category) ([]int, error)
+}
+
+// categoryRepository 商家分类仓储实现
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository 创建商家分类仓储实例
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{
+		db: db,
+	}
+}
+
+// Create 创建分类
+func (r *categoryRepository) Create(category *model.Category) error {
+	return r.db.Create(category).Error
+}
+
+// Update 更新分类，标识变化时在同一事务中同步商家 type 字段（含回收站中的商家）
+func (r *categoryRepository) Update(category *model.Category, previousSlug string) ([]int, error) {
+	var synced []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(category).
+			Select("parent_id", "slug", "name", "names", "icon", "sort_order", "attribute_schema").
+			Updates(category).Error; err != nil {
+			return err
+		}
+		if previousSlug == category.Slug {
+			return nil
+		}
+
+		if err := tx.Model(&model.BusinessCategory{}).
+			Where("category_id = ? AND position = 0", category.ID).
+			Pluck("business_id", &synced).Error; err != nil {
+			return err
+		}
+		return syncBusinessTypes(tx, synced, category.Slug)
+	})
+	return synced, err
+}
+
+// Delete 删除分类
+func (r *categoryRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Category{}, id).Error
+}
+
+// GetByID 根据ID获取分类
+func (r *categoryRepository) GetByID(id uint) (*model.Category, error) {
+	var category model.Category
+	err := r.db.First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetByIDs 根据ID批量获取分类
+func (r *categoryRepository) GetByIDs(ids []uint) ([]*model.Category, error) {
+	var categories []*model.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+// GetBySlug 根据标识获取分类
+func (r *categoryRepository) GetBySlug(slug string) (*model.Category, error) {
+	var category model.Category
+	err := r.db.Where("slug = ?", slug).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetAll 获取全部分类
+func (r *categoryRepository) GetAll() ([]*model.Category, error) {
+	var categories []*model.Category
+	err := r.db.Order("sort_order ASC, id ASC").Find(&categories).Error
+	return categories, err
+}
+
+// CountChildren 统计子分类数量
+func (r *categoryRepository) CountChildren(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// CountBusinesses 统计各分类直接关联的未删除商家数量
+func (r *categoryRepository) CountBusinesses() (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+	err := r.db.Model(&model.BusinessCategory{}).
+		Select("business_categories.category_id, COUNT(*) AS count").
+		Joins("JOIN business ON business.id = business_categories.business_id AND business.deleted_at IS NULL").
+		Group("business_categories.category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// LoadBusinessCategories 批量加载商家所属分类ID（主分类在前）
+func (r *categoryRepository) LoadBusinessCategories(businesses []*model.Business) error {
+	if len(businesses) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(businesses))
+	byID := make(map[int]*model.Business, len(businesses))
+	for _, business := range businesses {
+		ids = append(ids, business.ID)
+		byID[business.ID] = business
+		business.CategoryIDs = nil
+	}
+
+	var links []model.BusinessCategory
+	if err := r.db.Where("business_id IN ?", ids).Order("business_id, position, category_id").Find(&links).Error; err != nil {
+		return err
+	}
+	for _, link := range links {
+		business := byID[link.BusinessID]
+		business.CategoryIDs = append(business.CategoryIDs, link.CategoryID)
+	}
+	return nil
+}
+
+// SetBusinessCategories 替换商家所属分类，第一个为主分类
+func (r *categoryRepository) SetBusinessCategories(businessID int, categoryIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("business_id = ?", businessID).Delete(&model.BusinessCategory{}).Error; err != nil {
+			return err
+		}
+		if len(categoryIDs) == 0 {
+			return nil
+		}
+
+		links := make([]model.BusinessCategory, len(categoryIDs))
+		for i, id := range categoryIDs {
+			links[i] = model.BusinessCategory{BusinessID: businessID, CategoryID: id, Position: i}
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// Merge 合并分类
+// 已同时关联两个分类的商家保留目标分类，并取两者中靠前的位置；主分类变为目标分类的商家同步 type 字段
+func (r *categoryRepository) Merge(sourceID uint, target *model.Category) ([]int, error) {
+	var affected []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var links []model.BusinessCategory
+		if err := tx.Where("category_id = ?", sourceID).Find(&links).Error; err != nil {
+			return err
+		}
+
+		if len(links) > 0 {
+			for _, link := range links {
+				affected = append(affected, link.BusinessID)
+			}
+
+			var duplicates []model.BusinessCategory
+			if err := tx.Where("category_id = ? AND business_id IN ?", target.ID, affected).Find(&duplicates).Error; err != nil {
+				return err
+			}
+			sourcePosition := make(map[int]int, len(links))
+			for _, link := range links {
+				sourcePosition[link.BusinessID] = link.Position
+			}
+			duplicateIDs := make([]int, 0, len(duplicates))
+			for _, dup := range duplicates {
+				duplicateIDs = append(duplicateIDs, dup.BusinessID)
+				if position := sourcePosition[dup.BusinessID]; position < dup.Position {
+					if err := tx.Model(&model.BusinessCategory{}).
+						Where("business_id = ? AND category_id = ?", dup.BusinessID, target.ID).
+						Update("position", position).Error; err != nil {
+						return err
+					}
+				}
+			}
+
+			repoint := tx.Model(&model.BusinessCategory{}).Where("category_id = ?", sourceID)
+			if len(duplicateIDs) > 0 {
+				repoint = repoint.Where("business_id NOT IN ?", duplicateIDs)
+			}
+			if err := repoint.Update("category_id", target.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("category_id = ?", sourceID).Delete(&model.BusinessCategory{}).Error; err != nil {
+				return err
+			}
+
+			var primary []int
+			if err := tx.Model(&model.BusinessCategory{}).
+				Where("category_id = ? AND position = 0 AND business_id IN ?", target.ID, affected).
+				Pluck("business_id", &primary).Error; err != nil {
+				return err
+			}
+			if err := syncBusinessTypes(tx, primary, target.Slug); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.Category{}).Where("parent_id = ?", sourceID).Update("parent_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Category{}, sourceID).Error
+	})
+	return affected, err
+}
+
+// syncBusinessTypes 将商家 type 字段设为主分类标识，并递增版本号（含回收站中的商家）
+func syncBusinessTypes(tx *gorm.DB, businessIDs []int, slug string) error {
+	if len(businessIDs) == 0 {
+		return nil
+	}
+	return tx.Unscoped().Model(&model.Business{}).
+		Where("id IN ? AND type <> ?", businessIDs, slug).
+		Updates(map[string]interface{}{
+			"type":    slug,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+}
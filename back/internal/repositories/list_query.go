@@ -0,0 +1,390 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"merchant_back/internal/common"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListFieldKind 列表查询字段的取值类型
+type ListFieldKind int
+
+const (
+	ListFieldString ListFieldKind = iota // 字符串，支持 like
+	ListFieldNumber                      // 数值
+	ListFieldTime                        // 时间（RFC3339 或 2006-01-02）
+)
+
+// ListField 允许在列表查询中使用的字段
+type ListField struct {
+	Column   string        // 数据库列名
+	Kind     ListFieldKind // 取值类型
+	Sortable bool          // 是否允许排序
+	Nullable bool          // 是否可能为 NULL（不能用于游标分页的排序）
+	Within   string        // 条件所在的子查询（%s 处替换为字段条件），用于筛选关联表中的值
+	SortExpr string        // 排序使用的表达式，为空时按 Column 排序
+}
+
+// ListFields 列表查询字段白名单（JSON 字段名 → 列定义）
+type ListFields map[string]ListField
+
+// BusinessListFields 商家列表允许筛选与排序的字段
+var BusinessListFields = ListFields{
+	"id":          {Column: "id", Kind: ListFieldNumber, Sortable: true},
+	"name":        {Column: "name", Kind: ListFieldString, Sortable: true},
+	"email":       {Column: "email", Kind: ListFieldString, Sortable: true},
+	"type":        {Column: "type", Kind: ListFieldString, Sortable: true},
+	"status":      {Column: "status", Kind: ListFieldString, Sortable: true},
+	"rating":      {Column: "rating", Kind: ListFieldNumber, Sortable: true},
+	"ratingCount": {Column: "rating_count", Kind: ListFieldNumber, Sortable: true},
+	"country":     {Column: "country", Kind: ListFieldString, Sortable: true},
+	"province":    {Column: "province", Kind: ListFieldString, Sortable: true},
+	"city":        {Column: "city", Kind: ListFieldString, Sortable: true},
+	"district":    {Column: "district", Kind: ListFieldString, Sortable: true},
+	"postalCode":  {Column: "postal_code", Kind: ListFieldString},
+	"address":     {Column: "address", Kind: ListFieldString},
+	"phone":       {Column: "phone", Kind: ListFieldString},
+	"timezone":    {Column: "timezone", Kind: ListFieldString},
+	"createdAt":   {Column: "created_at", Kind: ListFieldTime, Sortable: true},
+	"updatedAt":   {Column: "updated_at", Kind: ListFieldTime, Sortable: true},
+	"tags":        {Column: "tag", Kind: ListFieldString, Within: "id IN (SELECT business_id FROM business_tags WHERE %s)"},
+	"brandId":     {Column: "brand_id", Kind: ListFieldNumber},
+}
+
+// BusinessAttributeListField 商家自定义属性字段（attributes.属性名），在属性索引表中筛选；
+// 数组属性任一元素满足条件即匹配，按最小值排序。未填写该属性的商家排序值为 NULL，不支持游标分页
+// name 须符合 common.AttributeNamePattern，会直接拼入 SQL
+func BusinessAttributeListField(name string, numeric bool) ListField {
+	column, kind := "string_value", ListFieldString
+	if numeric {
+		column, kind = "number_value", ListFieldNumber
+	}
+	return ListField{
+		Column:   column,
+		Kind:     kind,
+		Sortable: true,
+		Nullable: true,
+		Within:   "id IN (SELECT business_id FROM business_attribute_values WHERE name = '" + name + "' AND %s)",
+		SortExpr: "(SELECT MIN(v." + column + ") FROM business_attribute_values v WHERE v.business_id = business.id AND v.name = '" + name + "')",
+	}
+}
+
+// UserListFields 用户列表允许筛选与排序的字段（不含密码等敏感字段）
+var UserListFields = ListFields{
+	"id":        {Column: "id", Kind: ListFieldNumber, Sortable: true},
+	"username":  {Column: "username", Kind: ListFieldString, Sortable: true},
+	"email":     {Column: "email", Kind: ListFieldString, Sortable: true},
+	"firstName": {Column: "first_name", Kind: ListFieldString, Sortable: true},
+	"lastName":  {Column: "last_name", Kind: ListFieldString, Sortable: true},
+	"phone":     {Column: "phone", Kind: ListFieldString},
+	"role":      {Column: "role", Kind: ListFieldString, Sortable: true},
+	"status":    {Column: "status", Kind: ListFieldString, Sortable: true},
+	"lastLogin": {Column: "last_login", Kind: ListFieldTime, Sortable: true, Nullable: true},
+	"createdAt": {Column: "created_at", Kind: ListFieldTime, Sortable: true},
+	"updatedAt": {Column: "updated_at", Kind: ListFieldTime, Sortable: true},
+}
+
+// StatusTransitionListFields 商家状态变更历史允许筛选与排序的字段
+var StatusTransitionListFields = ListFields{
+	"id":         {Column: "id", Kind: ListFieldNumber, Sortable: true},
+	"fromStatus": {Column: "from_status", Kind: ListFieldString},
+	"toStatus":   {Column: "to_status", Kind: ListFieldString},
+	"actorId":    {Column: "actor_id", Kind: ListFieldNumber},
+	"reasonCode": {Column: "reason_code", Kind: ListFieldString},
+	"createdAt":  {Column: "created_at", Kind: ListFieldTime, Sortable: true},
+}
+
+// ReviewListFields 顾客评价列表允许筛选与排序的字段
+var ReviewListFields = ListFields{
+	"id":         {Column: "id", Kind: ListFieldNumber, Sortable: true},
+	"businessId": {Column: "business_id", Kind: ListFieldNumber},
+	"stars":      {Column: "stars", Kind: ListFieldNumber, Sortable: true},
+	"status":     {Column: "status", Kind: ListFieldString},
+	"userId":     {Column: "user_id", Kind: ListFieldNumber},
+	"createdAt":  {Column: "created_at", Kind: ListFieldTime, Sortable: true},
+	"updatedAt":  {Column: "updated_at", Kind: ListFieldTime, Sortable: true},
+}
+
+// BrandListFields 品牌列表允许筛选与排序的字段
+var BrandListFields = ListFields{
+	"id":         {Column: "id", Kind: ListFieldNumber, Sortable: true},
+	"name":       {Column: "name", Kind: ListFieldString, Sortable: true},
+	"email":      {Column: "email", Kind: ListFieldString},
+	"categoryId": {Column: "category_id", Kind: ListFieldNumber},
+	"createdAt":  {Column: "created_at", Kind: ListFieldTime, Sortable: true},
+	"updatedAt":  {Column: "updated_at", Kind: ListFieldTime, Sortable: true},
+}
+
+// likeEscaper 转义 LIKE 通配符，模糊匹配按字面量处理
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ApplyListFilters 将筛选条件编译为参数化查询，字段不在白名单中时返回参数错误
+// 列名只取自白名单，取值一律作为参数传递
+func ApplyListFilters(db *gorm.DB, q *common.ListQuery, fields ListFields) (*gorm.DB, error) {
+	for _, f := range q.Filters {
+		spec, ok := fields[f.Field]
+		if !ok {
+			return nil, common.InvalidListQuery("字段 " + f.Field + " 不支持筛选")
+		}
+
+		var cond string
+		var args []interface{}
+		if f.Op == common.FilterOpLike {
+			if spec.Kind != ListFieldString {
+				return nil, common.InvalidListQuery("字段 " + f.Field + " 不支持 like 条件")
+			}
+			cond = spec.Column + " LIKE ?"
+			args = []interface{}{"%" + likeEscaper.Replace(f.Values[0]) + "%"}
+		} else {
+			values := make([]interface{}, len(f.Values))
+			for i, raw := range f.Values {
+				v, err := spec.parseValue(raw)
+				if err != nil {
+					return nil, common.InvalidListQuery("字段 " + f.Field + " 的取值 " + raw + " 无效")
+				}
+				values[i] = v
+			}
+
+			switch f.Op {
+			case common.FilterOpEq:
+				cond, args = spec.Column+" = ?", values[:1]
+			case common.FilterOpIn:
+				cond, args = spec.Column+" IN ?", []interface{}{values}
+			case common.FilterOpGte:
+				cond, args = spec.Column+" >= ?", values[:1]
+			case common.FilterOpLte:
+				cond, args = spec.Column+" <= ?", values[:1]
+			case common.FilterOpBetween:
+				cond, args = spec.Column+" BETWEEN ? AND ?", values[:2]
+			default:
+				return nil, common.InvalidListQuery("不支持的筛选操作符: " + f.Op)
+			}
+		}
+
+		if spec.Within != "" {
+			cond = fmt.Sprintf(spec.Within, cond)
+		}
+		db = db.Where(cond, args...)
+	}
+	return db, nil
+}
+
+// ApplyListSort 按排序条件追加 ORDER BY，未指定时按 id 升序；始终以 id 兜底保证分页稳定
+func ApplyListSort(db *gorm.DB, q *common.ListQuery, fields ListFields) (*gorm.DB, error) {
+	hasID := false
+	for _, s := range q.Sorts {
+		spec, ok := fields[s.Field]
+		if !ok || !spec.Sortable {
+			return nil, common.InvalidListQuery("字段 " + s.Field + " 不支持排序")
+		}
+		if spec.Column == "id" {
+			hasID = true
+		}
+		column := clause.Column{Name: spec.Column}
+		if spec.SortExpr != "" {
+			column = clause.Column{Name: spec.SortExpr, Raw: true}
+		}
+		db = db.Order(clause.OrderByColumn{Column: column, Desc: s.Desc})
+	}
+	if !hasID {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+	}
+	return db, nil
+}
+
+// ApplyListPage 分页时追加 OFFSET/LIMIT
+func ApplyListPage(db *gorm.DB, q *common.ListQuery) *gorm.DB {
+	if !q.Paged() {
+		return db
+	}
+	return db.Offset(q.Offset()).Limit(q.PageSize)
+}
+
+// cursorKey 游标分页的一个排序键
+type cursorKey struct {
+	field  string // JSON 字段名
+	column string // 数据库列名
+	kind   ListFieldKind
+	desc   bool
+}
+
+// cursorKeys 返回游标分页的排序键：排序字段加 id 兜底，保证每行位置唯一
+func cursorKeys(q *common.ListQuery, fields ListFields) ([]cursorKey, error) {
+	keys := make([]cursorKey, 0, len(q.Sorts)+1)
+	hasID := false
+	for _, s := range q.Sorts {
+		spec, ok := fields[s.Field]
+		if !ok || !spec.Sortable {
+			return nil, common.InvalidListQuery("字段 " + s.Field + " 不支持排序")
+		}
+		if spec.Nullable {
+			return nil, common.InvalidListQuery("字段 " + s.Field + " 可能为空，不支持游标分页排序")
+		}
+		if spec.Column == "id" {
+			hasID = true
+		}
+		keys = append(keys, cursorKey{field: s.Field, column: spec.Column, kind: spec.Kind, desc: s.Desc})
+	}
+	if !hasID {
+		keys = append(keys, cursorKey{field: "id", column: "id", kind: ListFieldNumber})
+	}
+	return keys, nil
+}
+
+// ApplyListCursor 按游标追加键集条件、排序与 LIMIT（多取一条用于判断是否还有更多）
+// scope 为列表标识，游标必须来自同一列表且排序条件一致
+// 取上一页时排序方向反转，结果由 ListCursorPage 恢复为正常顺序
+func ApplyListCursor(db *gorm.DB, q *common.ListQuery, fields ListFields, scope string) (*gorm.DB, error) {
+	keys, err := cursorKeys(q, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	before := false
+	if cursor := q.Cursor; cursor != nil {
+		if cursor.Scope != scope || cursor.Sort != q.SortSignature() || len(cursor.Values) != len(keys) {
+			return nil, common.InvalidListQuery("游标与当前列表或排序条件不匹配")
+		}
+		before = cursor.Before
+
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			v, err := key.cursorValue(cursor.Values[i])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+
+		// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...，倒序字段或取上一页时比较方向相反
+		var clauses []string
+		var args []interface{}
+		for i, key := range keys {
+			op := " > ?"
+			if key.desc != before {
+				op = " < ?"
+			}
+			var parts []string
+			for j := 0; j < i; j++ {
+				parts = append(parts, keys[j].column+" = ?")
+				args = append(args, values[j])
+			}
+			parts = append(parts, key.column+op)
+			args = append(args, values[i])
+			clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+		}
+		db = db.Where("("+strings.Join(clauses, " OR ")+")", args...)
+	}
+
+	for _, key := range keys {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: key.column}, Desc: key.desc != before})
+	}
+	return db.Limit(q.PageSize + 1), nil
+}
+
+// ListCursorPage 截去多取的一条记录，恢复上一页的顺序，并生成上一页/下一页游标
+func ListCursorPage[T any](rows []T, q *common.ListQuery, fields ListFields, scope string) ([]T, string, string, error) {
+	keys, err := cursorKeys(q, fields)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	before := q.Cursor != nil && q.Cursor.Before
+	hasMore := len(rows) > q.PageSize
+	if hasMore {
+		rows = rows[:q.PageSize]
+	}
+	if before {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, "", "", nil
+	}
+
+	// 向后翻页时，有游标即说明存在上一页；向前翻页时，来源页即下一页
+	hasNext, hasPrev := hasMore, q.Cursor != nil
+	if before {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	var next, prev string
+	if hasNext {
+		if next, err = encodeRowCursor(rows[len(rows)-1], keys, q, scope, false); err != nil {
+			return nil, "", "", err
+		}
+	}
+	if hasPrev {
+		if prev, err = encodeRowCursor(rows[0], keys, q, scope, true); err != nil {
+			return nil, "", "", err
+		}
+	}
+	return rows, next, prev, nil
+}
+
+// encodeRowCursor 以记录的排序字段值生成游标
+func encodeRowCursor(row interface{}, keys []cursorKey, q *common.ListQuery, scope string, before bool) (string, error) {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return "", err
+	}
+	var fieldValues map[string]interface{}
+	if err := json.Unmarshal(data, &fieldValues); err != nil {
+		return "", err
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = fieldValues[key.field]
+	}
+	return common.EncodeListCursor(&common.ListCursor{
+		Scope:  scope,
+		Sort:   q.SortSignature(),
+		Values: values,
+		Before: before,
+	})
+}
+
+// cursorValue 将游标中的 JSON 值还原为查询参数
+func (k cursorKey) cursorValue(v interface{}) (interface{}, error) {
+	switch k.kind {
+	case ListFieldNumber:
+		if n, ok := v.(float64); ok {
+			return n, nil
+		}
+	case ListFieldTime:
+		if str, ok := v.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, str); err == nil {
+				return t, nil
+			}
+		}
+	default:
+		if str, ok := v.(string); ok {
+			return str, nil
+		}
+	}
+	return nil, common.InvalidListQuery("游标无效")
+}
+
+// parseValue 按字段类型转换筛选值
+func (f ListField) parseValue(raw string) (interface{}, error) {
+	switch f.Kind {
+	case ListFieldNumber:
+		return strconv.ParseFloat(raw, 64)
+	case ListFieldTime:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		return time.ParseInLocation("2006-01-02", raw, time.Local)
+	default:
+		return raw, nil
+	}
+}
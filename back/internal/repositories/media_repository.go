@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"time"
+
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MediaRepository 媒体文件仓储接口
+type MediaRepository interface {
+	Create(media *model.Media) error
+	GetByID(id uint) (*model.Media, error)
+	Delete(id uint) error
+	// CountBusinessReferences 统计引用该媒体文件的商家图片（含回收站中的商家）、品牌标志、相册照片与评价照片数
+	CountBusinessReferences(id uint) (int64, error)
+	GetByIDs(ids []uint) ([]*model.Media, error)
+	// LoadVariants 加载媒体文件的缩略图
+	LoadVariants(media []*model.Media) error
+	// PendingIDs 待处理的媒体文件ID：等待处理的，及开始处理时间早于 staleBefore 仍未完成的（处理进程中断）
+	PendingIDs(staleBefore time.Time, limit int) ([]uint, error)
+	// Claim 标记为处理中并增加尝试次数，已被其他进程处理时返回 false
+	Claim(id uint, staleBefore, now time.Time) (bool, error)
+	// SaveProcessed 保存处理结果：更新原图信息并替换缩略图记录
+	SaveProcessed(media *model.Media, variants []*model.MediaVariant) error
+	// SetStatus 更新处理状态
+	SetStatus(id uint, status string) error
+}
+
+// mediaRepository 媒体文件仓储实现
+type mediaRepository struct {
+	db *gorm.DB
+}
+
+// NewMediaRepository 创建媒体文件仓储实例
+func NewMediaRepository(db *gorm.DB) MediaRepository {
+	return &mediaRepository{
+		db: db,
+	}
+}
+
+// Create 创建媒体文件记录
+func (r *mediaRepository) Create(media *model.Media) error {
+	return r.db.Create(media).Error
+}
+
+// GetByID 根据ID获取媒体文件
+func (r *mediaRepository) GetByID(id uint) (*model.Media, error) {
+	var media model.Media
+	err := r.db.First(&media, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// Delete 删除媒体文件及其缩略图记录
+func (r *mediaRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", id).Delete(&model.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Media{}, id).Error
+	})
+}
+
+// CountBusinessReferences 统计引用该媒体文件的商家图片、品牌标志、相册照片与评价照片数
+func (r *mediaRepository) CountBusinessReferences(id uint) (int64, error) {
+	var images, logos, photos, reviews int64
+	if err := r.db.Unscoped().Model(&model.Business{}).Where("image_media_id = ?", id).Count(&images).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.Brand{}).Where("logo_media_id = ?", id).Count(&logos).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.BusinessPhoto{}).Where("media_id = ?", id).Count(&photos).Error; err != nil {
+		return 0, err
+	}
+	err := r.db.Model(&model.Review{}).Where("JSON_CONTAINS(photo_media_ids, CAST(? AS JSON))", id).Count(&reviews).Error
+	return images + logos + photos + reviews, err
+}
+
+// GetByIDs 根据ID批量获取媒体文件
+func (r *mediaRepository) GetByIDs(ids []uint) ([]*model.Media, error) {
+	var media []*model.Media
+	if len(ids) == 0 {
+		return media, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&media).Error
+	return media, err
+}
+
+// LoadVariants 加载媒体文件的缩略图，按规格与类型排序
+func (r *mediaRepository) LoadVariants(media []*model.Media) error {
+	if len(media) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(media))
+	byID := make(map[uint]*model.Media, len(media))
+	for i, m := range media {
+		ids[i] = m.ID
+		byID[m.ID] = m
+		m.Variants = nil
+	}
+
+	var variants []*model.MediaVariant
+	if err := r.db.Where("media_id IN ?", ids).Order("media_id, name, content_type").Find(&variants).Error; err != nil {
+		return err
+	}
+	for _, v := range variants {
+		if m := byID[v.MediaID]; m != nil {
+			m.Variants = append(m.Variants, v)
+		}
+	}
+	return nil
+}
+
+// PendingIDs 待处理的媒体文件ID，按上传顺序
+func (r *mediaRepository) PendingIDs(staleBefore time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.Media{}).
+		Where("status = ? OR (status = ? AND processing_at < ?)", model.MediaStatusPending, model.MediaStatusProcessing, staleBefore).
+		Order("id").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Claim 以条件更新抢占处理权，避免上传后的即时处理与后台任务重复处理
+func (r *mediaRepository) Claim(id uint, staleBefore, now time.Time) (bool, error) {
+	result := r.db.Model(&model.Media{}).
+		Where("id = ? AND (status = ? OR (status = ? AND processing_at < ?))", id, model.MediaStatusPending, model.MediaStatusProcessing, staleBefore).
+		Updates(map[string]interface{}{
+			"status":        model.MediaStatusProcessing,
+			"processing_at": now,
+			"attempts":      gorm.Expr("attempts + 1"),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// SaveProcessed 在事务中更新原图信息并替换缩略图记录
+func (r *mediaRepository) SaveProcessed(media *model.Media, variants []*model.MediaVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Media{}).Where("id = ?", media.ID).Updates(map[string]interface{}{
+			"storage_key":    media.StorageKey,
+			"size":           media.Size,
+			"width":          media.Width,
+			"height":         media.Height,
+			"checksum":       media.Checksum,
+			"dominant_color": media.DominantColor,
+			"status":         media.Status,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("media_id = ?", media.ID).Delete(&model.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		if len(variants) == 0 {
+			return nil
+		}
+		return tx.Create(&variants).Error
+	})
+}
+
+// SetStatus 更新处理状态
+func (r *mediaRepository) SetStatus(id uint, status string) error {
+	return r.db.Model(&model.Media{}).Where("id = ?", id).Update("status", status).Error
+}
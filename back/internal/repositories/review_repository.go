@@ -0,0 +1,364 @@
+package repositories
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReviewRepository 顾客评价仓储接口
+// 评价的发表、修改与删除在同一事务中增量更新商家评分汇总（business_ratings）及商家的 rating、rating_count
+type ReviewRepository interface {
+	// Create 发表评价并计入评分，同时写入审核记录
+	Create(review *model.Review, logs ...*model.ReviewModerationLog) error
+	// Update 保存评价，按修改前后的星级与审核状态调整评分，同时写入审核记录
+	// 审核记录的评价ID与前后状态由仓储按实际修改填写
+	Update(review *model.Review, logs ...*model.ReviewModerationLog) error
+	// Delete 删除评价及其审核记录，已计入评分的从评分中扣除
+	Delete(id uint) error
+	GetByID(id uint) (*model.Review, error)
+	GetByBusinessUser(businessID int, userID uint) (*model.Review, error)
+	// ListByBusiness 按统一列表查询条件获取商家的评价，statuses 为空时不按审核状态筛选，未指定排序时按发表时间倒序
+	ListByBusiness(businessID int, statuses []string, q *common.ListQuery) ([]*model.Review, *common.ListPageInfo, error)
+	// ListQueue 获取审核队列（按发表先后），未按 status 筛选时只含待审核的评价；flag 不为空时只含带有该标记的评价
+	ListQueue(flag string, q *common.ListQuery) ([]*model.Review, *common.ListPageInfo, error)
+	// GetModerationLogs 获取评价的审核记录（按时间先后）
+	GetModerationLogs(reviewID uint) ([]*model.ReviewModerationLog, error)
+
+	// 自动审核规则使用的统计
+	// FindDuplicate 查找内容摘要相同的其他评价
+	FindDuplicate(contentHash string, excludeID uint) (*model.Review, error)
+	CountByUserSince(userID uint, since time.Time) (int64, error)
+	CountByIPSince(clientIP string, since time.Time) (int64, error)
+	CountByBusinessSince(businessID int, since time.Time) (int64, error)
+
+	// GetRating 获取商家评分汇总，尚无评价时返回全零的汇总
+	GetRating(businessID int) (*model.BusinessRating, error)
+	// RebuildRatings 按已公开的评价重新汇总全部商家评分，返回评分有变化的商家数
+	RebuildRatings() (int64, error)
+}
+
+// reviewRepository 顾客评价仓储实现
+type reviewRepository struct {
+	db       *gorm.DB
+	settings model.RatingSettings
+}
+
+// NewReviewRepository 创建顾客评价仓储实例，settings 决定商家评分取算术平均还是贝叶斯平均
+func NewReviewRepository(db *gorm.DB, settings model.RatingSettings) ReviewRepository {
+	return &reviewRepository{
+		db:       db,
+		settings: settings,
+	}
+}
+
+// averageSQL 由计数重新计算汇总中的平均值（MySQL 的 UPDATE 按顺序赋值，计算时计数已更新）
+const averageSQL = "average = IF(review_count > 0, stars_sum / review_count, 0), " +
+	"bayesian = IF(review_count > 0, (? * ? + stars_sum) / (? + review_count), 0)"
+
+// averageArgs averageSQL 的参数
+func (r *reviewRepository) averageArgs() []interface{} {
+	return []interface{}{r.settings.PriorMean, r.settings.PriorCount, r.settings.PriorCount}
+}
+
+// ratingColumn 商家评分取用的汇总列
+func (r *reviewRepository) ratingColumn() string {
+	if r.settings.Bayesian {
+		return "bayesian"
+	}
+	return "average"
+}
+
+// applyRating 将评价的星级计入（sign 为 1）或扣出（sign 为 -1）商家评分，并同步商家的 rating、rating_count
+// 评分不是商家资料，变化时不增加商家版本号，以免顾客评价导致商家编辑时的版本冲突
+func (r *reviewRepository) applyRating(tx *gorm.DB, businessID, stars, sign int) error {
+	if stars < 1 || stars > 5 {
+		return nil
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.BusinessRating{BusinessID: businessID}).Error; err != nil {
+		return err
+	}
+
+	star := "star" + strconv.Itoa(stars)
+	args := append([]interface{}{sign, sign * stars, sign}, r.averageArgs()...)
+	args = append(args, businessID)
+	if err := tx.Exec("UPDATE business_ratings SET review_count = review_count + ?, stars_sum = stars_sum + ?, "+
+		star+" = "+star+" + ?, "+averageSQL+", updated_at = NOW() WHERE business_id = ?", args...).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec("UPDATE business b JOIN business_ratings r ON r.business_id = b.id "+
+		"SET b.rating = ROUND(r."+r.ratingColumn()+", 2), b.rating_count = r.review_count WHERE b.id = ?", businessID).Error
+}
+
+// writeLogs 写入审核记录
+func writeLogs(tx *gorm.DB, review *model.Review, fromStatus string, logs []*model.ReviewModerationLog) error {
+	for _, log := range logs {
+		log.ReviewID = review.ID
+		log.FromStatus = fromStatus
+		log.ToStatus = review.Status
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create 发表评价
+func (r *reviewRepository) Create(review *model.Review, logs ...*model.ReviewModerationLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if err := writeLogs(tx, review, "", logs); err != nil {
+			return err
+		}
+		if !review.Counted() {
+			return nil
+		}
+		return r.applyRating(tx, review.BusinessID, review.Stars, 1)
+	})
+}
+
+// Update 保存评价，锁定原记录以按实际的修改前状态调整评分
+func (r *reviewRepository) Update(review *model.Review, logs ...*model.ReviewModerationLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var previous model.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&previous, review.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(review).
+			Select("stars", "content", "content_hash", "photo_media_ids", "status", "flags",
+				"moderation_reason", "moderation_note", "moderated_by", "moderated_at", "reply", "replied_by", "replied_at").
+			Updates(review).Error; err != nil {
+			return err
+		}
+		if err := writeLogs(tx, review, previous.Status, logs); err != nil {
+			return err
+		}
+
+		if previous.Counted() == review.Counted() && previous.Stars == review.Stars {
+			return nil
+		}
+		if previous.Counted() {
+			if err := r.applyRating(tx, previous.BusinessID, previous.Stars, -1); err != nil {
+				return err
+			}
+		}
+		if review.Counted() {
+			return r.applyRating(tx, review.BusinessID, review.Stars, 1)
+		}
+		return nil
+	})
+}
+
+// Delete 删除评价
+func (r *reviewRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var review model.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&review).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", review.ID).Delete(&model.ReviewModerationLog{}).Error; err != nil {
+			return err
+		}
+		if !review.Counted() {
+			return nil
+		}
+		return r.applyRating(tx, review.BusinessID, review.Stars, -1)
+	})
+}
+
+// GetByID 根据ID获取评价
+func (r *reviewRepository) GetByID(id uint) (*model.Review, error) {
+	var review model.Review
+	if err := r.db.First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetByBusinessUser 获取用户对商家的评价
+func (r *reviewRepository) GetByBusinessUser(businessID int, userID uint) (*model.Review, error) {
+	var review model.Review
+	if err := r.db.Where("business_id = ? AND user_id = ?", businessID, userID).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ListByBusiness 获取商家的评价，支持筛选、偏移分页与游标分页（默认按发表先后倒序）
+func (r *reviewRepository) ListByBusiness(businessID int, statuses []string, q *common.ListQuery) ([]*model.Review, *common.ListPageInfo, error) {
+	if len(q.Sorts) == 0 {
+		sorted := *q
+		sorted.Sorts = []common.ListSort{{Field: "id", Desc: true}}
+		q = &sorted
+	}
+
+	query := r.db.Model(&model.Review{}).Where("business_id = ?", businessID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	query, err := ApplyListFilters(query, q, ReviewListFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &common.ListPageInfo{}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var reviews []*model.Review
+	if q.CursorMode {
+		if query, err = ApplyListCursor(query, q, ReviewListFields, "reviews"); err != nil {
+			return nil, nil, err
+		}
+		if err := query.Find(&reviews).Error; err != nil {
+			return nil, nil, err
+		}
+		reviews, page.NextCursor, page.PrevCursor, err = ListCursorPage(reviews, q, ReviewListFields, "reviews")
+		return reviews, page, err
+	}
+
+	if query, err = ApplyListSort(query, q, ReviewListFields); err != nil {
+		return nil, nil, err
+	}
+	err = ApplyListPage(query, q).Find(&reviews).Error
+	return reviews, page, err
+}
+
+// ListQueue 获取审核队列，支持筛选、偏移分页与游标分页（默认按发表先后）
+func (r *reviewRepository) ListQueue(flag string, q *common.ListQuery) ([]*model.Review, *common.ListPageInfo, error) {
+	if len(q.Sorts) == 0 {
+		sorted := *q
+		sorted.Sorts = []common.ListSort{{Field: "id"}}
+		q = &sorted
+	}
+
+	query := r.db.Model(&model.Review{})
+	statusFiltered := false
+	for _, filter := range q.Filters {
+		if filter.Field == "status" {
+			statusFiltered = true
+		}
+	}
+	if !statusFiltered {
+		query = query.Where("status = ?", model.ReviewStatusPending)
+	}
+	if flag != "" {
+		query = query.Where("JSON_SEARCH(flags, 'one', ?, NULL, '$[*].code') IS NOT NULL", flag)
+	}
+	query, err := ApplyListFilters(query, q, ReviewListFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &common.ListPageInfo{}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var reviews []*model.Review
+	if q.CursorMode {
+		if query, err = ApplyListCursor(query, q, ReviewListFields, "review-queue"); err != nil {
+			return nil, nil, err
+		}
+		if err := query.Find(&reviews).Error; err != nil {
+			return nil, nil, err
+		}
+		reviews, page.NextCursor, page.PrevCursor, err = ListCursorPage(reviews, q, ReviewListFields, "review-queue")
+		return reviews, page, err
+	}
+
+	if query, err = ApplyListSort(query, q, ReviewListFields); err != nil {
+		return nil, nil, err
+	}
+	err = ApplyListPage(query, q).Find(&reviews).Error
+	return reviews, page, err
+}
+
+// GetModerationLogs 获取评价的审核记录
+func (r *reviewRepository) GetModerationLogs(reviewID uint) ([]*model.ReviewModerationLog, error) {
+	var logs []*model.ReviewModerationLog
+	err := r.db.Where("review_id = ?", reviewID).Order("id ASC").Find(&logs).Error
+	return logs, err
+}
+
+// FindDuplicate 查找内容摘要相同的其他评价（最早的一条）
+func (r *reviewRepository) FindDuplicate(contentHash string, excludeID uint) (*model.Review, error) {
+	var review model.Review
+	if err := r.db.Where("content_hash = ? AND id <> ?", contentHash, excludeID).Order("id ASC").First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// CountByUserSince 统计用户在指定时间后发表的评价数
+func (r *reviewRepository) CountByUserSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Review{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&count).Error
+	return count, err
+}
+
+// CountByIPSince 统计同一 IP 在指定时间后发表的评价数
+func (r *reviewRepository) CountByIPSince(clientIP string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Review{}).Where("client_ip = ? AND created_at >= ?", clientIP, since).Count(&count).Error
+	return count, err
+}
+
+// CountByBusinessSince 统计商家在指定时间后收到的评价数
+func (r *reviewRepository) CountByBusinessSince(businessID int, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Review{}).Where("business_id = ? AND created_at >= ?", businessID, since).Count(&count).Error
+	return count, err
+}
+
+// GetRating 获取商家评分汇总
+func (r *reviewRepository) GetRating(businessID int) (*model.BusinessRating, error) {
+	rating := model.BusinessRating{BusinessID: businessID}
+	if err := r.db.Where("business_id = ?", businessID).First(&rating).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// RebuildRatings 重新汇总全部商家评分：修正增量维护可能产生的偏差，并使评分计算方式的配置变更生效
+func (r *reviewRepository) RebuildRatings() (int64, error) {
+	var changed int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO business_ratings (business_id, review_count, stars_sum, star1, star2, star3, star4, star5, updated_at) "+
+			"SELECT business_id, COUNT(*), SUM(stars), SUM(stars = 1), SUM(stars = 2), SUM(stars = 3), SUM(stars = 4), SUM(stars = 5), NOW() "+
+			"FROM reviews WHERE status = ? GROUP BY business_id "+
+			"ON DUPLICATE KEY UPDATE review_count = VALUES(review_count), stars_sum = VALUES(stars_sum), star1 = VALUES(star1), "+
+			"star2 = VALUES(star2), star3 = VALUES(star3), star4 = VALUES(star4), star5 = VALUES(star5), updated_at = NOW()",
+			model.ReviewStatusApproved).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE business_ratings SET review_count = 0, stars_sum = 0, star1 = 0, star2 = 0, star3 = 0, star4 = 0, star5 = 0, updated_at = NOW() "+
+			"WHERE review_count <> 0 AND business_id NOT IN (SELECT business_id FROM reviews WHERE status = ?)",
+			model.ReviewStatusApproved).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE business_ratings SET "+averageSQL, r.averageArgs()...).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec("UPDATE business b LEFT JOIN business_ratings r ON r.business_id = b.id " +
+			"SET b.rating = COALESCE(ROUND(r." + r.ratingColumn() + ", 2), 0), b.rating_count = COALESCE(r.review_count, 0)")
+		changed = result.RowsAffected
+		return result.Error
+	})
+	return changed, err
+}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	model "merchant_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSettlementChangePending 商家已有待审批的结算账户变更申请
+var ErrSettlementChangePending = errors.New("该商家已有待审批的结算账户变更申请")
+
+// ErrSettlementChangeNotPending 变更申请已被处理
+var ErrSettlementChangeNotPending = errors.New("变更申请已处理")
+
+// SettlementAccountRepository 结算账户仓储接口
+type SettlementAccountRepository interface {
+	GetByBusiness(businessID int) (*model.SettlementAccount, error)
+	// CreateChange 创建变更申请，已有待审批申请时返回 ErrSettlementChangePending
+	CreateChange(change *model.SettlementAccountChange) error
+	GetChange(id uint) (*model.SettlementAccountChange, error)
+	// ListChanges 获取商家的变更申请（最新在前）
+	ListChanges(businessID int) ([]*model.SettlementAccountChange, error)
+	// ListPendingChanges 获取全部待审批的变更申请（最早在前）
+	ListPendingChanges() ([]*model.SettlementAccountChange, error)
+	// ApproveChange 通过变更申请并更新商家账户，申请已被处理时返回 ErrSettlementChangeNotPending
+	ApproveChange(change *model.SettlementAccountChange, reviewerID uint) (*model.SettlementAccount, error)
+	// CloseChange 驳回或撤回变更申请，申请已被处理时返回 ErrSettlementChangeNotPending
+	CloseChange(change *model.SettlementAccountChange, status string, actorID uint, note *string) error
+}
+
+// settlementAccountRepository 结算账户仓储实现
+type settlementAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewSettlementAccountRepository 创建结算账户仓储实例
+func NewSettlementAccountRepository(db *gorm.DB) SettlementAccountRepository {
+	return &settlementAccountRepository{
+		db: db,
+	}
+}
+
+// GetByBusiness 获取商家的结算账户
+func (r *settlementAccountRepository) GetByBusiness(businessID int) (*model.SettlementAccount, error) {
+	var account model.SettlementAccount
+	if err := r.db.Where("business_id = ?", businessID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateChange 锁定商家行后检查待审批申请，同一商家的申请因此串行创建
+func (r *settlementAccountRepository) CreateChange(change *model.SettlementAccountChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var business model.Business
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&business, change.BusinessID).Error; err != nil {
+			return err
+		}
+
+		var pending int64
+		if err := tx.Model(&model.SettlementAccountChange{}).
+			Where("business_id = ? AND status = ?", change.BusinessID, model.SettlementChangeStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrSettlementChangePending
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// GetChange 根据ID获取变更申请
+func (r *settlementAccountRepository) GetChange(id uint) (*model.SettlementAccountChange, error) {
+	var change model.SettlementAccountChange
+	if err := r.db.First(&change, id).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ListChanges 获取商家的变更申请
+func (r *settlementAccountRepository) ListChanges(businessID int) ([]*model.SettlementAccountChange, error) {
+	var changes []*model.SettlementAccountChange
+	err := r.db.Where("business_id = ?", businessID).Order("id DESC").Find(&changes).Error
+	return changes, err
+}
+
+// ListPendingChanges 获取待审批的变更申请
+func (r *settlementAccountRepository) ListPendingChanges() ([]*model.SettlementAccountChange, error) {
+	var changes []*model.SettlementAccountChange
+	err := r.db.Where("status = ?", model.SettlementChangeStatusPending).Order("id ASC").Find(&changes).Error
+	return changes, err
+}
+
+// ApproveChange 在同一事务中更新商家账户并记录变更前的账户信息
+func (r *settlementAccountRepository) ApproveChange(change *model.SettlementAccountChange, reviewerID uint) (*model.SettlementAccount, error) {
+	var account model.SettlementAccount
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current model.SettlementAccountChange
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, change.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != model.SettlementChangeStatusPending {
+			return ErrSettlementChangeNotPending
+		}
+
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("business_id = ?", current.BusinessID).First(&account).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		current.PreviousAccountName = account.AccountName
+		current.PreviousBankName = account.BankName
+		current.PreviousLast4 = account.AccountLast4
+
+		account.BusinessID = current.BusinessID
+		account.AccountName = current.AccountName
+		account.BankName = current.BankName
+		account.BranchName = current.BranchName
+		account.AccountNumberCipher = current.AccountNumberCipher
+		account.AccountLast4 = current.AccountLast4
+		account.ChangeID = current.ID
+		account.ApprovedBy = reviewerID
+		account.ApprovedAt = now
+		if err := tx.Save(&account).Error; err != nil {
+			return err
+		}
+
+		current.Status = model.SettlementChangeStatusApproved
+		current.ReviewedBy = &reviewerID
+		current.ReviewedAt = &now
+		if err := tx.Model(&current).
+			Select("status", "reviewed_by", "reviewed_at", "previous_account_name", "previous_bank_name", "previous_last4").
+			Updates(&current).Error; err != nil {
+			return err
+		}
+		*change = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CloseChange 仅在申请仍为待审批时更新状态
+func (r *settlementAccountRepository) CloseChange(change *model.SettlementAccountChange, status string, actorID uint, note *string) error {
+	now := time.Now()
+	result := r.db.Model(&model.SettlementAccountChange{}).
+		Where("id = ? AND status = ?", change.ID, model.SettlementChangeStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": actorID,
+			"reviewed_at": now,
+			"review_note": note,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSettlementChangeNotPending
+	}
+	change.Status = status
+	change.ReviewedBy = &actorID
+	change.ReviewedAt = &now
+	change.ReviewNote = note
+	return nil
+}
@@ -1,30 +1,188 @@
 package routes
 
 import (
+	"context"
+	"log"
+	"merchant_back/internal/common"
+	"merchant_back/internal/config"
 	"merchant_back/internal/controllers"
+	"merchant_back/internal/imaging"
+	"merchant_back/internal/jobs"
 	"merchant_back/internal/middleware"
+	model "merchant_back/internal/models"
 	"merchant_back/internal/repositories"
 	"merchant_back/internal/services"
+	"merchant_back/internal/storage"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(db *gorm.DB) *gin.Engine {
+func SetupRoutes(db *gorm.DB, cfg *config.Config, scheduler *jobs.Scheduler) *gin.Engine {
 	r := gin.Default()
 
 	// 创建仓储层实例
 	businessRepo := repositories.NewBusinessRepository(db)
+	businessStatusRepo := repositories.NewBusinessStatusRepository(db)
+	businessRevisionRepo := repositories.NewBusinessRevisionRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	businessAttributeRepo := repositories.NewBusinessAttributeRepository(db)
+	mediaRepo := repositories.NewMediaRepository(db)
+	businessPhotoRepo := repositories.NewBusinessPhotoRepository(db)
+	brandRepo := repositories.NewBrandRepository(db)
+	businessMemberRepo := repositories.NewBusinessMemberRepository(db)
+	businessDocumentRepo := repositories.NewBusinessDocumentRepository(db)
+	businessContractRepo := repositories.NewBusinessContractRepository(db)
+	settlementAccountRepo := repositories.NewSettlementAccountRepository(db)
+	reviewRepo := repositories.NewReviewRepository(db, model.RatingSettings{
+		Bayesian:   cfg.Review.Bayesian,
+		PriorMean:  cfg.Review.PriorMean,
+		PriorCount: cfg.Review.PriorCount,
+	})
+
+	// 创建媒体存储
+	mediaStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatal("Failed to init media storage: ", err)
+	}
+	var webpEncoder imaging.WebPEncoder
+	if cfg.Storage.WebPEncoder != "" {
+		encoder, err := imaging.NewCWebPEncoder(cfg.Storage.WebPEncoder)
+		if err != nil {
+			log.Fatal("Failed to init WebP encoder: ", err)
+		}
+		webpEncoder = encoder
+	}
+
+	// 结算账户账号加密，未配置密钥时只能查看已有账户
+	var settlementCipher *common.FieldCipher
+	if cfg.Settlement.EncryptionKey != "" {
+		key, err := common.ParseFieldKey(cfg.Settlement.EncryptionKey)
+		if err != nil {
+			log.Fatal("Invalid SETTLEMENT_ENCRYPTION_KEY: ", err)
+		}
+		if settlementCipher, err = common.NewFieldCipher(key); err != nil {
+			log.Fatal("Failed to init settlement account cipher: ", err)
+		}
+	} else {
+		log.Println("SETTLEMENT_ENCRYPTION_KEY is not set, settlement account changes are disabled")
+	}
 
 	// 创建服务层实例
-	businessService := services.NewBusinessService(businessRepo)
+	businessSearch := services.NewBusinessSearchBackend(db, cfg.Search.Backend)
+	mediaService := services.NewMediaService(mediaRepo, userRepo, mediaStorage, cfg.Storage.MaxUploadSize, webpEncoder)
+	businessService := services.NewBusinessService(businessRepo, businessStatusRepo, businessRevisionRepo, userRepo, categoryRepo, businessAttributeRepo, brandRepo, businessMemberRepo, mediaService, businessSearch)
 	userService := services.NewUserService(db)
+	categoryService := services.NewCategoryService(categoryRepo, businessService)
+	businessPhotoService := services.NewBusinessPhotoService(businessPhotoRepo, businessRepo, userRepo, mediaService)
+	brandService := services.NewBrandService(brandRepo, userRepo, categoryRepo, mediaService, businessService)
+	businessMemberService := services.NewBusinessMemberService(businessMemberRepo, businessRepo, userRepo, brandRepo)
+	businessDocumentService := services.NewBusinessDocumentService(businessDocumentRepo, businessRepo, userRepo, businessService, mediaStorage, cfg.Storage.MaxUploadSize, services.DocumentExpiryRules{
+		WarningDays:    cfg.Document.ExpiryWarningDays,
+		MandatoryTypes: cfg.Document.MandatoryTypes,
+		AutoSuspend:    cfg.Document.AutoSuspend,
+	})
+	businessContractService := services.NewBusinessContractService(businessContractRepo, businessRepo, mediaStorage, cfg.Storage.MaxUploadSize)
+	settlementAccountService := services.NewSettlementAccountService(settlementAccountRepo, businessRepo, userRepo, settlementCipher)
+	profanityWords, err := services.LoadProfanityWords(cfg.Review.ProfanityFile)
+	if err != nil {
+		log.Fatal("Failed to load profanity word list: ", err)
+	}
+	reviewService := services.NewReviewService(reviewRepo, businessRepo, userRepo, businessMemberService, mediaService, services.ReviewModerationRules{
+		BurstWindow:       cfg.Review.BurstWindow,
+		BurstMaxPerUser:   cfg.Review.BurstMaxPerUser,
+		BurstMaxPerIP:     cfg.Review.BurstMaxPerIP,
+		SpikeMinReviews:   cfg.Review.SpikeMinReviews,
+		OutlierMinReviews: cfg.Review.OutlierMinReviews,
+		ProfanityWords:    profanityWords,
+	})
+
+	// 注册后台任务
+	scheduler.Register(jobs.Job{
+		Name:     "business-scheduled-transitions",
+		Interval: cfg.Jobs.StatusScheduleInterval,
+		Run: func(ctx context.Context) error {
+			_, err := businessService.RunDueScheduledTransitions(time.Now())
+			return err
+		},
+	})
+
+	scheduler.Register(jobs.Job{
+		Name:     "trash-retention",
+		Interval: cfg.Jobs.TrashPurgeInterval,
+		Run: func(ctx context.Context) error {
+			now := time.Now()
+			if _, err := businessService.PurgeExpiredBusinesses(cfg.Jobs.TrashRetentionDays, now); err != nil {
+				return err
+			}
+			_, err := userService.PurgeExpiredUsers(cfg.Jobs.TrashRetentionDays, now)
+			return err
+		},
+	})
+
+	scheduler.Register(jobs.Job{
+		Name:     "media-processing",
+		Interval: cfg.Jobs.MediaProcessInterval,
+		Run: func(ctx context.Context) error {
+			_, err := mediaService.ProcessPendingMedia(100)
+			return err
+		},
+	})
+
+	// 商家评分随评价增量更新，定期按评价重新汇总以修正偏差，并在启动时应用评分配置的变更
+	scheduler.Register(jobs.Job{
+		Name:     "rating-rebuild",
+		Interval: cfg.Jobs.RatingRebuildInterval,
+		Run: func(ctx context.Context) error {
+			_, err := reviewService.RebuildRatings()
+			return err
+		},
+	})
+
+	// 资质证件到期检查：标记即将到期的证件，过期证件标记为已过期，按配置暂停必备证件缺失的商家
+	scheduler.Register(jobs.Job{
+		Name:     "document-expiry",
+		Interval: cfg.Jobs.DocumentExpiryInterval,
+		Run: func(ctx context.Context) error {
+			result, err := businessDocumentService.CheckDocumentExpiry(time.Now())
+			if err == nil && (result.Flagged > 0 || result.Expired > 0) {
+				log.Printf("Document expiry check: %d expiring, %d expired, suspended businesses %v", result.Flagged, result.Expired, result.Suspended)
+			}
+			return err
+		},
+	})
+
+	// 联想索引及进程内搜索索引在启动时由该任务建立并定期重建；未启用后台任务时在此建立一次
+	if !cfg.Jobs.Enabled {
+		if _, err := businessService.RebuildSearchIndex(); err != nil {
+			log.Printf("Search index build failed: %v", err)
+		}
+	}
+	scheduler.Register(jobs.Job{
+		Name:     "search-index-rebuild",
+		Interval: cfg.Jobs.SearchReindexInterval,
+		Run: func(ctx context.Context) error {
+			_, err := businessService.RebuildSearchIndex()
+			return err
+		},
+	})
 
 	// 创建控制器实例
 	businessController := controllers.NewBusinessController(businessService)
 	userController := controllers.NewUserController(userService)
 	authController := controllers.NewAuthController(userService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	mediaController := controllers.NewMediaController(mediaService)
+	businessPhotoController := controllers.NewBusinessPhotoController(businessPhotoService)
+	reviewController := controllers.NewReviewController(reviewService)
+	brandController := controllers.NewBrandController(brandService)
+	businessMemberController := controllers.NewBusinessMemberController(businessMemberService)
+	businessDocumentController := controllers.NewBusinessDocumentController(businessDocumentService)
+	businessContractController := controllers.NewBusinessContractController(businessContractService)
+	settlementAccountController := controllers.NewSettlementAccountController(settlementAccountService)
 
 	// 认证路由
 	r.POST("/login", authController.Login)
@@ -35,23 +193,139 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 	// API 路由组
 	api := r.Group("/api/v1")
 	{
-		// 商家路由
+		// 商家路由（维护商家需要该商家成员的相应权限，见 model.BusinessRoleAllows；品牌管理员与管理员拥有全部权限）
+		canView := middleware.BusinessPermissionMiddleware(businessMemberService, model.BusinessPermissionView)
+		canEdit := middleware.BusinessPermissionMiddleware(businessMemberService, model.BusinessPermissionEdit)
+		canManage := middleware.BusinessPermissionMiddleware(businessMemberService, model.BusinessPermissionManage)
 		businesses := api.Group("/business", middleware.AuthMiddleware())
 		{
 			businesses.GET("", businessController.GetBusinesses)                    // 获取商家列表
 			businesses.GET("/:id", businessController.GetBusiness)                  // 获取单个商家
 			businesses.POST("", businessController.CreateBusiness)                  // 创建商家
-			businesses.PUT("/:id", businessController.UpdateBusiness)               // 更新商家
-			businesses.DELETE("/:id", businessController.DeleteBusiness)             // 删除商家
-			businesses.PUT("/:id/status", businessController.UpdateBusinessStatus)   // 更新商家状态
+			businesses.PUT("/:id", canEdit, businessController.UpdateBusiness)               // 更新商家
+			businesses.PATCH("/:id", canEdit, businessController.PatchBusiness)             // 部分更新商家
+			businesses.DELETE("/:id", canManage, businessController.DeleteBusiness)             // 删除商家
+			businesses.PUT("/:id/status", canEdit, businessController.UpdateBusinessStatus)   // 更新商家状态
+			businesses.PUT("/:id/hours", canEdit, businessController.SetOpeningHours)         // 设置营业时间
+			businesses.POST("/:id/submit", canEdit, businessController.SubmitBusiness)        // 提交入驻申请
+			businesses.PUT("/:id/reviewer", middleware.AdminMiddleware(userRepo), businessController.AssignReviewer) // 分配审核人（仅管理员）
+			businesses.POST("/:id/approve", businessController.ApproveBusiness)      // 审核通过
+			businesses.POST("/:id/reject", businessController.RejectBusiness)        // 驳回入驻申请
+			businesses.GET("/:id/status-history", canView, businessController.GetStatusHistory) // 状态变更历史
+			businesses.GET("/:id/scheduled-transitions", canView, businessController.GetScheduledTransitions)                // 计划流转列表
+			businesses.POST("/:id/scheduled-transitions", canEdit, businessController.ScheduleStatusTransition)              // 安排计划流转
+			businesses.DELETE("/:id/scheduled-transitions/:scheduleId", canEdit, businessController.CancelScheduledTransition) // 取消计划流转
+			businesses.GET("/review-queue", businessController.GetReviewQueue)       // 待审核队列
 			businesses.GET("/status/:status", businessController.GetBusinessByStatus) // 根据状态获取商家
 			businesses.GET("/search", businessController.SearchBusinesses)          // 搜索商家
+			businesses.GET("/suggest", businessController.SuggestBusinesses)        // 商家名称联想
+			businesses.GET("/tags", businessController.GetTags)                     // 标签统计与联想
 			businesses.GET("/type", businessController.GetBusinessesByType)         // 按类型获取商家
 			businesses.GET("/rating", businessController.GetBusinessesByRating)     // 按评分获取商家
 			businesses.GET("/page", businessController.GetBusinessesWithPagination) // 分页获取商家
 			businesses.GET("/nearby", businessController.GetNearbyBusinesses)       // 获取附近商家
 			businesses.GET("/count", businessController.GetBusinessCount)           // 获取商家总数
 			businesses.GET("/count/type", businessController.GetBusinessCountByType) // 根据类型获取商家数量
+			businesses.GET("/regions", businessController.GetRegionStats)             // 按地区统计商家数量
+			businesses.GET("/:id/revisions", canView, businessController.GetRevisions)         // 修订记录列表
+			businesses.GET("/:id/revisions/diff", canView, businessController.DiffRevisions)   // 对比修订版本
+			businesses.GET("/:id/revisions/:version", canView, businessController.GetRevision) // 修订版本详情
+			businesses.POST("/:id/revisions/:version/restore", canEdit, businessController.RestoreRevision) // 恢复到修订版本
+			businesses.GET("/trash", middleware.AdminMiddleware(userRepo), businessController.GetDeletedBusinesses) // 回收站商家列表（仅管理员）
+			businesses.POST("/:id/restore", canManage, businessController.RestoreBusiness)       // 从回收站恢复商家
+			businesses.DELETE("/:id/purge", middleware.AdminMiddleware(userRepo), businessController.PurgeBusiness) // 彻底删除商家（仅管理员）
+			businesses.GET("/:id/photos", businessPhotoController.GetPhotos)                                // 商家相册
+			businesses.POST("/:id/photos", canEdit, businessPhotoController.AddPhoto)                       // 添加相册照片
+			businesses.PUT("/:id/photos/order", canEdit, businessPhotoController.ReorderPhotos)             // 调整相册顺序
+			businesses.POST("/:id/photos/batch-delete", canEdit, businessPhotoController.BatchDeletePhotos) // 批量删除相册照片
+			businesses.PATCH("/:id/photos/:photoId", canEdit, businessPhotoController.UpdatePhoto)          // 修改照片说明、封面
+			businesses.DELETE("/:id/photos/:photoId", canEdit, businessPhotoController.DeletePhoto)         // 删除相册照片
+			businesses.POST("/:id/photos/:photoId/approve", middleware.AdminMiddleware(userRepo), businessPhotoController.ApprovePhoto) // 审核通过照片（仅管理员）
+			businesses.POST("/:id/photos/:photoId/reject", middleware.AdminMiddleware(userRepo), businessPhotoController.RejectPhoto)   // 驳回照片（仅管理员）
+			businesses.GET("/photos/pending", middleware.AdminMiddleware(userRepo), businessPhotoController.GetPendingPhotos)          // 待审核照片（仅管理员）
+			businesses.GET("/:id/reviews", reviewController.GetReviews)                                                                 // 商家的评价
+			businesses.POST("/:id/reviews", reviewController.CreateReview)                                                              // 发表评价
+			businesses.GET("/:id/rating", reviewController.GetRating)                                                                   // 商家评分汇总
+			businesses.GET("/memberships", businessMemberController.GetMemberships)                  // 我的商家（加入或被邀请）
+			businesses.POST("/:id/membership/accept", businessMemberController.AcceptInvitation)     // 接受商家邀请
+			businesses.DELETE("/:id/membership", businessMemberController.LeaveBusiness)             // 退出商家或拒绝邀请
+			businesses.GET("/:id/members", businessMemberController.GetMembers)                      // 商家成员
+			businesses.POST("/:id/members", businessMemberController.InviteMember)                   // 邀请商家成员（仅店主）
+			businesses.PUT("/:id/members/:userId", businessMemberController.UpdateMemberRole)        // 修改成员角色（仅店主）
+			businesses.DELETE("/:id/members/:userId", businessMemberController.RemoveMember)         // 移除成员或撤回邀请（仅店主）
+			businesses.GET("/documents/expiring", middleware.AdminMiddleware(userRepo), businessDocumentController.GetExpiringDocuments)                          // 即将到期的资质证件（仅管理员）
+			businesses.GET("/:id/documents", canEdit, businessDocumentController.GetDocuments)                                                                    // 商家资质证件
+			businesses.POST("/:id/documents", canEdit, businessDocumentController.UploadDocument)                                                                 // 上传资质证件
+			businesses.PUT("/:id/documents/:documentId", canEdit, businessDocumentController.UpdateDocument)                                                      // 修改资质证件
+			businesses.DELETE("/:id/documents/:documentId", canEdit, businessDocumentController.DeleteDocument)                                                   // 删除资质证件
+			businesses.GET("/:id/documents/:documentId/file", canEdit, businessDocumentController.GetDocumentFile)                                                // 下载资质证件文件
+			businesses.POST("/:id/documents/:documentId/verify", middleware.AdminMiddleware(userRepo), businessDocumentController.VerifyDocument)                 // 核验资质证件（仅管理员）
+			businesses.POST("/:id/documents/:documentId/reject", middleware.AdminMiddleware(userRepo), businessDocumentController.RejectDocument)                 // 驳回资质证件（仅管理员）
+			businesses.GET("/:id/contracts", canManage, businessContractController.GetContracts)                                                                  // 商家合同
+			businesses.GET("/:id/contracts/effective", canManage, businessContractController.GetEffectiveTerms)                                                   // 指定日期生效的合同条款
+			businesses.GET("/:id/contracts/:contractId", canManage, businessContractController.GetContract)                                                       // 合同详情
+			businesses.GET("/:id/contracts/:contractId/attachment", canManage, businessContractController.GetAttachment)                                          // 下载合同附件
+			businesses.POST("/:id/contracts", middleware.AdminMiddleware(userRepo), businessContractController.CreateContract)                                    // 创建合同（仅管理员）
+			businesses.PUT("/:id/contracts/:contractId", middleware.AdminMiddleware(userRepo), businessContractController.UpdateContract)                         // 修改合同（仅管理员）
+			businesses.DELETE("/:id/contracts/:contractId", middleware.AdminMiddleware(userRepo), businessContractController.DeleteContract)                      // 删除未生效的合同（仅管理员）
+			businesses.PUT("/:id/contracts/:contractId/attachment", middleware.AdminMiddleware(userRepo), businessContractController.UploadAttachment)            // 上传合同附件（仅管理员）
+			businesses.GET("/settlement-account/changes/pending", middleware.AdminMiddleware(userRepo), settlementAccountController.GetPendingChanges)            // 待审批的结算账户变更（仅管理员）
+			businesses.GET("/:id/settlement-account", canManage, settlementAccountController.GetAccount)                                                          // 结算账户（账号脱敏）
+			businesses.GET("/:id/settlement-account/changes", canManage, settlementAccountController.GetChanges)                                                  // 结算账户变更历史
+			businesses.POST("/:id/settlement-account/changes", canManage, settlementAccountController.RequestChange)                                              // 提交结算账户变更申请
+			businesses.POST("/:id/settlement-account/changes/:changeId/cancel", canManage, settlementAccountController.CancelChange)                              // 撤回变更申请
+			businesses.POST("/:id/settlement-account/changes/:changeId/approve", middleware.AdminMiddleware(userRepo), settlementAccountController.ApproveChange) // 审批通过变更（仅管理员，且不能是申请人）
+			businesses.POST("/:id/settlement-account/changes/:changeId/reject", middleware.AdminMiddleware(userRepo), settlementAccountController.RejectChange)   // 驳回变更（仅管理员）
+		}
+
+		// 品牌路由（门店为 brandId 指向品牌的商家，通过商家路由维护；品牌维护仅品牌管理员或管理员）
+		brands := api.Group("/brands", middleware.AuthMiddleware())
+		{
+			brands.GET("", brandController.GetBrands)                              // 获取品牌列表
+			brands.GET("/:id", brandController.GetBrand)                           // 获取品牌详情
+			brands.POST("", brandController.CreateBrand)                           // 创建品牌
+			brands.PUT("/:id", brandController.UpdateBrand)                        // 修改品牌
+			brands.DELETE("/:id", brandController.DeleteBrand)                     // 删除品牌
+			brands.GET("/:id/stores", brandController.GetBrandStores)              // 品牌的门店
+			brands.GET("/:id/stats", brandController.GetBrandStats)                // 品牌汇总统计
+			brands.GET("/:id/admins", brandController.GetBrandAdmins)              // 品牌管理员
+			brands.POST("/:id/admins", brandController.AddBrandAdmin)              // 添加品牌管理员
+			brands.DELETE("/:id/admins/:userId", brandController.RemoveBrandAdmin) // 移除品牌管理员
+		}
+
+		// 顾客评价路由（审核仅管理员，回复仅商家的店主、店长、收银员、品牌管理员或管理员）
+		reviews := api.Group("/reviews", middleware.AuthMiddleware())
+		{
+			reviews.GET("/moderation-queue", middleware.AdminMiddleware(userRepo), reviewController.GetModerationQueue)   // 审核队列（仅管理员）
+			reviews.GET("/:id", reviewController.GetReview)                                                               // 获取评价
+			reviews.PUT("/:id", reviewController.UpdateReview)                                                            // 修改评价
+			reviews.DELETE("/:id", reviewController.DeleteReview)                                                         // 删除评价
+			reviews.POST("/:id/approve", middleware.AdminMiddleware(userRepo), reviewController.ApproveReview)            // 审核通过评价（仅管理员）
+			reviews.POST("/:id/reject", middleware.AdminMiddleware(userRepo), reviewController.RejectReview)              // 驳回评价（仅管理员）
+			reviews.POST("/:id/hide", middleware.AdminMiddleware(userRepo), reviewController.HideReview)                  // 隐藏评价（仅管理员）
+			reviews.GET("/:id/moderation-logs", middleware.AdminMiddleware(userRepo), reviewController.GetModerationLogs) // 审核记录（仅管理员）
+			reviews.PUT("/:id/reply", reviewController.ReplyReview)                                                       // 回复评价
+			reviews.DELETE("/:id/reply", reviewController.DeleteReply)                                                    // 删除回复
+		}
+
+		// 商家分类路由（查询需登录，维护仅管理员）
+		categories := api.Group("/categories", middleware.AuthMiddleware())
+		{
+			categories.GET("", categoryController.GetCategoryTree)                                                // 获取分类树
+			categories.GET("/:id", categoryController.GetCategory)                                                // 获取单个分类
+			categories.POST("", middleware.AdminMiddleware(userRepo), categoryController.CreateCategory)          // 创建分类
+			categories.PUT("/:id", middleware.AdminMiddleware(userRepo), categoryController.UpdateCategory)       // 更新分类
+			categories.DELETE("/:id", middleware.AdminMiddleware(userRepo), categoryController.DeleteCategory)    // 删除分类
+			categories.POST("/:id/merge", middleware.AdminMiddleware(userRepo), categoryController.MergeCategory) // 合并到其他分类
+		}
+
+		// 媒体文件路由（内容地址无需登录，供 img 标签直接引用）
+		media := api.Group("/media")
+		{
+			media.POST("", middleware.AuthMiddleware(), mediaController.UploadMedia)       // 上传媒体文件
+			media.GET("/:id", middleware.AuthMiddleware(), mediaController.GetMedia)       // 获取媒体文件信息
+			media.GET("/:id/content", mediaController.GetMediaContent)                     // 获取媒体文件内容
+			media.DELETE("/:id", middleware.AuthMiddleware(), mediaController.DeleteMedia) // 删除媒体文件
 		}
 
 		// 用户路由
@@ -61,11 +335,15 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 			users.GET("/:id", userController.GetUser)                     // 获取单个用户
 			users.POST("", userController.CreateUser)                     // 创建用户
 			users.PUT("/:id", userController.UpdateUser)                  // 更新用户
+			users.PATCH("/:id", userController.PatchUser)                 // 部分更新用户
 			users.DELETE("/:id", userController.DeleteUser)               // 删除用户
 			users.PUT("/:id/password", userController.ChangePassword)     // 修改密码
 			users.PUT("/:id/status", userController.UpdateUserStatus)     // 更新用户状态
 			users.GET("/role/:role", userController.GetUsersByRole)       // 根据角色获取用户列表
 			users.GET("/status/:status", userController.GetUsersByStatus) // 根据状态获取用户列表
+			users.GET("/trash", middleware.AuthMiddleware(), middleware.AdminMiddleware(userRepo), userController.GetDeletedUsers)    // 回收站用户列表（仅管理员）
+			users.POST("/:id/restore", middleware.AuthMiddleware(), middleware.AdminMiddleware(userRepo), userController.RestoreUser) // 从回收站恢复用户（仅管理员）
+			users.DELETE("/:id/purge", middleware.AuthMiddleware(), middleware.AdminMiddleware(userRepo), userController.PurgeUser)   // 彻底删除用户（仅管理员）
 		}
 	}
 
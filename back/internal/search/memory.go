@@ -0,0 +1,190 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// MemoryBackend 进程内倒排索引，适用于开发、测试及单实例部署
+// 多实例部署时各实例的索引只反映本实例的写入，需依赖定时重建保持一致
+type MemoryBackend struct {
+	mu        sync.RWMutex
+	postings  map[string]map[int]map[string]int // 词 → 文档ID → 字段 → 词频
+	docs      map[int]map[string]int            // 文档ID → 字段 → 词数
+	docTerms  map[int][]string                  // 文档ID → 包含的词（删除时使用）
+	fieldLens map[string]int                    // 字段 → 全部文档词数之和
+}
+
+// NewMemoryBackend 创建进程内搜索后端
+func NewMemoryBackend() *MemoryBackend {
+	b := &MemoryBackend{}
+	b.reset()
+	return b
+}
+
+// reset 清空索引
+func (b *MemoryBackend) reset() {
+	b.postings = map[string]map[int]map[string]int{}
+	b.docs = map[int]map[string]int{}
+	b.docTerms = map[int][]string{}
+	b.fieldLens = map[string]int{}
+}
+
+// Name 后端名称
+func (b *MemoryBackend) Name() string {
+	return "memory"
+}
+
+// Index 新增或更新文档
+func (b *MemoryBackend) Index(docs ...Document) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, doc := range docs {
+		b.remove(doc.ID)
+		b.add(doc)
+	}
+	return nil
+}
+
+// Delete 删除文档
+func (b *MemoryBackend) Delete(ids ...int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, id := range ids {
+		b.remove(id)
+	}
+	return nil
+}
+
+// Rebuild 以给定文档重建索引
+func (b *MemoryBackend) Rebuild(docs []Document) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reset()
+	for _, doc := range docs {
+		b.add(doc)
+	}
+	return nil
+}
+
+// add 写入文档（调用方持有写锁）
+func (b *MemoryBackend) add(doc Document) {
+	lengths := map[string]int{}
+	var terms []string
+	for field, text := range doc.Fields {
+		tokens := Tokenize(text)
+		lengths[field] = len(tokens)
+		b.fieldLens[field] += len(tokens)
+		for _, t := range tokens {
+			docs, ok := b.postings[t.Term]
+			if !ok {
+				docs = map[int]map[string]int{}
+				b.postings[t.Term] = docs
+			}
+			fields, ok := docs[doc.ID]
+			if !ok {
+				fields = map[string]int{}
+				docs[doc.ID] = fields
+				terms = append(terms, t.Term)
+			}
+			fields[field]++
+		}
+	}
+	b.docs[doc.ID] = lengths
+	b.docTerms[doc.ID] = terms
+}
+
+// remove 删除文档（调用方持有写锁）
+func (b *MemoryBackend) remove(id int) {
+	lengths, ok := b.docs[id]
+	if !ok {
+		return
+	}
+	for field, n := range lengths {
+		b.fieldLens[field] -= n
+	}
+	for _, term := range b.docTerms[id] {
+		delete(b.postings[term], id)
+		if len(b.postings[term]) == 0 {
+			delete(b.postings, term)
+		}
+	}
+	delete(b.docs, id)
+	delete(b.docTerms, id)
+}
+
+// Search 搜索同时包含全部关键词的文档，按 BM25F 计算相关度
+func (b *MemoryBackend) Search(q Query) ([]Hit, error) {
+	terms := QueryTerms(q.Text)
+	if len(terms) == 0 {
+		return []Hit{}, nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	total := float64(len(b.docs))
+	scores := map[int]float64{}
+	for i, term := range terms {
+		matched := map[int]float64{}
+		docs := b.postings[term]
+		idf := math.Log(1 + (total-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+		for id, fields := range docs {
+			if i > 0 {
+				if _, ok := scores[id]; !ok {
+					continue
+				}
+			}
+			tf := 0.0
+			for field, n := range fields {
+				weight := q.Weights[field]
+				if weight <= 0 {
+					continue
+				}
+				avg := float64(b.fieldLens[field]) / total
+				norm := 1 - bm25B
+				if avg > 0 {
+					norm += bm25B * float64(b.docs[id][field]) / avg
+				}
+				tf += weight * float64(n) / norm
+			}
+			if tf > 0 {
+				matched[id] = scores[id] + idf*tf/(bm25K1+tf)
+			}
+		}
+		scores = matched
+		if len(scores) == 0 {
+			break
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sortHits(hits)
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+// sortHits 按相关度倒序、ID 升序排列
+func sortHits(hits []Hit) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+}
@@ -0,0 +1,86 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MySQLBackend 基于 MySQL FULLTEXT 索引（ngram 分词）的搜索后端
+// 每个参与搜索的列需要单独的 FULLTEXT ... WITH PARSER ngram 索引（见 sql/migrations），
+// 以便按字段分别计算相关度并加权；索引由数据库随写入维护，Index/Delete/Rebuild 无需操作
+type MySQLBackend struct {
+	db      *gorm.DB
+	columns map[string]string // 字段名 → 列名
+}
+
+// NewMySQLBackend 创建 MySQL 搜索后端，scope 为已指定表（及默认条件）的查询
+func NewMySQLBackend(scope *gorm.DB, columns map[string]string) *MySQLBackend {
+	return &MySQLBackend{
+		db:      scope.Session(&gorm.Session{}),
+		columns: columns,
+	}
+}
+
+// Name 后端名称
+func (b *MySQLBackend) Name() string {
+	return "mysql"
+}
+
+// Index 由数据库维护索引，无需操作
+func (b *MySQLBackend) Index(docs ...Document) error {
+	return nil
+}
+
+// Delete 由数据库维护索引，无需操作
+func (b *MySQLBackend) Delete(ids ...int) error {
+	return nil
+}
+
+// Rebuild 由数据库维护索引，无需操作
+func (b *MySQLBackend) Rebuild(docs []Document) error {
+	return nil
+}
+
+// Search 按字段分别 MATCH ... AGAINST，相关度为各字段得分的加权和
+func (b *MySQLBackend) Search(q Query) ([]Hit, error) {
+	text := strings.TrimSpace(q.Text)
+	if text == "" {
+		return []Hit{}, nil
+	}
+
+	fields := make([]string, 0, len(q.Weights))
+	for field, weight := range q.Weights {
+		if _, ok := b.columns[field]; ok && weight > 0 {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return []Hit{}, nil
+	}
+	sort.Strings(fields)
+
+	var scoreParts, matchParts []string
+	var scoreArgs, matchArgs []interface{}
+	for _, field := range fields {
+		match := "MATCH(" + b.columns[field] + ") AGAINST(? IN NATURAL LANGUAGE MODE)"
+		scoreParts = append(scoreParts, "? * "+match)
+		scoreArgs = append(scoreArgs, q.Weights[field], text)
+		matchParts = append(matchParts, match)
+		matchArgs = append(matchArgs, text)
+	}
+
+	query := b.db.Select("id, ("+strings.Join(scoreParts, " + ")+") AS score", scoreArgs...).
+		Where("("+strings.Join(matchParts, " OR ")+")", matchArgs...).
+		Order("score DESC, id ASC")
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	var hits []Hit
+	if err := query.Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
@@ -0,0 +1,35 @@
+package search
+
+// Document 待索引的文档，Fields 为字段名到文本的映射
+type Document struct {
+	ID     int
+	Fields map[string]string
+}
+
+// Query 搜索条件
+type Query struct {
+	Text    string             // 搜索关键词
+	Weights map[string]float64 // 参与搜索的字段及权重，未列出的字段不参与搜索
+	Limit   int                // 最多返回的命中数，0 表示不限制
+}
+
+// Hit 单个命中结果
+type Hit struct {
+	ID    int
+	Score float64 // 相关度，越大越相关
+}
+
+// Backend 搜索后端
+// 命中结果按相关度倒序排列，相关度相同时按 ID 升序
+type Backend interface {
+	// Name 后端名称
+	Name() string
+	// Index 新增或更新文档
+	Index(docs ...Document) error
+	// Delete 删除文档
+	Delete(ids ...int) error
+	// Rebuild 以给定文档重建索引
+	Rebuild(docs []Document) error
+	// Search 搜索文档
+	Search(q Query) ([]Hit, error)
+}
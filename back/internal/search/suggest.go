@@ -0,0 +1,432 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 联想匹配方式及基础得分，同一条目取最高得分
+const (
+	suggestScoreExact      = 100.0 // 与名称完全一致
+	suggestScorePrefix     = 80.0  // 名称前缀
+	suggestScoreWordPrefix = 60.0  // 名称中某个单词的前缀
+	suggestScoreFuzzy      = 40.0  // 编辑距离内的前缀（每差一处扣 10 分）
+	suggestRomajiPenalty   = 5.0   // 通过罗马字转写匹配时扣分
+	suggestPopularityBoost = 10.0  // 热门度加分系数（按对数增长）
+)
+
+// 联想限制
+const (
+	maxSuggestPrefixCandidates = 1000  // 前缀匹配最多考察的候选数
+	maxPopularQueries          = 10000 // 最多记录的热门关键词数
+	popularQueryMatchLimit     = 50    // 每次记录关键词时最多为多少个条目加热门度
+)
+
+// 联想键的来源
+const (
+	suggestKeyName   = iota // 完整名称
+	suggestKeyWord          // 名称中的单词
+	suggestKeyRomaji        // 假名的罗马字转写
+)
+
+// Suggestion 联想结果
+type Suggestion struct {
+	ID    int     `json:"id"`
+	Text  string  `json:"text"`  // 联想出的名称
+	Score float64 `json:"score"` // 排序得分，越大越靠前
+	Fuzzy bool    `json:"fuzzy"` // 是否为容错（编辑距离）匹配
+}
+
+// suggestEntry 联想条目
+type suggestEntry struct {
+	id         int
+	text       string
+	popularity float64 // 由热门关键词累计的热门度
+}
+
+// suggestKey 按字典序排列的联想键
+type suggestKey struct {
+	key   []rune
+	text  string
+	entry *suggestEntry
+	kind  int
+}
+
+// SuggestIndex 进程内的名称联想索引：前缀匹配、编辑距离容错、罗马字匹配与热门关键词加权
+// 键按字典序排列，前缀匹配为二分查找；容错匹配按字典树方式遍历，共享前缀的计算复用、超出距离的前缀整体跳过
+type SuggestIndex struct {
+	mu      sync.RWMutex
+	keys    []suggestKey
+	entries map[int]*suggestEntry
+	queries map[string]int // 热门关键词（归一化后）→ 搜索次数
+}
+
+// NewSuggestIndex 创建联想索引
+func NewSuggestIndex() *SuggestIndex {
+	return &SuggestIndex{
+		entries: map[int]*suggestEntry{},
+		queries: map[string]int{},
+	}
+}
+
+// normalizeSuggest 归一化联想文本：全角转半角、小写、片假名转平假名、去掉空白与标点
+func normalizeSuggest(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		r = foldKana(normalizeRune(r))
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == 'ー' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// suggestKeys 生成条目的联想键：完整名称、名称中第二个起的各单词开头的后缀、假名的罗马字转写
+func suggestKeys(entry *suggestEntry) []suggestKey {
+	full := normalizeSuggest(entry.text)
+	if full == "" {
+		return nil
+	}
+	keys := []suggestKey{{key: []rune(full), entry: entry, kind: suggestKeyName}}
+
+	words := strings.FieldsFunc(entry.text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == 0x3000 || r == '・' || r == '-' || r == '_' || r == '/'
+	})
+	for i := 1; i < len(words); i++ {
+		if rest := normalizeSuggest(strings.Join(words[i:], "")); rest != "" {
+			keys = append(keys, suggestKey{key: []rune(rest), entry: entry, kind: suggestKeyWord})
+		}
+	}
+
+	if hasKana(full) {
+		keys = append(keys, suggestKey{key: []rune(kanaToRomaji(full)), entry: entry, kind: suggestKeyRomaji})
+	}
+	for i := range keys {
+		keys[i].text = string(keys[i].key)
+	}
+	return keys
+}
+
+// Rebuild 以给定文档重建索引，文档取 field 字段作为联想文本；热门关键词保留
+func (x *SuggestIndex) Rebuild(docs []Document, field string) {
+	entries := make(map[int]*suggestEntry, len(docs))
+	var keys []suggestKey
+	for _, doc := range docs {
+		entry := &suggestEntry{id: doc.ID, text: doc.Fields[field]}
+		if old, ok := x.entry(doc.ID); ok {
+			entry.popularity = old.popularity
+		}
+		entries[doc.ID] = entry
+		keys = append(keys, suggestKeys(entry)...)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].text < keys[j].text })
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.entries = entries
+	x.keys = keys
+}
+
+// entry 读取条目
+func (x *SuggestIndex) entry(id int) (*suggestEntry, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.entries[id]
+	return e, ok
+}
+
+// Index 新增或更新文档，文档取 field 字段作为联想文本
+func (x *SuggestIndex) Index(field string, docs ...Document) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, doc := range docs {
+		entry := &suggestEntry{id: doc.ID, text: doc.Fields[field]}
+		if old, ok := x.entries[doc.ID]; ok {
+			entry.popularity = old.popularity
+			x.removeKeys(doc.ID)
+		}
+		x.entries[doc.ID] = entry
+		for _, k := range suggestKeys(entry) {
+			i := sort.Search(len(x.keys), func(i int) bool { return x.keys[i].text >= k.text })
+			x.keys = append(x.keys, suggestKey{})
+			copy(x.keys[i+1:], x.keys[i:])
+			x.keys[i] = k
+		}
+	}
+}
+
+// Delete 删除文档
+func (x *SuggestIndex) Delete(ids ...int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := x.entries[id]; ok {
+			x.removeKeys(id)
+			delete(x.entries, id)
+		}
+	}
+}
+
+// removeKeys 删除条目的全部联想键（调用方持有写锁）
+func (x *SuggestIndex) removeKeys(id int) {
+	kept := x.keys[:0]
+	for _, k := range x.keys {
+		if k.entry.id != id {
+			kept = append(kept, k)
+		}
+	}
+	for i := len(kept); i < len(x.keys); i++ {
+		x.keys[i] = suggestKey{}
+	}
+	x.keys = kept
+}
+
+// RecordQuery 记录一次搜索关键词，名称以该关键词开头的条目热门度增加
+// 热门关键词超过上限时淘汰搜索次数最少的一半
+func (x *SuggestIndex) RecordQuery(query string) {
+	q := normalizeSuggest(query)
+	if q == "" {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.queries[q]; !ok && len(x.queries) >= maxPopularQueries {
+		x.evictQueries()
+	}
+	x.queries[q]++
+
+	seen := map[int]bool{}
+	for _, variant := range queryVariants(q) {
+		for i := x.lowerBound(variant); i < len(x.keys) && len(seen) < popularQueryMatchLimit; i++ {
+			if !strings.HasPrefix(x.keys[i].text, variant) {
+				break
+			}
+			if entry := x.keys[i].entry; !seen[entry.id] {
+				seen[entry.id] = true
+				entry.popularity++
+			}
+		}
+	}
+}
+
+// evictQueries 淘汰搜索次数最少的一半热门关键词（调用方持有写锁）
+func (x *SuggestIndex) evictQueries() {
+	counts := make([]int, 0, len(x.queries))
+	for _, n := range x.queries {
+		counts = append(counts, n)
+	}
+	sort.Ints(counts)
+	threshold := counts[len(counts)/2]
+	for q, n := range x.queries {
+		if n <= threshold {
+			delete(x.queries, q)
+		}
+	}
+}
+
+// PopularQueries 返回以 prefix 开头的热门关键词（按搜索次数倒序）
+func (x *SuggestIndex) PopularQueries(prefix string, limit int) []string {
+	p := normalizeSuggest(prefix)
+
+	x.mu.RLock()
+	type popular struct {
+		query string
+		count int
+	}
+	var matched []popular
+	for q, n := range x.queries {
+		if strings.HasPrefix(q, p) && q != p {
+			matched = append(matched, popular{q, n})
+		}
+	}
+	x.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].count != matched[j].count {
+			return matched[i].count > matched[j].count
+		}
+		return matched[i].query < matched[j].query
+	})
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	result := make([]string, len(matched))
+	for i, m := range matched {
+		result[i] = m.query
+	}
+	return result
+}
+
+// queryVariants 关键词的匹配形式：归一化文本，含假名时追加罗马字转写
+func queryVariants(q string) []string {
+	variants := []string{q}
+	if hasKana(q) {
+		if r := kanaToRomaji(q); r != q {
+			variants = append(variants, r)
+		}
+	}
+	return variants
+}
+
+// lowerBound 第一个不小于 s 的键的位置（调用方持有锁）
+func (x *SuggestIndex) lowerBound(s string) int {
+	return sort.Search(len(x.keys), func(i int) bool { return x.keys[i].text >= s })
+}
+
+// Suggest 返回得分最高的 limit 条联想
+// 先做前缀匹配；结果不足 limit 且关键词不少于 3 个字符时，补充编辑距离内的容错匹配
+// （3-5 个字符允许 1 处差异，6 个字符以上允许 2 处）
+func (x *SuggestIndex) Suggest(prefix string, limit int) []Suggestion {
+	q := normalizeSuggest(prefix)
+	if q == "" || limit <= 0 {
+		return []Suggestion{}
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	best := map[int]*Suggestion{}
+	consider := func(k suggestKey, score float64, fuzzy bool) {
+		if k.kind == suggestKeyRomaji {
+			score -= suggestRomajiPenalty
+		}
+		score += suggestPopularityBoost * math.Log1p(k.entry.popularity)
+		if s, ok := best[k.entry.id]; ok && s.Score >= score {
+			return
+		}
+		best[k.entry.id] = &Suggestion{ID: k.entry.id, Text: k.entry.text, Score: score, Fuzzy: fuzzy}
+	}
+
+	for _, variant := range queryVariants(q) {
+		n := 0
+		for i := x.lowerBound(variant); i < len(x.keys) && n < maxSuggestPrefixCandidates; i++ {
+			k := x.keys[i]
+			if !strings.HasPrefix(k.text, variant) {
+				break
+			}
+			n++
+			switch {
+			case k.kind != suggestKeyWord && len(k.key) == len([]rune(variant)):
+				consider(k, suggestScoreExact, false)
+			case k.kind == suggestKeyWord:
+				consider(k, suggestScoreWordPrefix, false)
+			default:
+				// 同为前缀匹配时，名称越短越接近关键词
+				consider(k, suggestScorePrefix-0.1*float64(len(k.key)-len([]rune(variant))), false)
+			}
+		}
+	}
+
+	qr := []rune(q)
+	if len(best) < limit && len(qr) >= 3 {
+		maxDist := 1
+		if len(qr) >= 6 {
+			maxDist = 2
+		}
+		x.fuzzyScan(qr, maxDist, func(k suggestKey, dist int) {
+			consider(k, suggestScoreFuzzy-10*float64(dist), true)
+		})
+	}
+
+	suggestions := make([]Suggestion, 0, len(best))
+	for _, s := range best {
+		suggestions = append(suggestions, *s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if suggestions[i].Text != suggestions[j].Text {
+			return suggestions[i].Text < suggestions[j].Text
+		}
+		return suggestions[i].ID < suggestions[j].ID
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// fuzzyScan 找出前缀编辑距离（关键词与键的某个前缀之间的 Levenshtein 距离）在 1..maxDist 内的键
+// 键已按字典序排列，相当于按顺序遍历一棵字典树：相邻键共享前缀部分的动态规划行直接复用，
+// 某个前缀处的最小距离已超过 maxDist 时，以该前缀开头的键全部跳过（调用方持有读锁）
+func (x *SuggestIndex) fuzzyScan(q []rune, maxDist int, visit func(k suggestKey, dist int)) {
+	m := len(q)
+	width := m + maxDist
+
+	// rows[j] 为关键词与键前 j 个字符的编辑距离行；rowMin[j] 为该行最小值（随 j 单调不减）；
+	// best[j] 为键前 0..j 个字符中与完整关键词的最小距离
+	rows := make([][]int, width+1)
+	for j := range rows {
+		rows[j] = make([]int, m+1)
+	}
+	for i := 0; i <= m; i++ {
+		rows[0][i] = i
+	}
+	rowMin := make([]int, width+1)
+	best := make([]int, width+1)
+	best[0] = m
+
+	var prev []rune
+	computed := 0 // rows[0..computed] 对应 prev 的前缀，有效
+	for idx := 0; idx < len(x.keys); {
+		k := x.keys[idx]
+		head := k.key
+		if len(head) > width {
+			head = head[:width]
+		}
+
+		depth := 0
+		for depth < len(head) && depth < computed && head[depth] == prev[depth] {
+			depth++
+		}
+
+		pruned := depth > 0 && rowMin[depth] > maxDist
+		for j := depth + 1; j <= len(head) && !pruned; j++ {
+			c := head[j-1]
+			up, cur := rows[j-1], rows[j]
+			cur[0] = j
+			minVal := j
+			for i := 1; i <= m; i++ {
+				cost := 1
+				if q[i-1] == c {
+					cost = 0
+				}
+				cur[i] = min(up[i]+1, cur[i-1]+1, up[i-1]+cost)
+				minVal = min(minVal, cur[i])
+			}
+			rowMin[j] = minVal
+			best[j] = min(best[j-1], cur[m])
+			depth = j
+			pruned = minVal > maxDist
+		}
+		prev, computed = head, depth
+
+		if pruned {
+			// 跳过所有以 head[:depth] 开头的键
+			prefix := string(head[:depth])
+			next := idx + 1 + sort.Search(len(x.keys)-idx-1, func(i int) bool {
+				return !strings.HasPrefix(x.keys[idx+1+i].text, prefix)
+			})
+			if dist := best[depth]; dist > 0 && dist <= maxDist {
+				for ; idx < next; idx++ {
+					visit(x.keys[idx], dist)
+				}
+			}
+			idx = next
+			continue
+		}
+
+		if dist := best[len(head)]; dist > 0 && dist <= maxDist {
+			visit(k, dist)
+		}
+		idx++
+	}
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Token 分词结果，Start/End 为在原文中的字符（rune）位置
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// normalizeRune 归一化字符：全角英数与符号转半角、全角空格转空格、转小写
+func normalizeRune(r rune) rune {
+	switch {
+	case r >= 0xFF01 && r <= 0xFF5E:
+		r -= 0xFEE0
+	case r == 0x3000:
+		r = ' '
+	}
+	return unicode.ToLower(r)
+}
+
+// isCJK 是否为中日韩文字（汉字、平假名、片假名、谚文及长音符）
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// isWord 是否为非中日韩的单词字符
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokenize 分词：中日韩文字按相邻两字（bigram）切分，withUnigrams 为 true 时同时输出单字；
+// 其他字母与数字按连续片段切分为单词
+// 中日文没有空格分隔，bigram 无需词典即可匹配任意连续两字以上的关键词
+func tokenize(text string, withUnigrams bool) []Token {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = normalizeRune(r)
+	}
+
+	var tokens []Token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				j++
+			}
+			if j-i == 1 {
+				tokens = append(tokens, Token{Term: string(runes[i:j]), Start: i, End: j})
+			} else {
+				for k := i; k < j; k++ {
+					if withUnigrams {
+						tokens = append(tokens, Token{Term: string(runes[k]), Start: k, End: k + 1})
+					}
+					if k+1 < j {
+						tokens = append(tokens, Token{Term: string(runes[k : k+2]), Start: k, End: k + 2})
+					}
+				}
+			}
+			i = j
+		case isWord(r):
+			j := i
+			for j < len(runes) && isWord(runes[j]) && !isCJK(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, Token{Term: string(runes[i:j]), Start: i, End: j})
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// Tokenize 对文档文本分词（中日韩文字同时输出单字，以支持单字搜索）
+func Tokenize(text string) []Token {
+	return tokenize(text, true)
+}
+
+// QueryTerms 对搜索关键词分词并去重
+func QueryTerms(text string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, t := range tokenize(text, false) {
+		if !seen[t.Term] {
+			seen[t.Term] = true
+			terms = append(terms, t.Term)
+		}
+	}
+	return terms
+}
+
+// Highlight 用 <em> 标记文本中与关键词匹配的片段，其余文本做 HTML 转义；没有匹配时返回空字符串
+func Highlight(text, query string) string {
+	terms := map[string]bool{}
+	for _, term := range QueryTerms(query) {
+		terms[term] = true
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+
+	runes := []rune(text)
+	marked := make([]bool, len(runes))
+	matched := false
+	for _, t := range Tokenize(text) {
+		if terms[t.Term] {
+			matched = true
+			for i := t.Start; i < t.End; i++ {
+				marked[i] = true
+			}
+		}
+	}
+	if !matched {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			b.WriteString("<em>" + segment + "</em>")
+		} else {
+			b.WriteString(segment)
+		}
+		i = j
+	}
+	return b.String()
+}
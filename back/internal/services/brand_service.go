@@ -0,0 +1,312 @@
+package services
+
+import (
+	"errors"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"strconv"
+	"strings"
+)
+
+// BrandService 品牌服务接口
+// 品牌下的门店是普通商家（Business.BrandID 指向品牌），门店资料、营业时间与状态仍通过商家接口维护；
+// 品牌管理员（及系统管理员）可以维护品牌资料、品牌管理员，并为品牌添加或移出门店
+type BrandService interface {
+	// GetBrands 按统一列表查询条件获取品牌列表（含门店数量）
+	GetBrands(q *common.ListQuery) ([]*model.Brand, *common.ListPageInfo, error)
+	GetBrand(id uint) (*model.Brand, error)
+	// CreateBrand 创建品牌，仅商家用户或管理员可操作，创建人成为品牌管理员
+	CreateBrand(req *model.BrandRequest, actorID uint) (*model.Brand, error)
+	// UpdateBrand 修改品牌资料，未单独填写描述、图片的门店随之更新
+	UpdateBrand(id uint, req *model.BrandRequest, actorID uint) (*model.Brand, error)
+	// DeleteBrand 删除品牌，仍有门店时不能删除
+	DeleteBrand(id uint, actorID uint) error
+
+	// GetStores 获取品牌的门店，查询条件与商家列表相同
+	GetStores(id uint, q *common.ListQuery) ([]*model.Business, *common.ListPageInfo, error)
+	// GetStats 获取品牌汇总统计（门店状态、城市分布与评分）
+	GetStats(id uint) (*model.BrandStats, error)
+
+	// 品牌管理员
+	GetAdmins(id uint) ([]*model.BrandAdmin, error)
+	AddAdmin(id, userID, actorID uint) (*model.BrandAdmin, error)
+	RemoveAdmin(id, userID, actorID uint) error
+}
+
+// brandService 品牌服务实现
+type brandService struct {
+	brandRepo       repositories.BrandRepository
+	userRepo        repositories.UserRepository
+	categoryRepo    repositories.CategoryRepository
+	mediaService    MediaService
+	businessService BusinessService
+}
+
+// NewBrandService 创建品牌服务实例
+func NewBrandService(brandRepo repositories.BrandRepository, userRepo repositories.UserRepository, categoryRepo repositories.CategoryRepository, mediaService MediaService, businessService BusinessService) BrandService {
+	return &brandService{
+		brandRepo:       brandRepo,
+		userRepo:        userRepo,
+		categoryRepo:    categoryRepo,
+		mediaService:    mediaService,
+		businessService: businessService,
+	}
+}
+
+// getBrand 获取品牌
+func (s *brandService) getBrand(id uint) (*model.Brand, error) {
+	if id == 0 {
+		return nil, errors.New("无效的品牌ID")
+	}
+	brand, err := s.brandRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("品牌不存在")
+	}
+	return brand, nil
+}
+
+// checkManager 校验操作用户可以管理品牌（系统管理员或品牌管理员）
+func (s *brandService) checkManager(brandID, actorID uint) error {
+	if actor, err := s.userRepo.GetByID(actorID); err == nil && actor.IsAdmin() {
+		return nil
+	}
+	if ok, err := s.brandRepo.IsAdmin(brandID, actorID); err != nil || !ok {
+		return errors.New("只有品牌管理员可以执行该操作")
+	}
+	return nil
+}
+
+// withStoreCounts 设置标志地址与门店数量
+func (s *brandService) withStoreCounts(brands []*model.Brand) error {
+	ids := make([]uint, 0, len(brands))
+	for _, brand := range brands {
+		brand.ApplyLogoURL()
+		ids = append(ids, brand.ID)
+	}
+	counts, err := s.brandRepo.CountStores(ids)
+	if err != nil {
+		return err
+	}
+	for _, brand := range brands {
+		brand.StoreCount = counts[brand.ID]
+	}
+	return nil
+}
+
+// applyRequest 校验品牌资料并写入 brand，id 为修改的品牌（创建时为 0）
+func (s *brandService) applyRequest(brand *model.Brand, req *model.BrandRequest, id uint) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("品牌名称不能为空")
+	}
+	if len(name) > 255 {
+		return errors.New("品牌名称长度不能超过255个字符")
+	}
+	if existing, err := s.brandRepo.GetByName(name); err == nil && existing.ID != id {
+		return errors.New("品牌名称已被使用")
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email != "" && !validateEmail(email) {
+		return errors.New("邮箱格式不正确")
+	}
+	if len(req.Contact) > 255 {
+		return errors.New("联系方式长度不能超过255个字符")
+	}
+	if req.LogoMediaID != nil {
+		if _, err := s.mediaService.GetMedia(*req.LogoMediaID); err != nil {
+			return errors.New("品牌标志不存在")
+		}
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(*req.CategoryID); err != nil {
+			return errors.New("分类不存在: " + strconv.FormatUint(uint64(*req.CategoryID), 10))
+		}
+	}
+
+	brand.Name = name
+	brand.Email = email
+	brand.Contact = req.Contact
+	brand.Description = req.Description
+	brand.LogoMediaID = req.LogoMediaID
+	brand.CategoryID = req.CategoryID
+	return nil
+}
+
+// GetBrands 获取品牌列表
+func (s *brandService) GetBrands(q *common.ListQuery) ([]*model.Brand, *common.ListPageInfo, error) {
+	brands, page, err := s.brandRepo.List(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return brands, page, s.withStoreCounts(brands)
+}
+
+// GetBrand 获取品牌详情
+func (s *brandService) GetBrand(id uint) (*model.Brand, error) {
+	brand, err := s.getBrand(id)
+	if err != nil {
+		return nil, err
+	}
+	return brand, s.withStoreCounts([]*model.Brand{brand})
+}
+
+// CreateBrand 创建品牌
+func (s *brandService) CreateBrand(req *model.BrandRequest, actorID uint) (*model.Brand, error) {
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if !actor.IsAdmin() && !actor.IsMerchant() {
+		return nil, errors.New("只有商家用户或管理员可以创建品牌")
+	}
+
+	brand := &model.Brand{CreatedBy: actorID}
+	if err := s.applyRequest(brand, req, 0); err != nil {
+		return nil, err
+	}
+	if err := s.brandRepo.Create(brand); err != nil {
+		return nil, err
+	}
+	return s.GetBrand(brand.ID)
+}
+
+// UpdateBrand 修改品牌资料
+func (s *brandService) UpdateBrand(id uint, req *model.BrandRequest, actorID uint) (*model.Brand, error) {
+	brand, err := s.getBrand(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManager(id, actorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRequest(brand, req, id); err != nil {
+		return nil, err
+	}
+	if err := s.brandRepo.Update(brand); err != nil {
+		return nil, err
+	}
+	return s.GetBrand(id)
+}
+
+// DeleteBrand 删除品牌
+func (s *brandService) DeleteBrand(id uint, actorID uint) error {
+	if _, err := s.getBrand(id); err != nil {
+		return err
+	}
+	if err := s.checkManager(id, actorID); err != nil {
+		return err
+	}
+
+	counts, err := s.brandRepo.CountStores([]uint{id})
+	if err != nil {
+		return err
+	}
+	if counts[id] > 0 {
+		return errors.New("品牌下仍有" + strconv.FormatInt(counts[id], 10) + "家门店，请先移出或删除门店")
+	}
+	return s.brandRepo.Delete(id)
+}
+
+// GetStores 获取品牌的门店
+func (s *brandService) GetStores(id uint, q *common.ListQuery) ([]*model.Business, *common.ListPageInfo, error) {
+	if _, err := s.getBrand(id); err != nil {
+		return nil, nil, err
+	}
+	if q.HasFilter("brandId") {
+		return nil, nil, common.InvalidListQuery("门店列表不支持按 brandId 筛选")
+	}
+
+	stores := *q
+	stores.Filters = append(append([]common.ListFilter{}, q.Filters...), common.ListFilter{
+		Field:  "brandId",
+		Op:     common.FilterOpEq,
+		Values: []string{strconv.FormatUint(uint64(id), 10)},
+	})
+	return s.businessService.GetBusinesses(&stores, model.RegionFilter{}, nil)
+}
+
+// GetStats 获取品牌汇总统计
+func (s *brandService) GetStats(id uint) (*model.BrandStats, error) {
+	if _, err := s.getBrand(id); err != nil {
+		return nil, err
+	}
+	return s.brandRepo.GetStats(id)
+}
+
+// GetAdmins 获取品牌管理员
+func (s *brandService) GetAdmins(id uint) ([]*model.BrandAdmin, error) {
+	if _, err := s.getBrand(id); err != nil {
+		return nil, err
+	}
+	admins, err := s.brandRepo.GetAdmins(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, admin := range admins {
+		if user, err := s.userRepo.GetByID(admin.UserID); err == nil {
+			admin.Username = user.Username
+		}
+	}
+	return admins, nil
+}
+
+// AddAdmin 添加品牌管理员
+func (s *brandService) AddAdmin(id, userID, actorID uint) (*model.BrandAdmin, error) {
+	if _, err := s.getBrand(id); err != nil {
+		return nil, err
+	}
+	if err := s.checkManager(id, actorID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if !user.IsActive() {
+		return nil, errors.New("用户未启用")
+	}
+	if ok, err := s.brandRepo.IsAdmin(id, userID); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, errors.New("该用户已是品牌管理员")
+	}
+
+	admin := &model.BrandAdmin{BrandID: id, UserID: userID, CreatedBy: actorID}
+	if err := s.brandRepo.AddAdmin(admin); err != nil {
+		return nil, err
+	}
+	admin.Username = user.Username
+	return admin, nil
+}
+
+// RemoveAdmin 移除品牌管理员，品牌至少保留一名管理员
+func (s *brandService) RemoveAdmin(id, userID, actorID uint) error {
+	if _, err := s.getBrand(id); err != nil {
+		return err
+	}
+	if err := s.checkManager(id, actorID); err != nil {
+		return err
+	}
+
+	admins, err := s.brandRepo.GetAdmins(id)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, admin := range admins {
+		if admin.UserID == userID {
+			found = true
+		}
+	}
+	if !found {
+		return errors.New("该用户不是品牌管理员")
+	}
+	if len(admins) == 1 {
+		return errors.New("品牌至少需要保留一名管理员")
+	}
+	return s.brandRepo.RemoveAdmin(id, userID)
+}
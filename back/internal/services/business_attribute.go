@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"strings"
+	"unicode/utf8"
+)
+
+// 标签限制
+const (
+	maxBusinessTags      = 20 // 每个商家最多的标签数
+	maxBusinessTagLength = 50 // 标签最大长度（字符）
+	defaultTagLimit      = 20 // 标签统计默认返回数量
+	maxTagLimit          = 100
+)
+
+// normalizeTags 去除标签首尾空白与空标签，不区分大小写去重（保留先出现的写法）
+// 标签在查询参数中以逗号分隔，因此不能包含逗号
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if strings.Contains(tag, ",") {
+			return nil, errors.New("标签不能包含逗号: " + tag)
+		}
+		if utf8.RuneCountInString(tag) > maxBusinessTagLength {
+			return nil, errors.New("标签不能超过50个字符: " + tag)
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxBusinessTags {
+		return nil, errors.New("每个商家最多20个标签")
+	}
+	return normalized, nil
+}
+
+// attributeSchema 合并商家所属分类的属性定义，主分类的定义优先
+func (s *businessService) attributeSchema(categoryIDs []uint) (*common.AttributeSchema, error) {
+	categories, err := s.categoryRepo.GetByIDs(categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	schemas := make([]*common.AttributeSchema, 0, len(categoryIDs))
+	for _, id := range categoryIDs {
+		category, ok := byID[id]
+		if !ok || len(category.AttributeSchema) == 0 {
+			continue
+		}
+		schema, err := common.ParseAttributeSchema(category.AttributeSchema)
+		if err != nil {
+			return nil, errors.New("分类 " + category.Slug + " 的" + err.Error())
+		}
+		schemas = append(schemas, schema)
+	}
+	return common.MergeAttributeSchemas(schemas...), nil
+}
+
+// prepareTagsAndAttributes 规范化标签，并按所属分类的属性定义校验自定义属性
+// categoryIDs 为商家保存后所属的分类（主分类在前）
+func (s *businessService) prepareTagsAndAttributes(business *model.Business, categoryIDs []uint) error {
+	tags, err := normalizeTags(business.Tags)
+	if err != nil {
+		return err
+	}
+	business.Tags = tags
+
+	schema, err := s.attributeSchema(categoryIDs)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(business.Attributes)
+}
+
+// keepTagsAndAttributes 更新商家时未传 attributes 的保留原有属性（tags 为 nil 时由 saveTagsAndAttributes 保留原有标签），
+// 并按更新后所属的分类校验；CategoryIDs 为 nil 表示分类未变化
+func (s *businessService) keepTagsAndAttributes(business, existing *model.Business) error {
+	if business.Attributes == nil {
+		business.Attributes = existing.Attributes
+	}
+	categoryIDs := business.CategoryIDs
+	if categoryIDs == nil {
+		if err := s.categoryRepo.LoadBusinessCategories([]*model.Business{existing}); err != nil {
+			return err
+		}
+		categoryIDs = existing.CategoryIDs
+	}
+	return s.prepareTagsAndAttributes(business, categoryIDs)
+}
+
+// saveTagsAndAttributes 保存商家标签（Tags 为 nil 表示未变化）并重建自定义属性索引
+func (s *businessService) saveTagsAndAttributes(business *model.Business) error {
+	if business.Tags != nil {
+		if err := s.attributeRepo.SetTags(business.ID, business.Tags); err != nil {
+			return err
+		}
+	}
+	return s.attributeRepo.IndexAttributes(business.ID, business.Attributes)
+}
+
+// loadCategoriesAndTags 批量加载商家所属分类与标签
+func (s *businessService) loadCategoriesAndTags(businesses []*model.Business) error {
+	if err := s.categoryRepo.LoadBusinessCategories(businesses); err != nil {
+		return err
+	}
+	return s.attributeRepo.LoadTags(businesses)
+}
+
+// businessListFields 商家列表允许筛选与排序的字段：固定字段加上各分类定义的自定义属性（attributes.属性名）
+// 不同分类定义了同名属性时，任一定义为数值类型即按数值处理
+func (s *businessService) businessListFields() (repositories.ListFields, error) {
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(repositories.ListFields, len(repositories.BusinessListFields))
+	for name, field := range repositories.BusinessListFields {
+		fields[name] = field
+	}
+	numeric := map[string]bool{}
+	for _, category := range categories {
+		if len(category.AttributeSchema) == 0 {
+			continue
+		}
+		schema, err := common.ParseAttributeSchema(category.AttributeSchema)
+		if err != nil {
+			continue
+		}
+		for name, prop := range schema.Properties {
+			numeric[name] = numeric[name] || prop.IsNumeric()
+		}
+	}
+	for name, isNumeric := range numeric {
+		fields["attributes."+name] = repositories.BusinessAttributeListField(name, isNumeric)
+	}
+	return fields, nil
+}
+
+// GetTags 按使用的商家数倒序返回标签，用于标签联想与标签云
+func (s *businessService) GetTags(prefix string, limit int) ([]*model.TagCount, error) {
+	if limit <= 0 {
+		limit = defaultTagLimit
+	}
+	if limit > maxTagLimit {
+		limit = maxTagLimit
+	}
+	return s.attributeRepo.TagCounts(strings.TrimSpace(prefix), limit)
+}
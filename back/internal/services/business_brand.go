@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	model "merchant_back/internal/models"
+)
+
+// validateBusinessEmail 校验商家邮箱：独立商家必填，品牌门店可不填（统一使用品牌邮箱）
+func validateBusinessEmail(business *model.Business) error {
+	if business.Email == "" {
+		if business.BrandID != nil {
+			return nil
+		}
+		return errors.New("邮箱不能为空")
+	}
+	if !validateEmail(business.Email) {
+		return errors.New("邮箱格式不正确")
+	}
+	return nil
+}
+
+// emailInUse 邮箱是否已被其他未删除的商家使用，空邮箱（品牌门店）不占用
+func (s *businessService) emailInUse(email string) bool {
+	if email == "" {
+		return false
+	}
+	existing, err := s.businessRepo.GetByEmail(email)
+	return err == nil && existing != nil
+}
+
+// canManageBrand 操作用户是否可以管理品牌及其门店（系统管理员或品牌管理员）
+func (s *businessService) canManageBrand(brandID, actorID uint) bool {
+	if actor, err := s.userRepo.GetByID(actorID); err == nil && actor.IsAdmin() {
+		return true
+	}
+	ok, err := s.brandRepo.IsAdmin(brandID, actorID)
+	return err == nil && ok
+}
+
+// canManageStore 操作用户是否拥有商家的管理权限（系统管理员、所属品牌的管理员或店主），与 BusinessMemberService.CheckPermission 一致
+func (s *businessService) canManageStore(business *model.Business, actorID uint) bool {
+	if business.BrandID != nil && s.canManageBrand(*business.BrandID, actorID) {
+		return true
+	}
+	if actor, err := s.userRepo.GetByID(actorID); err == nil && actor.IsAdmin() {
+		return true
+	}
+	member, err := s.memberRepo.Get(business.ID, actorID)
+	return err == nil && member.IsActive() && model.BusinessRoleAllows(member.Role, model.BusinessPermissionManage)
+}
+
+// prepareBusinessBrand 校验门店所属品牌，existing 为修改前的商家（创建时为 nil）
+// 加入品牌须为该品牌的管理员，已有商家加入品牌还须拥有该商家的管理权限（品牌管理员拥有门店的全部权限）；
+// 移出品牌须为原品牌的管理员（系统管理员不受限制）。
+// 新门店未指定分类时使用品牌的默认分类；与品牌相同的描述、图片不单独保存，以便随品牌更新；
+// 移出品牌时保留原先继承的描述与图片
+func (s *businessService) prepareBusinessBrand(business, existing *model.Business, actorID uint) error {
+	if business.BrandID != nil && *business.BrandID == 0 {
+		business.BrandID = nil
+	}
+	var previous *uint
+	if existing != nil {
+		previous = existing.BrandID
+	}
+
+	changed := (previous == nil) != (business.BrandID == nil) ||
+		(previous != nil && business.BrandID != nil && *previous != *business.BrandID)
+	if changed && previous != nil {
+		if old, err := s.brandRepo.GetByID(*previous); err == nil {
+			if !s.canManageBrand(old.ID, actorID) {
+				return errors.New("只有原品牌的管理员可以将门店移出品牌")
+			}
+			if business.BrandID == nil {
+				if business.Description == nil {
+					business.Description = old.Description
+				}
+				if business.ImageMediaID == nil && business.ImageBase64 == nil {
+					business.ImageMediaID = old.LogoMediaID
+				}
+			}
+		}
+	}
+	if business.BrandID == nil {
+		return nil
+	}
+
+	brand, err := s.brandRepo.GetByID(*business.BrandID)
+	if err != nil {
+		return errors.New("品牌不存在")
+	}
+	if changed && !s.canManageBrand(brand.ID, actorID) {
+		return errors.New("只有品牌管理员可以为品牌添加门店")
+	}
+	if changed && existing != nil && !s.canManageStore(existing, actorID) {
+		return errors.New("只有店主可以将商家加入品牌")
+	}
+
+	if existing == nil && business.Type == "" && len(business.CategoryIDs) == 0 && brand.CategoryID != nil {
+		business.CategoryIDs = []uint{*brand.CategoryID}
+	}
+	if business.Description != nil && brand.Description != nil && *business.Description == *brand.Description {
+		business.Description = nil
+	}
+	if business.ImageMediaID != nil && brand.LogoMediaID != nil && *business.ImageMediaID == *brand.LogoMediaID {
+		business.ImageMediaID = nil
+	}
+	return nil
+}
+
+// attachBrands 门店未填写描述、图片时使用所属品牌的描述与标志，继承的字段记录在 InheritedFields 中
+func (s *businessService) attachBrands(businesses []*model.Business) error {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, business := range businesses {
+		business.InheritedFields = nil
+		if business.BrandID == nil || seen[*business.BrandID] {
+			continue
+		}
+		if business.Description == nil || business.ImageMediaID == nil {
+			seen[*business.BrandID] = true
+			ids = append(ids, *business.BrandID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	brands, err := s.brandRepo.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*model.Brand, len(brands))
+	for _, brand := range brands {
+		byID[brand.ID] = brand
+	}
+	for _, business := range businesses {
+		if business.BrandID == nil {
+			continue
+		}
+		brand := byID[*business.BrandID]
+		if brand == nil {
+			continue
+		}
+		if business.Description == nil && brand.Description != nil {
+			business.Description = brand.Description
+			business.InheritedFields = append(business.InheritedFields, model.BrandInheritedDescription)
+		}
+		if business.ImageMediaID == nil && brand.LogoMediaID != nil {
+			business.ImageMediaID = brand.LogoMediaID
+			business.InheritedFields = append(business.InheritedFields, model.BrandInheritedImage)
+		}
+	}
+	return nil
+}
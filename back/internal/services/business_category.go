@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	model "merchant_back/internal/models"
+	"strconv"
+	"strings"
+)
+
+// resolveBusinessCategories 确定商家所属分类，并将 type 设为主分类的标识
+// 传入 categoryIds 时按其设置（第一个为主分类）；否则按 type 匹配分类的标识或任一语言的名称，
+// 兼容只传 type 的客户端，"Restaurant"、"restaurant"、"餐厅" 都归入同一分类
+func (s *businessService) resolveBusinessCategories(business *model.Business) error {
+	if len(business.CategoryIDs) == 0 {
+		if strings.TrimSpace(business.Type) == "" {
+			return errors.New("商家分类不能为空")
+		}
+		category, err := s.findCategoryByLabel(business.Type)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return errors.New("商家类型无效: " + business.Type)
+		}
+		business.CategoryIDs = []uint{category.ID}
+		business.Type = category.Slug
+		return nil
+	}
+
+	seen := make(map[uint]bool, len(business.CategoryIDs))
+	ids := make([]uint, 0, len(business.CategoryIDs))
+	for _, id := range business.CategoryIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxBusinessCategoryNum {
+		return errors.New("每个商家最多关联5个分类")
+	}
+
+	categories, err := s.categoryRepo.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	slugs := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		slugs[category.ID] = category.Slug
+	}
+	for _, id := range ids {
+		if _, ok := slugs[id]; !ok {
+			return errors.New("分类不存在: " + strconv.FormatUint(uint64(id), 10))
+		}
+	}
+
+	business.CategoryIDs = ids
+	business.Type = slugs[ids[0]]
+	return nil
+}
+
+// findCategoryByLabel 按标识或任一语言的名称查找分类（不区分大小写），标识优先，未找到时返回 nil
+func (s *businessService) findCategoryByLabel(label string) (*model.Category, error) {
+	label = strings.TrimSpace(label)
+	if category, err := s.categoryRepo.GetBySlug(strings.ToLower(label)); err == nil {
+		return category, nil
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		if category.Matches(label) {
+			return category, nil
+		}
+	}
+	return nil, nil
+}
+
+// normalizeBusinessType 将类型查询条件转换为对应分类的标识，未匹配任何分类时原样返回
+func (s *businessService) normalizeBusinessType(businessType string) string {
+	if category, err := s.findCategoryByLabel(businessType); err == nil && category != nil {
+		return category.Slug
+	}
+	return businessType
+}
+
+// existingCategoryIDs 过滤掉已不存在的分类ID，保持原有顺序
+func (s *businessService) existingCategoryIDs(ids []uint) ([]uint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	categories, err := s.categoryRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	var kept []uint
+	for _, id := range ids {
+		if exists[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept, nil
+}
+
+// saveBusinessCategories 保存商家所属分类，CategoryIDs 为空表示分类未变化
+func (s *businessService) saveBusinessCategories(business *model.Business) error {
+	if len(business.CategoryIDs) == 0 {
+		return nil
+	}
+	return s.categoryRepo.SetBusinessCategories(business.ID, business.CategoryIDs)
+}
+
+// RefreshSearchIndex 刷新商家的搜索索引，供在商家服务之外修改商家数据（如合并分类）后调用
+func (s *businessService) RefreshSearchIndex(ids ...int) {
+	s.refreshSearchIndex(ids...)
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"merchant_back/internal/storage"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrContractAttachmentNotPDF 合同附件不是 PDF
+var ErrContractAttachmentNotPDF = errors.New("合同附件仅支持 PDF")
+
+// ErrNoEffectiveContract 指定日期没有生效的合同
+var ErrNoEffectiveContract = errors.New("该日期没有生效的合同")
+
+// contractKeyPrefix 合同附件在存储中的键前缀
+const contractKeyPrefix = "contracts"
+
+// contractAttachmentTypes 允许上传的合同附件类型
+var contractAttachmentTypes = map[string]string{"application/pdf": ".pdf"}
+
+// BusinessContractService 商家合同服务接口
+type BusinessContractService interface {
+	GetContracts(businessID int) ([]*model.BusinessContract, error)
+	GetContract(businessID int, id uint) (*model.BusinessContract, error)
+	// CreateContract 创建合同，有效期不能与该商家的其他合同重叠（可补录已结束的历史合同）
+	CreateContract(businessID int, req *model.BusinessContractRequest, actorID uint) (*model.BusinessContract, error)
+	// UpdateContract 修改合同：未生效的合同可修改全部条款；已生效的合同只能修改备注，及尚未结束时修改结束日期（不早于今天）
+	UpdateContract(businessID int, id uint, req *model.BusinessContractRequest) (*model.BusinessContract, error)
+	// DeleteContract 删除未生效的合同
+	DeleteContract(businessID int, id uint) error
+	// UploadAttachment 上传已签署的合同 PDF，替换原附件
+	UploadAttachment(businessID int, id uint, upload *DocumentUpload) (*model.BusinessContract, error)
+	// OpenAttachment 读取合同附件，由调用方关闭
+	OpenAttachment(businessID int, id uint) (*model.BusinessContract, io.ReadCloser, error)
+	// GetEffectiveTerms 获取商家在指定日期（YYYY-MM-DD，为空时为今天）生效的合同条款，
+	// 指定结算周期交易额（分）时同时返回适用的佣金比例
+	GetEffectiveTerms(businessID int, date string, amount *int64) (*model.ContractTerms, error)
+	// MaxUploadSize 单个文件的最大字节数
+	MaxUploadSize() int64
+}
+
+// businessContractService 商家合同服务实现
+type businessContractService struct {
+	contractRepo  repositories.BusinessContractRepository
+	businessRepo  repositories.BusinessRepository
+	storage       storage.Storage
+	maxUploadSize int64
+}
+
+// NewBusinessContractService 创建商家合同服务实例
+func NewBusinessContractService(contractRepo repositories.BusinessContractRepository, businessRepo repositories.BusinessRepository, store storage.Storage, maxUploadSize int64) BusinessContractService {
+	return &businessContractService{
+		contractRepo:  contractRepo,
+		businessRepo:  businessRepo,
+		storage:       store,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// MaxUploadSize 单个文件的最大字节数
+func (s *businessContractService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// getContract 获取商家的合同
+func (s *businessContractService) getContract(businessID int, id uint) (*model.BusinessContract, error) {
+	contract, err := s.contractRepo.GetByID(id)
+	if err != nil || contract.BusinessID != businessID {
+		return nil, errors.New("合同不存在")
+	}
+	return contract, nil
+}
+
+// validateCommissionRate 校验佣金比例：0-100，最多两位小数
+func validateCommissionRate(rate float64) error {
+	if rate < 0 || rate > 100 || math.IsNaN(rate) {
+		return errors.New("佣金比例必须在 0 到 100 之间")
+	}
+	if math.Abs(rate*100-math.Round(rate*100)) > 1e-6 {
+		return errors.New("佣金比例最多保留两位小数")
+	}
+	return nil
+}
+
+// validateContractRequest 校验并规范化合同条款
+func validateContractRequest(req *model.BusinessContractRequest) error {
+	req.ContractNo = strings.TrimSpace(req.ContractNo)
+	req.StartDate = strings.TrimSpace(req.StartDate)
+	req.EndDate = strings.TrimSpace(req.EndDate)
+	req.Notes = strings.TrimSpace(req.Notes)
+
+	if req.ContractNo == "" {
+		return errors.New("合同编号不能为空")
+	}
+	if len(req.ContractNo) > 64 {
+		return errors.New("合同编号长度不能超过64个字符")
+	}
+	if _, err := time.Parse(dateLayout, req.StartDate); err != nil {
+		return errors.New("生效日期格式应为 YYYY-MM-DD")
+	}
+	if req.EndDate != "" {
+		if _, err := time.Parse(dateLayout, req.EndDate); err != nil {
+			return errors.New("截止日期格式应为 YYYY-MM-DD")
+		}
+		if req.EndDate < req.StartDate {
+			return errors.New("截止日期不能早于生效日期")
+		}
+	}
+
+	switch req.CommissionType {
+	case model.CommissionTypePercentage:
+		if err := validateCommissionRate(req.CommissionRate); err != nil {
+			return err
+		}
+		req.CommissionTiers = nil
+	case model.CommissionTypeTiered:
+		if len(req.CommissionTiers) == 0 {
+			return errors.New("阶梯佣金至少需要一档")
+		}
+		for i, tier := range req.CommissionTiers {
+			if i == 0 && tier.MinAmount != 0 {
+				return errors.New("第一档阶梯佣金的交易额下限必须为 0")
+			}
+			if i > 0 && tier.MinAmount <= req.CommissionTiers[i-1].MinAmount {
+				return errors.New("阶梯佣金的交易额下限必须递增")
+			}
+			if err := validateCommissionRate(tier.Rate); err != nil {
+				return err
+			}
+		}
+		req.CommissionRate = 0
+	default:
+		return errors.New("佣金计算方式无效")
+	}
+
+	if req.FixedFee < 0 {
+		return errors.New("固定费用不能为负数")
+	}
+	switch req.FixedFeePeriod {
+	case model.FeePeriodNone:
+		if req.FixedFee > 0 {
+			return errors.New("请指定固定费用的收取周期")
+		}
+	case model.FeePeriodOnce, model.FeePeriodMonthly, model.FeePeriodYearly:
+		if req.FixedFee == 0 {
+			req.FixedFeePeriod = model.FeePeriodNone
+		}
+	default:
+		return errors.New("固定费用收取周期无效")
+	}
+
+	switch req.SettlementCycle {
+	case model.SettlementCycleDaily, model.SettlementCycleWeekly, model.SettlementCycleMonthly:
+	default:
+		return errors.New("结算周期无效")
+	}
+	if req.PaymentDays < 0 || req.PaymentDays > 365 {
+		return errors.New("账期天数必须在 0 到 365 之间")
+	}
+	return nil
+}
+
+// applyContractRequest 设置合同条款
+func applyContractRequest(contract *model.BusinessContract, req *model.BusinessContractRequest) {
+	contract.ContractNo = req.ContractNo
+	contract.StartDate = req.StartDate
+	contract.EndDate = req.EndDate
+	contract.CommissionType = req.CommissionType
+	contract.CommissionRate = req.CommissionRate
+	contract.CommissionTiers = req.CommissionTiers
+	contract.FixedFee = req.FixedFee
+	contract.FixedFeePeriod = req.FixedFeePeriod
+	contract.SettlementCycle = req.SettlementCycle
+	contract.PaymentDays = req.PaymentDays
+	contract.Notes = req.Notes
+}
+
+// sameContractTerms 修改后除结束日期与备注外的条款是否不变
+func sameContractTerms(contract *model.BusinessContract, req *model.BusinessContractRequest) bool {
+	return contract.ContractNo == req.ContractNo &&
+		contract.StartDate == req.StartDate &&
+		contract.CommissionType == req.CommissionType &&
+		contract.CommissionRate == req.CommissionRate &&
+		(len(contract.CommissionTiers) == 0 && len(req.CommissionTiers) == 0 || reflect.DeepEqual(contract.CommissionTiers, req.CommissionTiers)) &&
+		contract.FixedFee == req.FixedFee &&
+		contract.FixedFeePeriod == req.FixedFeePeriod &&
+		contract.SettlementCycle == req.SettlementCycle &&
+		contract.PaymentDays == req.PaymentDays
+}
+
+// GetContracts 获取商家的合同
+func (s *businessContractService) GetContracts(businessID int) ([]*model.BusinessContract, error) {
+	contracts, err := s.contractRepo.ListByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	for _, contract := range contracts {
+		contract.ApplyAttachmentURL()
+	}
+	return contracts, nil
+}
+
+// GetContract 获取合同
+func (s *businessContractService) GetContract(businessID int, id uint) (*model.BusinessContract, error) {
+	contract, err := s.getContract(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	contract.ApplyAttachmentURL()
+	return contract, nil
+}
+
+// CreateContract 创建合同
+func (s *businessContractService) CreateContract(businessID int, req *model.BusinessContractRequest, actorID uint) (*model.BusinessContract, error) {
+	if _, err := s.businessRepo.GetByID(businessID); err != nil {
+		return nil, errors.New("商家不存在")
+	}
+	if err := validateContractRequest(req); err != nil {
+		return nil, err
+	}
+
+	contract := &model.BusinessContract{BusinessID: businessID, CreatedBy: actorID}
+	applyContractRequest(contract, req)
+	if err := s.contractRepo.Create(contract); err != nil {
+		return nil, err
+	}
+	contract.ApplyAttachmentURL()
+	return contract, nil
+}
+
+// UpdateContract 修改合同
+func (s *businessContractService) UpdateContract(businessID int, id uint, req *model.BusinessContractRequest) (*model.BusinessContract, error) {
+	contract, err := s.getContract(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateContractRequest(req); err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Format(dateLayout)
+	if contract.StartDate <= today {
+		if !sameContractTerms(contract, req) {
+			return nil, errors.New("已生效的合同只能修改结束日期与备注，其他条款变更请签订新合同")
+		}
+		if req.EndDate != contract.EndDate {
+			if contract.EndDate != "" && contract.EndDate < today {
+				return nil, errors.New("已结束的合同不能修改结束日期")
+			}
+			if req.EndDate != "" && req.EndDate < today {
+				return nil, errors.New("结束日期不能早于今天")
+			}
+		}
+	}
+
+	applyContractRequest(contract, req)
+	if err := s.contractRepo.Update(contract); err != nil {
+		return nil, err
+	}
+	contract.ApplyAttachmentURL()
+	return contract, nil
+}
+
+// DeleteContract 删除合同及附件
+func (s *businessContractService) DeleteContract(businessID int, id uint) error {
+	contract, err := s.getContract(businessID, id)
+	if err != nil {
+		return err
+	}
+	if contract.StartDate <= time.Now().Format(dateLayout) {
+		return errors.New("已生效的合同不能删除，可修改结束日期提前终止")
+	}
+	if err := s.contractRepo.Delete(id); err != nil {
+		return err
+	}
+	if contract.AttachmentKey == "" {
+		return nil
+	}
+	return s.storage.Delete(context.Background(), contract.AttachmentKey)
+}
+
+// UploadAttachment 上传合同附件
+func (s *businessContractService) UploadAttachment(businessID int, id uint, upload *DocumentUpload) (*model.BusinessContract, error) {
+	contract, err := s.getContract(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	file, err := storePrivateFile(s.storage, contractKeyPrefix, upload, s.maxUploadSize, contractAttachmentTypes, ErrContractAttachmentNotPDF)
+	if err != nil {
+		return nil, err
+	}
+
+	oldKey := contract.AttachmentKey
+	contract.AttachmentKey = file.Key
+	contract.AttachmentFilename = file.Filename
+	contract.AttachmentSize = file.Size
+	contract.AttachmentChecksum = file.Checksum
+	if err := s.contractRepo.UpdateAttachment(contract); err != nil {
+		_ = s.storage.Delete(context.Background(), file.Key)
+		return nil, err
+	}
+	if oldKey != "" {
+		if err := s.storage.Delete(context.Background(), oldKey); err != nil {
+			log.Printf("Delete replaced contract attachment %s failed: %v", oldKey, err)
+		}
+	}
+	contract.ApplyAttachmentURL()
+	return contract, nil
+}
+
+// OpenAttachment 读取合同附件
+func (s *businessContractService) OpenAttachment(businessID int, id uint) (*model.BusinessContract, io.ReadCloser, error) {
+	contract, err := s.getContract(businessID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if contract.AttachmentKey == "" {
+		return nil, nil, errors.New("合同尚未上传附件")
+	}
+	body, err := s.storage.Get(context.Background(), contract.AttachmentKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, errors.New("文件不存在")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return contract, body, nil
+}
+
+// GetEffectiveTerms 获取生效的合同条款
+func (s *businessContractService) GetEffectiveTerms(businessID int, date string, amount *int64) (*model.ContractTerms, error) {
+	if date == "" {
+		date = time.Now().Format(dateLayout)
+	} else if _, err := time.Parse(dateLayout, date); err != nil {
+		return nil, errors.New("日期格式应为 YYYY-MM-DD")
+	}
+	if amount != nil && *amount < 0 {
+		return nil, errors.New("交易额不能为负数")
+	}
+
+	contract, err := s.contractRepo.GetEffective(businessID, date)
+	if err != nil {
+		return nil, ErrNoEffectiveContract
+	}
+	contract.ApplyAttachmentURL()
+
+	terms := &model.ContractTerms{Date: date, Contract: contract, Amount: amount}
+	if amount != nil {
+		rate := contract.RateFor(*amount)
+		terms.Rate = &rate
+	}
+	return terms, nil
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"merchant_back/internal/storage"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrDocumentTypeNotAllowed 不支持的证件文件类型
+var ErrDocumentTypeNotAllowed = errors.New("证件文件仅支持 PDF、JPEG、PNG")
+
+// documentKeyPrefix 资质证件在存储中的键前缀
+const documentKeyPrefix = "documents"
+
+// lapsedDocumentBatchSize 到期检查每批处理的过期证件数量
+const lapsedDocumentBatchSize = 100
+
+// DocumentExpiryRules 资质证件到期检查规则
+type DocumentExpiryRules struct {
+	WarningDays    int      // 有效期在该天数内的证件标记为即将到期
+	MandatoryTypes []string // 必备证件类型
+	AutoSuspend    bool     // 已核验的必备证件过期且没有其他有效的同类证件时，自动暂停正常营业的商家
+}
+
+// DocumentUpload 上传的文件（证件、合同附件）
+type DocumentUpload struct {
+	File     io.Reader
+	Size     int64
+	Filename string
+}
+
+// BusinessDocumentService 商家资质证件服务接口
+type BusinessDocumentService interface {
+	GetDocuments(businessID int) ([]*model.BusinessDocument, error)
+	GetDocument(businessID int, id uint) (*model.BusinessDocument, error)
+	// UploadDocument 上传资质证件，文件类型按内容识别，上传后等待管理员核验
+	UploadDocument(businessID int, req *model.BusinessDocumentRequest, upload *DocumentUpload, actorID uint) (*model.BusinessDocument, error)
+	// UpdateDocument 修改证件信息，upload 为空时保留原文件；修改后需重新核验
+	UpdateDocument(businessID int, id uint, req *model.BusinessDocumentRequest, upload *DocumentUpload, actorID uint) (*model.BusinessDocument, error)
+	// DeleteDocument 删除证件及文件，已核验的证件仅管理员可删除
+	DeleteDocument(businessID int, id uint, actorID uint) error
+	// OpenDocumentFile 读取证件文件，由调用方关闭
+	OpenDocumentFile(businessID int, id uint) (*model.BusinessDocument, io.ReadCloser, error)
+
+	// 管理员核验
+	VerifyDocument(businessID int, id uint, actorID uint) (*model.BusinessDocument, error)
+	RejectDocument(businessID int, id uint, actorID uint, reason string) (*model.BusinessDocument, error)
+	// GetExpiringDocuments 获取 days 天内到期的待审核或已核验证件，days 为 0 时使用配置的提醒天数
+	GetExpiringDocuments(days int) ([]*model.BusinessDocument, error)
+
+	// CheckDocumentExpiry 标记即将到期的证件、将过期证件标记为已过期，并按规则暂停必备证件缺失的商家
+	CheckDocumentExpiry(now time.Time) (*model.DocumentExpiryResult, error)
+	// MaxUploadSize 单个文件的最大字节数
+	MaxUploadSize() int64
+}
+
+// businessDocumentService 商家资质证件服务实现
+type businessDocumentService struct {
+	documentRepo    repositories.BusinessDocumentRepository
+	businessRepo    repositories.BusinessRepository
+	userRepo        repositories.UserRepository
+	businessService BusinessService
+	storage         storage.Storage
+	maxUploadSize   int64
+	rules           DocumentExpiryRules
+}
+
+// NewBusinessDocumentService 创建商家资质证件服务实例
+func NewBusinessDocumentService(documentRepo repositories.BusinessDocumentRepository, businessRepo repositories.BusinessRepository, userRepo repositories.UserRepository, businessService BusinessService, store storage.Storage, maxUploadSize int64, rules DocumentExpiryRules) BusinessDocumentService {
+	return &businessDocumentService{
+		documentRepo:    documentRepo,
+		businessRepo:    businessRepo,
+		userRepo:        userRepo,
+		businessService: businessService,
+		storage:         store,
+		maxUploadSize:   maxUploadSize,
+		rules:           rules,
+	}
+}
+
+// MaxUploadSize 单个文件的最大字节数
+func (s *businessDocumentService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// getDocument 获取商家的证件
+func (s *businessDocumentService) getDocument(businessID int, id uint) (*model.BusinessDocument, error) {
+	document, err := s.documentRepo.GetByID(id)
+	if err != nil || document.BusinessID != businessID {
+		return nil, errors.New("证件不存在")
+	}
+	return document, nil
+}
+
+// validateDocumentRequest 校验证件信息
+func validateDocumentRequest(req *model.BusinessDocumentRequest, today string) error {
+	req.Number = strings.TrimSpace(req.Number)
+	req.IssuingAuthority = strings.TrimSpace(req.IssuingAuthority)
+	req.ValidFrom = strings.TrimSpace(req.ValidFrom)
+	req.ValidUntil = strings.TrimSpace(req.ValidUntil)
+
+	if !model.IsValidDocumentType(req.Type) {
+		return errors.New("证件类型无效")
+	}
+	if req.Number == "" {
+		return errors.New("证件编号不能为空")
+	}
+	if len(req.Number) > 100 {
+		return errors.New("证件编号长度不能超过100个字符")
+	}
+	if len(req.IssuingAuthority) > 255 {
+		return errors.New("发证机关长度不能超过255个字符")
+	}
+	for _, date := range []string{req.ValidFrom, req.ValidUntil} {
+		if _, err := time.Parse(dateLayout, date); date != "" && err != nil {
+			return errors.New("有效期日期格式应为 YYYY-MM-DD")
+		}
+	}
+	if req.ValidFrom != "" && req.ValidUntil != "" && req.ValidUntil < req.ValidFrom {
+		return errors.New("有效期结束日期不能早于开始日期")
+	}
+	if req.ValidUntil != "" && req.ValidUntil < today {
+		return errors.New("证件已过有效期")
+	}
+	return nil
+}
+
+// storedFile 写入存储的私有文件（证件、合同附件等）
+type storedFile struct {
+	Key         string
+	Filename    string
+	ContentType string
+	Size        int64
+	Checksum    string
+}
+
+// storePrivateFile 校验文件大小与类型（按内容识别，allowed 为允许的类型及扩展名）后写入存储
+func storePrivateFile(store storage.Storage, prefix string, upload *DocumentUpload, maxSize int64, allowed map[string]string, errNotAllowed error) (*storedFile, error) {
+	if upload.Size <= 0 {
+		return nil, errors.New("文件不能为空")
+	}
+	if upload.Size > maxSize {
+		return nil, fmt.Errorf("%w（最大 %d 字节）", ErrMediaTooLarge, maxSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(upload.File, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w（最大 %d 字节）", ErrMediaTooLarge, maxSize)
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := allowed[contentType]
+	if !ok {
+		return nil, errNotAllowed
+	}
+
+	file := &storedFile{
+		Key:         storage.NewKey(prefix, ext),
+		Filename:    cleanMediaFilename(upload.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if err := store.Put(context.Background(), file.Key, bytes.NewReader(data), file.Size, contentType); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	file.Checksum = hex.EncodeToString(sum[:])
+	return file, nil
+}
+
+// storeFile 写入证件文件，设置证件的文件信息
+func (s *businessDocumentService) storeFile(document *model.BusinessDocument, upload *DocumentUpload) error {
+	file, err := storePrivateFile(s.storage, documentKeyPrefix, upload, s.maxUploadSize, model.DocumentTypeExtensions, ErrDocumentTypeNotAllowed)
+	if err != nil {
+		return err
+	}
+	document.StorageKey = file.Key
+	document.Filename = file.Filename
+	document.ContentType = file.ContentType
+	document.Size = file.Size
+	document.Checksum = file.Checksum
+	return nil
+}
+
+// applyDocumentRequest 设置证件信息并重置审核状态
+func applyDocumentRequest(document *model.BusinessDocument, req *model.BusinessDocumentRequest) {
+	document.Type = req.Type
+	document.Number = req.Number
+	document.IssuingAuthority = req.IssuingAuthority
+	document.ValidFrom = req.ValidFrom
+	document.ValidUntil = req.ValidUntil
+	document.Status = model.DocumentStatusPending
+	document.RejectReason = nil
+	document.ReviewedBy = nil
+	document.ReviewedAt = nil
+	document.ExpiryFlaggedAt = nil
+}
+
+// GetDocuments 获取商家的资质证件
+func (s *businessDocumentService) GetDocuments(businessID int) ([]*model.BusinessDocument, error) {
+	documents, err := s.documentRepo.ListByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		document.ApplyFileURL()
+	}
+	return documents, nil
+}
+
+// GetDocument 获取资质证件
+func (s *businessDocumentService) GetDocument(businessID int, id uint) (*model.BusinessDocument, error) {
+	document, err := s.getDocument(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	document.ApplyFileURL()
+	return document, nil
+}
+
+// UploadDocument 上传资质证件
+func (s *businessDocumentService) UploadDocument(businessID int, req *model.BusinessDocumentRequest, upload *DocumentUpload, actorID uint) (*model.BusinessDocument, error) {
+	if _, err := s.businessRepo.GetByID(businessID); err != nil {
+		return nil, errors.New("商家不存在")
+	}
+	if err := validateDocumentRequest(req, time.Now().Format(dateLayout)); err != nil {
+		return nil, err
+	}
+	if upload == nil {
+		return nil, errors.New("请上传证件文件")
+	}
+
+	document := &model.BusinessDocument{BusinessID: businessID, UploadedBy: actorID}
+	applyDocumentRequest(document, req)
+	if err := s.storeFile(document, upload); err != nil {
+		return nil, err
+	}
+	if err := s.documentRepo.Create(document); err != nil {
+		_ = s.storage.Delete(context.Background(), document.StorageKey)
+		return nil, err
+	}
+	document.ApplyFileURL()
+	return document, nil
+}
+
+// UpdateDocument 修改资质证件，替换文件时删除原文件
+func (s *businessDocumentService) UpdateDocument(businessID int, id uint, req *model.BusinessDocumentRequest, upload *DocumentUpload, actorID uint) (*model.BusinessDocument, error) {
+	document, err := s.getDocument(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDocumentRequest(req, time.Now().Format(dateLayout)); err != nil {
+		return nil, err
+	}
+
+	oldKey := document.StorageKey
+	applyDocumentRequest(document, req)
+	if upload != nil {
+		if err := s.storeFile(document, upload); err != nil {
+			return nil, err
+		}
+		document.UploadedBy = actorID
+	}
+	if err := s.documentRepo.Update(document); err != nil {
+		if document.StorageKey != oldKey {
+			_ = s.storage.Delete(context.Background(), document.StorageKey)
+		}
+		return nil, err
+	}
+	if document.StorageKey != oldKey {
+		if err := s.storage.Delete(context.Background(), oldKey); err != nil {
+			log.Printf("Delete replaced document file %s failed: %v", oldKey, err)
+		}
+	}
+	document.ApplyFileURL()
+	return document, nil
+}
+
+// DeleteDocument 删除资质证件
+func (s *businessDocumentService) DeleteDocument(businessID int, id uint, actorID uint) error {
+	document, err := s.getDocument(businessID, id)
+	if err != nil {
+		return err
+	}
+	if document.Status == model.DocumentStatusVerified {
+		if actor, err := s.userRepo.GetByID(actorID); err != nil || !actor.IsAdmin() {
+			return errors.New("已核验的证件只有管理员可以删除，如需更新请修改证件")
+		}
+	}
+	if err := s.documentRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.storage.Delete(context.Background(), document.StorageKey)
+}
+
+// OpenDocumentFile 读取证件文件
+func (s *businessDocumentService) OpenDocumentFile(businessID int, id uint) (*model.BusinessDocument, io.ReadCloser, error) {
+	document, err := s.getDocument(businessID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := s.storage.Get(context.Background(), document.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, errors.New("文件不存在")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, body, nil
+}
+
+// review 审核证件
+func (s *businessDocumentService) review(businessID int, id uint, actorID uint, status string, reason *string) (*model.BusinessDocument, error) {
+	document, err := s.getDocument(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.Status == model.DocumentStatusExpired {
+		return nil, errors.New("证件已过期，请商家更新后再审核")
+	}
+	if status == model.DocumentStatusVerified && document.IsLapsed(time.Now().Format(dateLayout)) {
+		return nil, errors.New("证件已过有效期，不能核验")
+	}
+
+	now := time.Now()
+	document.Status = status
+	document.RejectReason = reason
+	document.ReviewedBy = &actorID
+	document.ReviewedAt = &now
+	if err := s.documentRepo.Update(document); err != nil {
+		return nil, err
+	}
+	document.ApplyFileURL()
+	return document, nil
+}
+
+// VerifyDocument 核验通过证件
+func (s *businessDocumentService) VerifyDocument(businessID int, id uint, actorID uint) (*model.BusinessDocument, error) {
+	return s.review(businessID, id, actorID, model.DocumentStatusVerified, nil)
+}
+
+// RejectDocument 驳回证件
+func (s *businessDocumentService) RejectDocument(businessID int, id uint, actorID uint, reason string) (*model.BusinessDocument, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("驳回原因不能为空")
+	}
+	return s.review(businessID, id, actorID, model.DocumentStatusRejected, &reason)
+}
+
+// GetExpiringDocuments 获取即将到期的证件
+func (s *businessDocumentService) GetExpiringDocuments(days int) ([]*model.BusinessDocument, error) {
+	if days < 0 {
+		return nil, errors.New("天数不能为负数")
+	}
+	if days == 0 {
+		days = s.rules.WarningDays
+	}
+	now := time.Now()
+	documents, err := s.documentRepo.GetExpiring(now.Format(dateLayout), now.AddDate(0, 0, days).Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		document.ApplyFileURL()
+	}
+	return documents, nil
+}
+
+// isMandatory 是否为必备证件类型
+func (s *businessDocumentService) isMandatory(documentType string) bool {
+	for _, t := range s.rules.MandatoryTypes {
+		if t == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDocumentExpiry 执行资质证件到期检查
+func (s *businessDocumentService) CheckDocumentExpiry(now time.Time) (*model.DocumentExpiryResult, error) {
+	result := &model.DocumentExpiryResult{Suspended: []int{}}
+	today := now.Format(dateLayout)
+
+	flagged, err := s.documentRepo.FlagExpiring(today, now.AddDate(0, 0, s.rules.WarningDays).Format(dateLayout), now)
+	if err != nil {
+		return nil, err
+	}
+	result.Flagged = int(flagged)
+
+	// 过期的已核验必备证件
+	var lapsedMandatory []*model.BusinessDocument
+	for {
+		documents, err := s.documentRepo.GetLapsed(today, lapsedDocumentBatchSize)
+		if err != nil {
+			return result, err
+		}
+		for _, document := range documents {
+			expired, err := s.documentRepo.MarkExpired(document)
+			if err != nil {
+				return result, err
+			}
+			if !expired {
+				continue
+			}
+			result.Expired++
+			if document.Status == model.DocumentStatusVerified && s.isMandatory(document.Type) {
+				lapsedMandatory = append(lapsedMandatory, document)
+			}
+		}
+		if len(documents) < lapsedDocumentBatchSize {
+			break
+		}
+	}
+
+	if !s.rules.AutoSuspend {
+		return result, nil
+	}
+	for _, document := range lapsedMandatory {
+		businessID := document.BusinessID
+		valid, err := s.documentRepo.HasValid(businessID, document.Type, today)
+		if err != nil {
+			return result, err
+		}
+		if valid {
+			continue
+		}
+		// 已暂停（含本次检查中因其他证件暂停）或未在营业的商家不再处理
+		business, err := s.businessRepo.GetByID(businessID)
+		if err != nil || !model.CanTransitionBusinessStatus(business.Status, model.BusinessStatusSuspended) {
+			continue
+		}
+		change := model.StatusChange{
+			ActorID:    0,
+			ReasonCode: model.StatusReasonDocumentExpired,
+			Note:       "必备资质证件已过期：" + document.Type + " " + document.Number + "（有效期至 " + document.ValidUntil + "）",
+		}
+		if err := s.businessService.SuspendBusiness(businessID, change); err != nil {
+			log.Printf("Suspend business %d for expired document %d failed: %v", businessID, document.ID, err)
+			continue
+		}
+		result.Suspended = append(result.Suspended, businessID)
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+)
+
+// maxTagFacetValues 标签分面最多返回的取值数（按数量取前若干个）
+const maxTagFacetValues = 50
+
+// validateSearchFilter 校验分面筛选条件
+func validateSearchFilter(filter model.BusinessSearchFilter) error {
+	for _, status := range filter.Statuses {
+		if !validateBusinessStatus(status) {
+			return common.InvalidListQuery("商家状态无效: " + status)
+		}
+	}
+	for _, key := range filter.Ratings {
+		valid := false
+		for _, bucket := range model.RatingBuckets {
+			if bucket.Key == key {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return common.InvalidListQuery("评分区间无效: " + key)
+		}
+	}
+	return nil
+}
+
+// regionFacet 地区分面的层级，以及计算分面时使用的地区条件
+// 地区按层级逐级下钻：未选地区时按国家统计，选择国家后按省统计，依此类推；
+// 已选到区/县时按同一城市内的区/县统计
+func regionFacet(region model.RegionFilter) (string, model.RegionFilter) {
+	switch {
+	case region.City != "":
+		region.District = ""
+		return model.RegionLevelDistrict, region
+	case region.Province != "":
+		return model.RegionLevelCity, region
+	case region.Country != "":
+		return model.RegionLevelProvince, region
+	default:
+		return model.RegionLevelCountry, region
+	}
+}
+
+// searchFacets 在数据库中分组统计匹配集合（ids 为 nil 时为全部商家）在各维度的分布
+// 每个维度按其他维度的筛选条件计算，不含本维度的筛选；地区维度见 regionFacet
+func (s *businessService) searchFacets(ids []int, filter model.BusinessSearchFilter) (*model.BusinessFacets, error) {
+	level, regionScope := regionFacet(filter.Region)
+	facets := &model.BusinessFacets{RegionLevel: level}
+
+	withoutType, withoutStatus, withoutRating, withoutTags, inRegion := filter, filter, filter, filter, filter
+	withoutType.Types = nil
+	withoutStatus.Statuses = nil
+	withoutRating.Ratings = nil
+	withoutTags.Tags = nil
+	inRegion.Region = regionScope
+
+	var ratings []model.FacetCount
+	dimensions := []struct {
+		facet  string
+		filter model.BusinessSearchFilter
+		limit  int
+		counts *[]model.FacetCount
+	}{
+		{model.FacetType, withoutType, 0, &facets.Type},
+		{model.FacetStatus, withoutStatus, 0, &facets.Status},
+		{model.FacetRating, withoutRating, 0, &ratings},
+		{level, inRegion, 0, &facets.Region},
+		{model.FacetTags, withoutTags, maxTagFacetValues, &facets.Tags},
+	}
+	for _, dimension := range dimensions {
+		counts, err := s.businessRepo.CountSearchFacet(ids, dimension.filter, dimension.facet, dimension.limit)
+		if err != nil {
+			return nil, err
+		}
+		if counts == nil {
+			counts = []model.FacetCount{}
+		}
+		*dimension.counts = counts
+	}
+
+	// 评分区间按 RatingBuckets 的固定顺序返回，没有商家的区间数量为 0
+	byKey := make(map[string]int64, len(ratings))
+	for _, rating := range ratings {
+		byKey[rating.Value] = rating.Count
+	}
+	facets.Rating = make([]model.FacetCount, len(model.RatingBuckets))
+	for i, bucket := range model.RatingBuckets {
+		facets.Rating[i] = model.FacetCount{Value: bucket.Key, Count: byKey[bucket.Key]}
+	}
+	return facets, nil
+}
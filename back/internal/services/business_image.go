@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	model "merchant_back/internal/models"
+)
+
+// prepareBusinessImage 确定商家图片：提交了 imageBase64 的（兼容旧客户端）转存为媒体文件，空字符串表示清除图片；
+// 否则校验 imageMediaId 指向的媒体文件存在
+func (s *businessService) prepareBusinessImage(business *model.Business, actorID uint) error {
+	if business.ImageBase64 != nil {
+		data := *business.ImageBase64
+		business.ImageBase64 = nil
+		if data == "" {
+			business.ImageMediaID = nil
+			return nil
+		}
+		media, err := s.mediaService.UploadBase64(data, actorID)
+		if err != nil {
+			return errors.New("商家图片无效: " + err.Error())
+		}
+		business.ImageMediaID = &media.ID
+		return nil
+	}
+
+	if business.ImageMediaID != nil {
+		if _, err := s.mediaService.GetMedia(*business.ImageMediaID); err != nil {
+			return errors.New("商家图片不存在")
+		}
+	}
+	return nil
+}
+
+// attachImages 设置图片缩略图地址并加载主色调
+func (s *businessService) attachImages(businesses []*model.Business) error {
+	var ids []uint
+	for _, business := range businesses {
+		business.ApplyImageURL(false)
+		if business.ImageMediaID != nil {
+			ids = append(ids, *business.ImageMediaID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	colors, err := s.mediaService.DominantColors(ids)
+	if err != nil {
+		return err
+	}
+	for _, business := range businesses {
+		if business.ImageMediaID != nil {
+			business.ImageColor = colors[*business.ImageMediaID]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"errors"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"strings"
+	"time"
+)
+
+// BusinessMemberService 商家成员服务接口
+type BusinessMemberService interface {
+	// CheckPermission 检查用户对商家是否拥有权限：系统管理员、商家所属品牌的管理员拥有全部权限，
+	// 其余用户按其在该商家已加入的角色判断；回收站中的商家同样适用（用于恢复）
+	CheckPermission(businessID int, userID uint, permission string) (bool, error)
+
+	GetMembers(businessID int, actorID uint) ([]*model.BusinessMember, error)
+	// InviteMember 邀请用户加入商家，被邀请人接受后生效，仅店主可操作
+	InviteMember(businessID int, req *model.BusinessMemberInviteRequest, actorID uint) (*model.BusinessMember, error)
+	// UpdateMemberRole 修改成员角色，仅店主可操作，商家至少保留一名店主
+	UpdateMemberRole(businessID int, userID uint, role string, actorID uint) (*model.BusinessMember, error)
+	// RemoveMember 移除成员或撤回邀请，仅店主可操作，商家至少保留一名店主
+	RemoveMember(businessID int, userID uint, actorID uint) error
+
+	// GetMemberships 获取当前用户加入或被邀请的商家
+	GetMemberships(userID uint) ([]*model.BusinessMember, error)
+	// AcceptInvitation 接受商家邀请
+	AcceptInvitation(businessID int, userID uint) (*model.BusinessMember, error)
+	// LeaveBusiness 退出商家或拒绝邀请，唯一的店主不能退出
+	LeaveBusiness(businessID int, userID uint) error
+}
+
+// businessMemberService 商家成员服务实现
+type businessMemberService struct {
+	memberRepo   repositories.BusinessMemberRepository
+	businessRepo repositories.BusinessRepository
+	userRepo     repositories.UserRepository
+	brandRepo    repositories.BrandRepository
+}
+
+// NewBusinessMemberService 创建商家成员服务实例
+func NewBusinessMemberService(memberRepo repositories.BusinessMemberRepository, businessRepo repositories.BusinessRepository, userRepo repositories.UserRepository, brandRepo repositories.BrandRepository) BusinessMemberService {
+	return &businessMemberService{
+		memberRepo:   memberRepo,
+		businessRepo: businessRepo,
+		userRepo:     userRepo,
+		brandRepo:    brandRepo,
+	}
+}
+
+// getBusiness 获取商家（含回收站中的商家）
+func (s *businessMemberService) getBusiness(id int) (*model.Business, error) {
+	if id <= 0 {
+		return nil, errors.New("无效的商家ID")
+	}
+	if business, err := s.businessRepo.GetByID(id); err == nil {
+		return business, nil
+	}
+	business, err := s.businessRepo.GetDeletedByID(id)
+	if err != nil {
+		return nil, errors.New("商家不存在")
+	}
+	return business, nil
+}
+
+// CheckPermission 检查用户对商家的权限
+func (s *businessMemberService) CheckPermission(businessID int, userID uint, permission string) (bool, error) {
+	business, err := s.getBusiness(businessID)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || !user.IsActive() {
+		return false, nil
+	}
+	if user.IsAdmin() {
+		return true, nil
+	}
+	if business.BrandID != nil {
+		if ok, err := s.brandRepo.IsAdmin(*business.BrandID, userID); err != nil {
+			return false, err
+		} else if ok {
+			return true, nil
+		}
+	}
+
+	member, err := s.memberRepo.Get(businessID, userID)
+	if err != nil {
+		return false, nil
+	}
+	return member.IsActive() && model.BusinessRoleAllows(member.Role, permission), nil
+}
+
+// authorize 校验操作用户对商家拥有权限
+func (s *businessMemberService) authorize(businessID int, actorID uint, permission string) error {
+	ok, err := s.CheckPermission(businessID, actorID, permission)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if permission == model.BusinessPermissionManage {
+			return errors.New("只有店主可以管理商家成员")
+		}
+		return errors.New("无权查看该商家的成员")
+	}
+	return nil
+}
+
+// ensureOwnerRemains 校验移除或降级后商家仍保留至少一名店主
+func (s *businessMemberService) ensureOwnerRemains(member *model.BusinessMember) error {
+	if member.Role != model.BusinessRoleOwner || !member.IsActive() {
+		return nil
+	}
+	count, err := s.memberRepo.CountOwners(member.BusinessID)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return errors.New("商家至少需要保留一名店主")
+	}
+	return nil
+}
+
+// withUsername 设置成员的用户名
+func (s *businessMemberService) withUsername(member *model.BusinessMember) *model.BusinessMember {
+	if user, err := s.userRepo.GetByID(member.UserID); err == nil {
+		member.Username = user.Username
+	}
+	return member
+}
+
+// GetMembers 获取商家成员
+func (s *businessMemberService) GetMembers(businessID int, actorID uint) ([]*model.BusinessMember, error) {
+	if err := s.authorize(businessID, actorID, model.BusinessPermissionView); err != nil {
+		return nil, err
+	}
+	members, err := s.memberRepo.ListByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		s.withUsername(member)
+	}
+	return members, nil
+}
+
+// InviteMember 邀请商家成员
+func (s *businessMemberService) InviteMember(businessID int, req *model.BusinessMemberInviteRequest, actorID uint) (*model.BusinessMember, error) {
+	if err := s.authorize(businessID, actorID, model.BusinessPermissionManage); err != nil {
+		return nil, err
+	}
+	if !model.IsValidBusinessRole(req.Role) {
+		return nil, errors.New("成员角色无效")
+	}
+
+	var user *model.User
+	var err error
+	switch email := strings.TrimSpace(req.Email); {
+	case req.UserID != 0:
+		user, err = s.userRepo.GetByID(req.UserID)
+	case email != "":
+		user, err = s.userRepo.GetByEmail(email)
+	default:
+		return nil, errors.New("请指定被邀请的用户ID或邮箱")
+	}
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if !user.IsActive() {
+		return nil, errors.New("用户未启用")
+	}
+	if _, err := s.memberRepo.Get(businessID, user.ID); err == nil {
+		return nil, errors.New("该用户已是商家成员或已被邀请")
+	}
+
+	member := &model.BusinessMember{
+		BusinessID: businessID,
+		UserID:     user.ID,
+		Role:       req.Role,
+		Status:     model.BusinessMemberStatusInvited,
+		InvitedBy:  &actorID,
+	}
+	if err := s.memberRepo.Create(member); err != nil {
+		return nil, err
+	}
+	member.Username = user.Username
+	return member, nil
+}
+
+// UpdateMemberRole 修改成员角色
+func (s *businessMemberService) UpdateMemberRole(businessID int, userID uint, role string, actorID uint) (*model.BusinessMember, error) {
+	if err := s.authorize(businessID, actorID, model.BusinessPermissionManage); err != nil {
+		return nil, err
+	}
+	if !model.IsValidBusinessRole(role) {
+		return nil, errors.New("成员角色无效")
+	}
+	member, err := s.memberRepo.Get(businessID, userID)
+	if err != nil {
+		return nil, errors.New("该用户不是商家成员")
+	}
+	if member.Role == role {
+		return s.withUsername(member), nil
+	}
+	if err := s.ensureOwnerRemains(member); err != nil {
+		return nil, err
+	}
+
+	member.Role = role
+	if err := s.memberRepo.Update(member); err != nil {
+		return nil, err
+	}
+	return s.withUsername(member), nil
+}
+
+// RemoveMember 移除商家成员
+func (s *businessMemberService) RemoveMember(businessID int, userID uint, actorID uint) error {
+	if err := s.authorize(businessID, actorID, model.BusinessPermissionManage); err != nil {
+		return err
+	}
+	member, err := s.memberRepo.Get(businessID, userID)
+	if err != nil {
+		return errors.New("该用户不是商家成员")
+	}
+	if err := s.ensureOwnerRemains(member); err != nil {
+		return err
+	}
+	return s.memberRepo.Delete(businessID, userID)
+}
+
+// GetMemberships 获取当前用户的商家成员记录
+func (s *businessMemberService) GetMemberships(userID uint) ([]*model.BusinessMember, error) {
+	members, err := s.memberRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.BusinessID)
+	}
+	businesses, err := s.businessRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(businesses))
+	for _, business := range businesses {
+		names[business.ID] = business.Name
+	}
+	for _, member := range members {
+		member.BusinessName = names[member.BusinessID]
+	}
+	return members, nil
+}
+
+// AcceptInvitation 接受商家邀请
+func (s *businessMemberService) AcceptInvitation(businessID int, userID uint) (*model.BusinessMember, error) {
+	member, err := s.memberRepo.Get(businessID, userID)
+	if err != nil {
+		return nil, errors.New("邀请不存在")
+	}
+	if member.IsActive() {
+		return nil, errors.New("已加入该商家")
+	}
+
+	now := time.Now()
+	member.Status = model.BusinessMemberStatusActive
+	member.JoinedAt = &now
+	if err := s.memberRepo.Update(member); err != nil {
+		return nil, err
+	}
+	return s.withUsername(member), nil
+}
+
+// LeaveBusiness 退出商家或拒绝邀请
+func (s *businessMemberService) LeaveBusiness(businessID int, userID uint) error {
+	member, err := s.memberRepo.Get(businessID, userID)
+	if err != nil {
+		return errors.New("不是该商家的成员")
+	}
+	if err := s.ensureOwnerRemains(member); err != nil {
+		return err
+	}
+	return s.memberRepo.Delete(businessID, userID)
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+)
+
+// 不可通过 PATCH 修改的商家字段及原因
+var businessPatchForbiddenFields = map[string]string{
+	"id":              "不可修改",
+	"createdAt":       "不可修改",
+	"updatedAt":       "不可修改",
+	"deletedAt":       "不可修改",
+	"version":         "不可修改，请使用 If-Match 请求头",
+	"status":          "请通过状态流转接口修改",
+	"reviewerId":      "请通过入驻审核接口修改",
+	"rejectReason":    "请通过入驻审核接口修改",
+	"submittedAt":     "请通过入驻审核接口修改",
+	"openingHours":    "请通过营业时间接口修改",
+	"specialHours":    "请通过营业时间接口修改",
+	"isOpen":          "为计算字段，不可修改",
+	"nextChange":      "为计算字段，不可修改",
+	"searchScore":     "为计算字段，不可修改",
+	"highlights":      "为计算字段，不可修改",
+	"imageUrl":        "为计算字段，请修改 imageMediaId",
+	"rating":          "由顾客评价计算，不可修改",
+	"ratingCount":     "由顾客评价计算，不可修改",
+	"inheritedFields": "为计算字段，不可修改",
+}
+
+// 结构化地址字段，与展示地址 address 互相同步
+var businessStructuredAddressFields = []string{"country", "province", "city", "district", "street", "postalCode"}
+
+// businessPatchValidators 可通过 PATCH 修改的商家字段及其校验，仅校验补丁涉及的字段
+// 地址相关字段统一在 validateBusinessAddressPatch 中校验
+var businessPatchValidators = map[string]func(b *model.Business) error{
+	"name": func(b *model.Business) error {
+		if b.Name == "" {
+			return errors.New("商家名称不能为空")
+		}
+		if len(b.Name) > 255 {
+			return errors.New("商家名称长度不能超过255个字符")
+		}
+		return nil
+	},
+	"email":   validateBusinessEmail,
+	"brandId": validateBusinessEmail, // 移出品牌的门店须填写邮箱，品牌权限在 prepareBusinessBrand 中校验
+	"contact": func(b *model.Business) error {
+		if b.Contact == "" {
+			return errors.New("联系方式不能为空")
+		}
+		if len(b.Contact) > 255 {
+			return errors.New("联系方式长度不能超过255个字符")
+		}
+		return nil
+	},
+	"phone": func(b *model.Business) error {
+		if !validatePhone(b.Phone) {
+			return errors.New("手机号格式不正确")
+		}
+		return nil
+	},
+	"timezone": func(b *model.Business) error {
+		if !validateTimezone(b.Timezone) {
+			return errors.New("时区无效")
+		}
+		return nil
+	},
+	"latitude": func(b *model.Business) error {
+		if b.Latitude != nil && (*b.Latitude < -90 || *b.Latitude > 90) {
+			return errors.New("纬度必须在-90到90之间")
+		}
+		return nil
+	},
+	"longitude": func(b *model.Business) error {
+		if b.Longitude != nil && (*b.Longitude < -180 || *b.Longitude > 180) {
+			return errors.New("经度必须在-180到180之间")
+		}
+		return nil
+	},
+	"otherInfo":    nil,
+	"imageBase64":  nil, // 与 imageMediaId 统一在 prepareBusinessImage 中校验
+	"imageMediaId": nil,
+	"description":  nil,
+	"type":         nil, // 与 categoryIds 统一在 resolveBusinessCategories 中校验
+	"categoryIds":  nil,
+	"tags":         nil, // 与 attributes 统一在 prepareTagsAndAttributes 中校验
+	"attributes":   nil,
+}
+
+// PatchBusiness 按 JSON Merge Patch / JSON Patch 部分更新商家，仅校验补丁涉及的字段
+// version 为 If-Match 携带的版本号，0 表示不校验
+func (s *businessService) PatchBusiness(id int, patchType string, patch []byte, version int, actorID uint) (*model.Business, error) {
+	if id <= 0 {
+		return nil, errors.New("无效的商家ID")
+	}
+
+	existing, err := s.businessRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("商家不存在")
+	}
+	if version != 0 && version != existing.Version {
+		return nil, ErrVersionConflict
+	}
+	if err := s.loadCategoriesAndTags([]*model.Business{existing}); err != nil {
+		return nil, err
+	}
+
+	doc, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+	patched, touched, err := common.ApplyPatch(patchType, doc, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	addressTouched := false
+	structuredTouched := false
+	typeTouched := false
+	categoriesTouched := false
+	tagsTouched := false
+	attributesTouched := false
+	imageTouched := false
+	base64Touched := false
+	brandTouched := false
+	for _, field := range touched {
+		switch field {
+		case "brandId":
+			brandTouched = true
+		case "type":
+			typeTouched = true
+		case "categoryIds":
+			categoriesTouched = true
+		case "tags":
+			tagsTouched = true
+		case "attributes":
+			attributesTouched = true
+		case "imageBase64":
+			imageTouched, base64Touched = true, true
+		case "imageMediaId":
+			imageTouched = true
+		}
+		if reason, ok := businessPatchForbiddenFields[field]; ok {
+			return nil, errors.New("字段 " + field + " " + reason)
+		}
+		if field == "address" {
+			addressTouched = true
+			continue
+		}
+		if isStructuredAddressField(field) {
+			structuredTouched = true
+			continue
+		}
+		if _, ok := businessPatchValidators[field]; !ok {
+			return nil, errors.New("未知字段: " + field)
+		}
+	}
+
+	var business model.Business
+	if err := json.Unmarshal(patched, &business); err != nil {
+		return nil, errors.New("字段类型错误: " + err.Error())
+	}
+
+	for _, field := range touched {
+		if validate := businessPatchValidators[field]; validate != nil {
+			if err := validate(&business); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if addressTouched || structuredTouched {
+		if err := validateBusinessAddressPatch(&business, addressTouched, structuredTouched); err != nil {
+			return nil, err
+		}
+	}
+	if brandTouched {
+		if err := s.prepareBusinessBrand(&business, existing, actorID); err != nil {
+			return nil, err
+		}
+	}
+	if !categoriesTouched {
+		business.CategoryIDs = nil
+	}
+	if typeTouched || categoriesTouched {
+		if err := s.resolveBusinessCategories(&business); err != nil {
+			return nil, err
+		}
+	}
+	// 标签未修改时不重写；分类或属性变化时按新的分类重新校验属性
+	if !tagsTouched {
+		business.Tags = nil
+	} else if business.Tags == nil {
+		business.Tags = []string{}
+	}
+	if tagsTouched || attributesTouched || typeTouched || categoriesTouched {
+		categoryIDs := business.CategoryIDs
+		if categoryIDs == nil {
+			categoryIDs = existing.CategoryIDs
+		}
+		if err := s.prepareTagsAndAttributes(&business, categoryIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if imageTouched {
+		// 删除 imageBase64（null）按旧客户端的语义清除图片
+		if business.ImageBase64 == nil && base64Touched {
+			business.ImageMediaID = nil
+		}
+		if err := s.prepareBusinessImage(&business, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if business.Email != existing.Email && s.emailInUse(business.Email) {
+		return nil, errors.New("邮箱已被使用")
+	}
+
+	// 补丁不涉及的字段以数据库当前值为准
+	business.ID = existing.ID
+	business.Status = existing.Status
+	business.ReviewerID = existing.ReviewerID
+	business.RejectReason = existing.RejectReason
+	business.SubmittedAt = existing.SubmittedAt
+	business.CreatedAt = existing.CreatedAt
+	business.DeletedAt = existing.DeletedAt
+	business.DeleteMarker = existing.DeleteMarker
+	business.Version = existing.Version
+	business.OpeningHours = nil
+	business.SpecialHours = nil
+
+	if err := s.businessRepo.Update(&business); err != nil {
+		return nil, err
+	}
+	if err := s.saveBusinessCategories(&business); err != nil {
+		return nil, err
+	}
+	if err := s.saveTagsAndAttributes(&business); err != nil {
+		return nil, err
+	}
+	if err := s.recordRevision(id, model.RevisionActionUpdate, actorID, nil); err != nil {
+		return nil, err
+	}
+
+	return s.GetBusiness(id)
+}
+
+// validateBusinessAddressPatch 同步并校验补丁涉及的地址字段
+// 仅修改展示地址时重新解析结构化地址，仅修改结构化地址时重新拼接展示地址
+func validateBusinessAddressPatch(business *model.Business, addressTouched, structuredTouched bool) error {
+	if addressTouched && !structuredTouched {
+		clearStructuredAddress(business)
+	} else if structuredTouched && !addressTouched {
+		business.Address = ""
+	}
+
+	return validateAddress(business)
+}
+
+// isStructuredAddressField 检查是否为结构化地址字段
+func isStructuredAddressField(field string) bool {
+	for _, f := range businessStructuredAddressFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxPhotoCaptionLength 照片说明最大长度（字符）
+const maxPhotoCaptionLength = 255
+
+// BusinessPhotoService 商家相册服务接口
+type BusinessPhotoService interface {
+	// GetPhotos 获取商家相册：管理员可见全部照片（status 不为空时按审核状态筛选），其他用户可见已通过的照片及自己添加的照片
+	GetPhotos(businessID int, status string, actorID uint) ([]*model.BusinessPhoto, error)
+	// AddPhoto 添加照片到相册末尾，管理员添加的直接通过，其他用户添加的待审核
+	AddPhoto(businessID int, req *model.BusinessPhotoRequest, actorID uint) (*model.BusinessPhoto, error)
+	// UpdatePhoto 修改说明或封面；非管理员修改已通过照片的说明后需重新审核
+	UpdatePhoto(businessID int, photoID uint, req *model.BusinessPhotoUpdateRequest, actorID uint) (*model.BusinessPhoto, error)
+	// ReorderPhotos 按给定顺序排列照片，未列出的照片保持原有相对顺序排在其后
+	ReorderPhotos(businessID int, photoIDs []uint, actorID uint) ([]*model.BusinessPhoto, error)
+	// DeletePhotos 批量删除照片，非管理员只能删除自己添加的照片；任一照片不能删除时全部不删除
+	DeletePhotos(businessID int, photoIDs []uint, actorID uint) error
+	// ApprovePhoto 审核通过照片
+	ApprovePhoto(businessID int, photoID uint, actorID uint) (*model.BusinessPhoto, error)
+	// RejectPhoto 驳回照片，驳回原因必填；封面照片被驳回后取消封面
+	RejectPhoto(businessID int, photoID uint, actorID uint, reason string) (*model.BusinessPhoto, error)
+	// GetPendingPhotos 获取全部待审核照片
+	GetPendingPhotos() ([]*model.BusinessPhoto, error)
+}
+
+// businessPhotoService 商家相册服务实现
+type businessPhotoService struct {
+	photoRepo    repositories.BusinessPhotoRepository
+	businessRepo repositories.BusinessRepository
+	userRepo     repositories.UserRepository
+	mediaService MediaService
+}
+
+// NewBusinessPhotoService 创建商家相册服务实例
+func NewBusinessPhotoService(photoRepo repositories.BusinessPhotoRepository, businessRepo repositories.BusinessRepository, userRepo repositories.UserRepository, mediaService MediaService) BusinessPhotoService {
+	return &businessPhotoService{
+		photoRepo:    photoRepo,
+		businessRepo: businessRepo,
+		userRepo:     userRepo,
+		mediaService: mediaService,
+	}
+}
+
+// isAdmin 操作用户是否为管理员
+func (s *businessPhotoService) isAdmin(actorID uint) bool {
+	actor, err := s.userRepo.GetByID(actorID)
+	return err == nil && actor.IsAdmin()
+}
+
+// checkBusiness 校验商家存在（不含回收站中的商家）
+func (s *businessPhotoService) checkBusiness(businessID int) error {
+	if businessID <= 0 {
+		return errors.New("无效的商家ID")
+	}
+	if _, err := s.businessRepo.GetByID(businessID); err != nil {
+		return errors.New("商家不存在")
+	}
+	return nil
+}
+
+// getPhoto 获取属于该商家的照片
+func (s *businessPhotoService) getPhoto(businessID int, photoID uint) (*model.BusinessPhoto, error) {
+	photo, err := s.photoRepo.GetByID(photoID)
+	if err != nil || photo.BusinessID != businessID {
+		return nil, errors.New("照片不存在")
+	}
+	return photo, nil
+}
+
+// cleanCaption 校验并整理照片说明
+func cleanCaption(caption string) (string, error) {
+	caption = strings.TrimSpace(caption)
+	if utf8.RuneCountInString(caption) > maxPhotoCaptionLength {
+		return "", fmt.Errorf("照片说明不能超过 %d 个字符", maxPhotoCaptionLength)
+	}
+	return caption, nil
+}
+
+// withURLs 设置照片地址与占位色
+func (s *businessPhotoService) withURLs(photos []*model.BusinessPhoto) ([]*model.BusinessPhoto, error) {
+	ids := make([]uint, 0, len(photos))
+	for _, photo := range photos {
+		photo.ApplyURL()
+		ids = append(ids, photo.MediaID)
+	}
+	if len(ids) == 0 {
+		return photos, nil
+	}
+	colors, err := s.mediaService.DominantColors(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, photo := range photos {
+		photo.DominantColor = colors[photo.MediaID]
+	}
+	return photos, nil
+}
+
+// withURL 设置单张照片的地址与占位色
+func (s *businessPhotoService) withURL(photo *model.BusinessPhoto) (*model.BusinessPhoto, error) {
+	if _, err := s.withURLs([]*model.BusinessPhoto{photo}); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// GetPhotos 获取商家相册
+func (s *businessPhotoService) GetPhotos(businessID int, status string, actorID uint) ([]*model.BusinessPhoto, error) {
+	if err := s.checkBusiness(businessID); err != nil {
+		return nil, err
+	}
+	switch status {
+	case "", model.BusinessPhotoStatusPending, model.BusinessPhotoStatusApproved, model.BusinessPhotoStatusRejected:
+	default:
+		return nil, errors.New("无效的审核状态: " + status)
+	}
+
+	var statuses []string
+	if status != "" {
+		statuses = append(statuses, status)
+	}
+	photos, err := s.photoRepo.ListByBusiness(businessID, statuses...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.isAdmin(actorID) {
+		visible := photos[:0]
+		for _, photo := range photos {
+			if photo.Status == model.BusinessPhotoStatusApproved || (photo.UploadedBy != nil && *photo.UploadedBy == actorID) {
+				visible = append(visible, photo)
+			}
+		}
+		photos = visible
+	}
+	return s.withURLs(photos)
+}
+
+// AddPhoto 添加照片
+func (s *businessPhotoService) AddPhoto(businessID int, req *model.BusinessPhotoRequest, actorID uint) (*model.BusinessPhoto, error) {
+	if err := s.checkBusiness(businessID); err != nil {
+		return nil, err
+	}
+	caption, err := cleanCaption(req.Caption)
+	if err != nil {
+		return nil, err
+	}
+	media, err := s.mediaService.GetMedia(req.MediaID)
+	if err != nil {
+		return nil, errors.New("图片不存在")
+	}
+	if (media.UploadedBy == nil || *media.UploadedBy != actorID) && !s.isAdmin(actorID) {
+		return nil, errors.New("只能添加自己上传的图片")
+	}
+
+	count, err := s.photoRepo.CountByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= model.MaxBusinessPhotos {
+		return nil, fmt.Errorf("每个商家最多 %d 张照片", model.MaxBusinessPhotos)
+	}
+	exists, err := s.photoRepo.ExistsMedia(businessID, req.MediaID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("该图片已在相册中")
+	}
+
+	photo := &model.BusinessPhoto{
+		BusinessID: businessID,
+		MediaID:    req.MediaID,
+		Caption:    caption,
+		Status:     model.BusinessPhotoStatusPending,
+		UploadedBy: &actorID,
+	}
+	if s.isAdmin(actorID) {
+		now := time.Now()
+		photo.Status = model.BusinessPhotoStatusApproved
+		photo.ReviewedBy = &actorID
+		photo.ReviewedAt = &now
+	}
+	if req.IsCover {
+		if photo.Status != model.BusinessPhotoStatusApproved {
+			return nil, errors.New("照片审核通过后才能设为封面")
+		}
+		photo.IsCover = true
+	}
+
+	if err := s.photoRepo.Create(photo); err != nil {
+		return nil, err
+	}
+	return s.withURL(photo)
+}
+
+// UpdatePhoto 修改照片说明或封面
+func (s *businessPhotoService) UpdatePhoto(businessID int, photoID uint, req *model.BusinessPhotoUpdateRequest, actorID uint) (*model.BusinessPhoto, error) {
+	photo, err := s.getPhoto(businessID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	admin := s.isAdmin(actorID)
+
+	if req.Caption != nil {
+		caption, err := cleanCaption(*req.Caption)
+		if err != nil {
+			return nil, err
+		}
+		if caption != photo.Caption {
+			if !admin && (photo.UploadedBy == nil || *photo.UploadedBy != actorID) {
+				return nil, errors.New("只有添加者或管理员可以修改照片说明")
+			}
+			photo.Caption = caption
+			// 说明会公开显示，非管理员修改后需重新审核
+			if !admin && photo.Status == model.BusinessPhotoStatusApproved {
+				photo.Status = model.BusinessPhotoStatusPending
+				photo.IsCover = false
+				photo.ReviewedBy, photo.ReviewedAt = nil, nil
+			}
+		}
+	}
+	if req.IsCover != nil {
+		if *req.IsCover && photo.Status != model.BusinessPhotoStatusApproved {
+			return nil, errors.New("照片审核通过后才能设为封面")
+		}
+		photo.IsCover = *req.IsCover
+	}
+
+	if err := s.photoRepo.Update(photo); err != nil {
+		return nil, err
+	}
+	return s.withURL(photo)
+}
+
+// ReorderPhotos 排列照片顺序
+func (s *businessPhotoService) ReorderPhotos(businessID int, photoIDs []uint, actorID uint) ([]*model.BusinessPhoto, error) {
+	if err := s.checkBusiness(businessID); err != nil {
+		return nil, err
+	}
+	photos, err := s.photoRepo.ListByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]bool, len(photos))
+	for _, photo := range photos {
+		byID[photo.ID] = true
+	}
+	listed := make(map[uint]bool, len(photoIDs))
+	for _, id := range photoIDs {
+		if !byID[id] {
+			return nil, fmt.Errorf("照片 %d 不属于该商家", id)
+		}
+		if listed[id] {
+			return nil, fmt.Errorf("照片 %d 重复", id)
+		}
+		listed[id] = true
+	}
+
+	order := append([]uint(nil), photoIDs...)
+	for _, photo := range photos {
+		if !listed[photo.ID] {
+			order = append(order, photo.ID)
+		}
+	}
+	if err := s.photoRepo.SetPositions(businessID, order); err != nil {
+		return nil, err
+	}
+	return s.GetPhotos(businessID, "", actorID)
+}
+
+// DeletePhotos 批量删除照片
+func (s *businessPhotoService) DeletePhotos(businessID int, photoIDs []uint, actorID uint) error {
+	if len(photoIDs) == 0 {
+		return errors.New("请选择要删除的照片")
+	}
+	if err := s.checkBusiness(businessID); err != nil {
+		return err
+	}
+	admin := s.isAdmin(actorID)
+	for _, id := range photoIDs {
+		photo, err := s.getPhoto(businessID, id)
+		if err != nil {
+			return fmt.Errorf("照片 %d 不存在", id)
+		}
+		if !admin && (photo.UploadedBy == nil || *photo.UploadedBy != actorID) {
+			return fmt.Errorf("照片 %d 只有添加者或管理员可以删除", id)
+		}
+	}
+	return s.photoRepo.DeleteByIDs(businessID, photoIDs)
+}
+
+// review 记录审核结论
+func (s *businessPhotoService) review(businessID int, photoID uint, actorID uint, status string, reason *string) (*model.BusinessPhoto, error) {
+	photo, err := s.getPhoto(businessID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	photo.Status = status
+	photo.RejectReason = reason
+	photo.ReviewedBy = &actorID
+	photo.ReviewedAt = &now
+	if status != model.BusinessPhotoStatusApproved {
+		photo.IsCover = false
+	}
+	if err := s.photoRepo.Update(photo); err != nil {
+		return nil, err
+	}
+	return s.withURL(photo)
+}
+
+// ApprovePhoto 审核通过照片
+func (s *businessPhotoService) ApprovePhoto(businessID int, photoID uint, actorID uint) (*model.BusinessPhoto, error) {
+	return s.review(businessID, photoID, actorID, model.BusinessPhotoStatusApproved, nil)
+}
+
+// RejectPhoto 驳回照片
+func (s *businessPhotoService) RejectPhoto(businessID int, photoID uint, actorID uint, reason string) (*model.BusinessPhoto, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("驳回原因不能为空")
+	}
+	return s.review(businessID, photoID, actorID, model.BusinessPhotoStatusRejected, &reason)
+}
+
+// GetPendingPhotos 获取待审核照片
+func (s *businessPhotoService) GetPendingPhotos() ([]*model.BusinessPhoto, error) {
+	photos, err := s.photoRepo.GetPending()
+	if err != nil {
+		return nil, err
+	}
+	return s.withURLs(photos)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	model "merchant_back/internal/models"
+	"strconv"
+	"strings"
+)
+
+// recordRevision 保存商家当前资料快照为新的修订版本并刷新搜索索引，与上一版本相比没有变化时不记录
+func (s *businessService) recordRevision(id int, action string, actorID uint, restoredFrom *int) error {
+	business, err := s.businessRepo.GetByID(id)
+	if err != nil {
+		return errors.New("商家不存在")
+	}
+	if err := s.businessRepo.LoadOpeningHours([]*model.Business{business}); err != nil {
+		return err
+	}
+	if err := s.loadCategoriesAndTags([]*model.Business{business}); err != nil {
+		return err
+	}
+
+	snapshot, err := business.RevisionSnapshot()
+	if err != nil {
+		return err
+	}
+
+	previous := ""
+	if latest, err := s.revisionRepo.GetLatest(id); err == nil {
+		previous = latest.Snapshot
+	}
+	diffs, err := model.DiffRevisionSnapshots(previous, snapshot)
+	if err != nil {
+		return err
+	}
+	if previous != "" && len(diffs) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(diffs))
+	for i, d := range diffs {
+		fields[i] = d.Field
+	}
+
+	revision := &model.BusinessRevision{
+		BusinessID:    id,
+		Action:        action,
+		ChangedFields: strings.Join(fields, ","),
+		Snapshot:      snapshot,
+		RestoredFrom:  restoredFrom,
+		ActorID:       actorID,
+	}
+	if err := s.revisionRepo.Create(revision); err != nil {
+		return errors.New("保存修订记录失败: " + err.Error())
+	}
+
+	// 资料有变化时同步刷新搜索索引
+	s.refreshSearchIndex(id)
+	return nil
+}
+
+// GetRevisions 获取商家修订记录列表（按版本倒序）
+func (s *businessService) GetRevisions(id int) ([]*model.BusinessRevision, error) {
+	if _, err := s.businessRepo.GetByID(id); err != nil {
+		return nil, errors.New("商家不存在")
+	}
+	return s.revisionRepo.GetByBusinessID(id)
+}
+
+// GetRevision 获取商家指定修订版本及其资料快照
+func (s *businessService) GetRevision(id, version int) (*model.BusinessRevision, error) {
+	revision, err := s.revisionRepo.GetByVersion(id, version)
+	if err != nil {
+		return nil, errors.New("修订版本不存在")
+	}
+
+	business, err := revision.SnapshotBusiness()
+	if err != nil {
+		return nil, err
+	}
+	revision.Business = business
+
+	return revision, nil
+}
+
+// DiffRevisions 对比商家两个修订版本
+func (s *businessService) DiffRevisions(id, fromVersion, toVersion int) ([]model.RevisionFieldDiff, error) {
+	from, err := s.revisionRepo.GetByVersion(id, fromVersion)
+	if err != nil {
+		return nil, errors.New("修订版本" + strconv.Itoa(fromVersion) + "不存在")
+	}
+	to, err := s.revisionRepo.GetByVersion(id, toVersion)
+	if err != nil {
+		return nil, errors.New("修订版本" + strconv.Itoa(toVersion) + "不存在")
+	}
+
+	return model.DiffRevisionSnapshots(from.Snapshot, to.Snapshot)
+}
+
+// RestoreRevision 将商家资料与营业时间恢复为指定版本，恢复结果作为新的修订版本记录
+// 商家状态不随版本恢复，仍需通过状态流转接口修改
+func (s *businessService) RestoreRevision(id, version int, actorID uint) (*model.Business, error) {
+	revision, err := s.revisionRepo.GetByVersion(id, version)
+	if err != nil {
+		return nil, errors.New("修订版本不存在")
+	}
+
+	snapshot, err := revision.SnapshotBusiness()
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Status = ""
+	// 快照中的分类可能已被合并或删除，仅保留仍存在的分类
+	if snapshot.CategoryIDs, err = s.existingCategoryIDs(snapshot.CategoryIDs); err != nil {
+		return nil, err
+	}
+	// 快照中没有标签或属性表示当时为空，恢复时一并清空
+	if snapshot.Tags == nil {
+		snapshot.Tags = []string{}
+	}
+	if snapshot.Attributes == nil {
+		snapshot.Attributes = map[string]interface{}{}
+	}
+
+	// 快照中的图片可能已被删除，此时不恢复图片
+	if snapshot.ImageMediaID != nil {
+		if _, err := s.mediaService.GetMedia(*snapshot.ImageMediaID); err != nil {
+			snapshot.ImageMediaID = nil
+		}
+	}
+
+	if err := s.updateBusiness(id, snapshot, actorID); err != nil {
+		return nil, err
+	}
+	if err := s.businessRepo.ReplaceOpeningHours(id, snapshot.Timezone, snapshot.OpeningHours, snapshot.SpecialHours, 0); err != nil {
+		return nil, err
+	}
+	if err := s.recordRevision(id, model.RevisionActionRestore, actorID, &version); err != nil {
+		return nil, err
+	}
+
+	return s.GetBusiness(id)
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"errors"
+	"log"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/search"
+
+	"gorm.io/gorm"
+)
+
+// 搜索后端类型
+const (
+	SearchBackendMySQL  = "mysql"  // MySQL FULLTEXT（ngram 分词）
+	SearchBackendMemory = "memory" // 进程内索引
+)
+
+// 搜索限制
+const (
+	maxSearchHits        = 1000 // 单次搜索最多统计的命中数（按相关度取前若干个）
+	searchIndexBatchSize = 500  // 重建索引时每批读取的商家数
+	maxSearchQueryLength = 100  // 搜索关键词最大长度（字符）
+	defaultSuggestLimit  = 10   // 默认联想条数
+	maxSuggestLimit      = 20   // 最多联想条数
+	popularQueryLimit    = 5    // 返回的热门关键词条数
+)
+
+// ErrSearchQueryTooLong 搜索关键词过长
+var ErrSearchQueryTooLong = errors.New("搜索关键词不能超过100个字符")
+
+// businessSearchWeights 商家搜索字段及权重，名称命中比描述命中更相关
+var businessSearchWeights = map[string]float64{
+	"name":        3,
+	"type":        2,
+	"address":     1.5,
+	"contact":     1,
+	"description": 1,
+}
+
+// businessSearchColumns 商家搜索字段对应的列（MySQL 后端为每列建立 ngram 全文索引）
+var businessSearchColumns = map[string]string{
+	"name":        "name",
+	"type":        "type",
+	"address":     "address",
+	"contact":     "contact",
+	"description": "description",
+}
+
+// NewBusinessSearchBackend 按配置创建商家搜索后端，未知类型使用 MySQL
+func NewBusinessSearchBackend(db *gorm.DB, backend string) search.Backend {
+	if backend == SearchBackendMemory {
+		return search.NewMemoryBackend()
+	}
+	return search.NewMySQLBackend(db.Model(&model.Business{}), businessSearchColumns)
+}
+
+// businessSearchDocument 生成商家的搜索文档
+func businessSearchDocument(business *model.Business) search.Document {
+	return search.Document{
+		ID:     business.ID,
+		Fields: businessSearchFields(business),
+	}
+}
+
+// businessSearchFields 商家参与搜索的字段文本
+func businessSearchFields(business *model.Business) map[string]string {
+	fields := map[string]string{
+		"name":    business.Name,
+		"type":    business.Type,
+		"address": business.Address,
+		"contact": business.Contact,
+	}
+	if business.Description != nil {
+		fields["description"] = *business.Description
+	}
+	return fields
+}
+
+// refreshSearchIndex 按数据库当前数据刷新商家的搜索索引与联想索引，已删除的商家从索引中移除，
+// 已暂停的商家从联想索引中移除
+// 索引失败只记录日志，不影响已完成的写入，定时重建会修正遗漏
+func (s *businessService) refreshSearchIndex(ids ...int) {
+	if len(ids) == 0 {
+		return
+	}
+
+	businesses, err := s.businessRepo.GetByIDs(ids)
+	if err != nil {
+		log.Printf("Search index refresh failed: %v", err)
+		return
+	}
+
+	found := make(map[int]bool, len(businesses))
+	docs := make([]search.Document, len(businesses))
+	var suggestDocs []search.Document
+	var suspended []int
+	for i, business := range businesses {
+		found[business.ID] = true
+		docs[i] = businessSearchDocument(business)
+		if business.Status == model.BusinessStatusSuspended {
+			suspended = append(suspended, business.ID)
+		} else {
+			suggestDocs = append(suggestDocs, docs[i])
+		}
+	}
+	var removed []int
+	for _, id := range ids {
+		if !found[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	s.suggester.Index("name", suggestDocs...)
+	s.suggester.Delete(append(removed, suspended...)...)
+
+	if s.searcher == nil {
+		return
+	}
+	if err := s.searcher.Index(docs...); err != nil {
+		log.Printf("Search index refresh failed: %v", err)
+	}
+	if err := s.searcher.Delete(removed...); err != nil {
+		log.Printf("Search index refresh failed: %v", err)
+	}
+}
+
+// RebuildSearchIndex 以数据库中全部未删除的商家重建联想索引及进程内搜索索引，返回索引的商家数
+// MySQL 搜索后端的索引由数据库维护，无需重建
+func (s *businessService) RebuildSearchIndex() (int, error) {
+	var docs, suggestDocs []search.Document
+	err := s.businessRepo.ForEachBatch(searchIndexBatchSize, func(businesses []*model.Business) error {
+		for _, business := range businesses {
+			doc := businessSearchDocument(business)
+			docs = append(docs, doc)
+			if business.Status != model.BusinessStatusSuspended {
+				suggestDocs = append(suggestDocs, doc)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.suggester.Rebuild(suggestDocs, "name")
+	if s.searcher != nil {
+		if err := s.searcher.Rebuild(docs); err != nil {
+			return 0, err
+		}
+	}
+	return len(docs), nil
+}
+
+// SuggestBusinesses 按输入的名称前缀返回联想的商家名称，以及以该前缀开头的热门搜索关键词
+// 支持前缀匹配、编辑距离容错、假名与罗马字互相匹配（如 らーめん / ramen → ラーメン），
+// 常被搜索的商家排序靠前；中文拼音需要拼音词典，暂不支持
+func (s *businessService) SuggestBusinesses(prefix string, limit int) ([]search.Suggestion, []string, error) {
+	if len([]rune(prefix)) > maxSearchQueryLength {
+		return nil, nil, ErrSearchQueryTooLong
+	}
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	return s.suggester.Suggest(prefix, limit), s.suggester.PopularQueries(prefix, popularQueryLimit), nil
+}
+
+// SearchBusinesses 全文搜索商家，按相关度排序并返回命中字段的高亮文本，以及匹配关键词的商家的分面统计
+// 搜索名称、类型、地址、联系方式与描述，各字段按 businessSearchWeights 加权；中日文按 bigram 匹配
+// 关键词为空时在数据库中筛选、分页全部商家；filter 按分面筛选结果；pageSize 为 0 时不分页
+// 总数与分面在数据库中分组统计，只加载当前页的商家
+func (s *businessService) SearchBusinesses(query string, filter model.BusinessSearchFilter, page, pageSize int) ([]*model.Business, int64, *model.BusinessFacets, error) {
+	if len([]rune(query)) > maxSearchQueryLength {
+		return nil, 0, nil, ErrSearchQueryTooLong
+	}
+	if err := validateSearchFilter(filter); err != nil {
+		return nil, 0, nil, err
+	}
+
+	var businesses []*model.Business
+	var total int64
+	var facets *model.BusinessFacets
+	var err error
+	if query == "" {
+		businesses, total, err = s.businessRepo.ListSearchMatches(filter, page, pageSize)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		facets, err = s.searchFacets(nil, filter)
+	} else {
+		businesses, total, facets, err = s.searchByRelevance(query, filter, page, pageSize)
+	}
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if err := s.attributeRepo.LoadTags(businesses); err != nil {
+		return nil, 0, nil, err
+	}
+	businesses, err = s.withOpenStatus(businesses, nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return businesses, total, facets, nil
+}
+
+// searchByRelevance 按相关度顺序返回匹配关键词且满足筛选条件的商家（不含已暂停）中的一页、总数与分面，
+// 并附加相关度与高亮文本。只取最相关的 maxSearchHits 个命中，超出时总数与分面只统计这些命中（Truncated 为 true）
+func (s *businessService) searchByRelevance(query string, filter model.BusinessSearchFilter, page, pageSize int) ([]*model.Business, int64, *model.BusinessFacets, error) {
+	s.suggester.RecordQuery(query)
+
+	hits, err := s.searcher.Search(search.Query{
+		Text:    query,
+		Weights: businessSearchWeights,
+		Limit:   maxSearchHits + 1,
+	})
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	truncated := len(hits) > maxSearchHits
+	if truncated {
+		hits = hits[:maxSearchHits]
+	}
+
+	ids := make([]int, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	matched, err := s.businessRepo.FilterSearchIDs(ids, filter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	facets, err := s.searchFacets(ids, filter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	facets.Truncated = truncated
+
+	// 按相关度顺序保留满足筛选条件的命中，已暂停或索引尚未同步删除的商家不在 matched 中
+	isMatched := make(map[int]bool, len(matched))
+	for _, id := range matched {
+		isMatched[id] = true
+	}
+	matchedHits := make([]search.Hit, 0, len(matched))
+	for _, hit := range hits {
+		if isMatched[hit.ID] {
+			matchedHits = append(matchedHits, hit)
+		}
+	}
+	start, end := pageBounds(len(matchedHits), page, pageSize)
+	pageHits := matchedHits[start:end]
+
+	pageIDs := make([]int, len(pageHits))
+	for i, hit := range pageHits {
+		pageIDs[i] = hit.ID
+	}
+	found, err := s.businessRepo.GetByIDs(pageIDs)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	byID := make(map[int]*model.Business, len(found))
+	for _, business := range found {
+		byID[business.ID] = business
+	}
+
+	businesses := make([]*model.Business, 0, len(pageHits))
+	for _, hit := range pageHits {
+		business, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		score := hit.Score
+		business.SearchScore = &score
+		business.Highlights = map[string]string{}
+		for field, text := range businessSearchFields(business) {
+			if highlighted := search.Highlight(text, query); highlighted != "" {
+				business.Highlights[field] = highlighted
+			}
+		}
+		businesses = append(businesses, business)
+	}
+	return businesses, int64(len(matchedHits)), facets, nil
+}
+
+// pageBounds 返回第 page 页在长度为 n 的列表中的起止下标，pageSize 为 0 时为整个列表
+func pageBounds(n, page, pageSize int) (int, int) {
+	if pageSize <= 0 {
+		return 0, n
+	}
+	start := (page - 1) * pageSize
+	if start > n {
+		start = n
+	}
+	end := start + pageSize
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+// paginateBusinesses 对内存中的商家列表分页，pageSize 为 0 时不分页
+func paginateBusinesses(businesses []*model.Business, page, pageSize int) []*model.Business {
+	start, end := pageBounds(len(businesses), page, pageSize)
+	return businesses[start:end]
+}
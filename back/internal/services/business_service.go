@@ -2,59 +2,130 @@ package services
 
 import (
 	"errors"
+	"merchant_back/internal/common"
 	model "merchant_back/internal/models"
 	"merchant_back/internal/repositories"
+	"merchant_back/internal/search"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// ErrVersionConflict 数据已被并发修改，调用方携带的版本号与当前版本不一致
+var ErrVersionConflict = repositories.ErrVersionConflict
+
+// dateLayout 日期格式，用于证件有效期、合同期限等按天计算的日期
+const dateLayout = "2006-01-02"
+
 // BusinessService 商家服务接口
 type BusinessService interface {
 	// 基础CRUD操作
-	GetBusinesses() ([]*model.Business, error)
+	GetBusinesses(q *common.ListQuery, filter model.RegionFilter, openAt *time.Time) ([]*model.Business, *common.ListPageInfo, error)
 	GetBusiness(id int) (*model.Business, error)
-	CreateBusiness(business *model.Business) error
-	UpdateBusiness(id int, business *model.Business) error
-	DeleteBusiness(id int) error
+	CreateBusiness(business *model.Business, actorID uint) error
+	UpdateBusiness(id int, business *model.Business, actorID uint) error
+	PatchBusiness(id int, patchType string, patch []byte, version int, actorID uint) (*model.Business, error)
+	// DeleteBusiness 删除商家（移入回收站），version 为 If-Match 版本号，0 表示不校验
+	DeleteBusiness(id int, version int) error
 
 	// 业务查询方法
-	SearchBusinesses(query string) ([]*model.Business, error)
+	SearchBusinesses(query string, filter model.BusinessSearchFilter, page, pageSize int) ([]*model.Business, int64, *model.BusinessFacets, error)
 	GetBusinessesByType(businessType string) ([]*model.Business, error)
 	GetBusinessByEmail(email string) (*model.Business, error)
 	GetBusinessesByRating(minRating float64) ([]*model.Business, error)
-	GetNearbyBusinesses(lat, lng, radius float64) ([]*model.Business, error)
+	GetNearbyBusinesses(lat, lng, radius float64, openAt *time.Time) ([]*model.Business, error)
 	GetBusinessesByStatus(status string) ([]*model.Business, error)
 
 	// 分页查询
 	GetBusinessesWithPagination(page, pageSize int) ([]*model.Business, int64, error)
 
 	// 批量操作
-	BatchCreateBusinesses(businesses []*model.Business) error
-	BatchUpdateBusinesses(businesses []*model.Business) error
+	BatchCreateBusinesses(businesses []*model.Business, actorID uint) error
+	BatchUpdateBusinesses(businesses []*model.Business, actorID uint) ([]model.VersionConflict, error)
 	BatchDeleteBusinesses(ids []int) error
 
 	// 统计方法
-	GetBusinessCount() (int64, error)
+	GetBusinessCount(filter model.RegionFilter) (int64, error)
 	GetBusinessCountByType(businessType string) (int64, error)
 	GetBusinessCountByStatus(status string) (int64, error)
-
-	// 业务状态管理
-	UpdateBusinessStatus(id int, status string) error
-	ActivateBusiness(id int) error
-	DeactivateBusiness(id int) error
-	SuspendBusiness(id int) error
+	GetRegionStats(level string, filter model.RegionFilter) ([]*model.RegionCount, error)
+
+	// 营业时间
+	// SetOpeningHours version 为 If-Match 版本号（0 表示不校验），在更新语句中校验
+	SetOpeningHours(id int, req *model.OpeningHoursRequest, version int, actorID uint) (*model.Business, error)
+
+	// 业务状态管理（仅允许合法的状态流转，见 business_status.go）
+	// UpdateBusinessStatus version 为 If-Match 版本号（0 表示不校验），与状态条件一起在更新语句中校验
+	UpdateBusinessStatus(id int, req *model.BusinessStatusRequest, version int, actorID uint) error
+	ActivateBusiness(id int, change model.StatusChange) error
+	DeactivateBusiness(id int, change model.StatusChange) error
+	SuspendBusiness(id int, change model.StatusChange) error
+	GetStatusHistory(id int, q *common.ListQuery) ([]*model.BusinessStatusTransition, *common.ListPageInfo, error)
+
+	// 计划状态流转
+	ScheduleStatusTransition(id int, req *model.ScheduleTransitionRequest, actorID uint) (*model.BusinessScheduledTransition, error)
+	GetScheduledTransitions(id int) ([]*model.BusinessScheduledTransition, error)
+	CancelScheduledTransition(id int, scheduleID uint) error
+	RunDueScheduledTransitions(now time.Time) (int, error)
+
+	// 入驻审核流程
+	SubmitBusiness(id int, actorID uint) error
+	AssignReviewer(id int, reviewerID, actorID uint) error
+	ApproveBusiness(id int, actorID uint, note string) error
+	RejectBusiness(id int, actorID uint, reason string) error
+	GetReviewQueue(reviewerID uint) ([]*model.Business, error)
+
+	// 回收站（见 business_trash.go）
+	GetDeletedBusinesses(page, pageSize int) ([]*model.Business, int64, error)
+	RestoreBusiness(id int) (*model.Business, error)
+	PurgeBusiness(id int) error
+	PurgeExpiredBusinesses(retentionDays int, now time.Time) (int, error)
+
+	// 全文搜索与名称联想（见 business_search.go）
+	SuggestBusinesses(prefix string, limit int) ([]search.Suggestion, []string, error)
+	RebuildSearchIndex() (int, error)
+	RefreshSearchIndex(ids ...int)
+
+	// 标签统计（见 business_attribute.go）
+	GetTags(prefix string, limit int) ([]*model.TagCount, error)
+
+	// 资料修订记录（见 business_revision.go），状态变更记录见 GetStatusHistory
+	GetRevisions(id int) ([]*model.BusinessRevision, error)
+	GetRevision(id, version int) (*model.BusinessRevision, error)
+	DiffRevisions(id, fromVersion, toVersion int) ([]model.RevisionFieldDiff, error)
+	RestoreRevision(id, version int, actorID uint) (*model.Business, error)
 }
 
 // businessService 商家服务实现
 type businessService struct {
-	businessRepo repositories.BusinessRepository
+	businessRepo  repositories.BusinessRepository
+	statusRepo    repositories.BusinessStatusRepository
+	revisionRepo  repositories.BusinessRevisionRepository
+	userRepo      repositories.UserRepository
+	categoryRepo  repositories.CategoryRepository
+	attributeRepo repositories.BusinessAttributeRepository
+	brandRepo     repositories.BrandRepository
+	memberRepo    repositories.BusinessMemberRepository
+	mediaService  MediaService
+	searcher      search.Backend
+	suggester     *search.SuggestIndex
 }
 
 // NewBusinessService 创建商家服务实例
-func NewBusinessService(businessRepo repositories.BusinessRepository) BusinessService {
+func NewBusinessService(businessRepo repositories.BusinessRepository, statusRepo repositories.BusinessStatusRepository, revisionRepo repositories.BusinessRevisionRepository, userRepo repositories.UserRepository, categoryRepo repositories.CategoryRepository, attributeRepo repositories.BusinessAttributeRepository, brandRepo repositories.BrandRepository, memberRepo repositories.BusinessMemberRepository, mediaService MediaService, searcher search.Backend) BusinessService {
 	return &businessService{
-		businessRepo: businessRepo,
+		businessRepo:  businessRepo,
+		statusRepo:    statusRepo,
+		revisionRepo:  revisionRepo,
+		userRepo:      userRepo,
+		categoryRepo:  categoryRepo,
+		attributeRepo: attributeRepo,
+		brandRepo:     brandRepo,
+		memberRepo:    memberRepo,
+		mediaService:  mediaService,
+		searcher:      searcher,
+		suggester:     search.NewSuggestIndex(),
 	}
 }
 
@@ -75,27 +146,14 @@ func validatePhone(phone string) bool {
 	return regex.MatchString(phone)
 }
 
-// validateBusinessType 验证商家类型
-func validateBusinessType(businessType string) bool {
-	validTypes := []string{
-		model.BusinessTypeRestaurant,
-		model.BusinessTypeRetail,
-		model.BusinessTypeService,
-		model.BusinessTypeEntertainment,
-		model.BusinessTypeOther,
-	}
-
-	for _, validType := range validTypes {
-		if strings.EqualFold(businessType, validType) {
-			return true
-		}
-	}
-	return true // 允许自定义类型
-}
-
 // validateBusinessStatus 验证商家状态
 func validateBusinessStatus(status string) bool {
 	validStatuses := []string{
+		model.BusinessStatusDraft,
+		model.BusinessStatusSubmitted,
+		model.BusinessStatusUnderReview,
+		model.BusinessStatusApproved,
+		model.BusinessStatusRejected,
 		model.BusinessStatusActive,
 		model.BusinessStatusInactive,
 		model.BusinessStatusSuspended,
@@ -109,9 +167,234 @@ func validateBusinessStatus(status string) bool {
 	return false
 }
 
-// GetBusinesses 获取商家列表
-func (s *businessService) GetBusinesses() ([]*model.Business, error) {
-	return s.businessRepo.GetAll()
+// validateRegionLevel 验证地区聚合层级
+func validateRegionLevel(level string) bool {
+	switch level {
+	case model.RegionLevelCountry, model.RegionLevelProvince, model.RegionLevelCity, model.RegionLevelDistrict:
+		return true
+	}
+	return false
+}
+
+// normalizeAddress 同步展示地址与结构化地址：缺少结构化字段时从展示地址解析，缺少展示地址时由结构化字段拼接
+func normalizeAddress(business *model.Business) error {
+	if business.Address == "" && business.HasStructuredAddress() {
+		business.Address = common.FormatAddress(common.AddressParts{
+			Province:   business.Province,
+			City:       business.City,
+			District:   business.District,
+			Street:     business.Street,
+			PostalCode: business.PostalCode,
+		})
+	} else if business.Address != "" && !business.HasStructuredAddress() {
+		parts := common.ParseAddress(business.Address)
+		business.Country = parts.Country
+		business.Province = parts.Province
+		business.City = parts.City
+		business.District = parts.District
+		business.Street = parts.Street
+		business.PostalCode = parts.PostalCode
+	}
+
+	business.Country = strings.ToUpper(business.Country)
+	if business.Country != "" && !regexp.MustCompile(`^[A-Z]{2}$`).MatchString(business.Country) {
+		return errors.New("国家/地区代码必须为两位字母（ISO 3166-1）")
+	}
+	if len(business.Province) > 100 || len(business.City) > 100 || len(business.District) > 100 {
+		return errors.New("省/市/区名称长度不能超过100个字符")
+	}
+	if len(business.Street) > 255 {
+		return errors.New("街道地址长度不能超过255个字符")
+	}
+	if len(business.PostalCode) > 20 {
+		return errors.New("邮政编码长度不能超过20个字符")
+	}
+	return nil
+}
+
+// validateAddress 同步展示地址与结构化地址，并校验展示地址不为空且不超过255个字符
+func validateAddress(business *model.Business) error {
+	if err := normalizeAddress(business); err != nil {
+		return err
+	}
+	if business.Address == "" {
+		return errors.New("地址不能为空")
+	}
+	if len(business.Address) > 255 {
+		return errors.New("地址长度不能超过255个字符")
+	}
+	return nil
+}
+
+// validateContact 校验联系方式与手机号
+func validateContact(business *model.Business) error {
+	if business.Contact == "" {
+		return errors.New("联系方式不能为空")
+	}
+	if len(business.Contact) > 255 {
+		return errors.New("联系方式长度不能超过255个字符")
+	}
+	if business.Phone != "" && !validatePhone(business.Phone) {
+		return errors.New("手机号格式不正确")
+	}
+	return nil
+}
+
+// sameStructuredAddress 两个商家的结构化地址是否相同
+func sameStructuredAddress(a, b *model.Business) bool {
+	return a.Country == b.Country && a.Province == b.Province && a.City == b.City &&
+		a.District == b.District && a.Street == b.Street && a.PostalCode == b.PostalCode
+}
+
+// clearStructuredAddress 清空结构化地址，由 normalizeAddress 从展示地址重新解析
+func clearStructuredAddress(business *model.Business) {
+	business.Country = ""
+	business.Province = ""
+	business.City = ""
+	business.District = ""
+	business.Street = ""
+	business.PostalCode = ""
+}
+
+// validateTimezone 验证 IANA 时区
+func validateTimezone(timezone string) bool {
+	if timezone == "" {
+		return false
+	}
+	_, err := time.LoadLocation(timezone)
+	return err == nil
+}
+
+// validateOpeningHours 验证每周营业时段与特殊日期安排
+func validateOpeningHours(hours []model.BusinessHours, special []model.BusinessSpecialHours) error {
+	for i, h := range hours {
+		if h.Weekday < 0 || h.Weekday > 6 {
+			return errors.New("第" + strconv.Itoa(i+1) + "个营业时段星期无效（0-6）")
+		}
+		if _, err := time.Parse(model.HoursTimeLayout, h.OpenTime); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个营业时段开始时间格式不正确（HH:MM）")
+		}
+		if _, err := time.Parse(model.HoursTimeLayout, h.CloseTime); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个营业时段结束时间格式不正确（HH:MM）")
+		}
+	}
+	for i, h := range hours {
+		for j := i + 1; j < len(hours); j++ {
+			if h.Weekday == hours[j].Weekday && hoursOverlap(h.OpenTime, h.CloseTime, hours[j].OpenTime, hours[j].CloseTime) {
+				return errors.New("第" + strconv.Itoa(i+1) + "个与第" + strconv.Itoa(j+1) + "个营业时段重叠")
+			}
+		}
+	}
+
+	for i, sh := range special {
+		if _, err := time.Parse(model.HoursDateLayout, sh.Date); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个特殊日期格式不正确（YYYY-MM-DD）")
+		}
+		if sh.Closed {
+			continue
+		}
+		if _, err := time.Parse(model.HoursTimeLayout, sh.OpenTime); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个特殊日期开始时间格式不正确（HH:MM）")
+		}
+		if _, err := time.Parse(model.HoursTimeLayout, sh.CloseTime); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个特殊日期结束时间格式不正确（HH:MM）")
+		}
+		if len(sh.Note) > 255 {
+			return errors.New("第" + strconv.Itoa(i+1) + "个特殊日期备注长度不能超过255个字符")
+		}
+	}
+	return nil
+}
+
+// hoursOverlap 检查同一天的两个时段是否重叠（以分钟计，跨夜时段延伸到次日）
+func hoursOverlap(open1, close1, open2, close2 string) bool {
+	toRange := func(open, closing string) (int, int) {
+		o, _ := time.Parse(model.HoursTimeLayout, open)
+		c, _ := time.Parse(model.HoursTimeLayout, closing)
+		start := o.Hour()*60 + o.Minute()
+		end := c.Hour()*60 + c.Minute()
+		if end <= start {
+			end += 24 * 60
+		}
+		return start, end
+	}
+	s1, e1 := toRange(open1, close1)
+	s2, e2 := toRange(open2, close2)
+	return s1 < e2 && s2 < e1
+}
+
+// attachOpenStatus 加载营业时间并计算当前营业状态，同时应用品牌继承的字段、设置图片缩略图地址与占位色
+func (s *businessService) attachOpenStatus(businesses []*model.Business) error {
+	if err := s.businessRepo.LoadOpeningHours(businesses); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, business := range businesses {
+		business.ApplyOpenStatus(now)
+	}
+	if err := s.attachBrands(businesses); err != nil {
+		return err
+	}
+	return s.attachImages(businesses)
+}
+
+// withOpenStatus 为查询结果附加营业状态，openAt 不为空时仅保留该时刻营业的商家
+func (s *businessService) withOpenStatus(businesses []*model.Business, openAt *time.Time) ([]*model.Business, error) {
+	if err := s.attachOpenStatus(businesses); err != nil {
+		return nil, err
+	}
+	if openAt == nil {
+		return businesses, nil
+	}
+
+	open := make([]*model.Business, 0, len(businesses))
+	for _, business := range businesses {
+		if business.IsOpenAt(*openAt) {
+			open = append(open, business)
+		}
+	}
+	return open, nil
+}
+
+// GetBusinesses 按统一列表查询条件获取商家列表，返回当前页数据与分页信息
+// 指定营业时刻时营业状态无法在数据库中筛选，先取出全部符合条件的商家过滤后再分页，此时不支持游标分页
+func (s *businessService) GetBusinesses(q *common.ListQuery, filter model.RegionFilter, openAt *time.Time) ([]*model.Business, *common.ListPageInfo, error) {
+	fields, err := s.businessListFields()
+	if err != nil {
+		return nil, nil, err
+	}
+	if openAt == nil {
+		businesses, page, err := s.businessRepo.List(q, filter, fields)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.loadCategoriesAndTags(businesses); err != nil {
+			return nil, nil, err
+		}
+		businesses, err = s.withOpenStatus(businesses, nil)
+		return businesses, page, err
+	}
+	if q.CursorMode {
+		return nil, nil, common.InvalidListQuery("按营业时间筛选时不支持游标分页")
+	}
+
+	all := *q
+	all.PageSize = 0
+	businesses, _, err := s.businessRepo.List(&all, filter, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	businesses, err = s.withOpenStatus(businesses, openAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &common.ListPageInfo{Total: int64(len(businesses))}
+	businesses = paginateBusinesses(businesses, q.Page, q.PageSize)
+	if err := s.loadCategoriesAndTags(businesses); err != nil {
+		return nil, nil, err
+	}
+	return businesses, page, nil
 }
 
 // GetBusiness 获取单个商家
@@ -125,11 +408,19 @@ func (s *businessService) GetBusiness(id int) (*model.Business, error) {
 		return nil, errors.New("商家不存在")
 	}
 
+	if err := s.attachOpenStatus([]*model.Business{business}); err != nil {
+		return nil, err
+	}
+	if err := s.loadCategoriesAndTags([]*model.Business{business}); err != nil {
+		return nil, err
+	}
+	business.ApplyImageURL(true)
+
 	return business, nil
 }
 
-// CreateBusiness 创建商家
-func (s *businessService) CreateBusiness(business *model.Business) error {
+// CreateBusiness 创建商家并记录首个修订版本，创建人成为店主
+func (s *businessService) CreateBusiness(business *model.Business, actorID uint) error {
 	// 业务验证
 	if business.Name == "" {
 		return errors.New("商家名称不能为空")
@@ -138,65 +429,99 @@ func (s *businessService) CreateBusiness(business *model.Business) error {
 		return errors.New("商家名称长度不能超过255个字符")
 	}
 
-	if business.Email == "" {
-		return errors.New("邮箱不能为空")
+	if err := s.prepareBusinessBrand(business, nil, actorID); err != nil {
+		return err
 	}
-	if !validateEmail(business.Email) {
-		return errors.New("邮箱格式不正确")
+	if err := validateBusinessEmail(business); err != nil {
+		return err
 	}
 
-	if business.Address == "" {
-		return errors.New("地址不能为空")
-	}
-	if len(business.Address) > 255 {
-		return errors.New("地址长度不能超过255个字符")
+	if err := validateAddress(business); err != nil {
+		return err
 	}
 
-	if business.Type == "" {
-		return errors.New("商家类型不能为空")
+	if err := s.resolveBusinessCategories(business); err != nil {
+		return err
 	}
-	if !validateBusinessType(business.Type) {
-		return errors.New("商家类型无效")
+	if err := s.prepareTagsAndAttributes(business, business.CategoryIDs); err != nil {
+		return err
 	}
 
-	if business.Contact == "" {
-		return errors.New("联系方式不能为空")
-	}
-	if len(business.Contact) > 255 {
-		return errors.New("联系方式长度不能超过255个字符")
+	if err := validateContact(business); err != nil {
+		return err
 	}
 
-	if business.Phone != "" && !validatePhone(business.Phone) {
-		return errors.New("手机号格式不正确")
+	if business.Timezone == "" {
+		business.Timezone = model.DefaultBusinessTimezone
+	} else if !validateTimezone(business.Timezone) {
+		return errors.New("时区无效")
 	}
-
-	if business.Rating < 0 || business.Rating > 5 {
-		return errors.New("评分必须在0-5之间")
+	if err := validateOpeningHours(business.OpeningHours, business.SpecialHours); err != nil {
+		return err
 	}
 
-	// 设置默认状态
+	// 新商家从草稿开始，需经入驻审核流程激活
 	if business.Status == "" {
-		business.Status = model.BusinessStatusActive
-	} else if !validateBusinessStatus(business.Status) {
-		return errors.New("商家状态无效")
-	}
+		business.Status = model.BusinessStatusDraft
+	} else if business.Status != model.BusinessStatusDraft {
+		return errors.New("新商家只能以草稿状态创建，请通过入驻审核流程激活")
+	}
+	business.ReviewerID = nil
+	business.RejectReason = nil
+	business.SubmittedAt = nil
+	business.DeletedAt.Valid = false
+	business.DeleteMarker = 0
+	business.Version = 1
+	// 评分由顾客评价计算，新商家从零开始
+	business.Rating = 0
+	business.RatingCount = 0
 
 	// 检查邮箱是否已存在
-	existingBusiness, err := s.businessRepo.GetByEmail(business.Email)
-	if err == nil && existingBusiness != nil {
+	if s.emailInUse(business.Email) {
 		return errors.New("邮箱已被使用")
 	}
 
-	// 设置默认评分
-	if business.Rating == 0 {
-		business.Rating = 0.0
+	if err := s.prepareBusinessImage(business, actorID); err != nil {
+		return err
+	}
+	if err := s.businessRepo.Create(business); err != nil {
+		return err
+	}
+	if err := s.saveBusinessCategories(business); err != nil {
+		return err
+	}
+	if err := s.saveTagsAndAttributes(business); err != nil {
+		return err
+	}
+	if err := s.addOwner(business.ID, actorID); err != nil {
+		return err
 	}
 
-	return s.businessRepo.Create(business)
+	return s.recordRevision(business.ID, model.RevisionActionCreate, actorID, nil)
 }
 
-// UpdateBusiness 更新商家
-func (s *businessService) UpdateBusiness(id int, business *model.Business) error {
+// addOwner 将创建人设为商家店主
+func (s *businessService) addOwner(businessID int, actorID uint) error {
+	now := time.Now()
+	return s.memberRepo.Create(&model.BusinessMember{
+		BusinessID: businessID,
+		UserID:     actorID,
+		Role:       model.BusinessRoleOwner,
+		Status:     model.BusinessMemberStatusActive,
+		JoinedAt:   &now,
+	})
+}
+
+// UpdateBusiness 更新商家并记录修订版本
+func (s *businessService) UpdateBusiness(id int, business *model.Business, actorID uint) error {
+	if err := s.updateBusiness(id, business, actorID); err != nil {
+		return err
+	}
+	return s.recordRevision(id, model.RevisionActionUpdate, actorID, nil)
+}
+
+// updateBusiness 校验并保存商家资料（不含营业时间与状态）
+func (s *businessService) updateBusiness(id int, business *model.Business, actorID uint) error {
 	if id <= 0 {
 		return errors.New("无效的商家ID")
 	}
@@ -215,63 +540,84 @@ func (s *businessService) UpdateBusiness(id int, business *model.Business) error
 		return errors.New("商家名称长度不能超过255个字符")
 	}
 
-	if business.Email == "" {
-		return errors.New("邮箱不能为空")
+	if err := s.prepareBusinessBrand(business, existingBusiness, actorID); err != nil {
+		return err
 	}
-	if !validateEmail(business.Email) {
-		return errors.New("邮箱格式不正确")
+	if err := validateBusinessEmail(business); err != nil {
+		return err
 	}
 
-	if business.Address == "" {
-		return errors.New("地址不能为空")
+	// 展示地址已修改而结构化地址与原值相同（未显式修改）时，按新的展示地址重新解析
+	if business.Address != existingBusiness.Address && sameStructuredAddress(business, existingBusiness) {
+		clearStructuredAddress(business)
 	}
-	if len(business.Address) > 255 {
-		return errors.New("地址长度不能超过255个字符")
+	if err := validateAddress(business); err != nil {
+		return err
 	}
 
-	if business.Type == "" {
-		return errors.New("商家类型不能为空")
+	// 未传 categoryIds 且类型未变时保留原有分类
+	if len(business.CategoryIDs) == 0 && business.Type == existingBusiness.Type {
+		business.CategoryIDs = nil
+	} else if err := s.resolveBusinessCategories(business); err != nil {
+		return err
 	}
-	if !validateBusinessType(business.Type) {
-		return errors.New("商家类型无效")
+	if err := s.keepTagsAndAttributes(business, existingBusiness); err != nil {
+		return err
 	}
 
-	if business.Contact == "" {
-		return errors.New("联系方式不能为空")
-	}
-	if len(business.Contact) > 255 {
-		return errors.New("联系方式长度不能超过255个字符")
+	if err := validateContact(business); err != nil {
+		return err
 	}
 
-	if business.Phone != "" && !validatePhone(business.Phone) {
-		return errors.New("手机号格式不正确")
+	// 状态只能通过状态流转接口修改
+	if business.Status != "" && business.Status != existingBusiness.Status {
+		return errors.New("请通过状态流转接口修改商家状态")
 	}
+	business.Status = existingBusiness.Status
+	business.ReviewerID = existingBusiness.ReviewerID
+	business.RejectReason = existingBusiness.RejectReason
+	business.SubmittedAt = existingBusiness.SubmittedAt
+	business.DeletedAt = existingBusiness.DeletedAt
+	business.DeleteMarker = existingBusiness.DeleteMarker
+	business.CreatedAt = existingBusiness.CreatedAt
+	business.Rating = existingBusiness.Rating
+	business.RatingCount = existingBusiness.RatingCount
 
-	if business.Rating < 0 || business.Rating > 5 {
-		return errors.New("评分必须在0-5之间")
+	// 版本号为0表示调用方不校验版本（如 If-Match: *），以当前版本为准
+	if business.Version == 0 {
+		business.Version = existingBusiness.Version
+	} else if business.Version != existingBusiness.Version {
+		return ErrVersionConflict
 	}
 
-	// 验证状态
-	if business.Status != "" && !validateBusinessStatus(business.Status) {
-		return errors.New("商家状态无效")
+	if business.Timezone == "" {
+		business.Timezone = existingBusiness.Timezone
+	} else if !validateTimezone(business.Timezone) {
+		return errors.New("时区无效")
 	}
 
 	// 如果邮箱发生变化，检查新邮箱是否已被使用
-	if business.Email != existingBusiness.Email {
-		emailBusiness, err := s.businessRepo.GetByEmail(business.Email)
-		if err == nil && emailBusiness != nil {
-			return errors.New("邮箱已被使用")
-		}
+	if business.Email != existingBusiness.Email && s.emailInUse(business.Email) {
+		return errors.New("邮箱已被使用")
 	}
 
 	// 设置ID
 	business.ID = id
 
-	return s.businessRepo.Update(business)
+	if err := s.prepareBusinessImage(business, actorID); err != nil {
+		return err
+	}
+	if err := s.businessRepo.Update(business); err != nil {
+		return err
+	}
+	if err := s.saveBusinessCategories(business); err != nil {
+		return err
+	}
+	return s.saveTagsAndAttributes(business)
 }
 
 // DeleteBusiness 删除商家
-func (s *businessService) DeleteBusiness(id int) error {
+func (s *businessService) DeleteBusiness(id int, version int) error {
 	if id <= 0 {
 		return errors.New("无效的商家ID")
 	}
@@ -282,31 +628,11 @@ func (s *businessService) DeleteBusiness(id int) error {
 		return errors.New("商家不存在")
 	}
 
-	return s.businessRepo.Delete(id)
-}
-
-// SearchBusinesses 搜索商家
-func (s *businessService) SearchBusinesses(query string) ([]*model.Business, error) {
-	if query == "" {
-		return s.businessRepo.GetAll()
-	}
-
-	// 可以根据需求选择搜索策略
-	// 这里简单实现：先按名称搜索，如果没有结果再按地址搜索
-	businesses, err := s.businessRepo.SearchByName(query)
-	if err != nil {
-		return nil, err
-	}
-
-	// 如果名称搜索没有结果，尝试地址搜索
-	if len(businesses) == 0 {
-		businesses, err = s.businessRepo.SearchByAddress(query)
-		if err != nil {
-			return nil, err
-		}
+	if err := s.businessRepo.Delete(id, version); err != nil {
+		return err
 	}
-
-	return businesses, nil
+	s.refreshSearchIndex(id)
+	return nil
 }
 
 // GetBusinessesByType 根据类型获取商家
@@ -315,7 +641,12 @@ func (s *businessService) GetBusinessesByType(businessType string) ([]*model.Bus
 		return nil, errors.New("商家类型不能为空")
 	}
 
-	return s.businessRepo.GetByType(businessType)
+	businesses, err := s.businessRepo.GetByType(s.normalizeBusinessType(businessType))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withOpenStatus(businesses, nil)
 }
 
 // GetBusinessByEmail 根据邮箱获取商家
@@ -327,17 +658,22 @@ func (s *businessService) GetBusinessByEmail(email string) (*model.Business, err
 	return s.businessRepo.GetByEmail(email)
 }
 
-// GetBusinessesByRating 根据评分获取商家
+// GetBusinessesByRating 根据评分获取商家，评分为顾客评价的汇总值（见 ReviewService）
 func (s *businessService) GetBusinessesByRating(minRating float64) ([]*model.Business, error) {
 	if minRating < 0 || minRating > 5 {
 		return nil, errors.New("评分必须在0-5之间")
 	}
 
-	return s.businessRepo.GetByRating(minRating)
+	businesses, err := s.businessRepo.GetByRating(minRating)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withOpenStatus(businesses, nil)
 }
 
 // GetNearbyBusinesses 获取附近商家
-func (s *businessService) GetNearbyBusinesses(lat, lng, radius float64) ([]*model.Business, error) {
+func (s *businessService) GetNearbyBusinesses(lat, lng, radius float64, openAt *time.Time) ([]*model.Business, error) {
 	if lat < -90 || lat > 90 {
 		return nil, errors.New("纬度必须在-90到90之间")
 	}
@@ -348,7 +684,12 @@ func (s *businessService) GetNearbyBusinesses(lat, lng, radius float64) ([]*mode
 		return nil, errors.New("搜索半径必须大于0")
 	}
 
-	return s.businessRepo.GetByLocation(lat, lng, radius)
+	businesses, err := s.businessRepo.GetByLocation(lat, lng, radius)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withOpenStatus(businesses, openAt)
 }
 
 // GetBusinessesByStatus 根据状态获取商家
@@ -360,30 +701,12 @@ func (s *businessService) GetBusinessesByStatus(status string) ([]*model.Busines
 		return nil, errors.New("商家状态无效")
 	}
 
-	return s.businessRepo.GetByStatus(status)
-}
-
-// UpdateBusinessStatus 更新商家状态
-func (s *businessService) UpdateBusinessStatus(id int, status string) error {
-	if id <= 0 {
-		return errors.New("无效的商家ID")
-	}
-	if status == "" {
-		return errors.New("商家状态不能为空")
-	}
-	if !validateBusinessStatus(status) {
-		return errors.New("商家状态无效")
-	}
-
-	// 获取现有商家信息
-	business, err := s.businessRepo.GetByID(id)
+	businesses, err := s.businessRepo.GetByStatus(status)
 	if err != nil {
-		return errors.New("商家不存在")
+		return nil, err
 	}
 
-	// 更新状态
-	business.Status = status
-	return s.businessRepo.Update(business)
+	return s.withOpenStatus(businesses, nil)
 }
 
 // GetBusinessesWithPagination 分页获取商家列表
@@ -398,11 +721,17 @@ func (s *businessService) GetBusinessesWithPagination(page, pageSize int) ([]*mo
 		pageSize = 100 // 限制最大页面大小
 	}
 
-	return s.businessRepo.GetWithPagination(page, pageSize)
+	businesses, total, err := s.businessRepo.GetWithPagination(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	businesses, err = s.withOpenStatus(businesses, nil)
+	return businesses, total, err
 }
 
 // BatchCreateBusinesses 批量创建商家
-func (s *businessService) BatchCreateBusinesses(businesses []*model.Business) error {
+func (s *businessService) BatchCreateBusinesses(businesses []*model.Business, actorID uint) error {
 	if len(businesses) == 0 {
 		return errors.New("商家列表不能为空")
 	}
@@ -412,51 +741,184 @@ func (s *businessService) BatchCreateBusinesses(businesses []*model.Business) er
 		if business.Name == "" {
 			return errors.New("第" + strconv.Itoa(i+1) + "个商家名称不能为空")
 		}
-		if business.Email == "" {
-			return errors.New("第" + strconv.Itoa(i+1) + "个商家邮箱不能为空")
+		if err := s.prepareBusinessBrand(business, nil, actorID); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if err := validateBusinessEmail(business); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if err := validateAddress(business); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
 		}
-		if !validateEmail(business.Email) {
-			return errors.New("第" + strconv.Itoa(i+1) + "个商家邮箱格式不正确")
+		if err := validateContact(business); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
 		}
+		if business.Timezone == "" {
+			business.Timezone = model.DefaultBusinessTimezone
+		} else if !validateTimezone(business.Timezone) {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家时区无效")
+		}
+		if err := validateOpeningHours(business.OpeningHours, business.SpecialHours); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if err := s.resolveBusinessCategories(business); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if err := s.prepareTagsAndAttributes(business, business.CategoryIDs); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if business.Status == "" {
+			business.Status = model.BusinessStatusDraft
+		} else if business.Status != model.BusinessStatusDraft {
+			return errors.New("第" + strconv.Itoa(i+1) + "个商家只能以草稿状态创建")
+		}
+		business.DeletedAt.Valid = false
+		business.DeleteMarker = 0
+		business.Version = 1
+		business.Rating = 0
+		business.RatingCount = 0
 		// 检查邮箱是否重复
-		existingBusiness, err := s.businessRepo.GetByEmail(business.Email)
-		if err == nil && existingBusiness != nil {
+		if s.emailInUse(business.Email) {
 			return errors.New("第" + strconv.Itoa(i+1) + "个商家邮箱已被使用")
 		}
 	}
 
-	return s.businessRepo.BatchCreate(businesses)
+	for i, business := range businesses {
+		if err := s.prepareBusinessImage(business, actorID); err != nil {
+			return errors.New("第" + strconv.Itoa(i+1) + "个" + err.Error())
+		}
+	}
+	if err := s.businessRepo.BatchCreate(businesses); err != nil {
+		return err
+	}
+
+	for _, business := range businesses {
+		if err := s.saveBusinessCategories(business); err != nil {
+			return err
+		}
+		if err := s.saveTagsAndAttributes(business); err != nil {
+			return err
+		}
+		if err := s.addOwner(business.ID, actorID); err != nil {
+			return err
+		}
+		if err := s.recordRevision(business.ID, model.RevisionActionCreate, actorID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// BatchUpdateBusinesses 批量更新商家
-func (s *businessService) BatchUpdateBusinesses(businesses []*model.Business) error {
+// BatchUpdateBusinesses 批量更新商家，存在版本冲突时不做任何修改，返回全部冲突的商家
+func (s *businessService) BatchUpdateBusinesses(businesses []*model.Business, actorID uint) ([]model.VersionConflict, error) {
 	if len(businesses) == 0 {
-		return errors.New("商家列表不能为空")
+		return nil, errors.New("商家列表不能为空")
 	}
 
+	var conflicts []model.VersionConflict
+
 	// 验证每个商家
 	for i, business := range businesses {
 		if business.ID <= 0 {
-			return errors.New("第" + strconv.Itoa(i+1) + "个商家ID无效")
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家ID无效")
 		}
 		if business.Name == "" {
-			return errors.New("第" + strconv.Itoa(i+1) + "个商家名称不能为空")
-		}
-		if business.Email == "" {
-			return errors.New("第" + strconv.Itoa(i+1) + "个商家邮箱不能为空")
-		}
-		if !validateEmail(business.Email) {
-			return errors.New("第" + strconv.Itoa(i+1) + "个商家邮箱格式不正确")
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家名称不能为空")
 		}
 
 		// 检查商家是否存在
-		_, err := s.businessRepo.GetByID(int(business.ID))
+		existingBusiness, err := s.businessRepo.GetByID(int(business.ID))
 		if err != nil {
-			return errors.New("第" + strconv.Itoa(i+1) + "个商家不存在")
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家不存在")
+		}
+		if err := s.prepareBusinessBrand(business, existingBusiness, actorID); err != nil {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if err := validateBusinessEmail(business); err != nil {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		// 与单个更新一致：仅修改展示地址时按新的展示地址重新解析结构化地址
+		if business.Address != existingBusiness.Address && sameStructuredAddress(business, existingBusiness) {
+			clearStructuredAddress(business)
+		}
+		if err := validateAddress(business); err != nil {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if err := validateContact(business); err != nil {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
 		}
+		if business.Timezone == "" {
+			business.Timezone = existingBusiness.Timezone
+		} else if !validateTimezone(business.Timezone) {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家时区无效")
+		}
+		if business.Status != "" && business.Status != existingBusiness.Status {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家状态只能通过状态流转接口修改")
+		}
+		if len(business.CategoryIDs) == 0 && business.Type == existingBusiness.Type {
+			business.CategoryIDs = nil
+		} else if err := s.resolveBusinessCategories(business); err != nil {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		if err := s.keepTagsAndAttributes(business, existingBusiness); err != nil {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个商家" + err.Error())
+		}
+		business.Status = existingBusiness.Status
+		business.ReviewerID = existingBusiness.ReviewerID
+		business.RejectReason = existingBusiness.RejectReason
+		business.SubmittedAt = existingBusiness.SubmittedAt
+		business.DeletedAt = existingBusiness.DeletedAt
+		business.DeleteMarker = existingBusiness.DeleteMarker
+		business.CreatedAt = existingBusiness.CreatedAt
+		business.Rating = existingBusiness.Rating
+		business.RatingCount = existingBusiness.RatingCount
+
+		if business.Version == 0 {
+			business.Version = existingBusiness.Version
+		} else if business.Version != existingBusiness.Version {
+			conflicts = append(conflicts, model.VersionConflict{
+				Index:           i,
+				ID:              business.ID,
+				ExpectedVersion: business.Version,
+				CurrentVersion:  existingBusiness.Version,
+			})
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts, ErrVersionConflict
 	}
 
-	return s.businessRepo.BatchUpdate(businesses)
+	for i, business := range businesses {
+		if err := s.prepareBusinessImage(business, actorID); err != nil {
+			return nil, errors.New("第" + strconv.Itoa(i+1) + "个" + err.Error())
+		}
+	}
+
+	// 校验后到写入前仍可能被并发修改，以仓储的条件更新结果为准
+	indexes, err := s.businessRepo.BatchUpdate(businesses)
+	if err != nil {
+		for _, i := range indexes {
+			conflict := model.VersionConflict{Index: i, ID: businesses[i].ID, ExpectedVersion: businesses[i].Version}
+			if current, err := s.businessRepo.GetByID(businesses[i].ID); err == nil {
+				conflict.CurrentVersion = current.Version
+			}
+			conflicts = append(conflicts, conflict)
+		}
+		return conflicts, err
+	}
+
+	for _, business := range businesses {
+		if err := s.saveBusinessCategories(business); err != nil {
+			return nil, err
+		}
+		if err := s.saveTagsAndAttributes(business); err != nil {
+			return nil, err
+		}
+		if err := s.recordRevision(business.ID, model.RevisionActionUpdate, actorID, nil); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
 }
 
 // BatchDeleteBusinesses 批量删除商家
@@ -477,12 +939,17 @@ func (s *businessService) BatchDeleteBusinesses(ids []int) error {
 		}
 	}
 
-	return s.businessRepo.BatchDelete(ids)
+	if err := s.businessRepo.BatchDelete(ids); err != nil {
+		return err
+	}
+	s.refreshSearchIndex(ids...)
+	return nil
 }
 
-// GetBusinessCount 获取商家总数
-func (s *businessService) GetBusinessCount() (int64, error) {
-	return s.businessRepo.Count()
+// GetBusinessCount 获取商家总数（不含已暂停），筛选条件为空时统计全部地区
+// 有无地区筛选都走同一查询，保证口径一致
+func (s *businessService) GetBusinessCount(filter model.RegionFilter) (int64, error) {
+	return s.businessRepo.CountByRegion(filter)
 }
 
 // GetBusinessCountByType 根据类型获取商家数量
@@ -491,7 +958,7 @@ func (s *businessService) GetBusinessCountByType(businessType string) (int64, er
 		return 0, errors.New("商家类型不能为空")
 	}
 
-	return s.businessRepo.CountByType(businessType)
+	return s.businessRepo.CountByType(s.normalizeBusinessType(businessType))
 }
 
 // GetBusinessCountByStatus 根据状态获取商家数量
@@ -506,35 +973,45 @@ func (s *businessService) GetBusinessCountByStatus(status string) (int64, error)
 	return s.businessRepo.CountByStatus(status)
 }
 
-// ActivateBusiness 激活商家
-func (s *businessService) ActivateBusiness(id int) error {
-	business, err := s.GetBusiness(id)
-	if err != nil {
-		return err
+// GetRegionStats 按地区层级统计商家数量
+func (s *businessService) GetRegionStats(level string, filter model.RegionFilter) ([]*model.RegionCount, error) {
+	if level == "" {
+		level = model.RegionLevelProvince
+	}
+	if !validateRegionLevel(level) {
+		return nil, errors.New("地区层级无效")
 	}
 
-	business.Status = model.BusinessStatusActive
-	return s.businessRepo.Update(business)
+	return s.businessRepo.AggregateByRegion(level, filter)
 }
 
-// DeactivateBusiness 停用商家
-func (s *businessService) DeactivateBusiness(id int) error {
+// SetOpeningHours 设置商家时区与营业时间（整体替换）
+func (s *businessService) SetOpeningHours(id int, req *model.OpeningHoursRequest, version int, actorID uint) (*model.Business, error) {
 	business, err := s.GetBusiness(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	business.Status = model.BusinessStatusInactive
-	return s.businessRepo.Update(business)
-}
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = business.Timezone
+	}
+	if timezone == "" {
+		timezone = model.DefaultBusinessTimezone
+	}
+	if !validateTimezone(timezone) {
+		return nil, errors.New("时区无效")
+	}
+	if err := validateOpeningHours(req.OpeningHours, req.SpecialHours); err != nil {
+		return nil, err
+	}
 
-// SuspendBusiness 暂停商家
-func (s *businessService) SuspendBusiness(id int) error {
-	business, err := s.GetBusiness(id)
-	if err != nil {
-		return err
+	if err := s.businessRepo.ReplaceOpeningHours(id, timezone, req.OpeningHours, req.SpecialHours, version); err != nil {
+		return nil, err
+	}
+	if err := s.recordRevision(id, model.RevisionActionHours, actorID, nil); err != nil {
+		return nil, err
 	}
 
-	business.Status = model.BusinessStatusSuspended
-	return s.businessRepo.Update(business)
+	return s.GetBusiness(id)
 }
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+)
+
+// dueScheduleBatchSize 每次执行的到期计划流转数量上限
+const dueScheduleBatchSize = 100
+
+// normalizeStatusChange 校验原因代码，未填写时使用默认原因
+func normalizeStatusChange(change *model.StatusChange, defaultReason string) error {
+	change.Note = strings.TrimSpace(change.Note)
+	if change.ReasonCode == "" {
+		change.ReasonCode = defaultReason
+	}
+	if !model.IsValidStatusReason(change.ReasonCode) {
+		return errors.New("原因代码无效")
+	}
+	return nil
+}
+
+// checkLeaveSuspended 解除暂停（管理员处置或证件过期自动暂停）须由系统管理员操作，actorID 为 0 表示系统任务
+func (s *businessService) checkLeaveSuspended(from, to string, actorID uint) error {
+	if from != model.BusinessStatusSuspended || to == model.BusinessStatusSuspended || actorID == 0 {
+		return nil
+	}
+	if actor, err := s.userRepo.GetByID(actorID); err == nil && actor.IsAdmin() && actor.IsActive() {
+		return nil
+	}
+	return errors.New("只有管理员可以解除商家的暂停状态")
+}
+
+// UpdateBusinessStatus 更新商家状态，仅允许合法的状态流转；设置 until 时到期自动恢复原状态
+func (s *businessService) UpdateBusinessStatus(id int, req *model.BusinessStatusRequest, version int, actorID uint) error {
+	if id <= 0 {
+		return errors.New("无效的商家ID")
+	}
+	if req.Status == "" {
+		return errors.New("商家状态不能为空")
+	}
+	if !validateBusinessStatus(req.Status) {
+		return errors.New("商家状态无效")
+	}
+
+	switch req.Status {
+	case model.BusinessStatusSubmitted:
+		return s.submitBusiness(id, actorID, version)
+	case model.BusinessStatusUnderReview:
+		return errors.New("请通过分配审核人开始审核")
+	case model.BusinessStatusApproved:
+		return s.approveBusiness(id, actorID, req.Note, version)
+	case model.BusinessStatusRejected:
+		return s.rejectBusiness(id, actorID, req.Note, version)
+	}
+
+	change := model.StatusChange{ActorID: actorID, ReasonCode: req.ReasonCode, Note: req.Note, Version: version}
+	if err := normalizeStatusChange(&change, model.StatusReasonOther); err != nil {
+		return err
+	}
+
+	business, err := s.businessRepo.GetByID(id)
+	if err != nil {
+		return errors.New("商家不存在")
+	}
+
+	from := business.Status
+	if req.Until != nil {
+		if !req.Until.After(time.Now()) {
+			return errors.New("恢复时间必须晚于当前时间")
+		}
+		if !model.CanTransitionBusinessStatus(req.Status, from) {
+			return errors.New("该状态流转不支持到期自动恢复")
+		}
+		if err := s.checkLeaveSuspended(req.Status, from, actorID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.transition(business, req.Status, change, nil); err != nil {
+		return err
+	}
+	// 手动变更状态后，之前安排的计划流转不再适用
+	if err := s.statusRepo.CancelPendingSchedules(id); err != nil {
+		return err
+	}
+
+	if req.Until != nil {
+		return s.statusRepo.CreateSchedule(&model.BusinessScheduledTransition{
+			BusinessID: id,
+			FromStatus: req.Status,
+			ToStatus:   from,
+			ExecuteAt:  *req.Until,
+			ReasonCode: model.StatusReasonScheduled,
+			Note:       "到期自动恢复",
+			ActorID:    actorID,
+			Status:     model.ScheduleStatusPending,
+		})
+	}
+	return nil
+}
+
+// transition 执行状态流转并记录操作人、原因与时间
+func (s *businessService) transition(business *model.Business, to string, change model.StatusChange, fields map[string]interface{}) error {
+	if !model.CanTransitionBusinessStatus(business.Status, to) {
+		return errors.New("不允许从 " + business.Status + " 流转到 " + to)
+	}
+	if err := s.checkLeaveSuspended(business.Status, to, change.ActorID); err != nil {
+		return err
+	}
+
+	reviewerID := business.ReviewerID
+	if v, ok := fields["reviewer_id"].(*uint); ok {
+		reviewerID = v
+	}
+
+	transition := &model.BusinessStatusTransition{
+		BusinessID: business.ID,
+		FromStatus: business.Status,
+		ToStatus:   to,
+		ActorID:    change.ActorID,
+		ReviewerID: reviewerID,
+		ReasonCode: change.ReasonCode,
+		Note:       change.Note,
+		ScheduleID: change.ScheduleID,
+	}
+	if err := s.statusRepo.Transition(transition, fields, change.Version); err != nil {
+		return err
+	}
+
+	business.Status = to
+	s.refreshSearchIndex(business.ID) // 暂停的商家不出现在联想中
+	return nil
+}
+
+// changeStatus 按 ID 执行状态流转
+func (s *businessService) changeStatus(id int, to string, change model.StatusChange) error {
+	if err := normalizeStatusChange(&change, model.StatusReasonOther); err != nil {
+		return err
+	}
+
+	business, err := s.GetBusiness(id)
+	if err != nil {
+		return err
+	}
+
+	return s.transition(business, to, change, nil)
+}
+
+// ActivateBusiness 激活商家（审核通过或恢复停用/暂停的商家）
+func (s *businessService) ActivateBusiness(id int, change model.StatusChange) error {
+	return s.changeStatus(id, model.BusinessStatusActive, change)
+}
+
+// DeactivateBusiness 停用商家
+func (s *businessService) DeactivateBusiness(id int, change model.StatusChange) error {
+	return s.changeStatus(id, model.BusinessStatusInactive, change)
+}
+
+// SuspendBusiness 暂停商家
+func (s *businessService) SuspendBusiness(id int, change model.StatusChange) error {
+	return s.changeStatus(id, model.BusinessStatusSuspended, change)
+}
+
+// GetStatusHistory 获取商家状态变更历史
+func (s *businessService) GetStatusHistory(id int, q *common.ListQuery) ([]*model.BusinessStatusTransition, *common.ListPageInfo, error) {
+	if _, err := s.GetBusiness(id); err != nil {
+		return nil, nil, err
+	}
+
+	return s.statusRepo.GetTransitions(id, q)
+}
+
+// ScheduleStatusTransition 安排计划状态流转，到期由后台任务执行
+func (s *businessService) ScheduleStatusTransition(id int, req *model.ScheduleTransitionRequest, actorID uint) (*model.BusinessScheduledTransition, error) {
+	business, err := s.GetBusiness(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validateBusinessStatus(req.Status) {
+		return nil, errors.New("商家状态无效")
+	}
+	if !req.ExecuteAt.After(time.Now()) {
+		return nil, errors.New("计划执行时间必须晚于当前时间")
+	}
+	if !model.CanTransitionBusinessStatus(business.Status, req.Status) {
+		return nil, errors.New("不允许从 " + business.Status + " 流转到 " + req.Status)
+	}
+	if err := s.checkLeaveSuspended(business.Status, req.Status, actorID); err != nil {
+		return nil, err
+	}
+	switch req.Status {
+	case model.BusinessStatusActive, model.BusinessStatusInactive, model.BusinessStatusSuspended:
+	default:
+		return nil, errors.New("入驻审核流程状态不支持计划流转")
+	}
+
+	change := model.StatusChange{ActorID: actorID, ReasonCode: req.ReasonCode, Note: req.Note}
+	if err := normalizeStatusChange(&change, model.StatusReasonScheduled); err != nil {
+		return nil, err
+	}
+
+	schedule := &model.BusinessScheduledTransition{
+		BusinessID: id,
+		FromStatus: business.Status,
+		ToStatus:   req.Status,
+		ExecuteAt:  req.ExecuteAt,
+		ReasonCode: change.ReasonCode,
+		Note:       change.Note,
+		ActorID:    actorID,
+		Status:     model.ScheduleStatusPending,
+	}
+	if err := s.statusRepo.CreateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// GetScheduledTransitions 获取商家的计划流转
+func (s *businessService) GetScheduledTransitions(id int) ([]*model.BusinessScheduledTransition, error) {
+	if _, err := s.GetBusiness(id); err != nil {
+		return nil, err
+	}
+
+	return s.statusRepo.GetSchedules(id)
+}
+
+// CancelScheduledTransition 取消待执行的计划流转
+func (s *businessService) CancelScheduledTransition(id int, scheduleID uint) error {
+	cancelled, err := s.statusRepo.CancelSchedule(id, scheduleID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return errors.New("计划流转不存在或已执行")
+	}
+	return nil
+}
+
+// RunDueScheduledTransitions 执行已到期的计划流转，返回成功执行的数量
+func (s *businessService) RunDueScheduledTransitions(now time.Time) (int, error) {
+	schedules, err := s.statusRepo.GetDueSchedules(now, dueScheduleBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	executed := 0
+	for _, schedule := range schedules {
+		claimed, err := s.statusRepo.ClaimSchedule(schedule.ID)
+		if err != nil {
+			return executed, err
+		}
+		if !claimed {
+			continue
+		}
+
+		status, errMsg := s.runScheduledTransition(schedule)
+		if status == model.ScheduleStatusDone {
+			executed++
+		} else if errMsg != "" {
+			log.Printf("Scheduled transition %d for business %d %s: %s", schedule.ID, schedule.BusinessID, status, errMsg)
+		}
+		if err := s.statusRepo.FinishSchedule(schedule.ID, status, errMsg); err != nil {
+			return executed, err
+		}
+	}
+	return executed, nil
+}
+
+// runScheduledTransition 执行单个计划流转，返回执行状态与错误信息
+func (s *businessService) runScheduledTransition(schedule *model.BusinessScheduledTransition) (string, string) {
+	business, err := s.businessRepo.GetByID(schedule.BusinessID)
+	if err != nil {
+		return model.ScheduleStatusFailed, "商家不存在"
+	}
+	if business.Status != schedule.FromStatus {
+		return model.ScheduleStatusSkipped, "商家当前状态为 " + business.Status + "，计划要求 " + schedule.FromStatus
+	}
+	// 计划以系统身份执行，解除暂停的权限按安排计划的操作人校验
+	if err := s.checkLeaveSuspended(business.Status, schedule.ToStatus, schedule.ActorID); err != nil {
+		return model.ScheduleStatusFailed, err.Error()
+	}
+
+	scheduleID := schedule.ID
+	change := model.StatusChange{
+		ActorID:    0,
+		ReasonCode: schedule.ReasonCode,
+		Note:       strings.TrimSpace("计划流转#" + strconv.FormatUint(uint64(schedule.ID), 10) + " " + schedule.Note),
+		ScheduleID: &scheduleID,
+	}
+	if err := s.transition(business, schedule.ToStatus, change, nil); err != nil {
+		return model.ScheduleStatusFailed, err.Error()
+	}
+	return model.ScheduleStatusDone, ""
+}
+
+// SubmitBusiness 提交入驻申请（草稿或驳回后重新提交）
+func (s *businessService) SubmitBusiness(id int, actorID uint) error {
+	return s.submitBusiness(id, actorID, 0)
+}
+
+// submitBusiness 提交入驻申请，version 不为 0 时要求商家版本号一致
+func (s *businessService) submitBusiness(id int, actorID uint, version int) error {
+	business, err := s.GetBusiness(id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	change := model.StatusChange{ActorID: actorID, ReasonCode: model.StatusReasonMerchantRequest, Version: version}
+	return s.transition(business, model.BusinessStatusSubmitted, change, map[string]interface{}{
+		"submitted_at": &now,
+	})
+}
+
+// AssignReviewer 分配审核人，已提交的申请进入审核中；审核中的申请可重新分配
+func (s *businessService) AssignReviewer(id int, reviewerID, actorID uint) error {
+	business, err := s.GetBusiness(id)
+	if err != nil {
+		return err
+	}
+
+	reviewer, err := s.userRepo.GetByID(reviewerID)
+	if err != nil {
+		return errors.New("审核人不存在")
+	}
+	if !reviewer.IsAdmin() || !reviewer.IsActive() {
+		return errors.New("审核人必须是有效的管理员")
+	}
+
+	change := model.StatusChange{ActorID: actorID, ReasonCode: model.StatusReasonOther, Note: "分配审核人"}
+	switch business.Status {
+	case model.BusinessStatusSubmitted:
+		return s.transition(business, model.BusinessStatusUnderReview, change, map[string]interface{}{
+			"reviewer_id": &reviewerID,
+		})
+	case model.BusinessStatusUnderReview:
+		if business.ReviewerID != nil && *business.ReviewerID == reviewerID {
+			return nil
+		}
+		transition := &model.BusinessStatusTransition{
+			BusinessID: business.ID,
+			FromStatus: business.Status,
+			ToStatus:   business.Status,
+			ActorID:    actorID,
+			ReviewerID: &reviewerID,
+			ReasonCode: model.StatusReasonOther,
+			Note:       "重新分配审核人",
+		}
+		return s.statusRepo.Transition(transition, map[string]interface{}{"reviewer_id": &reviewerID}, 0)
+	default:
+		return errors.New("只有已提交或审核中的申请可以分配审核人")
+	}
+}
+
+// checkReviewer 检查用户能否审核该商家：指定审核人或管理员
+func (s *businessService) checkReviewer(business *model.Business, actorID uint) error {
+	if business.ReviewerID != nil && *business.ReviewerID == actorID {
+		return nil
+	}
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil || !actor.IsAdmin() {
+		return errors.New("只有指定审核人或管理员可以审核该商家")
+	}
+	return nil
+}
+
+// ApproveBusiness 审核通过
+func (s *businessService) ApproveBusiness(id int, actorID uint, note string) error {
+	return s.approveBusiness(id, actorID, note, 0)
+}
+
+// approveBusiness 审核通过，version 不为 0 时要求商家版本号一致
+func (s *businessService) approveBusiness(id int, actorID uint, note string, version int) error {
+	business, err := s.GetBusiness(id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkReviewer(business, actorID); err != nil {
+		return err
+	}
+
+	change := model.StatusChange{ActorID: actorID, ReasonCode: model.StatusReasonReviewApproved, Note: note, Version: version}
+	return s.transition(business, model.BusinessStatusApproved, change, map[string]interface{}{
+		"reject_reason": nil,
+	})
+}
+
+// RejectBusiness 驳回入驻申请，必须填写原因
+func (s *businessService) RejectBusiness(id int, actorID uint, reason string) error {
+	return s.rejectBusiness(id, actorID, reason, 0)
+}
+
+// rejectBusiness 驳回入驻申请，version 不为 0 时要求商家版本号一致
+func (s *businessService) rejectBusiness(id int, actorID uint, reason string, version int) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("驳回原因不能为空")
+	}
+
+	business, err := s.GetBusiness(id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkReviewer(business, actorID); err != nil {
+		return err
+	}
+
+	change := model.StatusChange{ActorID: actorID, ReasonCode: model.StatusReasonReviewRejected, Note: reason, Version: version}
+	return s.transition(business, model.BusinessStatusRejected, change, map[string]interface{}{
+		"reject_reason": reason,
+	})
+}
+
+// GetReviewQueue 获取待审核队列，reviewerID 为 0 时返回全部待审核申请
+func (s *businessService) GetReviewQueue(reviewerID uint) ([]*model.Business, error) {
+	businesses, err := s.statusRepo.GetReviewQueue(reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	return businesses, s.attachImages(businesses)
+}
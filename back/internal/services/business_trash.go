@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	model "merchant_back/internal/models"
+	"time"
+)
+
+// expiredTrashBatchSize 每次清理过期回收站商家的数量上限
+const expiredTrashBatchSize = 100
+
+// GetDeletedBusinesses 分页获取回收站中的商家
+func (s *businessService) GetDeletedBusinesses(page, pageSize int) ([]*model.Business, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	businesses, total, err := s.businessRepo.GetDeleted(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return businesses, total, s.attachImages(businesses)
+}
+
+// RestoreBusiness 从回收站恢复商家，邮箱已被其他商家占用时不能恢复
+func (s *businessService) RestoreBusiness(id int) (*model.Business, error) {
+	if id <= 0 {
+		return nil, errors.New("无效的商家ID")
+	}
+
+	business, err := s.businessRepo.GetDeletedByID(id)
+	if err != nil {
+		return nil, errors.New("回收站中不存在该商家")
+	}
+
+	if s.emailInUse(business.Email) {
+		return nil, errors.New("邮箱已被其他商家使用，无法恢复")
+	}
+
+	if err := s.businessRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	s.refreshSearchIndex(id)
+
+	return s.GetBusiness(id)
+}
+
+// PurgeBusiness 彻底删除回收站中的商家，不可恢复
+func (s *businessService) PurgeBusiness(id int) error {
+	if id <= 0 {
+		return errors.New("无效的商家ID")
+	}
+
+	if _, err := s.businessRepo.GetDeletedByID(id); err != nil {
+		return errors.New("回收站中不存在该商家")
+	}
+
+	return s.businessRepo.Purge([]int{id})
+}
+
+// PurgeExpiredBusinesses 彻底删除在回收站中超过保留天数的商家，返回删除数量
+func (s *businessService) PurgeExpiredBusinesses(retentionDays int, now time.Time) (int, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+
+	before := now.AddDate(0, 0, -retentionDays)
+	purged := 0
+	for {
+		ids, err := s.businessRepo.GetDeletedBefore(before, expiredTrashBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+		if err := s.businessRepo.Purge(ids); err != nil {
+			return purged, err
+		}
+		purged += len(ids)
+	}
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"regexp"
+	"strings"
+)
+
+// 分类限制
+const (
+	maxCategoryDepth       = 3   // 分类最多层级
+	maxCategoryNameLength  = 100 // 分类名称最大长度（字符）
+	maxCategoryIconLength  = 255 // 图标最大长度
+	maxCategoryLocales     = 20  // 最多支持的语言数
+	maxCategoryLocaleCode  = 10  // 语言代码最大长度
+	maxBusinessCategoryNum = 5   // 每个商家最多关联的分类数
+)
+
+// categorySlugPattern 分类标识格式：小写字母、数字，以连字符分隔
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// CategoryService 商家分类服务接口
+type CategoryService interface {
+	// GetCategoryTree 获取分类树，locale 不为空时附带该语言的显示名称
+	GetCategoryTree(locale string) ([]*model.Category, error)
+	GetCategory(id uint, locale string) (*model.Category, error)
+	CreateCategory(req *model.CategoryRequest) (*model.Category, error)
+	UpdateCategory(id uint, req *model.CategoryRequest) (*model.Category, error)
+	DeleteCategory(id uint) error
+	// MergeCategories 将源分类合并到目标分类，返回关联发生变化的商家数
+	MergeCategories(sourceID, targetID uint) (int, error)
+}
+
+// categoryService 商家分类服务实现
+type categoryService struct {
+	categoryRepo    repositories.CategoryRepository
+	businessService BusinessService
+}
+
+// NewCategoryService 创建商家分类服务实例
+func NewCategoryService(categoryRepo repositories.CategoryRepository, businessService BusinessService) CategoryService {
+	return &categoryService{
+		categoryRepo:    categoryRepo,
+		businessService: businessService,
+	}
+}
+
+// GetCategoryTree 获取分类树（同级按排序、ID 升序），附带各分类直接关联的商家数量
+func (s *categoryService) GetCategoryTree(locale string) ([]*model.Category, error) {
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.categoryRepo.CountBusinesses()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*model.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+		count := counts[category.ID]
+		category.BusinessCount = &count
+		if locale != "" {
+			category.DisplayName = category.LocalizedName(locale)
+		}
+	}
+
+	roots := []*model.Category{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			if parent, ok := byID[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, category)
+				continue
+			}
+		}
+		roots = append(roots, category)
+	}
+	return roots, nil
+}
+
+// GetCategory 获取分类
+func (s *categoryService) GetCategory(id uint, locale string) (*model.Category, error) {
+	category, err := s.categoryRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("分类不存在")
+	}
+	if locale != "" {
+		category.DisplayName = category.LocalizedName(locale)
+	}
+	return category, nil
+}
+
+// CreateCategory 创建分类
+func (s *categoryService) CreateCategory(req *model.CategoryRequest) (*model.Category, error) {
+	if err := validateCategoryRequest(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategoryParent(0, req.ParentID); err != nil {
+		return nil, err
+	}
+	if existing, err := s.categoryRepo.GetBySlug(req.Slug); err == nil && existing != nil {
+		return nil, errors.New("分类标识已被使用")
+	}
+
+	category := &model.Category{
+		ParentID:        req.ParentID,
+		Slug:            req.Slug,
+		Name:            req.Name,
+		Names:           req.Names,
+		Icon:            req.Icon,
+		SortOrder:       req.SortOrder,
+		AttributeSchema: req.AttributeSchema,
+	}
+	if err := s.categoryRepo.Create(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory 更新分类，标识变化时同步以该分类为主分类的商家类型
+func (s *categoryService) UpdateCategory(id uint, req *model.CategoryRequest) (*model.Category, error) {
+	category, err := s.categoryRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("分类不存在")
+	}
+	if err := validateCategoryRequest(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategoryParent(id, req.ParentID); err != nil {
+		return nil, err
+	}
+	if req.Slug != category.Slug {
+		if existing, err := s.categoryRepo.GetBySlug(req.Slug); err == nil && existing != nil {
+			return nil, errors.New("分类标识已被使用")
+		}
+	}
+
+	previousSlug := category.Slug
+	category.ParentID = req.ParentID
+	category.Slug = req.Slug
+	category.Name = req.Name
+	category.Names = req.Names
+	category.Icon = req.Icon
+	category.SortOrder = req.SortOrder
+	category.AttributeSchema = req.AttributeSchema
+
+	synced, err := s.categoryRepo.Update(category, previousSlug)
+	if err != nil {
+		return nil, err
+	}
+	s.businessService.RefreshSearchIndex(synced...)
+	return category, nil
+}
+
+// DeleteCategory 删除分类，仍有子分类或商家使用的分类不能删除（可先合并到其他分类）
+func (s *categoryService) DeleteCategory(id uint) error {
+	if _, err := s.categoryRepo.GetByID(id); err != nil {
+		return errors.New("分类不存在")
+	}
+
+	children, err := s.categoryRepo.CountChildren(id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return errors.New("请先删除或移动子分类")
+	}
+
+	counts, err := s.categoryRepo.CountBusinesses()
+	if err != nil {
+		return err
+	}
+	if counts[id] > 0 {
+		return errors.New("仍有商家使用该分类，请先合并到其他分类")
+	}
+
+	return s.categoryRepo.Delete(id)
+}
+
+// MergeCategories 合并分类：源分类的商家改为关联目标分类，子分类移到目标分类下，然后删除源分类
+func (s *categoryService) MergeCategories(sourceID, targetID uint) (int, error) {
+	if sourceID == targetID {
+		return 0, errors.New("不能将分类合并到自身")
+	}
+	if _, err := s.categoryRepo.GetByID(sourceID); err != nil {
+		return 0, errors.New("分类不存在")
+	}
+	target, err := s.categoryRepo.GetByID(targetID)
+	if err != nil {
+		return 0, errors.New("目标分类不存在")
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	parents := categoryParents(categories)
+	for _, ancestor := range categoryAncestors(parents, targetID) {
+		if ancestor == sourceID {
+			return 0, errors.New("不能将分类合并到其子分类")
+		}
+	}
+	// 源分类的子分类移到目标分类下后，层级不能超过上限
+	if len(categoryAncestors(parents, targetID))+categoryHeight(categories, sourceID)-1 > maxCategoryDepth {
+		return 0, errors.New("合并后分类层级超过3级")
+	}
+
+	affected, err := s.categoryRepo.Merge(sourceID, target)
+	if err != nil {
+		return 0, err
+	}
+	s.businessService.RefreshSearchIndex(affected...)
+	return len(affected), nil
+}
+
+// checkCategoryParent 校验上级分类存在、不形成循环且层级不超过上限，id 为 0 表示新建分类
+func (s *categoryService) checkCategoryParent(id uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == id {
+		return errors.New("上级分类不能是自身")
+	}
+	if _, err := s.categoryRepo.GetByID(*parentID); err != nil {
+		return errors.New("上级分类不存在")
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	parents := categoryParents(categories)
+	ancestors := categoryAncestors(parents, *parentID)
+	for _, ancestor := range ancestors {
+		if ancestor == id {
+			return errors.New("上级分类不能是其子分类")
+		}
+	}
+
+	height := 1
+	if id != 0 {
+		height = categoryHeight(categories, id)
+	}
+	if len(ancestors)+height > maxCategoryDepth {
+		return errors.New("分类层级不能超过3级")
+	}
+	return nil
+}
+
+// categoryParents 分类ID → 上级分类ID
+func categoryParents(categories []*model.Category) map[uint]uint {
+	parents := make(map[uint]uint, len(categories))
+	for _, category := range categories {
+		if category.ParentID != nil {
+			parents[category.ID] = *category.ParentID
+		}
+	}
+	return parents
+}
+
+// categoryAncestors 返回分类自身及其全部上级分类ID（由近及远）
+func categoryAncestors(parents map[uint]uint, id uint) []uint {
+	ancestors := []uint{id}
+	for {
+		parent, ok := parents[id]
+		if !ok || len(ancestors) > maxCategoryDepth {
+			return ancestors
+		}
+		ancestors = append(ancestors, parent)
+		id = parent
+	}
+}
+
+// categoryHeight 分类子树的层数（仅自身为1）
+func categoryHeight(categories []*model.Category, id uint) int {
+	height := 1
+	for _, category := range categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			if h := categoryHeight(categories, category.ID) + 1; h > height {
+				height = h
+			}
+		}
+	}
+	return height
+}
+
+// validateCategoryRequest 校验并规范化分类字段，未填写标识时由名称生成
+func validateCategoryRequest(req *model.CategoryRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("分类名称不能为空")
+	}
+	if len([]rune(req.Name)) > maxCategoryNameLength {
+		return errors.New("分类名称不能超过100个字符")
+	}
+
+	req.Slug = strings.TrimSpace(req.Slug)
+	if req.Slug == "" {
+		req.Slug = model.Slugify(req.Name)
+		if req.Slug == "" {
+			return errors.New("分类名称不含字母或数字，请填写分类标识（slug）")
+		}
+	}
+	if len(req.Slug) > model.MaxCategorySlugLength || !categorySlugPattern.MatchString(req.Slug) {
+		return errors.New("分类标识只能包含小写字母、数字和连字符，且不能超过100个字符")
+	}
+
+	if len(req.Icon) > maxCategoryIconLength {
+		return errors.New("图标不能超过255个字符")
+	}
+
+	if len(req.Names) > maxCategoryLocales {
+		return errors.New("分类名称最多支持20种语言")
+	}
+	names := make(map[string]string, len(req.Names))
+	for locale, name := range req.Names {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		name = strings.TrimSpace(name)
+		if locale == "" || len(locale) > maxCategoryLocaleCode {
+			return errors.New("语言代码无效: " + locale)
+		}
+		if name == "" {
+			continue
+		}
+		if len([]rune(name)) > maxCategoryNameLength {
+			return errors.New("分类名称不能超过100个字符")
+		}
+		names[locale] = name
+	}
+	req.Names = names
+
+	// 属性定义只影响之后保存的商家，已有商家在下次保存时按新定义校验
+	if raw := bytes.TrimSpace(req.AttributeSchema); len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		req.AttributeSchema = nil
+	} else {
+		if _, err := common.ParseAttributeSchema(raw); err != nil {
+			return err
+		}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, raw); err != nil {
+			return err
+		}
+		req.AttributeSchema = compacted.Bytes()
+	}
+	return nil
+}
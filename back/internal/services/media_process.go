@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"merchant_back/internal/imaging"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/storage"
+	"time"
+)
+
+// 缩略图处理参数
+const (
+	mediaProcessWorkers    = 2                // 上传后即时处理的最大并发数，超出的由后台任务处理
+	mediaMaxAttempts       = 3                // 最大尝试次数，超过后标记为失败
+	mediaProcessingTimeout = 10 * time.Minute // 处理中超过该时长视为进程中断，可重新处理
+	mediaVariantKeyPrefix  = "media-variants" // 缩略图在存储中的键前缀
+)
+
+// processInBackground 上传后在后台立即处理；并发名额已满时留给后台任务
+func (s *mediaService) processInBackground(id uint) {
+	select {
+	case s.workers <- struct{}{}:
+		go func() {
+			defer func() { <-s.workers }()
+			if err := s.ProcessMedia(id); err != nil {
+				log.Printf("Media %d processing failed: %v", id, err)
+			}
+		}()
+	default:
+	}
+}
+
+// ProcessPendingMedia 逐个处理等待中的媒体文件，单个失败不影响其他文件
+func (s *mediaService) ProcessPendingMedia(limit int) (int, error) {
+	ids, err := s.mediaRepo.PendingIDs(time.Now().Add(-mediaProcessingTimeout), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, id := range ids {
+		if err := s.ProcessMedia(id); err != nil {
+			log.Printf("Media %d processing failed: %v", id, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// ProcessMedia 抢占处理权后生成缩略图；失败时未达最大次数的重新排队，否则标记为失败
+func (s *mediaService) ProcessMedia(id uint) (err error) {
+	now := time.Now()
+	claimed, err := s.mediaRepo.Claim(id, now.Add(-mediaProcessingTimeout), now)
+	if err != nil || !claimed {
+		return err
+	}
+	media, err := s.mediaRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("处理图片时发生异常: %v", r)
+		}
+		if err != nil {
+			status := model.MediaStatusPending
+			if media.Attempts >= mediaMaxAttempts {
+				status = model.MediaStatusFailed
+			}
+			if statusErr := s.mediaRepo.SetStatus(id, status); statusErr != nil {
+				log.Printf("Media %d status update failed: %v", id, statusErr)
+			}
+		}
+	}()
+	return s.generateVariants(media)
+}
+
+// generateVariants 读取原图，移除残留的元数据（早于缩略图处理上传或由数据迁移导入的原图），
+// 按 EXIF 方向摆正后生成各规格缩略图并计算主色调。无法解码的格式（WebP）只处理元数据
+func (s *mediaService) generateVariants(media *model.Media) error {
+	ctx := context.Background()
+	body, err := s.storage.Get(ctx, media.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+
+	var written []string // 本次写入的对象，失败时清理
+	cleanup := func() {
+		for _, key := range written {
+			_ = s.storage.Delete(ctx, key)
+		}
+	}
+	put := func(prefix string, content []byte, contentType string) (string, string, error) {
+		key := storage.NewKey(prefix, model.MediaTypeExtensions[contentType])
+		if err := s.storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), contentType); err != nil {
+			return "", "", err
+		}
+		written = append(written, key)
+		sum := sha256.Sum256(content)
+		return key, hex.EncodeToString(sum[:]), nil
+	}
+
+	stripped, orientation, err := imaging.StripMetadata(data, media.ContentType)
+	if err != nil {
+		return err
+	}
+	oldKey := media.StorageKey
+	if !bytes.Equal(stripped, data) {
+		key, checksum, err := put(mediaKeyPrefix, stripped, media.ContentType)
+		if err != nil {
+			return err
+		}
+		media.StorageKey, media.Size, media.Checksum = key, int64(len(stripped)), checksum
+	}
+
+	var variants []*model.MediaVariant
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(stripped)); err == nil && cfg.Width*cfg.Height <= maxMediaPixels {
+		img, _, err := image.Decode(bytes.NewReader(stripped))
+		if err != nil {
+			cleanup()
+			return err
+		}
+		oriented := imaging.Orient(img, orientation)
+		media.Width, media.Height = oriented.Rect.Dx(), oriented.Rect.Dy()
+		media.DominantColor = imaging.DominantColor(oriented)
+
+		for _, size := range model.MediaVariantSizes {
+			w, h := imaging.Fit(media.Width, media.Height, size.MaxSide)
+			resized := imaging.Resize(oriented, w, h)
+			encoded, contentType, err := imaging.Encode(resized)
+			if err != nil {
+				cleanup()
+				return err
+			}
+			encodings := map[string][]byte{contentType: encoded}
+			if s.webp != nil {
+				if webp, err := s.webp.EncodeWebP(ctx, resized); err == nil {
+					encodings["image/webp"] = webp
+				} else {
+					log.Printf("Media %d WebP encoding skipped: %v", media.ID, err)
+				}
+			}
+			for contentType, content := range encodings {
+				key, checksum, err := put(mediaVariantKeyPrefix, content, contentType)
+				if err != nil {
+					cleanup()
+					return err
+				}
+				variants = append(variants, &model.MediaVariant{
+					MediaID:     media.ID,
+					Name:        size.Name,
+					ContentType: contentType,
+					StorageKey:  key,
+					Size:        int64(len(content)),
+					Width:       w,
+					Height:      h,
+					Checksum:    checksum,
+				})
+			}
+		}
+	}
+
+	previous := &model.Media{ID: media.ID}
+	if err := s.mediaRepo.LoadVariants([]*model.Media{previous}); err != nil {
+		cleanup()
+		return err
+	}
+	media.Status = model.MediaStatusReady
+	if err := s.mediaRepo.SaveProcessed(media, variants); err != nil {
+		cleanup()
+		return err
+	}
+
+	// 结果保存后删除被替换的对象
+	for _, v := range previous.Variants {
+		_ = s.storage.Delete(ctx, v.StorageKey)
+	}
+	if media.StorageKey != oldKey {
+		_ = s.storage.Delete(ctx, oldKey)
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // 注册解码器，用于读取图片尺寸及生成缩略图
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"merchant_back/internal/common"
+	"merchant_back/internal/imaging"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"merchant_back/internal/storage"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// 媒体文件错误
+var (
+	ErrMediaTooLarge       = errors.New("文件超过大小限制")
+	ErrMediaTypeNotAllowed = errors.New("仅支持 JPEG、PNG、GIF、WebP 图片")
+	ErrMediaInvalid        = errors.New("图片文件无法识别或已损坏")
+	ErrMediaVariantInvalid = errors.New("无效的图片规格或格式")
+)
+
+// mediaKeyPrefix 媒体文件在存储中的键前缀
+const mediaKeyPrefix = "media"
+
+// maxMediaFilenameLength 原始文件名最大长度（字符），超出部分截断
+const maxMediaFilenameLength = 255
+
+// maxMediaPixels 图片的最大像素数，防止小文件解码出超大图像耗尽内存
+const maxMediaPixels = 40_000_000
+
+// MediaFormatWebP size 参数配合 format=webp 请求 WebP 缩略图
+const MediaFormatWebP = "webp"
+
+// MediaContent 媒体内容：原图或缩略图
+type MediaContent struct {
+	ContentType string
+	Size        int64
+	Checksum    string
+	Redirect    string        // 存储配置了公开地址时为该地址，此时 Body 为空
+	Body        io.ReadCloser // 内容，由调用方关闭
+	Provisional bool          // 请求的缩略图尚未生成，暂以原图代替，不应长期缓存
+}
+
+// MediaService 媒体文件服务接口
+type MediaService interface {
+	// UploadMedia 上传媒体文件，类型按文件内容识别（不信任扩展名与客户端声明的类型），uploaderID 为 0 表示系统导入。
+	// 保存前移除 EXIF（含 GPS 位置）等元数据，缩略图在后台生成
+	UploadMedia(file io.Reader, size int64, filename string, uploaderID uint) (*model.Media, error)
+	// UploadBase64 上传 base64 编码（可带 data URI 前缀）的图片
+	UploadBase64(data string, uploaderID uint) (*model.Media, error)
+	GetMedia(id uint) (*model.Media, error)
+	// OpenMediaContent 读取原图（size 为空）或指定规格的缩略图；format 为 webp 或客户端接受 WebP 时优先返回 WebP 缩略图
+	OpenMediaContent(id uint, size, format string, acceptWebP bool) (*MediaContent, error)
+	// DeleteMedia 删除媒体文件，仅上传者或管理员可删除，仍被商家引用时不能删除
+	DeleteMedia(id, actorID uint) error
+	// DominantColors 批量获取媒体文件的主色调（媒体文件ID → #rrggbb），尚未处理的不包含在结果中
+	DominantColors(ids []uint) (map[uint]string, error)
+	// ProcessMedia 生成缩略图与主色调，已被其他进程处理时直接返回
+	ProcessMedia(id uint) error
+	// ProcessPendingMedia 处理等待中的媒体文件（含中断后超时的），返回处理成功的数量
+	ProcessPendingMedia(limit int) (int, error)
+	// MaxUploadSize 单个文件的最大字节数
+	MaxUploadSize() int64
+}
+
+// mediaService 媒体文件服务实现
+type mediaService struct {
+	mediaRepo     repositories.MediaRepository
+	userRepo      repositories.UserRepository
+	storage       storage.Storage
+	maxUploadSize int64
+	webp          imaging.WebPEncoder // 为空时不生成 WebP 缩略图
+	workers       chan struct{}       // 上传后即时处理的并发名额
+}
+
+// NewMediaService 创建媒体文件服务实例，webp 为空时只生成 JPEG/PNG 缩略图
+func NewMediaService(mediaRepo repositories.MediaRepository, userRepo repositories.UserRepository, store storage.Storage, maxUploadSize int64, webp imaging.WebPEncoder) MediaService {
+	return &mediaService{
+		mediaRepo:     mediaRepo,
+		userRepo:      userRepo,
+		storage:       store,
+		maxUploadSize: maxUploadSize,
+		webp:          webp,
+		workers:       make(chan struct{}, mediaProcessWorkers),
+	}
+}
+
+// MaxUploadSize 单个文件的最大字节数
+func (s *mediaService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// UploadMedia 校验大小与类型、移除元数据后写入存储并记录；记录保存失败时删除已写入的对象
+func (s *mediaService) UploadMedia(file io.Reader, size int64, filename string, uploaderID uint) (*model.Media, error) {
+	if size <= 0 {
+		return nil, errors.New("文件不能为空")
+	}
+	if size > s.maxUploadSize {
+		return nil, fmt.Errorf("%w（最大 %d 字节）", ErrMediaTooLarge, s.maxUploadSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, s.maxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxUploadSize {
+		return nil, fmt.Errorf("%w（最大 %d 字节）", ErrMediaTooLarge, s.maxUploadSize)
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := model.MediaTypeExtensions[contentType]
+	if !ok {
+		return nil, ErrMediaTypeNotAllowed
+	}
+	data, orientation, err := imaging.StripMetadata(data, contentType)
+	if err != nil {
+		return nil, ErrMediaInvalid
+	}
+
+	media := &model.Media{
+		StorageKey:  storage.NewKey(mediaKeyPrefix, ext),
+		Filename:    cleanMediaFilename(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Status:      model.MediaStatusPending,
+	}
+	if uploaderID != 0 {
+		media.UploadedBy = &uploaderID
+	}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		if cfg.Width*cfg.Height > maxMediaPixels {
+			return nil, fmt.Errorf("%w（图片不能超过 %d 像素）", ErrMediaTooLarge, maxMediaPixels)
+		}
+		media.Width, media.Height = cfg.Width, cfg.Height
+		if orientation >= 5 {
+			media.Width, media.Height = cfg.Height, cfg.Width
+		}
+	}
+	sum := sha256.Sum256(data)
+	media.Checksum = hex.EncodeToString(sum[:])
+
+	ctx := context.Background()
+	if err := s.storage.Put(ctx, media.StorageKey, bytes.NewReader(data), media.Size, contentType); err != nil {
+		return nil, err
+	}
+	if err := s.mediaRepo.Create(media); err != nil {
+		_ = s.storage.Delete(ctx, media.StorageKey)
+		return nil, err
+	}
+	s.processInBackground(media.ID)
+	s.applyURL(media)
+	return media, nil
+}
+
+// UploadBase64 解码 base64 图片后上传
+func (s *mediaService) UploadBase64(data string, uploaderID uint) (*model.Media, error) {
+	decoded, err := common.DecodeBase64Image(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadMedia(bytes.NewReader(decoded), int64(len(decoded)), "", uploaderID)
+}
+
+// GetMedia 获取媒体文件信息及缩略图
+func (s *mediaService) GetMedia(id uint) (*model.Media, error) {
+	media, err := s.mediaRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("文件不存在")
+	}
+	if err := s.mediaRepo.LoadVariants([]*model.Media{media}); err != nil {
+		return nil, err
+	}
+	s.applyURL(media)
+	return media, nil
+}
+
+// OpenMediaContent 读取原图或缩略图，缩略图尚未生成时以原图代替
+func (s *mediaService) OpenMediaContent(id uint, size, format string, acceptWebP bool) (*MediaContent, error) {
+	if (size != "" && !model.IsMediaVariantSize(size)) || (format != "" && format != MediaFormatWebP) {
+		return nil, ErrMediaVariantInvalid
+	}
+	media, err := s.GetMedia(id)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &MediaContent{ContentType: media.ContentType, Size: media.Size, Checksum: media.Checksum}
+	key := media.StorageKey
+	if size != "" {
+		if variant := pickMediaVariant(media.Variants, size, format == MediaFormatWebP || (format == "" && acceptWebP)); variant != nil {
+			content.ContentType, content.Size, content.Checksum = variant.ContentType, variant.Size, variant.Checksum
+			key = variant.StorageKey
+		} else {
+			content.Provisional = media.Status == model.MediaStatusPending || media.Status == model.MediaStatusProcessing
+		}
+	}
+
+	if public := s.storage.PublicURL(key); public != "" {
+		content.Redirect = public
+		return content, nil
+	}
+	body, err := s.storage.Get(context.Background(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, errors.New("文件不存在")
+	}
+	if err != nil {
+		return nil, err
+	}
+	content.Body = body
+	return content, nil
+}
+
+// pickMediaVariant 选择指定规格的缩略图，preferWebP 时优先 WebP
+func pickMediaVariant(variants []*model.MediaVariant, size string, preferWebP bool) *model.MediaVariant {
+	var picked *model.MediaVariant
+	for _, v := range variants {
+		if v.Name != size {
+			continue
+		}
+		if (v.ContentType == "image/webp") == preferWebP {
+			return v
+		}
+		if picked == nil {
+			picked = v
+		}
+	}
+	return picked
+}
+
+// DeleteMedia 删除媒体文件记录及存储中的原图与缩略图
+func (s *mediaService) DeleteMedia(id, actorID uint) error {
+	media, err := s.mediaRepo.GetByID(id)
+	if err != nil {
+		return errors.New("文件不存在")
+	}
+	if media.UploadedBy == nil || *media.UploadedBy != actorID {
+		actor, err := s.userRepo.GetByID(actorID)
+		if err != nil || !actor.IsAdmin() {
+			return errors.New("只有上传者或管理员可以删除该文件")
+		}
+	}
+
+	refs, err := s.mediaRepo.CountBusinessReferences(id)
+	if err != nil {
+		return err
+	}
+	if refs > 0 {
+		return errors.New("文件仍被商家使用，不能删除")
+	}
+	if err := s.mediaRepo.LoadVariants([]*model.Media{media}); err != nil {
+		return err
+	}
+
+	if err := s.mediaRepo.Delete(id); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	for _, v := range media.Variants {
+		if err := s.storage.Delete(ctx, v.StorageKey); err != nil {
+			return err
+		}
+	}
+	return s.storage.Delete(ctx, media.StorageKey)
+}
+
+// DominantColors 批量获取媒体文件的主色调
+func (s *mediaService) DominantColors(ids []uint) (map[uint]string, error) {
+	media, err := s.mediaRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	colors := make(map[uint]string, len(media))
+	for _, m := range media {
+		if m.DominantColor != "" {
+			colors[m.ID] = m.DominantColor
+		}
+	}
+	return colors, nil
+}
+
+// applyURL 设置原图与缩略图的访问地址：存储配置了公开地址时直接使用，否则由 API 转发
+func (s *mediaService) applyURL(media *model.Media) {
+	media.URL = s.storage.PublicURL(media.StorageKey)
+	if media.URL == "" {
+		media.URL = model.MediaContentURL(media.ID)
+	}
+	for _, v := range media.Variants {
+		v.URL = s.storage.PublicURL(v.StorageKey)
+		if v.URL == "" {
+			v.URL = model.MediaVariantURL(media.ID, v.Name)
+			if v.ContentType == "image/webp" {
+				v.URL += "&format=" + MediaFormatWebP
+			}
+		}
+	}
+}
+
+// cleanMediaFilename 只保留文件名部分并限制长度
+func cleanMediaFilename(filename string) string {
+	filename = strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if filename == "." || filename == "/" || !utf8.ValidString(filename) {
+		return ""
+	}
+	if utf8.RuneCountInString(filename) > maxMediaFilenameLength {
+		filename = string([]rune(filename)[:maxMediaFilenameLength])
+	}
+	return filename
+}
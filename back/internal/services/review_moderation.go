@@ -0,0 +1,362 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 自动审核规则的固定参数
+const (
+	minDuplicateContentLength = 15                  // 规范化后不少于该长度的内容才检查重复，短评（如“很好吃”）重复是正常现象
+	spikeRecentWindow         = 24 * time.Hour      // 评价激增的统计时间窗
+	spikeBaselineWindow       = 30 * 24 * time.Hour // 日常评价数的统计时间窗
+	spikeFactor               = 3.0                 // 时间窗内评价数超过日常水平的倍数
+	outlierMinDeviation       = 2.0                 // 星级与历史平均至少相差的星数
+	outlierSigmas             = 2.5                 // 星级与历史平均相差的标准差倍数
+	outlierMinSigma           = 0.5                 // 标准差下限，避免评分高度一致时轻微偏离也被标记
+)
+
+// defaultProfanityWords 内置不雅词汇表，可通过 REVIEW_PROFANITY_FILE 替换
+var defaultProfanityWords = []string{
+	"fuck", "fucking", "motherfucker", "shit", "bitch", "cunt", "asshole", "bastard", "dickhead", "whore",
+	"傻逼", "煞笔", "沙比", "操你", "草你", "他妈的", "你妈的", "草泥马", "狗日的", "贱人", "婊子", "去死", "脑残", "废物",
+}
+
+// ReviewModerationRules 评价自动审核规则的配置，全部规则在本地执行
+type ReviewModerationRules struct {
+	BurstWindow       time.Duration // 发表频率的统计时间窗
+	BurstMaxPerUser   int           // 时间窗内同一用户最多发表的评价数，0 表示不检查
+	BurstMaxPerIP     int           // 时间窗内同一 IP 最多发表的评价数，0 表示不检查
+	SpikeMinReviews   int           // 商家 24 小时内评价数达到该值且远超日常水平时视为激增，0 表示不检查
+	OutlierMinReviews int           // 商家已有评价数达到该值后才检查星级偏离，0 表示不检查
+	ProfanityWords    []string      // 不雅词汇
+}
+
+// LoadProfanityWords 读取不雅词汇表文件（每行一个词，# 开头为注释），path 为空时返回内置词表
+func LoadProfanityWords(path string) ([]string, error) {
+	if path == "" {
+		return defaultProfanityWords, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
+
+// profanityFilter 不雅词汇匹配：英文等词按完整单词匹配，避免误伤包含该字母序列的正常单词；
+// 中文等词在去除空白与符号后的文本中按子串匹配，以识别用符号隔开的写法
+type profanityFilter struct {
+	words     map[string]bool
+	fragments []string
+}
+
+// newProfanityFilter 创建不雅词汇匹配器
+func newProfanityFilter(words []string) *profanityFilter {
+	f := &profanityFilter{words: make(map[string]bool)}
+	for _, word := range words {
+		word = normalizeReviewText(word)
+		if word == "" {
+			continue
+		}
+		if isLatinWord(word) {
+			f.words[word] = true
+		} else {
+			f.fragments = append(f.fragments, word)
+		}
+	}
+	return f
+}
+
+// isLatinWord 是否只由拉丁字母与数字组成
+func isLatinWord(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxLatin1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Match 返回文本中出现的第一个不雅词汇，没有时返回空字符串
+func (f *profanityFilter) Match(text string) string {
+	text = strings.ToLower(text)
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+		if f.words[token] {
+			return token
+		}
+	}
+	compact := normalizeReviewText(text)
+	for _, fragment := range f.fragments {
+		if strings.Contains(compact, fragment) {
+			return fragment
+		}
+	}
+	return ""
+}
+
+// normalizeReviewText 规范化评价内容：转为小写并去除空白与符号
+func normalizeReviewText(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// reviewContentHash 规范化内容的摘要，内容过短时返回空字符串（不参与重复检查）
+func reviewContentHash(content string) string {
+	normalized := normalizeReviewText(content)
+	if utf8.RuneCountInString(normalized) < minDuplicateContentLength {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// detectFlags 按自动审核规则检查评价，返回命中的标记；review 须已设置内容摘要，新评价的ID为 0
+// previous 为修改前的评价（发表时为 nil），修改时只检查内容与星级相关的规则
+func (s *reviewService) detectFlags(review *model.Review, previous *model.Review) ([]model.ReviewFlag, error) {
+	var flags []model.ReviewFlag
+	now := time.Now()
+
+	if review.ContentHash != "" && (previous == nil || previous.ContentHash != review.ContentHash) {
+		duplicate, err := s.reviewRepo.FindDuplicate(review.ContentHash, review.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			flags = append(flags, model.ReviewFlag{
+				Code:   model.ReviewFlagDuplicateText,
+				Detail: fmt.Sprintf("内容与评价 %d 重复", duplicate.ID),
+			})
+		}
+	}
+
+	if word := s.profanity.Match(review.Content); word != "" {
+		flags = append(flags, model.ReviewFlag{Code: model.ReviewFlagProfanity, Detail: "包含不雅词汇“" + word + "”"})
+	}
+
+	if previous == nil {
+		burstFlags, err := s.detectBurst(review, now)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, burstFlags...)
+	}
+
+	if previous == nil || previous.Stars != review.Stars {
+		flag, err := s.detectOutlier(review, previous)
+		if err != nil {
+			return nil, err
+		}
+		if flag != nil {
+			flags = append(flags, *flag)
+		}
+	}
+	return flags, nil
+}
+
+// detectBurst 检查同一用户、同一 IP 的发表频率及商家收到评价的数量是否异常（发表时检查）
+func (s *reviewService) detectBurst(review *model.Review, now time.Time) ([]model.ReviewFlag, error) {
+	var flags []model.ReviewFlag
+	rules := s.rules
+	since := now.Add(-rules.BurstWindow)
+
+	if rules.BurstMaxPerUser > 0 {
+		count, err := s.reviewRepo.CountByUserSince(review.UserID, since)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(rules.BurstMaxPerUser) {
+			flags = append(flags, model.ReviewFlag{
+				Code:   model.ReviewFlagUserBurst,
+				Detail: fmt.Sprintf("该用户 %.0f 分钟内已发表 %d 条评价", rules.BurstWindow.Minutes(), count),
+			})
+		}
+	}
+	if rules.BurstMaxPerIP > 0 && review.ClientIP != "" {
+		count, err := s.reviewRepo.CountByIPSince(review.ClientIP, since)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(rules.BurstMaxPerIP) {
+			flags = append(flags, model.ReviewFlag{
+				Code:   model.ReviewFlagIPBurst,
+				Detail: fmt.Sprintf("IP %s 在 %.0f 分钟内已发表 %d 条评价", review.ClientIP, rules.BurstWindow.Minutes(), count),
+			})
+		}
+	}
+
+	if rules.SpikeMinReviews > 0 {
+		recent, err := s.reviewRepo.CountByBusinessSince(review.BusinessID, now.Add(-spikeRecentWindow))
+		if err != nil {
+			return nil, err
+		}
+		recent++ // 含本条评价
+		if recent >= int64(rules.SpikeMinReviews) {
+			baseline, err := s.reviewRepo.CountByBusinessSince(review.BusinessID, now.Add(-spikeBaselineWindow))
+			if err != nil {
+				return nil, err
+			}
+			days := (spikeBaselineWindow - spikeRecentWindow).Hours() / 24
+			daily := float64(baseline-recent+1) / days
+			if float64(recent) > spikeFactor*math.Max(daily, 1) {
+				flags = append(flags, model.ReviewFlag{
+					Code:   model.ReviewFlagRatingSpike,
+					Detail: fmt.Sprintf("商家 24 小时内收到 %d 条评价，此前日均 %.1f 条", recent, daily),
+				})
+			}
+		}
+	}
+	return flags, nil
+}
+
+// detectOutlier 检查星级是否明显偏离商家的历史评分：偏离历史平均至少 2 星，且超过星级分布标准差的 2.5 倍
+// 修改评价时从历史中扣除该评价原先计入的星级
+func (s *reviewService) detectOutlier(review *model.Review, previous *model.Review) (*model.ReviewFlag, error) {
+	if s.rules.OutlierMinReviews <= 0 {
+		return nil, nil
+	}
+	rating, err := s.reviewRepo.GetRating(review.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []int{0, rating.Star1, rating.Star2, rating.Star3, rating.Star4, rating.Star5}
+	if previous != nil && previous.Counted() && previous.Stars >= 1 && previous.Stars <= 5 {
+		counts[previous.Stars]--
+	}
+	total, sum := 0, 0
+	for stars := 1; stars <= 5; stars++ {
+		total += counts[stars]
+		sum += stars * counts[stars]
+	}
+	if total < s.rules.OutlierMinReviews {
+		return nil, nil
+	}
+
+	mean := float64(sum) / float64(total)
+	variance := 0.0
+	for stars := 1; stars <= 5; stars++ {
+		d := float64(stars) - mean
+		variance += float64(counts[stars]) * d * d
+	}
+	sigma := math.Max(math.Sqrt(variance/float64(total)), outlierMinSigma)
+	deviation := math.Abs(float64(review.Stars) - mean)
+	if deviation < outlierMinDeviation || deviation < outlierSigmas*sigma {
+		return nil, nil
+	}
+	return &model.ReviewFlag{
+		Code:   model.ReviewFlagRatingOutlier,
+		Detail: fmt.Sprintf("%d 星与商家历史平均 %.2f 星（%d 条评价，标准差 %.2f）偏离过大", review.Stars, mean, total, sigma),
+	}, nil
+}
+
+// flagLog 自动规则标记的审核记录
+func flagLog(flags []model.ReviewFlag) *model.ReviewModerationLog {
+	return &model.ReviewModerationLog{Action: model.ReviewActionFlag, Flags: flags}
+}
+
+// GetModerationQueue 获取审核队列
+func (s *reviewService) GetModerationQueue(flag string, q *common.ListQuery) ([]*model.Review, *common.ListPageInfo, error) {
+	switch flag {
+	case "", model.ReviewFlagDuplicateText, model.ReviewFlagUserBurst, model.ReviewFlagIPBurst,
+		model.ReviewFlagProfanity, model.ReviewFlagRatingOutlier, model.ReviewFlagRatingSpike:
+	default:
+		return nil, nil, common.InvalidListQuery("无效的标记代码: " + flag)
+	}
+	reviews, page, err := s.reviewRepo.ListQueue(flag, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	withPhotos(reviews...)
+	return reviews, page, nil
+}
+
+// GetModerationLogs 获取评价的审核记录
+func (s *reviewService) GetModerationLogs(id uint) ([]*model.ReviewModerationLog, error) {
+	if _, err := s.getReview(id); err != nil {
+		return nil, err
+	}
+	return s.reviewRepo.GetModerationLogs(id)
+}
+
+// ModerateReview 审核评价
+func (s *reviewService) ModerateReview(id uint, action string, req *model.ReviewModerationRequest, actorID uint) (*model.Review, error) {
+	var status string
+	switch action {
+	case model.ReviewActionApprove:
+		status = model.ReviewStatusApproved
+	case model.ReviewActionReject:
+		status = model.ReviewStatusRejected
+	case model.ReviewActionHide:
+		status = model.ReviewStatusHidden
+	default:
+		return nil, errors.New("无效的审核操作")
+	}
+
+	reason, note := strings.TrimSpace(req.Reason), strings.TrimSpace(req.Note)
+	if reason == "" {
+		if action != model.ReviewActionApprove {
+			return nil, errors.New("请选择原因")
+		}
+		reason = model.ReviewReasonVerified
+	}
+	if !model.IsValidReviewReason(reason) {
+		return nil, errors.New("原因代码无效")
+	}
+	if reason == model.ReviewReasonOther && note == "" {
+		return nil, errors.New("原因为其他时请填写备注")
+	}
+
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status == status {
+		return nil, errors.New("评价已是该状态")
+	}
+
+	now := time.Now()
+	review.Status = status
+	review.ModerationReason = reason
+	review.ModeratedBy = &actorID
+	review.ModeratedAt = &now
+	review.ModerationNote = nil
+	if note != "" {
+		review.ModerationNote = &note
+	}
+	log := &model.ReviewModerationLog{Action: action, ActorID: actorID, Reason: reason, Note: note}
+	if err := s.reviewRepo.Update(review, log); err != nil {
+		return nil, err
+	}
+	withPhotos(review)
+	return review, nil
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ReviewService 顾客评价服务接口
+// 商家评分（Business.Rating）由已公开的评价计算，评价的发表、修改、审核与删除会同步更新评分
+// 发表与修改评价时按自动审核规则检查，命中的评价进入审核队列，审核通过前不公开显示、不计入评分
+type ReviewService interface {
+	// GetReviews 获取商家的评价：管理员可见全部评价（可按 status 筛选），其他用户只能看到已公开的评价
+	GetReviews(businessID int, q *common.ListQuery, actorID uint) ([]*model.Review, *common.ListPageInfo, error)
+	// GetReview 获取评价，未公开的评价只有作者与管理员可见
+	GetReview(id uint, actorID uint) (*model.Review, error)
+	// GetRating 获取商家评分汇总（平均分、评价数与星级分布）
+	GetRating(businessID int) (*model.BusinessRating, error)
+	// CreateReview 发表评价，每个用户对每个商家只能发表一条评价；未命中自动审核规则的直接公开
+	CreateReview(businessID int, req *model.ReviewRequest, actorID uint, clientIP string) (*model.Review, error)
+	// UpdateReview 修改自己的评价；被驳回或修改后命中自动审核规则的评价进入待审核，被隐藏的评价保持隐藏
+	UpdateReview(id uint, req *model.ReviewRequest, actorID uint) (*model.Review, error)
+	// DeleteReview 删除评价，仅作者或管理员可操作
+	DeleteReview(id uint, actorID uint) error
+
+	// GetModerationQueue 获取审核队列（按发表先后），flag 不为空时只含带有该标记的评价
+	GetModerationQueue(flag string, q *common.ListQuery) ([]*model.Review, *common.ListPageInfo, error)
+	// GetModerationLogs 获取评价的审核记录
+	GetModerationLogs(id uint) ([]*model.ReviewModerationLog, error)
+	// ModerateReview 审核评价：approve 公开并计入评分，reject 驳回、hide 隐藏（须选择原因），均从评分中排除
+	ModerateReview(id uint, action string, req *model.ReviewModerationRequest, actorID uint) (*model.Review, error)
+
+	// ReplyReview 商家回复评价，已有回复时覆盖，仅拥有该商家回复权限的成员（店主、店长、收银员）、品牌管理员或管理员可操作
+	ReplyReview(id uint, content string, actorID uint) (*model.Review, error)
+	// DeleteReply 删除商家回复，权限同 ReplyReview
+	DeleteReply(id uint, actorID uint) (*model.Review, error)
+	// RebuildRatings 按已公开的评价重新汇总全部商家评分，返回评分有变化的商家数
+	RebuildRatings() (int64, error)
+}
+
+// reviewService 顾客评价服务实现
+type reviewService struct {
+	reviewRepo   repositories.ReviewRepository
+	businessRepo repositories.BusinessRepository
+	userRepo     repositories.UserRepository
+	members      BusinessMemberService
+	mediaService MediaService
+	rules        ReviewModerationRules
+	profanity    *profanityFilter
+}
+
+// NewReviewService 创建顾客评价服务实例
+func NewReviewService(reviewRepo repositories.ReviewRepository, businessRepo repositories.BusinessRepository, userRepo repositories.UserRepository, members BusinessMemberService, mediaService MediaService, rules ReviewModerationRules) ReviewService {
+	return &reviewService{
+		reviewRepo:   reviewRepo,
+		businessRepo: businessRepo,
+		userRepo:     userRepo,
+		members:      members,
+		mediaService: mediaService,
+		rules:        rules,
+		profanity:    newProfanityFilter(rules.ProfanityWords),
+	}
+}
+
+// isAdmin 操作用户是否为管理员
+func (s *reviewService) isAdmin(actorID uint) bool {
+	actor, err := s.userRepo.GetByID(actorID)
+	return err == nil && actor.IsAdmin()
+}
+
+// checkBusiness 校验商家存在（不含回收站中的商家）
+func (s *reviewService) checkBusiness(businessID int) (*model.Business, error) {
+	if businessID <= 0 {
+		return nil, errors.New("无效的商家ID")
+	}
+	business, err := s.businessRepo.GetByID(businessID)
+	if err != nil {
+		return nil, errors.New("商家不存在")
+	}
+	return business, nil
+}
+
+// getReview 获取评价
+func (s *reviewService) getReview(id uint) (*model.Review, error) {
+	review, err := s.reviewRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("评价不存在")
+	}
+	return review, nil
+}
+
+// validateReview 校验并整理评价内容，照片须为评价人自己上传的图片
+func (s *reviewService) validateReview(req *model.ReviewRequest, actorID uint) (string, []uint, error) {
+	if req.Stars < 1 || req.Stars > 5 {
+		return "", nil, errors.New("星级必须在1-5之间")
+	}
+	content := strings.TrimSpace(req.Content)
+	if utf8.RuneCountInString(content) > model.MaxReviewContentLength {
+		return "", nil, fmt.Errorf("评价内容不能超过 %d 个字符", model.MaxReviewContentLength)
+	}
+	if len(req.PhotoMediaIDs) > model.MaxReviewPhotos {
+		return "", nil, fmt.Errorf("每条评价最多 %d 张照片", model.MaxReviewPhotos)
+	}
+
+	photos := make([]uint, 0, len(req.PhotoMediaIDs))
+	seen := make(map[uint]bool, len(req.PhotoMediaIDs))
+	for _, id := range req.PhotoMediaIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		media, err := s.mediaService.GetMedia(id)
+		if err != nil {
+			return "", nil, fmt.Errorf("图片 %d 不存在", id)
+		}
+		if media.UploadedBy == nil || *media.UploadedBy != actorID {
+			return "", nil, fmt.Errorf("图片 %d 不是当前用户上传的", id)
+		}
+		photos = append(photos, id)
+	}
+	return content, photos, nil
+}
+
+// withPhotos 设置评价的照片地址
+func withPhotos(reviews ...*model.Review) {
+	for _, review := range reviews {
+		review.ApplyPhotoURLs()
+	}
+}
+
+// forViewer 设置照片地址，非管理员不返回自动审核标记，以免暴露规则细节
+func (s *reviewService) forViewer(actorID uint, reviews ...*model.Review) {
+	withPhotos(reviews...)
+	if s.isAdmin(actorID) {
+		return
+	}
+	for _, review := range reviews {
+		review.Flags = nil
+	}
+}
+
+// GetReviews 获取商家的评价
+func (s *reviewService) GetReviews(businessID int, q *common.ListQuery, actorID uint) ([]*model.Review, *common.ListPageInfo, error) {
+	if _, err := s.checkBusiness(businessID); err != nil {
+		return nil, nil, err
+	}
+
+	var statuses []string
+	if !s.isAdmin(actorID) {
+		statuses = []string{model.ReviewStatusApproved}
+	}
+	reviews, page, err := s.reviewRepo.ListByBusiness(businessID, statuses, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.forViewer(actorID, reviews...)
+	return reviews, page, nil
+}
+
+// GetReview 获取评价
+func (s *reviewService) GetReview(id uint, actorID uint) (*model.Review, error) {
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != model.ReviewStatusApproved && review.UserID != actorID && !s.isAdmin(actorID) {
+		return nil, errors.New("评价不存在")
+	}
+	s.forViewer(actorID, review)
+	return review, nil
+}
+
+// GetRating 获取商家评分汇总
+func (s *reviewService) GetRating(businessID int) (*model.BusinessRating, error) {
+	business, err := s.checkBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	rating, err := s.reviewRepo.GetRating(businessID)
+	if err != nil {
+		return nil, err
+	}
+	rating.Rating = business.Rating
+	rating.ApplyDistribution()
+	return rating, nil
+}
+
+// CreateReview 发表评价
+func (s *reviewService) CreateReview(businessID int, req *model.ReviewRequest, actorID uint, clientIP string) (*model.Review, error) {
+	business, err := s.checkBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business.Status != model.BusinessStatusActive {
+		return nil, errors.New("商家未在营业，暂不能评价")
+	}
+	content, photos, err := s.validateReview(req, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.reviewRepo.GetByBusinessUser(businessID, actorID)
+	if err == nil {
+		return nil, errors.New("已评价过该商家，请修改原有评价")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	review := &model.Review{
+		BusinessID:    businessID,
+		UserID:        actorID,
+		Stars:         req.Stars,
+		Content:       content,
+		ContentHash:   reviewContentHash(content),
+		PhotoMediaIDs: photos,
+		Status:        model.ReviewStatusApproved,
+		ClientIP:      clientIP,
+	}
+	flags, err := s.detectFlags(review, nil)
+	if err != nil {
+		return nil, err
+	}
+	var logs []*model.ReviewModerationLog
+	if len(flags) > 0 {
+		review.Status = model.ReviewStatusPending
+		review.Flags = flags
+		logs = append(logs, flagLog(flags))
+	}
+	if err := s.reviewRepo.Create(review, logs...); err != nil {
+		return nil, err
+	}
+	s.forViewer(actorID, review)
+	return review, nil
+}
+
+// UpdateReview 修改评价
+func (s *reviewService) UpdateReview(id uint, req *model.ReviewRequest, actorID uint) (*model.Review, error) {
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != actorID {
+		return nil, errors.New("只能修改自己的评价")
+	}
+	content, photos, err := s.validateReview(req, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *review
+	review.Stars = req.Stars
+	review.Content = content
+	review.ContentHash = reviewContentHash(content)
+	review.PhotoMediaIDs = photos
+	flags, err := s.detectFlags(review, &previous)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []*model.ReviewModerationLog
+	if len(flags) > 0 {
+		review.Flags = flags
+		logs = append(logs, flagLog(flags))
+	}
+	// 被驳回的评价修改后重新审核；已公开的评价修改后命中规则的暂停公开，等待审核
+	if review.Status == model.ReviewStatusRejected || (review.Status == model.ReviewStatusApproved && len(flags) > 0) {
+		review.Status = model.ReviewStatusPending
+	}
+	if err := s.reviewRepo.Update(review, logs...); err != nil {
+		return nil, err
+	}
+	s.forViewer(actorID, review)
+	return review, nil
+}
+
+// DeleteReview 删除评价
+func (s *reviewService) DeleteReview(id uint, actorID uint) error {
+	review, err := s.getReview(id)
+	if err != nil {
+		return err
+	}
+	if review.UserID != actorID && !s.isAdmin(actorID) {
+		return errors.New("只有作者或管理员可以删除评价")
+	}
+	return s.reviewRepo.Delete(id)
+}
+
+// checkReplyPermission 校验操作用户可以代表评价所属商家回复
+func (s *reviewService) checkReplyPermission(review *model.Review, actorID uint) error {
+	ok, err := s.members.CheckPermission(review.BusinessID, actorID, model.BusinessPermissionReply)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("只有该商家的成员或管理员可以回复评价")
+	}
+	return nil
+}
+
+// ReplyReview 商家回复评价
+func (s *reviewService) ReplyReview(id uint, content string, actorID uint) (*model.Review, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("回复内容不能为空")
+	}
+	if utf8.RuneCountInString(content) > model.MaxReviewReplyLength {
+		return nil, fmt.Errorf("回复内容不能超过 %d 个字符", model.MaxReviewReplyLength)
+	}
+
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkReplyPermission(review, actorID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	review.Reply = &content
+	review.RepliedBy = &actorID
+	review.RepliedAt = &now
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, err
+	}
+	s.forViewer(actorID, review)
+	return review, nil
+}
+
+// DeleteReply 删除商家回复，回复代表商家而非个人，商家的其他成员同样可以删除
+func (s *reviewService) DeleteReply(id uint, actorID uint) (*model.Review, error) {
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Reply == nil {
+		return nil, errors.New("该评价尚无回复")
+	}
+	if err := s.checkReplyPermission(review, actorID); err != nil {
+		return nil, err
+	}
+
+	review.Reply, review.RepliedBy, review.RepliedAt = nil, nil, nil
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, err
+	}
+	s.forViewer(actorID, review)
+	return review, nil
+}
+
+// RebuildRatings 重新汇总全部商家评分
+func (s *reviewService) RebuildRatings() (int64, error) {
+	return s.reviewRepo.RebuildRatings()
+}
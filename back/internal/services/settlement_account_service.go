@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ErrSettlementEncryptionDisabled 未配置结算账户加密密钥
+var ErrSettlementEncryptionDisabled = errors.New("未配置结算账户加密密钥（SETTLEMENT_ENCRYPTION_KEY）")
+
+// SettlementAccountService 商家结算账户服务接口。账号加密保存，除 GetPayoutAccount 外只返回脱敏后的账号
+type SettlementAccountService interface {
+	// GetAccount 获取商家当前的结算账户，未设置时返回 nil
+	GetAccount(businessID int) (*model.SettlementAccount, error)
+	// GetChanges 获取商家的结算账户变更历史（最新在前）
+	GetChanges(businessID int) ([]*model.SettlementAccountChange, error)
+	// GetPendingChanges 获取全部待审批的变更申请
+	GetPendingChanges() ([]*model.SettlementAccountChange, error)
+	// RequestChange 提交结算账户变更申请，由申请人以外的管理员审批后生效
+	RequestChange(businessID int, req *model.SettlementAccountChangeRequest, actorID uint) (*model.SettlementAccountChange, error)
+	// CancelChange 撤回待审批的变更申请（申请人或管理员）
+	CancelChange(businessID int, id uint, actorID uint) (*model.SettlementAccountChange, error)
+	// ApproveChange 管理员审批通过变更申请，审批人不能是申请人
+	ApproveChange(businessID int, id uint, actorID uint) (*model.SettlementAccount, error)
+	// RejectChange 管理员驳回变更申请
+	RejectChange(businessID int, id uint, actorID uint, note string) (*model.SettlementAccountChange, error)
+	// GetPayoutAccount 获取解密后的结算账户，仅供内部打款使用，不得通过接口返回
+	GetPayoutAccount(businessID int) (*model.PayoutAccount, error)
+}
+
+// settlementAccountService 商家结算账户服务实现
+type settlementAccountService struct {
+	accountRepo  repositories.SettlementAccountRepository
+	businessRepo repositories.BusinessRepository
+	userRepo     repositories.UserRepository
+	cipher       *common.FieldCipher
+}
+
+// NewSettlementAccountService 创建商家结算账户服务实例，cipher 为空时不能提交变更或解密账号
+func NewSettlementAccountService(accountRepo repositories.SettlementAccountRepository, businessRepo repositories.BusinessRepository, userRepo repositories.UserRepository, cipher *common.FieldCipher) SettlementAccountService {
+	return &settlementAccountService{
+		accountRepo:  accountRepo,
+		businessRepo: businessRepo,
+		userRepo:     userRepo,
+		cipher:       cipher,
+	}
+}
+
+// settlementCipherContext 加密附加数据，密文只能在所属商家的记录中解密
+func settlementCipherContext(businessID int) string {
+	return "settlement_account:" + strconv.Itoa(businessID)
+}
+
+// normalizeAccountNumber 去除空格与连字符后校验账号：8-34 位字母或数字（兼容 IBAN）
+func normalizeAccountNumber(number string) (string, error) {
+	number = strings.ToUpper(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, number))
+	if len(number) < 8 || len(number) > 34 {
+		return "", errors.New("银行账号长度应为 8 到 34 位")
+	}
+	for _, r := range number {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return "", errors.New("银行账号只能包含数字或字母")
+		}
+	}
+	return number, nil
+}
+
+// getChange 获取商家的变更申请
+func (s *settlementAccountService) getChange(businessID int, id uint) (*model.SettlementAccountChange, error) {
+	change, err := s.accountRepo.GetChange(id)
+	if err != nil || change.BusinessID != businessID {
+		return nil, errors.New("变更申请不存在")
+	}
+	return change, nil
+}
+
+// GetAccount 获取结算账户
+func (s *settlementAccountService) GetAccount(businessID int) (*model.SettlementAccount, error) {
+	account, err := s.accountRepo.GetByBusiness(businessID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	account.ApplyMask()
+	return account, nil
+}
+
+// GetChanges 获取变更历史
+func (s *settlementAccountService) GetChanges(businessID int) ([]*model.SettlementAccountChange, error) {
+	changes, err := s.accountRepo.ListChanges(businessID)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		change.ApplyMask()
+	}
+	return changes, nil
+}
+
+// GetPendingChanges 获取待审批的变更申请
+func (s *settlementAccountService) GetPendingChanges() ([]*model.SettlementAccountChange, error) {
+	changes, err := s.accountRepo.ListPendingChanges()
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		change.ApplyMask()
+	}
+	return changes, nil
+}
+
+// RequestChange 提交变更申请
+func (s *settlementAccountService) RequestChange(businessID int, req *model.SettlementAccountChangeRequest, actorID uint) (*model.SettlementAccountChange, error) {
+	if s.cipher == nil {
+		return nil, ErrSettlementEncryptionDisabled
+	}
+	if _, err := s.businessRepo.GetByID(businessID); err != nil {
+		return nil, errors.New("商家不存在")
+	}
+
+	accountName := strings.TrimSpace(req.AccountName)
+	bankName := strings.TrimSpace(req.BankName)
+	branchName := strings.TrimSpace(req.BranchName)
+	if accountName == "" || utf8.RuneCountInString(accountName) > 100 {
+		return nil, errors.New("户名不能为空且不能超过100个字符")
+	}
+	if bankName == "" || utf8.RuneCountInString(bankName) > 100 {
+		return nil, errors.New("开户银行不能为空且不能超过100个字符")
+	}
+	if utf8.RuneCountInString(branchName) > 255 {
+		return nil, errors.New("开户支行不能超过255个字符")
+	}
+	number, err := normalizeAccountNumber(req.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.cipher.Encrypt(number, settlementCipherContext(businessID))
+	if err != nil {
+		return nil, err
+	}
+
+	change := &model.SettlementAccountChange{
+		BusinessID:          businessID,
+		AccountName:         accountName,
+		BankName:            bankName,
+		BranchName:          branchName,
+		AccountNumberCipher: encrypted,
+		AccountLast4:        number[len(number)-4:],
+		Reason:              strings.TrimSpace(req.Reason),
+		Status:              model.SettlementChangeStatusPending,
+		RequestedBy:         actorID,
+	}
+	if err := s.accountRepo.CreateChange(change); err != nil {
+		return nil, err
+	}
+	change.ApplyMask()
+	return change, nil
+}
+
+// CancelChange 撤回变更申请
+func (s *settlementAccountService) CancelChange(businessID int, id uint, actorID uint) (*model.SettlementAccountChange, error) {
+	change, err := s.getChange(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.RequestedBy != actorID {
+		if actor, err := s.userRepo.GetByID(actorID); err != nil || !actor.IsAdmin() {
+			return nil, errors.New("只有申请人或管理员可以撤回变更申请")
+		}
+	}
+	if err := s.accountRepo.CloseChange(change, model.SettlementChangeStatusCancelled, actorID, nil); err != nil {
+		return nil, err
+	}
+	change.ApplyMask()
+	return change, nil
+}
+
+// ApproveChange 审批通过变更申请
+func (s *settlementAccountService) ApproveChange(businessID int, id uint, actorID uint) (*model.SettlementAccount, error) {
+	change, err := s.getChange(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.RequestedBy == actorID {
+		return nil, errors.New("结算账户变更需由申请人以外的管理员审批")
+	}
+	if change.Status != model.SettlementChangeStatusPending {
+		return nil, repositories.ErrSettlementChangeNotPending
+	}
+
+	account, err := s.accountRepo.ApproveChange(change, actorID)
+	if err != nil {
+		return nil, err
+	}
+	account.ApplyMask()
+	return account, nil
+}
+
+// RejectChange 驳回变更申请
+func (s *settlementAccountService) RejectChange(businessID int, id uint, actorID uint, note string) (*model.SettlementAccountChange, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, errors.New("请填写驳回原因")
+	}
+	change, err := s.getChange(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accountRepo.CloseChange(change, model.SettlementChangeStatusRejected, actorID, &note); err != nil {
+		return nil, err
+	}
+	change.ApplyMask()
+	return change, nil
+}
+
+// GetPayoutAccount 获取解密后的结算账户
+func (s *settlementAccountService) GetPayoutAccount(businessID int) (*model.PayoutAccount, error) {
+	if s.cipher == nil {
+		return nil, ErrSettlementEncryptionDisabled
+	}
+	account, err := s.accountRepo.GetByBusiness(businessID)
+	if err != nil {
+		return nil, errors.New("商家尚未设置结算账户")
+	}
+	number, err := s.cipher.Decrypt(account.AccountNumberCipher, settlementCipherContext(businessID))
+	if err != nil {
+		return nil, err
+	}
+	return &model.PayoutAccount{
+		BusinessID:    businessID,
+		AccountName:   account.AccountName,
+		BankName:      account.BankName,
+		BranchName:    account.BranchName,
+		AccountNumber: number,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"merchant_back/internal/common"
+	model "merchant_back/internal/models"
+	"strconv"
+)
+
+// 不可通过 PATCH 修改的用户字段及原因
+var userPatchForbiddenFields = map[string]string{
+	"id":        "不可修改",
+	"createdAt": "不可修改",
+	"updatedAt": "不可修改",
+	"deletedAt": "不可修改",
+	"lastLogin": "不可修改",
+	"version":   "不可修改，请使用 If-Match 请求头",
+	"username":  "不可修改",
+	"email":     "不可修改",
+	"role":      "不可修改",
+	"status":    "请通过用户状态接口修改",
+}
+
+// userPatchMaxLengths 可通过 PATCH 修改的用户字段及其最大长度（与 UserUpdateRequest 一致）
+var userPatchMaxLengths = map[string]int{
+	"firstName": 100,
+	"lastName":  100,
+	"phone":     20,
+	"avatar":    500,
+}
+
+// PatchUser 按 JSON Merge Patch / JSON Patch 部分更新用户，仅校验补丁涉及的字段
+// version 为 If-Match 携带的版本号，0 表示不校验
+func (s *userService) PatchUser(id uint, patchType string, patch []byte, version int) (*model.User, error) {
+	existing, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != existing.Version {
+		return nil, ErrVersionConflict
+	}
+
+	doc, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+	patched, touched, err := common.ApplyPatch(patchType, doc, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range touched {
+		if reason, ok := userPatchForbiddenFields[field]; ok {
+			return nil, errors.New("字段 " + field + " " + reason)
+		}
+		if _, ok := userPatchMaxLengths[field]; !ok {
+			return nil, errors.New("未知字段: " + field)
+		}
+	}
+
+	var user model.User
+	if err := json.Unmarshal(patched, &user); err != nil {
+		return nil, errors.New("字段类型错误: " + err.Error())
+	}
+
+	values := map[string]string{
+		"firstName": user.FirstName,
+		"lastName":  user.LastName,
+		"phone":     user.Phone,
+		"avatar":    user.Avatar,
+	}
+	for _, field := range touched {
+		if max := userPatchMaxLengths[field]; len(values[field]) > max {
+			return nil, errors.New("字段 " + field + " 长度不能超过" + strconv.Itoa(max) + "个字符")
+		}
+	}
+
+	existing.FirstName = user.FirstName
+	existing.LastName = user.LastName
+	existing.Phone = user.Phone
+	existing.Avatar = user.Avatar
+	if err := s.UpdateUser(existing); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
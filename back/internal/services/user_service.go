@@ -4,7 +4,9 @@ import (
 	"errors"
 	"time"
 
+	"merchant_back/internal/common"
 	model "merchant_back/internal/models"
+	"merchant_back/internal/repositories"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -18,8 +20,10 @@ type UserService interface {
 	GetUserByEmail(email string) (*model.User, error)
 	CreateUser(user *model.User) error
 	UpdateUser(user *model.User) error
-	DeleteUser(id uint) error
-	GetUsers(page, pageSize int) ([]*model.User, int64, error)
+	PatchUser(id uint, patchType string, patch []byte, version int) (*model.User, error)
+	// DeleteUser version 为 If-Match 版本号（0 表示不校验），在更新语句中校验
+	DeleteUser(id uint, version int) error
+	GetUsers(q *common.ListQuery) ([]*model.User, *common.ListPageInfo, error)
 
 	// 认证相关方法
 	Login(username, password string) (*model.User, error)
@@ -31,9 +35,16 @@ type UserService interface {
 	// 业务方法
 	GetUsersByRole(role string) ([]*model.User, error)
 	GetUsersByStatus(status string) ([]*model.User, error)
-	ActivateUser(id uint) error
-	DeactivateUser(id uint) error
-	SuspendUser(id uint) error
+	// 状态修改的 version 为 If-Match 版本号（0 表示不校验），在更新语句中校验
+	ActivateUser(id uint, version int) error
+	DeactivateUser(id uint, version int) error
+	SuspendUser(id uint, version int) error
+
+	// 回收站（软删除的用户）
+	GetDeletedUsers(page, pageSize int) ([]*model.User, int64, error)
+	RestoreUser(id uint) (*model.User, error)
+	PurgeUser(id uint) error
+	PurgeExpiredUsers(retentionDays int, now time.Time) (int, error)
 
 	// 初始化方法
 	CreateDefaultAdmin() error
@@ -112,39 +123,73 @@ func (s *userService) CreateUser(user *model.User) error {
 	if user.Status == "" {
 		user.Status = model.UserStatusActive
 	}
+	user.Version = 1
 
 	return s.db.Create(user).Error
 }
 
 // UpdateUser 更新用户
+// 仅当数据库中的版本号与 user.Version 一致时生效，成功后版本号加一，否则返回 ErrVersionConflict
 func (s *userService) UpdateUser(user *model.User) error {
-	return s.db.Save(user).Error
-}
+	expected := user.Version
+	user.Version = expected + 1
 
-// DeleteUser 删除用户
-func (s *userService) DeleteUser(id uint) error {
-	return s.db.Delete(&model.User{}, id).Error
+	result := s.db.Select("*").Where("version = ?", expected).Updates(user)
+	if result.Error != nil {
+		user.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		user.Version = expected
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// GetUsers 分页获取用户列表
-func (s *userService) GetUsers(page, pageSize int) ([]*model.User, int64, error) {
-	var users []*model.User
-	var total int64
+// DeleteUser 删除用户（软删除，移入回收站）
+// 同时写入删除标记，使用户名和邮箱可被新用户使用；version 不为 0 且与数据库不一致时返回 ErrVersionConflict
+func (s *userService) DeleteUser(id uint, version int) error {
+	return s.updateVersioned(id, version, map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"delete_marker": gorm.Expr("id"),
+		"version":       gorm.Expr("version + 1"),
+	})
+}
 
-	offset := (page - 1) * pageSize
+// GetUsers 按统一列表查询条件筛选、排序并分页获取用户列表
+func (s *userService) GetUsers(q *common.ListQuery) ([]*model.User, *common.ListPageInfo, error) {
+	query, err := repositories.ApplyListFilters(s.db.Model(&model.User{}), q, repositories.UserListFields)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// 获取总数
-	if err := s.db.Model(&model.User{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	page := &common.ListPageInfo{}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var users []*model.User
+	if q.CursorMode {
+		if query, err = repositories.ApplyListCursor(query, q, repositories.UserListFields, "users"); err != nil {
+			return nil, nil, err
+		}
+		if err := query.Find(&users).Error; err != nil {
+			return nil, nil, err
+		}
+		users, page.NextCursor, page.PrevCursor, err = repositories.ListCursorPage(users, q, repositories.UserListFields, "users")
+		return users, page, err
 	}
 
 	// 获取分页数据
-	err := s.db.Offset(offset).Limit(pageSize).Find(&users).Error
-	if err != nil {
-		return nil, 0, err
+	if query, err = repositories.ApplyListSort(query, q, repositories.UserListFields); err != nil {
+		return nil, nil, err
+	}
+	if err := repositories.ApplyListPage(query, q).Find(&users).Error; err != nil {
+		return nil, nil, err
 	}
 
-	return users, total, nil
+	return users, page, nil
 }
 
 // Login 用户登录
@@ -225,7 +270,7 @@ func (s *userService) ChangePassword(userID uint, oldPassword, newPassword strin
 
 	// 更新密码
 	user.Password = string(hashedPassword)
-	return s.db.Save(user).Error
+	return s.UpdateUser(user)
 }
 
 // ResetPassword 重置密码
@@ -249,7 +294,7 @@ func (s *userService) ResetPassword(email string, newPassword string) error {
 
 	// 更新密码
 	user.Password = string(hashedPassword)
-	return s.db.Save(user).Error
+	return s.UpdateUser(user)
 }
 
 // UpdateLastLogin 更新最后登录时间
@@ -273,18 +318,147 @@ func (s *userService) GetUsersByStatus(status string) ([]*model.User, error) {
 }
 
 // ActivateUser 激活用户
-func (s *userService) ActivateUser(id uint) error {
-	return s.db.Model(&model.User{}).Where("id = ?", id).Update("status", model.UserStatusActive).Error
+func (s *userService) ActivateUser(id uint, version int) error {
+	return s.updateStatus(id, model.UserStatusActive, version)
 }
 
 // DeactivateUser 禁用用户
-func (s *userService) DeactivateUser(id uint) error {
-	return s.db.Model(&model.User{}).Where("id = ?", id).Update("status", model.UserStatusInactive).Error
+func (s *userService) DeactivateUser(id uint, version int) error {
+	return s.updateStatus(id, model.UserStatusInactive, version)
 }
 
 // SuspendUser 暂停用户
-func (s *userService) SuspendUser(id uint) error {
-	return s.db.Model(&model.User{}).Where("id = ?", id).Update("status", model.UserStatusSuspended).Error
+func (s *userService) SuspendUser(id uint, version int) error {
+	return s.updateStatus(id, model.UserStatusSuspended, version)
+}
+
+// GetDeletedUsers 分页获取回收站中的用户（按删除时间倒序）
+func (s *userService) GetDeletedUsers(page, pageSize int) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	offset := (page - 1) * pageSize
+
+	if err := s.db.Unscoped().Model(&model.User{}).Where("deleted_at IS NOT NULL").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := s.db.Unscoped().Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// getDeletedUser 根据ID获取回收站中的用户
+func (s *userService) getDeletedUser(id uint) (*model.User, error) {
+	var user model.User
+	err := s.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("回收站中不存在该用户")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RestoreUser 从回收站恢复用户，用户名或邮箱已被占用时不能恢复
+func (s *userService) RestoreUser(id uint) (*model.User, error) {
+	user, err := s.getDeletedUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingUser model.User
+	if err := s.db.Where("username = ? OR email = ?", user.Username, user.Email).First(&existingUser).Error; err == nil {
+		return nil, errors.New("用户名或邮箱已被其他用户使用，无法恢复")
+	}
+
+	err = s.db.Unscoped().Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at":    nil,
+		"delete_marker": 0,
+		"version":       gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(id)
+}
+
+// PurgeUser 彻底删除回收站中的用户，不可恢复
+func (s *userService) PurgeUser(id uint) error {
+	if _, err := s.getDeletedUser(id); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return purgeUsers(tx, []uint{id})
+	})
+}
+
+// PurgeExpiredUsers 彻底删除在回收站中超过保留天数的用户，返回删除数量
+func (s *userService) PurgeExpiredUsers(retentionDays int, now time.Time) (int, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+
+	before := now.AddDate(0, 0, -retentionDays)
+	var ids []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.User{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return purgeUsers(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// purgeUsers 彻底删除用户及其商家成员、品牌管理员记录，须在事务中调用
+func purgeUsers(tx *gorm.DB, ids []uint) error {
+	if err := tx.Where("user_id IN ?", ids).Delete(&model.BusinessMember{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id IN ?", ids).Delete(&model.BrandAdmin{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&model.User{}, ids).Error
+}
+
+// updateStatus 更新用户状态并增加版本号
+func (s *userService) updateStatus(id uint, status string, version int) error {
+	return s.updateVersioned(id, version, map[string]interface{}{
+		"status":  status,
+		"version": gorm.Expr("version + 1"),
+	})
+}
+
+// updateVersioned 更新用户字段，version 不为 0 时在更新语句中校验版本号，不一致时返回 ErrVersionConflict
+func (s *userService) updateVersioned(id uint, version int, fields map[string]interface{}) error {
+	query := s.db.Model(&model.User{}).Where("id = ?", id)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 && version != 0 {
+		return ErrVersionConflict
+	}
+	return nil
 }
 
 // CreateDefaultAdmin 创建默认管理员账户
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"merchant_back/internal/config"
+)
+
+// 存储后端
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("文件不存在")
+
+// keyPattern 对象键格式：以 / 分隔的若干段，每段为小写字母、数字、连字符、下划线与点，不能以点开头
+var keyPattern = regexp.MustCompile(`^[a-z0-9_-][a-z0-9._-]*(/[a-z0-9_-][a-z0-9._-]*)*$`)
+
+// Storage 媒体文件存储，对象以键标识，写入后内容不再修改
+type Storage interface {
+	// Name 后端名称
+	Name() string
+	// Put 写入对象，size 为内容长度，已存在时覆盖
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get 读取对象，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// PublicURL 对象的公开访问地址，未配置公开地址时返回空字符串
+	PublicURL(key string) string
+}
+
+// New 按配置创建存储后端
+func New(cfg *config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case BackendLocal, "":
+		return NewLocalStorage(cfg.LocalDir, cfg.PublicBaseURL)
+	case BackendS3:
+		return NewS3Storage(S3Options{
+			Endpoint:      cfg.S3Endpoint,
+			Region:        cfg.S3Region,
+			Bucket:        cfg.S3Bucket,
+			AccessKey:     cfg.S3AccessKey,
+			SecretKey:     cfg.S3SecretKey,
+			PathStyle:     cfg.S3PathStyle,
+			PublicBaseURL: cfg.PublicBaseURL,
+		})
+	default:
+		return nil, errors.New("不支持的媒体存储后端: " + cfg.Backend)
+	}
+}
+
+// NewKey 生成新的对象键：前缀/年/月/随机串+扩展名，如 media/2024/05/3f9c...e1.jpg
+func NewKey(prefix, ext string) string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return prefix + "/" + time.Now().Format("2006/01") + "/" + hex.EncodeToString(buf) + ext
+}
+
+// checkKey 校验对象键，避免路径穿越及需要转义的字符
+func checkKey(key string) error {
+	if len(key) > 255 || !keyPattern.MatchString(key) || strings.Contains(key, "..") {
+		return errors.New("无效的对象键: " + key)
+	}
+	return nil
+}
+
+// joinURL 拼接公开地址前缀与对象键
+func joinURL(base, key string) string {
+	if base == "" {
+		return ""
+	}
+	return strings.TrimRight(base, "/") + "/" + key
+}
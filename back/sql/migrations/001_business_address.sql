@@ -0,0 +1,13 @@
+-- 商家结构化地址
+USE merchant_admin;
+
+ALTER TABLE business
+    ADD COLUMN country VARCHAR(2) NOT NULL DEFAULT '' COMMENT '国家/地区代码（ISO 3166-1 alpha-2）' AFTER address,
+    ADD COLUMN province VARCHAR(100) NOT NULL DEFAULT '' COMMENT '省/都道府县' AFTER country,
+    ADD COLUMN city VARCHAR(100) NOT NULL DEFAULT '' COMMENT '城市' AFTER province,
+    ADD COLUMN district VARCHAR(100) NOT NULL DEFAULT '' COMMENT '区/县' AFTER city,
+    ADD COLUMN street VARCHAR(255) NOT NULL DEFAULT '' COMMENT '街道门牌' AFTER district,
+    ADD COLUMN postal_code VARCHAR(20) NOT NULL DEFAULT '' COMMENT '邮政编码' AFTER street,
+    ADD INDEX idx_business_region (country, province, city, district);
+
+-- 已有地址由服务启动时的数据迁移（internal/migrations）尽力解析填充
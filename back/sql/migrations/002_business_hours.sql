@@ -0,0 +1,29 @@
+-- 商家营业时间
+USE merchant_admin;
+
+ALTER TABLE business
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Tokyo' COMMENT 'IANA 时区' AFTER phone;
+
+CREATE TABLE IF NOT EXISTS business_hours (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    weekday TINYINT NOT NULL COMMENT '星期（0=周日，6=周六）',
+    open_time CHAR(5) NOT NULL COMMENT '开始时间（HH:MM）',
+    close_time CHAR(5) NOT NULL COMMENT '结束时间（HH:MM），不晚于开始时间表示跨夜',
+
+    INDEX idx_business_hours_business_id (business_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家每周营业时段表';
+
+CREATE TABLE IF NOT EXISTS business_special_hours (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    date CHAR(10) NOT NULL COMMENT '日期（YYYY-MM-DD）',
+    closed TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否全天休息',
+    open_time CHAR(5) NULL COMMENT '开始时间（HH:MM）',
+    close_time CHAR(5) NULL COMMENT '结束时间（HH:MM）',
+    note VARCHAR(255) NULL COMMENT '备注（如节假日名称）',
+
+    INDEX idx_special_hours_business_date (business_id, date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家特殊日期营业安排表';
+
+-- otherInfo 中的 "Open 24 hours" 由数据迁移转换为全天营业时段
@@ -0,0 +1,23 @@
+-- 商家入驻审核流程
+USE merchant_admin;
+
+ALTER TABLE business
+    MODIFY COLUMN status VARCHAR(50) DEFAULT 'draft' COMMENT '状态（draft, submitted, under_review, approved, rejected, active, inactive, suspended）',
+    ADD COLUMN reviewer_id BIGINT UNSIGNED NULL COMMENT '入驻审核人ID' AFTER updated_at,
+    ADD COLUMN reject_reason TEXT NULL COMMENT '最近一次驳回原因' AFTER reviewer_id,
+    ADD COLUMN submitted_at DATETIME NULL COMMENT '最近一次提交审核时间' AFTER reject_reason,
+    ADD INDEX idx_business_reviewer_id (reviewer_id);
+
+CREATE TABLE IF NOT EXISTS business_status_transitions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    from_status VARCHAR(50) NULL COMMENT '原状态',
+    to_status VARCHAR(50) NOT NULL COMMENT '新状态',
+    actor_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID（0 表示系统）',
+    reviewer_id BIGINT UNSIGNED NULL COMMENT '流转后的审核人ID',
+    note TEXT NULL COMMENT '备注/驳回原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '流转时间',
+
+    INDEX idx_status_transitions_business_id (business_id),
+    INDEX idx_status_transitions_actor_id (actor_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家状态流转记录表';
@@ -0,0 +1,24 @@
+-- 商家状态变更历史与计划流转
+USE merchant_admin;
+
+ALTER TABLE business_status_transitions
+    ADD COLUMN reason_code VARCHAR(50) NULL COMMENT '原因代码' AFTER reviewer_id,
+    ADD COLUMN schedule_id BIGINT UNSIGNED NULL COMMENT '触发的计划流转ID' AFTER note;
+
+CREATE TABLE IF NOT EXISTS business_scheduled_transitions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    from_status VARCHAR(50) NOT NULL COMMENT '执行时商家应处的状态',
+    to_status VARCHAR(50) NOT NULL COMMENT '目标状态',
+    execute_at DATETIME NOT NULL COMMENT '计划执行时间',
+    reason_code VARCHAR(50) NULL COMMENT '原因代码',
+    note TEXT NULL COMMENT '备注',
+    actor_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '执行状态（pending, running, done, skipped, failed, cancelled）',
+    executed_at DATETIME NULL COMMENT '实际执行时间',
+    error VARCHAR(500) NULL COMMENT '失败原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    INDEX idx_scheduled_business_id (business_id),
+    INDEX idx_scheduled_due (status, execute_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家计划状态流转表';
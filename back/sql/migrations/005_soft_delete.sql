@@ -0,0 +1,20 @@
+-- 商家与用户软删除（回收站）
+-- 删除时 delete_marker 写入自身ID，唯一索引包含 delete_marker，
+-- 使已删除记录不再占用邮箱/用户名，未删除记录之间仍保持唯一
+USE merchant_admin;
+
+ALTER TABLE business
+    ADD COLUMN deleted_at DATETIME NULL COMMENT '删除时间（软删除）',
+    ADD COLUMN delete_marker INT NOT NULL DEFAULT 0 COMMENT '删除标记：未删除为0，删除后为自身ID',
+    DROP INDEX email,
+    ADD UNIQUE INDEX uk_business_email (email, delete_marker),
+    ADD INDEX idx_business_deleted_at (deleted_at);
+
+ALTER TABLE users
+    ADD COLUMN deleted_at DATETIME NULL COMMENT '删除时间（软删除）',
+    ADD COLUMN delete_marker BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '删除标记：未删除为0，删除后为自身ID',
+    DROP INDEX username,
+    DROP INDEX email,
+    ADD UNIQUE INDEX uk_users_username (username, delete_marker),
+    ADD UNIQUE INDEX uk_users_email (email, delete_marker),
+    ADD INDEX idx_users_deleted_at (deleted_at);
@@ -0,0 +1,17 @@
+-- 商家资料修订记录
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS business_revisions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    version INT NOT NULL COMMENT '版本号（从1开始递增）',
+    action VARCHAR(20) NOT NULL COMMENT '修订来源（create, update, hours, restore, baseline）',
+    changed_fields TEXT NULL COMMENT '相对上一版本变化的字段（逗号分隔）',
+    snapshot LONGTEXT NOT NULL COMMENT '商家资料快照（JSON）',
+    restored_from INT NULL COMMENT '恢复来源版本号',
+    actor_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID（0 表示系统）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '修订时间',
+
+    UNIQUE INDEX uk_business_revision (business_id, version),
+    INDEX idx_business_revisions_actor_id (actor_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家资料修订记录表';
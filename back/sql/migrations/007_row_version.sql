@@ -0,0 +1,8 @@
+-- 商家与用户数据版本号（乐观并发控制，对应接口的 ETag / If-Match）
+USE merchant_admin;
+
+ALTER TABLE business
+    ADD COLUMN version INT NOT NULL DEFAULT 1 COMMENT '数据版本号，每次修改加一';
+
+ALTER TABLE users
+    ADD COLUMN version INT NOT NULL DEFAULT 1 COMMENT '数据版本号，每次修改加一';
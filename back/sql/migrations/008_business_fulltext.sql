@@ -0,0 +1,11 @@
+-- 商家全文搜索索引（SEARCH_BACKEND=mysql 时使用）
+-- 使用 ngram 分词器以支持中文、日文等没有空格分隔的文字，分词长度由 ngram_token_size 控制（默认 2）
+-- 每列单独建索引，以便按字段分别计算相关度并加权
+USE merchant_admin;
+
+ALTER TABLE business
+    ADD FULLTEXT INDEX ft_business_name (name) WITH PARSER ngram,
+    ADD FULLTEXT INDEX ft_business_type (type) WITH PARSER ngram,
+    ADD FULLTEXT INDEX ft_business_address (address) WITH PARSER ngram,
+    ADD FULLTEXT INDEX ft_business_contact (contact) WITH PARSER ngram,
+    ADD FULLTEXT INDEX ft_business_description (description) WITH PARSER ngram;
@@ -0,0 +1,27 @@
+-- 商家分类（多级、多语言），商家与分类多对多关联
+-- 已有的 type 值由数据迁移 004_migrate_business_types 转换为分类
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS categories (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    parent_id INT UNSIGNED NULL COMMENT '上级分类ID（顶级分类为空）',
+    slug VARCHAR(100) NOT NULL COMMENT '分类标识（唯一，用作商家 type 字段的值）',
+    name VARCHAR(100) NOT NULL COMMENT '默认名称',
+    names TEXT NULL COMMENT '各语言名称（JSON，语言代码 → 名称）',
+    icon VARCHAR(255) NOT NULL DEFAULT '' COMMENT '图标（URL 或图标名称）',
+    sort_order INT NOT NULL DEFAULT 0 COMMENT '排序（升序）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE INDEX uk_category_slug (slug),
+    INDEX idx_categories_parent_id (parent_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家分类表';
+
+CREATE TABLE IF NOT EXISTS business_categories (
+    business_id INT NOT NULL COMMENT '商家ID',
+    category_id INT UNSIGNED NOT NULL COMMENT '分类ID',
+    position INT NOT NULL DEFAULT 0 COMMENT '顺序（0 为主分类）',
+
+    PRIMARY KEY (business_id, category_id),
+    INDEX idx_business_categories_category_id (category_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家分类关联表';
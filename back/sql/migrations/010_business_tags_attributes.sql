@@ -0,0 +1,30 @@
+-- 商家标签与自定义属性
+-- 属性定义保存在分类上（attribute_schema），属性值保存在商家上（attributes），
+-- business_attribute_values 为属性值的查询索引，保存商家时重建，用于列表的筛选与排序
+USE merchant_admin;
+
+ALTER TABLE categories
+    ADD COLUMN attribute_schema JSON NULL COMMENT '商家自定义属性定义（JSON Schema 子集）' AFTER sort_order;
+
+ALTER TABLE business
+    ADD COLUMN attributes JSON NULL COMMENT '自定义属性（按所属分类的属性定义校验）' AFTER description;
+
+CREATE TABLE IF NOT EXISTS business_tags (
+    business_id INT NOT NULL COMMENT '商家ID',
+    tag VARCHAR(50) NOT NULL COMMENT '标签',
+
+    PRIMARY KEY (business_id, tag),
+    INDEX idx_business_tags_tag (tag)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家标签表';
+
+CREATE TABLE IF NOT EXISTS business_attribute_values (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    name VARCHAR(64) NOT NULL COMMENT '属性名',
+    string_value VARCHAR(255) NULL COMMENT '文本值（字符串、布尔属性）',
+    number_value DOUBLE NULL COMMENT '数值（integer、number 属性）',
+
+    INDEX idx_business_attribute_values_business_id (business_id),
+    INDEX idx_attr_string (name, string_value),
+    INDEX idx_attr_number (name, number_value)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家自定义属性索引表';
@@ -0,0 +1,26 @@
+-- 媒体文件存储
+-- 文件内容保存在媒体存储（本地文件系统或 S3 兼容对象存储，见 MEDIA_STORAGE 配置），media 表只保存元数据；
+-- 商家图片改为通过 image_media_id 引用媒体文件。已有的 base64 图片由数据迁移 005_extract_business_images
+-- 转存后置空，image_base64 列在确认迁移完成后的后续版本中删除
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS media (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    storage_key VARCHAR(255) NOT NULL COMMENT '存储中的对象键',
+    filename VARCHAR(255) NULL COMMENT '原始文件名',
+    content_type VARCHAR(100) NOT NULL COMMENT '按文件内容识别的类型',
+    size BIGINT NOT NULL COMMENT '字节数',
+    width INT NOT NULL DEFAULT 0 COMMENT '图片宽度（像素）',
+    height INT NOT NULL DEFAULT 0 COMMENT '图片高度（像素）',
+    checksum CHAR(64) NOT NULL COMMENT '内容的 SHA-256（十六进制）',
+    uploaded_by INT UNSIGNED NULL COMMENT '上传用户ID（数据迁移导入的为空）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '上传时间',
+
+    UNIQUE INDEX uk_media_storage_key (storage_key),
+    INDEX idx_media_checksum (checksum),
+    INDEX idx_media_uploaded_by (uploaded_by)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='媒体文件表';
+
+ALTER TABLE business
+    ADD COLUMN image_media_id INT UNSIGNED NULL COMMENT '商家图片（媒体文件ID）',
+    ADD INDEX idx_business_image_media_id (image_media_id);
@@ -0,0 +1,26 @@
+-- 图片处理：上传后异步生成多种规格的缩略图（JPEG/PNG，配置 MEDIA_WEBP_ENCODER 时另生成 WebP）并计算主色调
+-- 已有的媒体文件状态为 pending，由后台任务 media-processing 补充处理，同时移除原图中残留的 EXIF 等元数据
+USE merchant_admin;
+
+ALTER TABLE media
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '处理状态（pending, processing, ready, failed）',
+    ADD COLUMN dominant_color VARCHAR(7) NOT NULL DEFAULT '' COMMENT '主色调（#rrggbb），用作加载前的占位色',
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0 COMMENT '已尝试处理次数',
+    ADD COLUMN processing_at DATETIME NULL COMMENT '最近一次开始处理的时间',
+    ADD INDEX idx_media_status (status);
+
+CREATE TABLE IF NOT EXISTS media_variants (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    media_id INT UNSIGNED NOT NULL COMMENT '媒体文件ID',
+    name VARCHAR(20) NOT NULL COMMENT '规格名称（sm, md, lg）',
+    content_type VARCHAR(100) NOT NULL COMMENT '编码类型',
+    storage_key VARCHAR(255) NOT NULL COMMENT '存储中的对象键',
+    size BIGINT NOT NULL COMMENT '字节数',
+    width INT NOT NULL COMMENT '宽度（像素）',
+    height INT NOT NULL COMMENT '高度（像素）',
+    checksum CHAR(64) NOT NULL COMMENT '内容的 SHA-256（十六进制）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '生成时间',
+
+    UNIQUE INDEX uk_media_variant (media_id, name, content_type),
+    UNIQUE INDEX uk_media_variant_storage_key (storage_key)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='媒体文件缩略图表';
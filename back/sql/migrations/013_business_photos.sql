@@ -0,0 +1,23 @@
+-- 商家相册：每个商家可有多张按顺序排列的照片，带说明文字与封面标记
+-- 管理员添加的照片直接通过，其他用户添加的需审核通过后公开显示
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS business_photos (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    media_id INT UNSIGNED NOT NULL COMMENT '媒体文件ID',
+    caption VARCHAR(255) NOT NULL DEFAULT '' COMMENT '说明文字',
+    position INT NOT NULL DEFAULT 0 COMMENT '顺序（升序）',
+    is_cover TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为封面（每个商家至多一张）',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '审核状态（pending, approved, rejected）',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    uploaded_by INT UNSIGNED NULL COMMENT '添加照片的用户ID',
+    reviewed_by INT UNSIGNED NULL COMMENT '审核人ID',
+    reviewed_at DATETIME NULL COMMENT '审核时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '添加时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    INDEX idx_business_photos_position (business_id, position),
+    INDEX idx_business_photos_media_id (media_id),
+    INDEX idx_business_photos_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家相册表';
@@ -0,0 +1,48 @@
+-- 顾客评价：每个用户对每个商家一条评价（星级、内容、照片），支持审核与商家回复
+-- 商家评分改为由已公开的评价计算：business_ratings 随评价变化增量维护评价数、星级合计与星级分布，
+-- business.rating 为其算术平均或贝叶斯平均（由 REVIEW_RATING_BAYESIAN 配置），不再手工填写
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS reviews (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    user_id INT UNSIGNED NOT NULL COMMENT '评价人ID',
+    stars TINYINT NOT NULL COMMENT '星级（1-5）',
+    content TEXT NULL COMMENT '评价内容',
+    photo_media_ids JSON NULL COMMENT '照片（媒体文件ID数组）',
+    status VARCHAR(20) NOT NULL DEFAULT 'approved' COMMENT '审核状态（pending, approved, rejected），只有 approved 计入评分',
+    moderation_note TEXT NULL COMMENT '审核意见',
+    moderated_by INT UNSIGNED NULL COMMENT '审核人ID',
+    moderated_at DATETIME NULL COMMENT '审核时间',
+    reply TEXT NULL COMMENT '商家回复',
+    replied_by INT UNSIGNED NULL COMMENT '回复人ID',
+    replied_at DATETIME NULL COMMENT '回复时间',
+    client_ip VARCHAR(45) NOT NULL DEFAULT '' COMMENT '提交评价的客户端 IP',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '发表时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_review_business_user (business_id, user_id),
+    INDEX idx_reviews_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='顾客评价表';
+
+CREATE TABLE IF NOT EXISTS business_ratings (
+    business_id INT NOT NULL PRIMARY KEY COMMENT '商家ID',
+    review_count INT NOT NULL DEFAULT 0 COMMENT '计入评分的评价数',
+    stars_sum INT NOT NULL DEFAULT 0 COMMENT '星级合计',
+    star1 INT NOT NULL DEFAULT 0 COMMENT '1星评价数',
+    star2 INT NOT NULL DEFAULT 0 COMMENT '2星评价数',
+    star3 INT NOT NULL DEFAULT 0 COMMENT '3星评价数',
+    star4 INT NOT NULL DEFAULT 0 COMMENT '4星评价数',
+    star5 INT NOT NULL DEFAULT 0 COMMENT '5星评价数',
+    average DOUBLE NOT NULL DEFAULT 0 COMMENT '算术平均',
+    bayesian DOUBLE NOT NULL DEFAULT 0 COMMENT '贝叶斯平均',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家评分汇总表';
+
+-- 原手工填写的评分保留在 legacy_rating 中备查，商家评分从零开始由评价计算
+ALTER TABLE business
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0 COMMENT '计入评分的评价数' AFTER rating,
+    ADD COLUMN legacy_rating FLOAT NULL COMMENT '改为评价计算前手工填写的评分' AFTER rating_count,
+    ADD INDEX idx_business_rating_rank (rating, rating_count);
+
+UPDATE business SET legacy_rating = rating, rating = 0;
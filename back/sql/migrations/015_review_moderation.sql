@@ -0,0 +1,28 @@
+-- 评价审核：自动规则（内容重复、同一用户/IP 频繁发表、不雅词汇、星级偏离历史评分、评价数激增）
+-- 命中的评价进入待审核队列；审核人可通过、驳回或隐藏评价并注明原因，驳回与隐藏的评价不计入商家评分
+USE merchant_admin;
+
+ALTER TABLE reviews
+    MODIFY COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved' COMMENT '审核状态（pending, approved, rejected, hidden），只有 approved 计入评分',
+    ADD COLUMN moderation_reason VARCHAR(30) NULL COMMENT '最近一次审核的原因代码' AFTER status,
+    ADD COLUMN content_hash VARCHAR(64) NOT NULL DEFAULT '' COMMENT '规范化内容的摘要，用于识别重复内容（内容过短时为空）' AFTER client_ip,
+    ADD COLUMN flags JSON NULL COMMENT '自动审核规则的标记' AFTER content_hash,
+    ADD INDEX idx_reviews_client_ip (client_ip),
+    ADD INDEX idx_reviews_content_hash (content_hash),
+    ADD INDEX idx_reviews_user_id (user_id);
+
+CREATE TABLE IF NOT EXISTS review_moderation_logs (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    review_id INT UNSIGNED NOT NULL COMMENT '评价ID',
+    action VARCHAR(20) NOT NULL COMMENT '操作（flag, approve, reject, hide）',
+    from_status VARCHAR(20) NULL COMMENT '原状态',
+    to_status VARCHAR(20) NOT NULL COMMENT '新状态',
+    actor_id INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID（0 表示自动规则）',
+    reason VARCHAR(30) NULL COMMENT '原因代码',
+    note TEXT NULL COMMENT '备注',
+    flags JSON NULL COMMENT '自动规则的标记',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+
+    INDEX idx_review_moderation_logs_review_id (review_id),
+    INDEX idx_review_moderation_logs_actor_id (actor_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='评价审核记录表';
@@ -0,0 +1,38 @@
+-- 品牌（连锁商家）与门店：门店为 brand_id 指向品牌的商家，地址、营业时间与坐标由门店各自维护
+-- 门店未填写的描述、图片使用品牌的描述与标志；品牌管理员可维护品牌资料及其全部门店
+-- 门店可不填写邮箱（统一使用品牌邮箱），邮箱唯一索引改为忽略空邮箱（需要 MySQL 8.0.13+ 的函数索引）
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS brands (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    name VARCHAR(255) NOT NULL COMMENT '品牌名称（唯一）',
+    email VARCHAR(255) NOT NULL DEFAULT '' COMMENT '品牌联系邮箱',
+    contact VARCHAR(255) NOT NULL DEFAULT '' COMMENT '联系方式',
+    description TEXT NULL COMMENT '品牌描述，门店未填写时继承',
+    logo_media_id INT UNSIGNED NULL COMMENT '品牌标志（媒体文件ID），门店未设置图片时继承',
+    category_id INT UNSIGNED NULL COMMENT '默认分类，创建门店未指定分类时使用',
+    created_by INT UNSIGNED NOT NULL COMMENT '创建人ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE INDEX uk_brands_name (name),
+    INDEX idx_brands_logo_media_id (logo_media_id),
+    INDEX idx_brands_category_id (category_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='品牌表';
+
+CREATE TABLE IF NOT EXISTS brand_admins (
+    brand_id INT UNSIGNED NOT NULL COMMENT '品牌ID',
+    user_id INT UNSIGNED NOT NULL COMMENT '用户ID',
+    created_by INT UNSIGNED NOT NULL COMMENT '添加人ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '添加时间',
+
+    PRIMARY KEY (brand_id, user_id),
+    INDEX idx_brand_admins_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='品牌管理员表';
+
+ALTER TABLE business
+    ADD COLUMN brand_id INT UNSIGNED NULL COMMENT '所属品牌ID（为空表示独立商家）',
+    ADD INDEX idx_business_brand_id (brand_id),
+    MODIFY COLUMN email VARCHAR(255) NOT NULL DEFAULT '' COMMENT '邮箱（未删除商家中唯一），品牌门店可为空',
+    DROP INDEX uk_business_email,
+    ADD UNIQUE INDEX uk_business_email ((NULLIF(email, '')), delete_marker);
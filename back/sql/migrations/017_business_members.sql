@@ -0,0 +1,24 @@
+-- 商家成员：用户在某个商家的角色（owner 店主 / manager 店长 / cashier 收银员 / viewer 观察者），同一用户在不同商家可以有不同角色
+-- 商家维护接口按成员角色授权，系统管理员与商家所属品牌的管理员拥有全部权限；被邀请人接受邀请前没有任何权限
+-- 已有商家以首个修订版本（create）的操作人作为店主
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS business_members (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    user_id INT UNSIGNED NOT NULL COMMENT '用户ID',
+    role VARCHAR(20) NOT NULL COMMENT '角色（owner, manager, cashier, viewer）',
+    status VARCHAR(20) NOT NULL DEFAULT 'invited' COMMENT '状态（invited 已邀请, active 已加入）',
+    invited_by INT UNSIGNED NULL COMMENT '邀请人ID（创建商家时自动成为店主的为空）',
+    joined_at DATETIME NULL COMMENT '接受邀请时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '邀请时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE INDEX uk_business_member (business_id, user_id),
+    INDEX idx_business_members_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家成员表';
+
+INSERT IGNORE INTO business_members (business_id, user_id, role, status, joined_at)
+SELECT business_id, actor_id, 'owner', 'active', created_at
+FROM business_revisions
+WHERE action = 'create' AND actor_id > 0;
@@ -0,0 +1,31 @@
+-- 商家资质证件：营业执照、食品经营许可证、身份证件等，文件保存在媒体存储的 documents/ 前缀下且不公开
+-- 到期检查任务标记即将到期的证件（expiry_flagged_at），将过期证件标记为 expired，并可按配置暂停必备证件缺失的商家
+-- 商家彻底删除后证件记录作为合规记录保留
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS business_documents (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    type VARCHAR(30) NOT NULL COMMENT '证件类型（business_license, food_permit, id_card, other）',
+    number VARCHAR(100) NOT NULL COMMENT '证件编号',
+    issuing_authority VARCHAR(255) NOT NULL DEFAULT '' COMMENT '发证机关',
+    valid_from CHAR(10) NOT NULL DEFAULT '' COMMENT '有效期起（YYYY-MM-DD）',
+    valid_until CHAR(10) NOT NULL DEFAULT '' COMMENT '有效期至（YYYY-MM-DD），为空表示长期有效',
+    storage_key VARCHAR(255) NOT NULL COMMENT '文件在存储中的键',
+    filename VARCHAR(255) NOT NULL DEFAULT '' COMMENT '原始文件名',
+    content_type VARCHAR(100) NOT NULL COMMENT '文件类型',
+    size BIGINT NOT NULL COMMENT '文件大小（字节）',
+    checksum CHAR(64) NOT NULL COMMENT 'SHA-256',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '审核状态（pending, verified, rejected, expired）',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    reviewed_by INT UNSIGNED NULL COMMENT '审核人ID',
+    reviewed_at DATETIME NULL COMMENT '审核时间',
+    expiry_flagged_at DATETIME NULL COMMENT '被标记为即将到期的时间',
+    uploaded_by INT UNSIGNED NOT NULL COMMENT '上传人ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '上传时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    INDEX idx_business_documents_business_id (business_id),
+    INDEX idx_business_documents_valid_until (valid_until),
+    INDEX idx_business_documents_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家资质证件表';
@@ -0,0 +1,29 @@
+-- 商家合同：有效期内的佣金（固定比例或阶梯比例）、固定费用与结算账期，已签署的合同 PDF 保存在媒体存储的 contracts/ 前缀下且不公开
+-- 同一商家的合同有效期不能重叠（end_date 为空表示长期有效），写入时锁定商家行串行检查
+-- 商家彻底删除后合同记录作为财务记录保留
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS business_contracts (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    contract_no VARCHAR(64) NOT NULL COMMENT '合同编号',
+    start_date CHAR(10) NOT NULL COMMENT '生效日期（YYYY-MM-DD）',
+    end_date CHAR(10) NOT NULL DEFAULT '' COMMENT '截止日期（YYYY-MM-DD，含当天），为空表示长期有效',
+    commission_type VARCHAR(20) NOT NULL COMMENT '佣金计算方式（percentage, tiered）',
+    commission_rate DECIMAL(5,2) NOT NULL DEFAULT 0 COMMENT '佣金比例（百分比），固定比例时使用',
+    commission_tiers JSON NULL COMMENT '阶梯佣金 [{minAmount, rate}]，交易额单位为分，阶梯计费时使用',
+    fixed_fee BIGINT NOT NULL DEFAULT 0 COMMENT '固定费用（分）',
+    fixed_fee_period VARCHAR(20) NOT NULL DEFAULT '' COMMENT '固定费用收取周期（once, monthly, yearly）',
+    settlement_cycle VARCHAR(20) NOT NULL COMMENT '结算周期（daily, weekly, monthly）',
+    payment_days INT NOT NULL DEFAULT 0 COMMENT '账期：结算周期结束后多少天内付款',
+    notes TEXT NULL COMMENT '备注',
+    attachment_key VARCHAR(255) NOT NULL DEFAULT '' COMMENT '已签署合同 PDF 在存储中的键',
+    attachment_filename VARCHAR(255) NOT NULL DEFAULT '' COMMENT '附件原始文件名',
+    attachment_size BIGINT NOT NULL DEFAULT 0 COMMENT '附件大小（字节）',
+    attachment_checksum CHAR(64) NOT NULL DEFAULT '' COMMENT '附件 SHA-256',
+    created_by INT UNSIGNED NOT NULL COMMENT '创建人ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    INDEX idx_business_contracts_period (business_id, start_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家合同表';
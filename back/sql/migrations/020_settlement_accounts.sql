@@ -0,0 +1,45 @@
+-- 商家结算账户：银行账号以 AES-256-GCM 加密保存（密钥为 SETTLEMENT_ENCRYPTION_KEY，附加数据绑定商家ID），另存后四位用于脱敏显示
+-- 账户只能通过变更申请修改，申请由申请人以外的管理员审批后生效；变更申请表同时作为账户的变更历史
+-- 同一商家同时只能有一个待审批的申请，创建申请时锁定商家行串行检查
+-- 商家从回收站彻底删除时一并删除结算账户及全部变更申请，加密的银行账号不单独保留
+USE merchant_admin;
+
+CREATE TABLE IF NOT EXISTS settlement_accounts (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    account_name VARCHAR(100) NOT NULL COMMENT '户名',
+    bank_name VARCHAR(100) NOT NULL COMMENT '开户银行',
+    branch_name VARCHAR(255) NOT NULL DEFAULT '' COMMENT '开户支行',
+    account_number_cipher VARCHAR(255) NOT NULL COMMENT '加密后的账号',
+    account_last4 VARCHAR(4) NOT NULL COMMENT '账号后四位',
+    change_id INT UNSIGNED NOT NULL COMMENT '生效的变更申请ID',
+    approved_by INT UNSIGNED NOT NULL COMMENT '审批人ID',
+    approved_at DATETIME NOT NULL COMMENT '审批时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_settlement_accounts_business_id (business_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家结算账户表';
+
+CREATE TABLE IF NOT EXISTS settlement_account_changes (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    business_id INT NOT NULL COMMENT '商家ID',
+    account_name VARCHAR(100) NOT NULL COMMENT '户名',
+    bank_name VARCHAR(100) NOT NULL COMMENT '开户银行',
+    branch_name VARCHAR(255) NOT NULL DEFAULT '' COMMENT '开户支行',
+    account_number_cipher VARCHAR(255) NOT NULL COMMENT '加密后的账号',
+    account_last4 VARCHAR(4) NOT NULL COMMENT '账号后四位',
+    reason TEXT NULL COMMENT '变更说明',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '申请状态（pending, approved, rejected, cancelled）',
+    requested_by INT UNSIGNED NOT NULL COMMENT '申请人ID',
+    reviewed_by INT UNSIGNED NULL COMMENT '审批人ID（撤回时为撤回人）',
+    reviewed_at DATETIME NULL COMMENT '处理时间',
+    review_note TEXT NULL COMMENT '驳回原因',
+    previous_account_name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '变更前户名（通过时记录）',
+    previous_bank_name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '变更前开户银行',
+    previous_last4 VARCHAR(4) NOT NULL DEFAULT '' COMMENT '变更前账号后四位',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '申请时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    INDEX idx_settlement_changes_business_status (business_id, status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='商家结算账户变更申请表';